@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 常用原子操作脚本
+// 每个脚本只访问一个键，集群模式下可以直接使用；键同样会添加KeyPrefix
+
+var compareAndSetScript = NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[2], 'KEEPTTL')
+end
+return 1
+`)
+
+var compareAndDeleteScript = NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+var getAndExpireScript = NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return value
+`)
+
+var setIfVersionGreaterScript = NewScript(`
+local current = tonumber(redis.call('HGET', KEYS[1], 'version'))
+if current and current >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'version', ARGV[1], 'value', ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+var incrWithCapScript = NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local delta = tonumber(ARGV[1])
+if current + delta > tonumber(ARGV[2]) then
+	return {0, current}
+end
+local value = redis.call('INCRBY', KEYS[1], delta)
+if tonumber(ARGV[3]) > 0 and redis.call('PTTL', KEYS[1]) == -1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return {1, value}
+`)
+
+var hSetWithTTLScript = NewScript(`
+local ttl = tonumber(ARGV[3])
+if ttl <= 0 then
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+	redis.call('HDEL', KEYS[1], ARGV[4] .. ARGV[1])
+	redis.call('PERSIST', KEYS[1])
+	return 1
+end
+local now = redis.call('TIME')
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2], ARGV[4] .. ARGV[1], string.format('%.0f', ms + ttl))
+local pttl = redis.call('PTTL', KEYS[1])
+if existed == 0 or (pttl >= 0 and pttl < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+var hGetWithTTLScript = NewScript(`
+local value = redis.call('HGET', KEYS[1], ARGV[1])
+if not value then
+	return false
+end
+local deadline = tonumber(redis.call('HGET', KEYS[1], ARGV[2] .. ARGV[1]))
+if deadline then
+	local now = redis.call('TIME')
+	local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+	if deadline <= ms then
+		redis.call('HDEL', KEYS[1], ARGV[1], ARGV[2] .. ARGV[1])
+		return false
+	end
+end
+return value
+`)
+
+// HashFieldTTLPrefix 哈希字段过期时间的辅助字段前缀
+// HSetWithTTL会写入名为HashFieldTTLPrefix+field的字段保存过期时间，HGetAll等命令会返回这些字段
+const HashFieldTTLPrefix = "__ttl:"
+
+// CompareAndSet 键的当前值等于expected时设置为value，返回是否设置成功
+// expiration为0时保留键原有的过期时间（需要Redis 6.0+）
+func CompareAndSet(ctx context.Context, client Client, key, expected string, value interface{}, expiration time.Duration) (bool, error) {
+	return compareAndSetScript.Run(ctx, client, []string{key}, expected, value, expiration.Milliseconds()).Bool()
+}
+
+// CompareAndDelete 键的当前值等于expected时删除，返回是否删除成功，常用于释放分布式锁
+func CompareAndDelete(ctx context.Context, client Client, key, expected string) (bool, error) {
+	return compareAndDeleteScript.Run(ctx, client, []string{key}, expected).Bool()
+}
+
+// GetAndExpire 获取值并重新设置过期时间，键不存在时返回ErrKeyNotFound
+func GetAndExpire(ctx context.Context, client Client, key string, expiration time.Duration) (string, error) {
+	val, err := getAndExpireScript.Run(ctx, client, []string{key}, expiration.Milliseconds()).Text()
+	if err == redis.Nil {
+		return "", ErrKeyNotFound
+	}
+	return val, err
+}
+
+// SetIfVersionGreater 版本号大于当前版本时写入值，返回是否写入成功
+// 值和版本号保存在哈希表的value和version字段中，可通过GetVersioned读取
+// expiration大于0时重新设置过期时间
+func SetIfVersionGreater(ctx context.Context, client Client, key string, version int64, value interface{}, expiration time.Duration) (bool, error) {
+	return setIfVersionGreaterScript.Run(ctx, client, []string{key}, version, value, expiration.Milliseconds()).Bool()
+}
+
+// GetVersioned 读取SetIfVersionGreater写入的值和版本号，键不存在时返回ErrKeyNotFound
+func GetVersioned(ctx context.Context, client Client, key string) (string, int64, error) {
+	vals, err := client.HMGet(ctx, key, "value", "version")
+	if err != nil {
+		return "", 0, err
+	}
+	if len(vals) != 2 || vals[0] == nil || vals[1] == nil {
+		return "", 0, ErrKeyNotFound
+	}
+
+	value, ok := vals[0].(string)
+	if !ok {
+		return "", 0, ErrInvalidType
+	}
+	versionStr, ok := vals[1].(string)
+	if !ok {
+		return "", 0, ErrInvalidType
+	}
+	version, err := strconv.ParseInt(versionStr, 10, 64)
+	if err != nil {
+		return "", 0, ErrInvalidType
+	}
+	return value, version, nil
+}
+
+// IncrWithCap 增加后的值不超过limit时增加delta，返回当前值以及是否增加成功
+// 增加失败时返回增加前的值；键新建时按expiration设置过期时间，为0时不过期
+func IncrWithCap(ctx context.Context, client Client, key string, delta, limit int64, expiration time.Duration) (int64, bool, error) {
+	vals, err := incrWithCapScript.Run(ctx, client, []string{key}, delta, limit, expiration.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	if len(vals) != 2 {
+		return 0, false, ErrInvalidType
+	}
+	return vals[1], vals[0] == 1, nil
+}
+
+// HSetWithTTL 设置哈希字段并指定字段的过期时间，过期的字段在HGetWithTTL读取时删除，ttl为0时字段不过期
+// 哈希表新建或已有过期时间时，会将其过期时间延长到不早于字段的过期时间；
+// 写入不过期的字段时去掉哈希表的过期时间，此后哈希表不再过期，直到被删除
+func HSetWithTTL(ctx context.Context, client Client, key, field string, value interface{}, ttl time.Duration) error {
+	return hSetWithTTLScript.Run(ctx, client, []string{key}, field, value, ttl.Milliseconds(), HashFieldTTLPrefix).Err()
+}
+
+// HGetWithTTL 获取HSetWithTTL设置的哈希字段，字段不存在或已过期时返回ErrKeyNotFound
+func HGetWithTTL(ctx context.Context, client Client, key, field string) (string, error) {
+	val, err := hGetWithTTLScript.Run(ctx, client, []string{key}, field, HashFieldTTLPrefix).Text()
+	if err == redis.Nil {
+		return "", ErrKeyNotFound
+	}
+	return val, err
+}
+
+// HDelWithTTL 删除HSetWithTTL设置的哈希字段及其过期时间
+func HDelWithTTL(ctx context.Context, client Client, key string, fields ...string) error {
+	all := make([]string, 0, len(fields)*2)
+	for _, field := range fields {
+		all = append(all, field, HashFieldTTLPrefix+field)
+	}
+	_, err := client.HDel(ctx, key, all...)
+	return err
+}
@@ -0,0 +1,318 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchingOptions 自动批量客户端配置
+type BatchingOptions struct {
+	// 收集命令的最长等待时间，默认500微秒
+	Window time.Duration
+	// 单个批次的最大命令数，达到后立即发送，默认100
+	MaxBatch int
+}
+
+// BatchingStats 自动批量统计
+type BatchingStats struct {
+	// 发送的管道数量
+	Batches int64
+	// 通过管道发送的命令数量
+	Commands int64
+}
+
+// BatchingClient 自动批量客户端
+// 并发的Get、Set、HGet、HSet、HGetAll、Incr、TTL调用在Window内或攒够MaxBatch条后合并为一个管道发送，
+// 再把每条命令的结果返回给各自的调用方；其余方法直接调用被包装的客户端。
+// 集群模式下按键所在分片的主节点分别攒批，每个管道只发往一个节点
+type BatchingClient struct {
+	Client
+
+	cluster  *ClusterClient
+	window   time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	batches map[batchKey]*batch
+	closed  bool
+	wg      sync.WaitGroup
+
+	batchCount atomic.Int64
+	commands   atomic.Int64
+}
+
+// batchKey 批次的分组，执行选项不同的调用不合并到同一个管道
+type batchKey struct {
+	group   string
+	timeout time.Duration
+	policy  RetryPolicy
+}
+
+// batch 等待发送的一批命令
+type batch struct {
+	ops   []*batchOp
+	timer *time.Timer
+	// 携带调用选项的上下文，取自第一个调用方，不随其取消
+	ctx context.Context
+	// 所有调用方中最晚的截止时间，有调用方没有截止时间时为零值
+	deadline time.Time
+}
+
+// batchOp 批次中的一条命令
+type batchOp struct {
+	// 将命令加入管道，结果在管道执行后读取
+	queue func(pipe Pipeliner)
+	done  chan struct{}
+}
+
+// NewBatchingClient 创建自动批量客户端
+func NewBatchingClient(client Client, opts *BatchingOptions) *BatchingClient {
+	c := &BatchingClient{
+		Client:   client,
+		window:   500 * time.Microsecond,
+		maxBatch: 100,
+		batches:  make(map[batchKey]*batch),
+	}
+	if opts != nil {
+		if opts.Window > 0 {
+			c.window = opts.Window
+		}
+		if opts.MaxBatch > 0 {
+			c.maxBatch = opts.MaxBatch
+		}
+	}
+	c.cluster, _ = unwrapClient(client).(*ClusterClient)
+	return c
+}
+
+// Unwrap 获取被包装的客户端
+func (c *BatchingClient) Unwrap() Client {
+	return c.Client
+}
+
+// BatchingStats 获取自动批量统计
+func (c *BatchingClient) BatchingStats() BatchingStats {
+	return BatchingStats{
+		Batches:  c.batchCount.Load(),
+		Commands: c.commands.Load(),
+	}
+}
+
+// Close 发送所有等待中的命令后关闭被包装的客户端
+func (c *BatchingClient) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClientClosed
+	}
+	c.closed = true
+	pending := c.batches
+	c.batches = nil
+	for _, b := range pending {
+		b.timer.Stop()
+		c.wg.Add(1)
+	}
+	c.mu.Unlock()
+
+	for _, b := range pending {
+		c.flush(b)
+	}
+	c.wg.Wait()
+	return c.Client.Close()
+}
+
+// Get 获取字符串值
+func (c *BatchingClient) Get(ctx context.Context, key string) (string, error) {
+	var cmd *StringCmd
+	if err := c.do(ctx, key, func(pipe Pipeliner) {
+		cmd = pipe.Get(ctx, key)
+	}); err != nil {
+		return "", err
+	}
+	return cmd.Result()
+}
+
+// Set 设置字符串值
+func (c *BatchingClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	var cmd *StatusCmd
+	if err := c.do(ctx, key, func(pipe Pipeliner) {
+		cmd = pipe.Set(ctx, key, value, expiration)
+	}); err != nil {
+		return err
+	}
+	return cmd.Err()
+}
+
+// HGet 获取哈希表字段值
+func (c *BatchingClient) HGet(ctx context.Context, key, field string) (string, error) {
+	var cmd *StringCmd
+	if err := c.do(ctx, key, func(pipe Pipeliner) {
+		cmd = pipe.HGet(ctx, key, field)
+	}); err != nil {
+		return "", err
+	}
+	return cmd.Result()
+}
+
+// HSet 设置哈希表字段值
+func (c *BatchingClient) HSet(ctx context.Context, key, field string, value interface{}) error {
+	var cmd *IntCmd
+	if err := c.do(ctx, key, func(pipe Pipeliner) {
+		cmd = pipe.HSet(ctx, key, field, value)
+	}); err != nil {
+		return err
+	}
+	return cmd.Err()
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (c *BatchingClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	var cmd *MapStringStringCmd
+	if err := c.do(ctx, key, func(pipe Pipeliner) {
+		cmd = pipe.HGetAll(ctx, key)
+	}); err != nil {
+		return nil, err
+	}
+	return cmd.Result()
+}
+
+// Incr 递增计数器
+func (c *BatchingClient) Incr(ctx context.Context, key string) (int64, error) {
+	var cmd *IntCmd
+	if err := c.do(ctx, key, func(pipe Pipeliner) {
+		cmd = pipe.Incr(ctx, key)
+	}); err != nil {
+		return 0, err
+	}
+	return cmd.Result()
+}
+
+// TTL 获取键的剩余生存时间
+func (c *BatchingClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	var cmd *DurationCmd
+	if err := c.do(ctx, key, func(pipe Pipeliner) {
+		cmd = pipe.TTL(ctx, key)
+	}); err != nil {
+		return 0, err
+	}
+	return cmd.Result()
+}
+
+// do 将命令加入键所在分组的批次并等待批次发送完成
+// ctx结束时立即返回ctx.Err()，但已加入批次的命令仍会被发送
+func (c *BatchingClient) do(ctx context.Context, key string, queue func(pipe Pipeliner)) error {
+	op := &batchOp{
+		queue: queue,
+		done:  make(chan struct{}),
+	}
+	k, ok := newBatchKey(ctx, shardGroup(ctx, c.cluster, key))
+	deadline, hasDeadline := ctx.Deadline()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClientClosed
+	}
+	b := c.batches[k]
+	if b == nil || !ok {
+		b = &batch{ctx: context.WithoutCancel(ctx), deadline: deadline}
+		if ok {
+			c.batches[k] = b
+			b.timer = time.AfterFunc(c.window, func() {
+				c.flushGroup(k, b)
+			})
+		}
+	} else if !b.deadline.IsZero() && (!hasDeadline || deadline.After(b.deadline)) {
+		b.deadline = deadline
+	}
+	b.ops = append(b.ops, op)
+	full := !ok || len(b.ops) >= c.maxBatch
+	if full {
+		if ok {
+			delete(c.batches, k)
+			b.timer.Stop()
+		}
+		c.wg.Add(1)
+	}
+	c.mu.Unlock()
+
+	if full {
+		c.flush(b)
+	}
+
+	select {
+	case <-op.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shardGroup 获取键所在的分组，集群模式下为分片主节点地址，非集群模式或查询拓扑失败时不区分分片
+func shardGroup(ctx context.Context, cluster *ClusterClient, key string) string {
+	if cluster == nil {
+		return ""
+	}
+	shard, err := cluster.SlotOwner(ctx, key)
+	if err != nil {
+		return ""
+	}
+	return shard.Master.Addr
+}
+
+// newBatchKey 按分组和执行时生效的调用选项（超时和重试策略）创建批次的键
+// 重试策略不可比较时返回false，该调用单独发送
+func newBatchKey(ctx context.Context, group string) (batchKey, bool) {
+	k := batchKey{
+		group:   group,
+		timeout: callOptionsFromContext(ctx).timeout,
+		policy:  retryPolicyFromContext(ctx, nil),
+	}
+	if k.policy != nil && !reflect.TypeOf(k.policy).Comparable() {
+		return k, false
+	}
+	return k, true
+}
+
+// flushGroup 窗口到期时发送批次，批次已因攒满被发送时忽略
+func (c *BatchingClient) flushGroup(k batchKey, b *batch) {
+	c.mu.Lock()
+	if c.batches[k] != b {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.batches, k)
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	c.flush(b)
+}
+
+// flush 将批次作为一个管道发送，每条命令的结果和错误由各自的调用方读取
+// 管道在批次的调用选项下执行，截止时间为调用方中最晚的截止时间
+func (c *BatchingClient) flush(b *batch) {
+	defer c.wg.Done()
+
+	ctx := b.ctx
+	if !b.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, b.deadline)
+		defer cancel()
+	}
+
+	pipe := c.Client.Pipeline()
+	for _, op := range b.ops {
+		op.queue(pipe)
+	}
+	// Exec返回的是第一条失败命令的错误（如未命中），每条命令的错误已经记录在各自的命令上
+	_, _ = pipe.Exec(ctx)
+
+	c.batchCount.Add(1)
+	c.commands.Add(int64(len(b.ops)))
+	for _, op := range b.ops {
+		close(op.done)
+	}
+}
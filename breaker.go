@@ -0,0 +1,290 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	// BreakerClosed 关闭状态，请求正常通过
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 打开状态，请求被快速拒绝
+	BreakerOpen
+	// BreakerHalfOpen 半开状态，允许少量探测请求通过
+	BreakerHalfOpen
+)
+
+// String 返回状态名称
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// 熔断器默认参数
+const (
+	defaultBreakerWindow              = 10 * time.Second
+	defaultBreakerMinRequests         = 20
+	defaultBreakerFailureRate         = 0.5
+	defaultBreakerOpenTimeout         = 5 * time.Second
+	defaultBreakerHalfOpenMaxRequests = 1
+)
+
+// CircuitBreaker 熔断器
+// 在统计窗口内按错误率和慢调用率判断是否熔断，熔断期间请求直接返回ErrCircuitOpen
+type CircuitBreaker struct {
+	name          string
+	config        CircuitBreakerConfig
+	onStateChange func(name string, from, to BreakerState)
+
+	mu                sync.Mutex
+	state             BreakerState
+	windowStart       time.Time
+	requests          int
+	failures          int
+	slowCalls         int
+	openedAt          time.Time
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+}
+
+// NewCircuitBreaker 创建熔断器，未设置的参数使用默认值
+func NewCircuitBreaker(name string, config CircuitBreakerConfig) *CircuitBreaker {
+	if config.Window <= 0 {
+		config.Window = defaultBreakerWindow
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = defaultBreakerMinRequests
+	}
+	if config.FailureRateThreshold <= 0 {
+		config.FailureRateThreshold = defaultBreakerFailureRate
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = defaultBreakerHalfOpenMaxRequests
+	}
+
+	return &CircuitBreaker{
+		name:        name,
+		config:      config,
+		windowStart: time.Now(),
+	}
+}
+
+// OnStateChange 注册状态变化回调
+func (b *CircuitBreaker) OnStateChange(fn func(name string, from, to BreakerState)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onStateChange = fn
+}
+
+// Name 获取熔断器名称
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// State 获取当前状态
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+	return b.state
+}
+
+// Allow 判断请求是否允许通过，不允许时返回ErrCircuitOpen
+// 允许通过的请求必须在完成后调用Record
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	from, to, changed := b.advance(time.Now())
+
+	var err error
+	switch b.state {
+	case BreakerOpen:
+		err = ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.halfOpenInFlight >= b.config.HalfOpenMaxRequests {
+			err = ErrCircuitOpen
+		} else {
+			b.halfOpenInFlight++
+		}
+	}
+	fn := b.onStateChange
+	b.mu.Unlock()
+
+	if changed && fn != nil {
+		fn(b.name, from, to)
+	}
+	return err
+}
+
+// Record 记录请求结果
+func (b *CircuitBreaker) Record(err error, duration time.Duration) {
+	failure := isBreakerFailure(err)
+	slow := b.config.SlowCallDuration > 0 && duration >= b.config.SlowCallDuration
+
+	b.mu.Lock()
+	now := time.Now()
+	from, to, changed := b.advance(now)
+
+	switch b.state {
+	case BreakerClosed:
+		b.requests++
+		if failure {
+			b.failures++
+		}
+		if slow {
+			b.slowCalls++
+		}
+		if b.shouldTrip() {
+			from, to, changed = b.setState(BreakerOpen, now)
+		}
+	case BreakerHalfOpen:
+		if b.halfOpenInFlight > 0 {
+			b.halfOpenInFlight--
+		}
+		if failure || slow {
+			from, to, changed = b.setState(BreakerOpen, now)
+		} else {
+			b.halfOpenSuccesses++
+			if b.halfOpenSuccesses >= b.config.HalfOpenMaxRequests {
+				from, to, changed = b.setState(BreakerClosed, now)
+			}
+		}
+	}
+	fn := b.onStateChange
+	b.mu.Unlock()
+
+	if changed && fn != nil {
+		fn(b.name, from, to)
+	}
+}
+
+// advance 根据当前时间推进窗口和状态，调用方需持有锁
+func (b *CircuitBreaker) advance(now time.Time) (from, to BreakerState, changed bool) {
+	switch b.state {
+	case BreakerClosed:
+		if now.Sub(b.windowStart) >= b.config.Window {
+			b.resetCounts(now)
+		}
+	case BreakerOpen:
+		if now.Sub(b.openedAt) >= b.config.OpenTimeout {
+			return b.setState(BreakerHalfOpen, now)
+		}
+	}
+	return b.state, b.state, false
+}
+
+// shouldTrip 判断窗口内统计是否达到熔断条件，调用方需持有锁
+func (b *CircuitBreaker) shouldTrip() bool {
+	if b.requests < b.config.MinRequests {
+		return false
+	}
+	total := float64(b.requests)
+	if float64(b.failures)/total >= b.config.FailureRateThreshold {
+		return true
+	}
+	if b.config.SlowCallDuration > 0 && b.config.SlowCallRateThreshold > 0 &&
+		float64(b.slowCalls)/total >= b.config.SlowCallRateThreshold {
+		return true
+	}
+	return false
+}
+
+// setState 切换状态，调用方需持有锁
+func (b *CircuitBreaker) setState(state BreakerState, now time.Time) (from, to BreakerState, changed bool) {
+	from = b.state
+	if from == state {
+		return from, state, false
+	}
+
+	b.state = state
+	b.halfOpenInFlight = 0
+	b.halfOpenSuccesses = 0
+	b.resetCounts(now)
+	if state == BreakerOpen {
+		b.openedAt = now
+	}
+	return from, state, true
+}
+
+// resetCounts 清空窗口计数，调用方需持有锁
+func (b *CircuitBreaker) resetCounts(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+	b.slowCalls = 0
+}
+
+// isBreakerFailure 判断错误是否计入熔断失败
+// 键不存在、调用方取消以及普通的服务端错误回复不代表Redis不可用
+func isBreakerFailure(err error) bool {
+	if err == nil || err == redis.Nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return redis.HasErrorPrefix(err, "LOADING") ||
+			redis.HasErrorPrefix(err, "CLUSTERDOWN") ||
+			redis.HasErrorPrefix(err, "MASTERDOWN")
+	}
+	return true
+}
+
+// breakerHook 将熔断器接入go-redis命令处理流程
+type breakerHook struct {
+	breaker *CircuitBreaker
+}
+
+// DialHook 建立连接时不做处理
+func (h breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 单条命令经过熔断器
+func (h breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.breaker.Allow(); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.breaker.Record(err, time.Since(start))
+		return err
+	}
+}
+
+// ProcessPipelineHook 整个管道作为一次请求经过熔断器
+func (h breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.breaker.Allow(); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.breaker.Record(err, time.Since(start))
+		return err
+	}
+}
@@ -0,0 +1,425 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BulkOptions 批量写入配置
+type BulkOptions struct {
+	// 单个管道的最大命令数，默认500
+	BatchSize int
+	// 单条命令的最大元素数（哈希字段、有序集合成员、集合成员、列表元素），超过时拆分为多条命令，默认1000
+	MaxArgs int
+	// 并发执行的管道数，默认4
+	Concurrency int
+	// 每个管道执行完成后的进度回调，可能被并发调用
+	OnProgress func(progress BulkProgress)
+	// 条目写入失败的回调，可能被并发调用；为空时失败记录在BulkResult.Failures中
+	OnFailure func(key string, err error)
+}
+
+// BulkProgress 批量写入进度，按条目计数
+type BulkProgress struct {
+	// 写入成功的条目数
+	Written int64
+	// 写入失败的条目数
+	Failed int64
+}
+
+// BulkFailure 写入失败的条目
+type BulkFailure struct {
+	Key string
+	Err error
+}
+
+// BulkResult 批量写入结果
+type BulkResult struct {
+	// 写入成功的条目数
+	Written int64
+	// 写入失败的条目数
+	Failed int64
+	// 失败的条目，设置了OnFailure时为空
+	Failures []BulkFailure
+}
+
+// bulkKind 批量写入条目类型
+type bulkKind int
+
+const (
+	bulkSet bulkKind = iota
+	bulkHSet
+	bulkZAdd
+	bulkSAdd
+	bulkRPush
+)
+
+// BulkItem 批量写入的条目，通过BulkSet、BulkHSet等函数创建
+type BulkItem struct {
+	kind       bulkKind
+	key        string
+	value      interface{}
+	args       []interface{}
+	expiration time.Duration
+}
+
+// BulkSet 创建设置字符串值的条目，expiration为0时使用DefaultTTL
+func BulkSet(key string, value interface{}, expiration time.Duration) BulkItem {
+	return BulkItem{kind: bulkSet, key: key, value: value, expiration: expiration}
+}
+
+// BulkHSet 创建设置哈希表字段的条目，expiration为0时使用DefaultTTL
+func BulkHSet(key string, fields map[string]interface{}, expiration time.Duration) BulkItem {
+	args := make([]interface{}, 0, len(fields)*2)
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+	return BulkItem{kind: bulkHSet, key: key, args: args, expiration: expiration}
+}
+
+// BulkZAdd 创建向有序集合添加成员的条目，expiration为0时使用DefaultTTL
+func BulkZAdd(key string, expiration time.Duration, members ...ZMember) BulkItem {
+	args := make([]interface{}, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+	return BulkItem{kind: bulkZAdd, key: key, args: args, expiration: expiration}
+}
+
+// BulkSAdd 创建向集合添加成员的条目，expiration为0时使用DefaultTTL
+func BulkSAdd(key string, expiration time.Duration, members ...interface{}) BulkItem {
+	return BulkItem{kind: bulkSAdd, key: key, args: members, expiration: expiration}
+}
+
+// BulkRPush 创建从列表右侧推入元素的条目，expiration为0时使用DefaultTTL
+func BulkRPush(key string, expiration time.Duration, values ...interface{}) BulkItem {
+	return BulkItem{kind: bulkRPush, key: key, args: values, expiration: expiration}
+}
+
+// Key 获取条目的键
+func (item BulkItem) Key() string {
+	return item.key
+}
+
+// chunk 单条命令的元素数，哈希表的一个元素为字段和值两个参数
+func (item BulkItem) chunk(maxArgs int) int {
+	if item.kind == bulkHSet {
+		return maxArgs * 2
+	}
+	return maxArgs
+}
+
+// commands 条目拆分后的命令数
+func (item BulkItem) commands(maxArgs int, config *Config) int {
+	if item.kind == bulkSet {
+		return 1
+	}
+	chunk := item.chunk(maxArgs)
+	n := (len(item.args) + chunk - 1) / chunk
+	if bulkTTL(config, item.expiration) > 0 {
+		n++
+	}
+	return n
+}
+
+// queue 将条目拆分为命令加入管道，集合类条目在最后追加EXPIRE
+func (item BulkItem) queue(ctx context.Context, pipe Pipeliner, maxArgs int, config *Config) []interface{ Err() error } {
+	if item.kind == bulkSet {
+		return []interface{ Err() error }{pipe.Set(ctx, item.key, item.value, item.expiration)}
+	}
+
+	var cmds []interface{ Err() error }
+	chunk := item.chunk(maxArgs)
+	for start := 0; start < len(item.args); start += chunk {
+		end := start + chunk
+		if end > len(item.args) {
+			end = len(item.args)
+		}
+		args := item.args[start:end]
+
+		switch item.kind {
+		case bulkHSet:
+			cmds = append(cmds, pipe.HMSet(ctx, item.key, args...))
+		case bulkZAdd:
+			members := make([]ZMember, len(args))
+			for i, arg := range args {
+				members[i] = arg.(ZMember)
+			}
+			cmds = append(cmds, pipe.ZAdd(ctx, item.key, members...))
+		case bulkSAdd:
+			cmds = append(cmds, pipe.SAdd(ctx, item.key, args...))
+		case bulkRPush:
+			cmds = append(cmds, pipe.RPush(ctx, item.key, args...))
+		}
+	}
+	if ttl := bulkTTL(config, item.expiration); ttl > 0 {
+		cmds = append(cmds, pipe.Expire(ctx, item.key, ttl))
+	}
+	return cmds
+}
+
+// bulkTTL 集合类条目的过期时间，未指定时使用DefaultTTL
+func bulkTTL(config *Config, expiration time.Duration) time.Duration {
+	if config == nil {
+		return expiration
+	}
+	return config.GetTTL(expiration)
+}
+
+// clientConfig 获取客户端的配置，自定义实现可以通过Config方法提供配置，无法获取时返回nil
+func clientConfig(client Client) *Config {
+	switch c := unwrapClient(client).(type) {
+	case *SingleClient:
+		return c.config
+	case *ClusterClient:
+		return c.config
+	case *SentinelClient:
+		return c.config
+	case interface{ Config() *Config }:
+		return c.Config()
+	}
+	return nil
+}
+
+// BulkWriter 批量写入器
+// 条目按BatchSize切分为多个管道并发执行，过大的哈希表、集合等按MaxArgs拆分为多条命令，避免单条命令阻塞Redis；
+// 同一条目的命令总在同一个管道中按顺序执行，不同条目之间的执行顺序不保证。
+// 集群模式下按键所在分片的主节点分别组批，每个管道只发往一个节点
+type BulkWriter struct {
+	client  Client
+	cluster *ClusterClient
+	config  *Config
+
+	batchSize  int
+	maxArgs    int
+	onProgress func(progress BulkProgress)
+	onFailure  func(key string, err error)
+
+	mu      sync.Mutex
+	pending map[string]*bulkBatch
+	closed  bool
+	jobs    chan *bulkBatch
+	workers sync.WaitGroup
+	// 已取出但尚未执行完的批次数，由mu保护；Add可能与Flush并发，不能使用WaitGroup
+	inflight int
+	idle     *sync.Cond
+
+	written    atomic.Int64
+	failed     atomic.Int64
+	failuresMu sync.Mutex
+	failures   []BulkFailure
+}
+
+// bulkBatch 一个管道中的条目
+type bulkBatch struct {
+	ctx      context.Context
+	entries  []bulkEntry
+	commands int
+}
+
+// bulkEntry 条目及其加入时的上下文，上下文中的调用选项对条目生效
+type bulkEntry struct {
+	ctx  context.Context
+	item BulkItem
+}
+
+// NewBulkWriter 创建批量写入器，使用完毕后需要调用Close
+func NewBulkWriter(client Client, opts *BulkOptions) *BulkWriter {
+	w := &BulkWriter{
+		client:    client,
+		config:    clientConfig(client),
+		batchSize: 500,
+		maxArgs:   1000,
+		pending:   make(map[string]*bulkBatch),
+	}
+	w.idle = sync.NewCond(&w.mu)
+	concurrency := 4
+	if opts != nil {
+		if opts.BatchSize > 0 {
+			w.batchSize = opts.BatchSize
+		}
+		if opts.MaxArgs > 0 {
+			w.maxArgs = opts.MaxArgs
+		}
+		if opts.Concurrency > 0 {
+			concurrency = opts.Concurrency
+		}
+		w.onProgress = opts.OnProgress
+		w.onFailure = opts.OnFailure
+	}
+	w.cluster, _ = unwrapClient(client).(*ClusterClient)
+
+	w.jobs = make(chan *bulkBatch, concurrency)
+	w.workers.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer w.workers.Done()
+			for b := range w.jobs {
+				w.exec(b)
+			}
+		}()
+	}
+	return w
+}
+
+// Add 添加条目，攒够BatchSize条命令后发送，所有并发管道都在执行时阻塞
+// 发送前ctx结束时返回ctx.Err()，未发送的条目按失败处理
+func (w *BulkWriter) Add(ctx context.Context, items ...BulkItem) error {
+	for _, item := range items {
+		group := shardGroup(ctx, w.cluster, item.key)
+
+		w.mu.Lock()
+		if w.closed {
+			w.mu.Unlock()
+			return ErrClientClosed
+		}
+		b := w.pending[group]
+		if b == nil {
+			b = &bulkBatch{}
+			w.pending[group] = b
+		}
+		b.entries = append(b.entries, bulkEntry{ctx: ctx, item: item})
+		b.commands += item.commands(w.maxArgs, w.config)
+		full := b.commands >= w.batchSize
+		if full {
+			delete(w.pending, group)
+			w.inflight++
+		}
+		w.mu.Unlock()
+
+		if full {
+			if err := w.send(ctx, b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush 发送所有未满的批次，并等待已发送的管道执行完成，与Add并发时也等待其间发送的管道
+func (w *BulkWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = make(map[string]*bulkBatch)
+	w.inflight += len(pending)
+	w.mu.Unlock()
+
+	var err error
+	for _, b := range pending {
+		if sendErr := w.send(ctx, b); sendErr != nil {
+			err = sendErr
+		}
+	}
+	w.mu.Lock()
+	for w.inflight > 0 {
+		w.idle.Wait()
+	}
+	w.mu.Unlock()
+	return err
+}
+
+// Close 发送剩余条目，等待所有管道执行完成并返回写入结果
+func (w *BulkWriter) Close(ctx context.Context) (*BulkResult, error) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil, ErrClientClosed
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	err := w.Flush(ctx)
+	close(w.jobs)
+	w.workers.Wait()
+
+	w.failuresMu.Lock()
+	defer w.failuresMu.Unlock()
+	return &BulkResult{
+		Written:  w.written.Load(),
+		Failed:   w.failed.Load(),
+		Failures: w.failures,
+	}, err
+}
+
+// Progress 获取当前进度
+func (w *BulkWriter) Progress() BulkProgress {
+	return BulkProgress{
+		Written: w.written.Load(),
+		Failed:  w.failed.Load(),
+	}
+}
+
+// send 将批次交给执行管道的协程，ctx结束时批次中的条目按失败处理
+func (w *BulkWriter) send(ctx context.Context, b *bulkBatch) error {
+	b.ctx = ctx
+	select {
+	case w.jobs <- b:
+		return nil
+	case <-ctx.Done():
+		for _, entry := range b.entries {
+			w.fail(entry.item.key, ctx.Err())
+		}
+		w.progress()
+		w.done()
+		return ctx.Err()
+	}
+}
+
+// done 标记一个批次执行完成，没有执行中的批次时唤醒Flush
+func (w *BulkWriter) done() {
+	w.mu.Lock()
+	w.inflight--
+	if w.inflight == 0 {
+		w.idle.Broadcast()
+	}
+	w.mu.Unlock()
+}
+
+// exec 以管道执行批次，条目的任一命令失败时该条目按失败处理
+func (w *BulkWriter) exec(b *bulkBatch) {
+	defer w.done()
+
+	pipe := w.client.Pipeline()
+	cmds := make([][]interface{ Err() error }, len(b.entries))
+	for i, entry := range b.entries {
+		cmds[i] = entry.item.queue(entry.ctx, pipe, w.maxArgs, w.config)
+	}
+	// 管道的错误已经记录在每条命令上
+	_, _ = pipe.Exec(b.ctx)
+
+	for i, entry := range b.entries {
+		var err error
+		for _, cmd := range cmds[i] {
+			if err = cmd.Err(); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			w.fail(entry.item.key, err)
+		} else {
+			w.written.Add(1)
+		}
+	}
+	w.progress()
+}
+
+// fail 记录写入失败的条目
+func (w *BulkWriter) fail(key string, err error) {
+	w.failed.Add(1)
+	if w.onFailure != nil {
+		w.onFailure(key, err)
+		return
+	}
+	w.failuresMu.Lock()
+	w.failures = append(w.failures, BulkFailure{Key: key, Err: err})
+	w.failuresMu.Unlock()
+}
+
+// progress 回调当前进度
+func (w *BulkWriter) progress() {
+	if w.onProgress != nil {
+		w.onProgress(w.Progress())
+	}
+}
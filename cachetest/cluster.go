@@ -0,0 +1,301 @@
+package cachetest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cache"
+)
+
+// clusterSlots 集群的哈希槽数量
+const clusterSlots = 16384
+
+// errCrossSlot 多键命令的键不在同一个哈希槽
+const errCrossSlot = redisError("CROSSSLOT Keys in request don't hash to the same slot")
+
+// Cluster 模拟的Redis集群，用于测试集群客户端的路由和重定向
+// 每个节点是一个数据独立的Server，哈希槽在节点之间平均分配。节点对不属于自己的键返回MOVED，
+// 跨槽的多键命令返回CROSSSLOT；MigrateSlot开始迁移哈希槽，迁移期间源节点对不存在的键返回ASK，
+// 目标节点在ASKING之后接受该槽的命令；MoveSlot将槽中剩余的键转移到目标节点并完成迁移
+type Cluster struct {
+	nodes []*Server
+
+	mu sync.RWMutex
+	// 每个哈希槽所属节点的序号
+	owners [clusterSlots]int
+	// 正在迁移的哈希槽及目标节点的序号
+	migrating map[int]int
+
+	moved atomic.Int64
+	ask   atomic.Int64
+}
+
+// StartCluster 启动n个主节点的集群，opts应用于每个节点的数据
+func StartCluster(n int, opts ...Option) (*Cluster, error) {
+	if n <= 0 {
+		return nil, errors.New("cachetest: cluster needs at least one node")
+	}
+	c := &Cluster{migrating: make(map[int]int)}
+	for i := 0; i < n; i++ {
+		node, err := newServer(NewFake(opts...))
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		node.cluster = c
+		node.index = i
+		c.nodes = append(c.nodes, node)
+	}
+	for slot := range c.owners {
+		c.owners[slot] = slot * n / clusterSlots
+	}
+	for _, node := range c.nodes {
+		node.start()
+	}
+	return c, nil
+}
+
+// Nodes 获取集群的所有节点
+func (c *Cluster) Nodes() []*Server {
+	return c.nodes
+}
+
+// Addrs 获取所有节点的地址
+func (c *Cluster) Addrs() []string {
+	addrs := make([]string, len(c.nodes))
+	for i, node := range c.nodes {
+		addrs[i] = node.Addr()
+	}
+	return addrs
+}
+
+// Config 获取连接集群的配置，键前缀和默认过期时间与第一个节点的Fake一致
+func (c *Cluster) Config() *cache.Config {
+	return &cache.Config{
+		Mode:    cache.ModeCluster,
+		Cluster: &cache.ClusterConfig{Addrs: c.Addrs()},
+		Common:  c.nodes[0].fake.config.Common,
+	}
+}
+
+// NodeForKey 获取键所在哈希槽当前所属的节点
+func (c *Cluster) NodeForKey(key string) *Server {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nodes[c.owners[cache.KeySlot(key)]]
+}
+
+// Advance 将所有节点的时钟向前拨动d
+func (c *Cluster) Advance(d time.Duration) {
+	for _, node := range c.nodes {
+		node.fake.Advance(d)
+	}
+}
+
+// Redirects 获取节点返回的MOVED和ASK重定向次数
+func (c *Cluster) Redirects() (moved, ask int64) {
+	return c.moved.Load(), c.ask.Load()
+}
+
+// MigrateSlot 开始将哈希槽迁移到序号为to的节点
+// 迁移期间槽仍属于源节点，源节点上不存在的键被重定向到目标节点，由MoveSlot完成迁移
+func (c *Cluster) MigrateSlot(slot, to int) error {
+	if err := c.checkSlot(slot, to); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.owners[slot] == to {
+		return fmt.Errorf("cachetest: slot %d is already served by node %d", slot, to)
+	}
+	c.migrating[slot] = to
+	return nil
+}
+
+// MoveSlot 将哈希槽中的键转移到序号为to的节点并修改槽的归属，之后源节点对该槽返回MOVED
+func (c *Cluster) MoveSlot(slot, to int) error {
+	if err := c.checkSlot(slot, to); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.migrating, slot)
+	from := c.owners[slot]
+	if from == to {
+		return nil
+	}
+
+	src, dst := c.nodes[from].fake, c.nodes[to].fake
+	src.mu.Lock()
+	dst.mu.Lock()
+	for key, e := range src.store.data {
+		if cache.KeySlot(key) != slot {
+			continue
+		}
+		if src.store.lookup(key) != nil {
+			dst.store.data[key] = e
+			dst.store.touch(key)
+		}
+		delete(src.store.data, key)
+		src.store.touch(key)
+	}
+	dst.mu.Unlock()
+	src.mu.Unlock()
+
+	c.owners[slot] = to
+	return nil
+}
+
+// Close 关闭所有节点
+func (c *Cluster) Close() error {
+	var err error
+	for _, node := range c.nodes {
+		if closeErr := node.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// checkSlot 检查哈希槽和节点序号
+func (c *Cluster) checkSlot(slot, to int) error {
+	if slot < 0 || slot >= clusterSlots {
+		return fmt.Errorf("cachetest: invalid slot %d", slot)
+	}
+	if to < 0 || to >= len(c.nodes) {
+		return fmt.Errorf("cachetest: invalid node %d", to)
+	}
+	return nil
+}
+
+// route 检查节点能否处理这些键，返回CROSSSLOT、MOVED或ASK错误
+// 调用时持有c.mu的读锁和节点的数据锁
+func (c *Cluster) route(node *Server, keys []string, asking bool) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	slot := cache.KeySlot(keys[0])
+	for _, key := range keys[1:] {
+		if cache.KeySlot(key) != slot {
+			return errCrossSlot
+		}
+	}
+
+	to, migrating := c.migrating[slot]
+	owner := c.owners[slot]
+	if owner == node.index {
+		if migrating {
+			for _, key := range keys {
+				if node.fake.store.lookup(key) == nil {
+					c.ask.Add(1)
+					return redisError(fmt.Sprintf("ASK %d %s", slot, c.nodes[to].Addr()))
+				}
+			}
+		}
+		return nil
+	}
+	if migrating && to == node.index && asking {
+		return nil
+	}
+	c.moved.Add(1)
+	return redisError(fmt.Sprintf("MOVED %d %s", slot, c.nodes[owner].Addr()))
+}
+
+// nodeID 节点的ID，40位十六进制字符串
+func nodeID(index int) string {
+	return fmt.Sprintf("%040x", index+1)
+}
+
+// slotRange 属于同一个节点的连续哈希槽
+type slotRange struct {
+	start, end, owner int
+}
+
+// ranges 按归属划分连续的哈希槽，调用时持有c.mu的读锁
+func (c *Cluster) ranges() []slotRange {
+	var ranges []slotRange
+	for slot, owner := range c.owners {
+		if n := len(ranges); n > 0 && ranges[n-1].owner == owner {
+			ranges[n-1].end = slot
+			continue
+		}
+		ranges = append(ranges, slotRange{start: slot, end: slot, owner: owner})
+	}
+	return ranges
+}
+
+// command CLUSTER命令
+func (c *Cluster) command(node *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return wrongArgs("cluster")
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	switch sub := strings.ToLower(args[1]); sub {
+	case "slots":
+		var slots []interface{}
+		for _, r := range c.ranges() {
+			host, port := splitAddr(c.nodes[r.owner].Addr())
+			slots = append(slots, []interface{}{
+				int64(r.start), int64(r.end),
+				[]interface{}{host, port, nodeID(r.owner)},
+			})
+		}
+		return slots
+	case "nodes":
+		var b strings.Builder
+		ranges := c.ranges()
+		for i, n := range c.nodes {
+			flags := "master"
+			if n == node {
+				flags = "myself,master"
+			}
+			fmt.Fprintf(&b, "%s %s@%d %s - 0 0 %d connected", nodeID(i), n.Addr(), addrPort(n.Addr())+10000, flags, i+1)
+			for _, r := range ranges {
+				if r.owner != i {
+					continue
+				}
+				if r.start == r.end {
+					fmt.Fprintf(&b, " %d", r.start)
+				} else {
+					fmt.Fprintf(&b, " %d-%d", r.start, r.end)
+				}
+			}
+			b.WriteString("\n")
+		}
+		return b.String()
+	case "info":
+		return fmt.Sprintf("cluster_enabled:1\r\ncluster_state:ok\r\ncluster_slots_assigned:%d\r\ncluster_slots_ok:%d\r\ncluster_known_nodes:%d\r\ncluster_size:%d\r\n",
+			clusterSlots, clusterSlots, len(c.nodes), len(c.nodes))
+	case "myid":
+		return nodeID(node.index)
+	case "keyslot":
+		if len(args) != 3 {
+			return wrongArgs("cluster|keyslot")
+		}
+		return int64(cache.KeySlot(args[2]))
+	case "countkeysinslot":
+		if len(args) != 3 {
+			return wrongArgs("cluster|countkeysinslot")
+		}
+		slot, err := parseInt(args[2])
+		if err != nil {
+			return err
+		}
+		node.fake.mu.Lock()
+		defer node.fake.mu.Unlock()
+		var count int64
+		for _, key := range node.fake.store.sortedKeys() {
+			if int64(cache.KeySlot(key)) == slot {
+				count++
+			}
+		}
+		return count
+	}
+	return redisError(fmt.Sprintf("ERR unknown subcommand '%s'", args[1]))
+}
@@ -0,0 +1,637 @@
+package cachetest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// command 服务端支持的数据命令
+type command struct {
+	// 参数个数（包括命令名），负数表示至少-arity个
+	arity int
+	// 是否修改数据，从节点拒绝写命令
+	write bool
+	// 第一个键、最后一个键（负数从末尾计数）的位置和步长，与COMMAND命令的返回值一致，用于集群路由检查
+	firstKey, lastKey, step int
+	// 在持有数据锁时执行
+	run func(s *store, args []string) interface{}
+}
+
+// checkArity 检查参数个数
+func (cmd *command) checkArity(name string, args []string) error {
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		return wrongArgs(name)
+	}
+	return nil
+}
+
+// keys 获取命令中的键
+func (cmd *command) keys(args []string) []string {
+	if cmd.firstKey == 0 {
+		return nil
+	}
+	last := cmd.lastKey
+	if last < 0 {
+		last += len(args)
+	}
+	var keys []string
+	for i := cmd.firstKey; i <= last && i < len(args); i += cmd.step {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+// commands 数据命令表
+var commands = map[string]*command{
+	// 字符串
+	"get":    {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.get(a[1])) }},
+	"set":    {-3, true, 1, 1, 1, setCommand},
+	"setnx":  {3, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.setNX(a[1], a[2], 0)) }},
+	"setex":  {4, true, 1, 1, 1, setexCommand},
+	"getset": {3, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.getSet(a[1], a[2])) }},
+	"mget":   {-2, false, 1, -1, 1, func(s *store, a []string) interface{} { return result(s.mget(a[1:])) }},
+	"mset":   {-3, true, 1, -1, 2, func(s *store, a []string) interface{} { return status(s.mset(toArgs(a[1:]))) }},
+	"incr":   {2, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.incrBy(a[1], 1)) }},
+	"decr":   {2, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.incrBy(a[1], -1)) }},
+	"incrby": {3, true, 1, 1, 1, incrByCommand(1)},
+	"decrby": {3, true, 1, 1, 1, incrByCommand(-1)},
+
+	// 哈希表
+	"hget":    {3, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hget(a[1], a[2])) }},
+	"hset":    {-4, true, 1, 1, 1, hsetCommand(false)},
+	"hmset":   {-4, true, 1, 1, 1, hsetCommand(true)},
+	"hsetnx":  {4, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hsetNX(a[1], a[2], a[3])) }},
+	"hdel":    {-3, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hdel(a[1], a[2:])) }},
+	"hexists": {3, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hexists(a[1], a[2])) }},
+	"hgetall": {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hgetAll(a[1])) }},
+	"hkeys":   {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hkeys(a[1])) }},
+	"hvals":   {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hvals(a[1])) }},
+	"hlen":    {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hlen(a[1])) }},
+	"hmget":   {-3, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.hmget(a[1], a[2:])) }},
+	"hincrby": {4, true, 1, 1, 1, hincrByCommand},
+
+	// 列表
+	"lpush":  {-3, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.push(a[1], toArgs(a[2:]), true)) }},
+	"rpush":  {-3, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.push(a[1], toArgs(a[2:]), false)) }},
+	"lpop":   {2, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.pop(a[1], true)) }},
+	"rpop":   {2, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.pop(a[1], false)) }},
+	"llen":   {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.llen(a[1])) }},
+	"lrange": {4, false, 1, 1, 1, listIndexCommand(2, func(s *store, a []string, n []int64) interface{} { return result(s.lrange(a[1], n[0], n[1])) })},
+	"lindex": {3, false, 1, 1, 1, listIndexCommand(1, func(s *store, a []string, n []int64) interface{} { return result(s.lindex(a[1], n[0])) })},
+	"lset":   {4, true, 1, 1, 1, listIndexCommand(1, func(s *store, a []string, n []int64) interface{} { return status(s.lset(a[1], n[0], a[3])) })},
+	"lrem":   {4, true, 1, 1, 1, listIndexCommand(1, func(s *store, a []string, n []int64) interface{} { return result(s.lrem(a[1], n[0], a[3])) })},
+	"ltrim":  {4, true, 1, 1, 1, listIndexCommand(2, func(s *store, a []string, n []int64) interface{} { return status(s.ltrim(a[1], n[0], n[1])) })},
+
+	// 集合
+	"sadd":        {-3, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.sadd(a[1], toArgs(a[2:]))) }},
+	"srem":        {-3, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.srem(a[1], toArgs(a[2:]))) }},
+	"smembers":    {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.smembers(a[1])) }},
+	"sismember":   {3, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.sismember(a[1], a[2])) }},
+	"scard":       {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.scard(a[1])) }},
+	"srandmember": {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.srandmember(a[1])) }},
+	"spop":        {2, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.spop(a[1])) }},
+	"sinter":      {-2, false, 1, -1, 1, func(s *store, a []string) interface{} { return result(s.sinter(a[1:])) }},
+	"sunion":      {-2, false, 1, -1, 1, func(s *store, a []string) interface{} { return result(s.sunion(a[1:])) }},
+	"sdiff":       {-2, false, 1, -1, 1, func(s *store, a []string) interface{} { return result(s.sdiff(a[1:])) }},
+
+	// 有序集合
+	"zadd":             {-4, true, 1, 1, 1, zaddCommand},
+	"zrem":             {-3, true, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.zrem(a[1], toArgs(a[2:]))) }},
+	"zscore":           {3, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.zscore(a[1], a[2])) }},
+	"zrank":            {3, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.zrank(a[1], a[2], false)) }},
+	"zrevrank":         {3, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.zrank(a[1], a[2], true)) }},
+	"zrange":           {-4, false, 1, 1, 1, zrangeCommand(false)},
+	"zrevrange":        {-4, false, 1, 1, 1, zrangeCommand(true)},
+	"zrangebyscore":    {-4, false, 1, 1, 1, zrangeByScoreCommand(false)},
+	"zrevrangebyscore": {-4, false, 1, 1, 1, zrangeByScoreCommand(true)},
+	"zcard":            {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.zcard(a[1])) }},
+	"zcount":           {4, false, 1, 1, 1, func(s *store, a []string) interface{} { return result(s.zcount(a[1], a[2], a[3])) }},
+	"zincrby":          {4, true, 1, 1, 1, zincrByCommand},
+
+	// 通用
+	"del":       {-2, true, 1, -1, 1, func(s *store, a []string) interface{} { return result(s.del(a[1:])) }},
+	"unlink":    {-2, true, 1, -1, 1, func(s *store, a []string) interface{} { return result(s.del(a[1:])) }},
+	"exists":    {-2, false, 1, -1, 1, func(s *store, a []string) interface{} { return result(s.exists(a[1:])) }},
+	"expire":    {3, true, 1, 1, 1, expireCommand(time.Second, false)},
+	"pexpire":   {3, true, 1, 1, 1, expireCommand(time.Millisecond, false)},
+	"expireat":  {3, true, 1, 1, 1, expireCommand(time.Second, true)},
+	"pexpireat": {3, true, 1, 1, 1, expireCommand(time.Millisecond, true)},
+	"ttl":       {2, false, 1, 1, 1, ttlCommand(time.Second)},
+	"pttl":      {2, false, 1, 1, 1, ttlCommand(time.Millisecond)},
+	"persist":   {2, true, 1, 1, 1, persistCommand},
+	"type":      {2, false, 1, 1, 1, func(s *store, a []string) interface{} { return simpleString(s.kind(a[1])) }},
+	"keys":      {2, false, 0, 0, 0, func(s *store, a []string) interface{} { return result(s.keys(a[1], "")) }},
+	"scan":      {-2, false, 0, 0, 0, scanCommand},
+	"dbsize":    {1, false, 0, 0, 0, func(s *store, a []string) interface{} { return int64(len(s.sortedKeys())) }},
+	"flushall":  {-1, true, 0, 0, 0, flushCommand},
+	"flushdb":   {-1, true, 0, 0, 0, flushCommand},
+	"time":      {1, false, 0, 0, 0, timeCommand},
+}
+
+// result 将存储层的结果转换为回复，键或成员不存在时回复空值
+func result[T any](v T, err error) interface{} {
+	switch {
+	case err == cache.ErrKeyNotFound || err == redis.Nil:
+		return nil
+	case err != nil:
+		return err
+	}
+	return v
+}
+
+// status 将存储层返回的OK转换为状态回复
+func status[T any](v T, err error) interface{} {
+	if err != nil {
+		return err
+	}
+	return simpleString("OK")
+}
+
+// toArgs 将字符串参数转换为存储层的参数
+func toArgs(strs []string) []interface{} {
+	args := make([]interface{}, len(strs))
+	for i, str := range strs {
+		args[i] = str
+	}
+	return args
+}
+
+// parseInt 解析整数参数
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errNotInteger
+	}
+	return n, nil
+}
+
+// parseFloat 解析浮点数参数
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, redisError("ERR value is not a valid float")
+	}
+	return f, nil
+}
+
+// setCommand SET key value [NX|XX] [GET] [EX|PX|EXAT|PXAT time|KEEPTTL]
+func setCommand(s *store, args []string) interface{} {
+	key, value := args[1], args[2]
+	var nx, xx, get, keepTTL bool
+	var expireAt time.Time
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToLower(args[i]); opt {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "get":
+			get = true
+		case "keepttl":
+			keepTTL = true
+		case "ex", "px", "exat", "pxat":
+			if i+1 >= len(args) || !expireAt.IsZero() {
+				return errSyntax
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return redisError("ERR invalid expire time in 'set' command")
+			}
+			i++
+			switch opt {
+			case "ex":
+				expireAt = s.now().Add(time.Duration(n) * time.Second)
+			case "px":
+				expireAt = s.now().Add(time.Duration(n) * time.Millisecond)
+			case "exat":
+				expireAt = time.Unix(n, 0)
+			case "pxat":
+				expireAt = time.UnixMilli(n)
+			}
+		default:
+			return errSyntax
+		}
+	}
+	if (nx && xx) || (keepTTL && !expireAt.IsZero()) {
+		return errSyntax
+	}
+
+	old := s.lookup(key)
+	var prev interface{}
+	if get && old != nil {
+		if old.kind != typeString {
+			return errWrongType
+		}
+		prev = old.str
+	}
+	if (nx && old != nil) || (xx && old == nil) {
+		return prev
+	}
+	e := &entry{kind: typeString, str: value, expireAt: expireAt}
+	if keepTTL && old != nil {
+		e.expireAt = old.expireAt
+	}
+	s.data[key] = e
+	s.touch(key)
+	if get {
+		return prev
+	}
+	return simpleString("OK")
+}
+
+func setexCommand(s *store, args []string) interface{} {
+	seconds, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	if seconds <= 0 {
+		return redisError("ERR invalid expire time in 'setex' command")
+	}
+	return status(s.set(args[1], args[3], time.Duration(seconds)*time.Second))
+}
+
+func incrByCommand(sign int64) func(s *store, args []string) interface{} {
+	return func(s *store, args []string) interface{} {
+		delta, err := parseInt(args[2])
+		if err != nil {
+			return err
+		}
+		return result(s.incrBy(args[1], sign*delta))
+	}
+}
+
+// hsetCommand HSET返回新增的字段数，HMSET返回OK
+func hsetCommand(hmset bool) func(s *store, args []string) interface{} {
+	return func(s *store, args []string) interface{} {
+		if len(args)%2 != 0 {
+			return wrongArgs(strings.ToLower(args[0]))
+		}
+		added, err := s.hsetPairs(args[1], args[2:])
+		if hmset {
+			return status(added, err)
+		}
+		return result(added, err)
+	}
+}
+
+func hincrByCommand(s *store, args []string) interface{} {
+	delta, err := parseInt(args[3])
+	if err != nil {
+		return err
+	}
+	return result(s.hincrBy(args[1], args[2], delta))
+}
+
+// listIndexCommand 解析从第二个参数开始的n个整数参数
+func listIndexCommand(n int, run func(s *store, args []string, nums []int64) interface{}) func(s *store, args []string) interface{} {
+	return func(s *store, args []string) interface{} {
+		nums := make([]int64, n)
+		for i := range nums {
+			num, err := parseInt(args[2+i])
+			if err != nil {
+				return err
+			}
+			nums[i] = num
+		}
+		return run(s, args, nums)
+	}
+}
+
+// zaddCommand ZADD key [NX|XX] [CH] [INCR] score member [score member ...]
+func zaddCommand(s *store, args []string) interface{} {
+	var nx, xx, ch, incr bool
+	i := 2
+options:
+	for ; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "ch":
+			ch = true
+		case "incr":
+			incr = true
+		default:
+			break options
+		}
+	}
+	rest := args[i:]
+	if len(rest) == 0 || len(rest)%2 != 0 || (nx && xx) || (incr && len(rest) != 2) {
+		return errSyntax
+	}
+	scores := make([]float64, len(rest)/2)
+	for j := range scores {
+		score, err := parseFloat(rest[j*2])
+		if err != nil {
+			return err
+		}
+		scores[j] = score
+	}
+
+	key := args[1]
+	e, err := s.lookupKind(key, typeZSet)
+	if err != nil {
+		return err
+	}
+	if incr {
+		member := rest[1]
+		exists := false
+		if e != nil {
+			_, exists = e.zset[member]
+		}
+		if (nx && exists) || (xx && !exists) {
+			return nil
+		}
+		return result(s.zincrBy(key, scores[0], member))
+	}
+	if e == nil && xx {
+		return int64(0)
+	}
+
+	var members []cache.ZMember
+	var changed int64
+	for j, score := range scores {
+		member := rest[j*2+1]
+		old, exists := 0.0, false
+		if e != nil {
+			old, exists = e.zset[member]
+		}
+		if (nx && exists) || (xx && !exists) {
+			continue
+		}
+		if !exists || old != score {
+			changed++
+		}
+		members = append(members, cache.ZMember{Score: score, Member: member})
+	}
+	if len(members) == 0 {
+		return int64(0)
+	}
+	added, err := s.zadd(key, members)
+	if err != nil {
+		return err
+	}
+	if ch {
+		return changed
+	}
+	return added
+}
+
+// zrangeCommand ZRANGE key start stop [REV] [WITHSCORES]
+func zrangeCommand(reverse bool) func(s *store, args []string) interface{} {
+	return func(s *store, args []string) interface{} {
+		start, err := parseInt(args[2])
+		if err != nil {
+			return err
+		}
+		stop, err := parseInt(args[3])
+		if err != nil {
+			return err
+		}
+		var withScores bool
+		for _, opt := range args[4:] {
+			switch strings.ToLower(opt) {
+			case "withscores":
+				withScores = true
+			case "rev":
+				reverse = !reverse
+			default:
+				return errSyntax
+			}
+		}
+		members, err := s.zrangeWithScores(args[1], start, stop, reverse)
+		if err != nil {
+			return err
+		}
+		return zreply(members, withScores)
+	}
+}
+
+// zrangeByScoreCommand ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count]，
+// ZREVRANGEBYSCORE的范围参数为max min
+func zrangeByScoreCommand(reverse bool) func(s *store, args []string) interface{} {
+	return func(s *store, args []string) interface{} {
+		min, max := args[2], args[3]
+		if reverse {
+			min, max = max, min
+		}
+		minBound, err := parseScoreBound(min)
+		if err != nil {
+			return err
+		}
+		maxBound, err := parseScoreBound(max)
+		if err != nil {
+			return err
+		}
+
+		var withScores bool
+		offset, count := int64(0), int64(-1)
+		for i := 4; i < len(args); i++ {
+			switch strings.ToLower(args[i]) {
+			case "withscores":
+				withScores = true
+			case "limit":
+				if i+2 >= len(args) {
+					return errSyntax
+				}
+				if offset, err = parseInt(args[i+1]); err != nil {
+					return err
+				}
+				if count, err = parseInt(args[i+2]); err != nil {
+					return err
+				}
+				i += 2
+			default:
+				return errSyntax
+			}
+		}
+
+		all, err := s.zrangeWithScores(args[1], 0, -1, reverse)
+		if err != nil {
+			return err
+		}
+		members := []cache.ZMember{}
+		for _, member := range all {
+			if !inScoreRange(member.Score, minBound, maxBound) {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			if count == 0 {
+				break
+			}
+			members = append(members, member)
+			count--
+		}
+		return zreply(members, withScores)
+	}
+}
+
+// zreply 有序集合成员的回复，WITHSCORES时成员和分数交替排列
+func zreply(members []cache.ZMember, withScores bool) interface{} {
+	if !withScores {
+		return memberNames(members)
+	}
+	reply := make([]interface{}, 0, len(members)*2)
+	for _, member := range members {
+		reply = append(reply, member.Member, member.Score)
+	}
+	return reply
+}
+
+func zincrByCommand(s *store, args []string) interface{} {
+	increment, err := parseFloat(args[2])
+	if err != nil {
+		return err
+	}
+	return result(s.zincrBy(args[1], increment, args[3]))
+}
+
+// expireCommand 设置相对或绝对的过期时间，unit为参数的单位
+func expireCommand(unit time.Duration, absolute bool) func(s *store, args []string) interface{} {
+	return func(s *store, args []string) interface{} {
+		n, err := parseInt(args[2])
+		if err != nil {
+			return err
+		}
+		if absolute {
+			return result(s.expireAt(args[1], time.Unix(0, 0).Add(time.Duration(n)*unit)))
+		}
+		return result(s.expire(args[1], time.Duration(n)*unit))
+	}
+}
+
+// ttlCommand 剩余生存时间，键不存在时返回-2，没有过期时间时返回-1
+func ttlCommand(unit time.Duration) func(s *store, args []string) interface{} {
+	return func(s *store, args []string) interface{} {
+		e := s.lookup(args[1])
+		switch {
+		case e == nil:
+			return int64(-2)
+		case e.expireAt.IsZero():
+			return int64(-1)
+		}
+		remaining := e.expireAt.Sub(s.now())
+		return int64((remaining + unit/2) / unit)
+	}
+}
+
+func persistCommand(s *store, args []string) interface{} {
+	e := s.lookup(args[1])
+	if e == nil || e.expireAt.IsZero() {
+		return int64(0)
+	}
+	e.expireAt = time.Time{}
+	s.touch(args[1])
+	return int64(1)
+}
+
+// kind 键的类型，键不存在时返回none
+func (s *store) kind(key string) string {
+	kind, _ := s.typ(key)
+	return kind
+}
+
+// scanCommand SCAN cursor [MATCH pattern] [COUNT count] [TYPE type]
+func scanCommand(s *store, args []string) interface{} {
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return redisError("ERR invalid cursor")
+	}
+	var match, kind string
+	var count int64
+	for i := 2; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return errSyntax
+		}
+		switch strings.ToLower(args[i]) {
+		case "match":
+			match = args[i+1]
+		case "count":
+			if count, err = parseInt(args[i+1]); err != nil {
+				return err
+			}
+		case "type":
+			kind = strings.ToLower(args[i+1])
+		default:
+			return errSyntax
+		}
+	}
+
+	keys, next, err := s.scan(cursor, match, count, "")
+	if err != nil {
+		return err
+	}
+	if kind != "" {
+		filtered := keys[:0]
+		for _, key := range keys {
+			if s.kind(key) == kind {
+				filtered = append(filtered, key)
+			}
+		}
+		keys = filtered
+	}
+	return []interface{}{strconv.FormatUint(next, 10), keys}
+}
+
+// flushCommand 删除所有键，ASYNC和SYNC选项被忽略
+func flushCommand(s *store, args []string) interface{} {
+	for key := range s.data {
+		s.touch(key)
+	}
+	s.data = make(map[string]*entry)
+	return simpleString("OK")
+}
+
+// timeCommand 按时钟的当前时间回复秒数和微秒数
+func timeCommand(s *store, args []string) interface{} {
+	now := s.now()
+	return []string{strconv.FormatInt(now.Unix(), 10), strconv.Itoa(now.Nanosecond() / 1000)}
+}
+
+// commandInfo COMMAND命令，回复数据命令的参数个数、读写标志和键的位置
+func commandInfo(args []string) interface{} {
+	var names []string
+	if len(args) > 1 {
+		switch strings.ToLower(args[1]) {
+		case "count":
+			return int64(len(commands))
+		case "info":
+			names = args[2:]
+		case "docs":
+			return respMap{}
+		default:
+			return redisError("ERR unknown subcommand '" + args[1] + "'")
+		}
+	} else {
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	infos := make([]interface{}, len(names))
+	for i, name := range names {
+		cmd, ok := commands[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		flag := "readonly"
+		if cmd.write {
+			flag = "write"
+		}
+		infos[i] = []interface{}{
+			strings.ToLower(name), int64(cmd.arity), []interface{}{simpleString(flag)},
+			int64(cmd.firstKey), int64(cmd.lastKey), int64(cmd.step), []interface{}{},
+		}
+	}
+	return infos
+}
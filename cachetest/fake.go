@@ -0,0 +1,868 @@
+// Package cachetest 提供cache.Client的内存实现，用于不依赖Redis的单元测试
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"cache"
+)
+
+// ErrNotSupported 内存实现不支持的命令，如Redis Functions
+var ErrNotSupported = errors.New("cachetest: command not supported by fake client")
+
+// Option 内存客户端配置项
+type Option func(f *Fake)
+
+// WithConfig 使用配置中的KeyPrefix、DefaultTTL和WatchRetries，部署模式和连接配置被忽略
+func WithConfig(config *cache.Config) Option {
+	return func(f *Fake) {
+		f.config = config
+	}
+}
+
+// WithKeyPrefix 设置键前缀
+func WithKeyPrefix(prefix string) Option {
+	return func(f *Fake) {
+		f.config.Common.KeyPrefix = prefix
+	}
+}
+
+// WithDefaultTTL 设置默认过期时间
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(f *Fake) {
+		f.config.Common.DefaultTTL = ttl
+	}
+}
+
+// WithTime 设置时钟的初始时间，默认为创建时的当前时间
+func WithTime(now time.Time) Option {
+	return func(f *Fake) {
+		f.now = now
+	}
+}
+
+// Fake cache.Client的内存实现
+// 支持字符串、哈希表、列表、集合、有序集合、过期时间、Scan、键前缀、管道和乐观事务，
+// 语义与Redis一致；过期时间按可控的时钟计算，时钟只在调用Advance或SetTime时前进。
+// Lua脚本在内嵌的解释器中原子地执行，支持Redis脚本常用的Lua 5.1子集（不能定义函数），
+// redis.call可以调用Fake支持的数据命令和TIME（按时钟返回）。不支持Redis Functions，执行时返回ErrNotSupported
+type Fake struct {
+	config *cache.Config
+
+	mu    sync.Mutex
+	store *store
+	now   time.Time
+	// 已缓存的脚本，SHA1到源码
+	scripts map[string]string
+	closed  bool
+}
+
+// 确保实现了cache.Client接口
+var _ cache.Client = (*Fake)(nil)
+
+// NewFake 创建内存客户端
+func NewFake(opts ...Option) *Fake {
+	f := &Fake{
+		config:  &cache.Config{Mode: cache.ModeSingle},
+		now:     time.Now(),
+		scripts: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	f.store = newStore(func() time.Time {
+		return f.now
+	})
+	return f
+}
+
+// Config 获取内存客户端使用的配置
+func (f *Fake) Config() *cache.Config {
+	return f.config
+}
+
+// 时钟操作
+
+// Now 获取时钟的当前时间
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance 将时钟向前拨动d，到期的键随之过期
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// SetTime 将时钟设置为指定时间
+func (f *Fake) SetTime(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// FlushAll 删除所有键
+func (f *Fake) FlushAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key := range f.store.data {
+		f.store.touch(key)
+	}
+	f.store.data = make(map[string]*entry)
+}
+
+// call 加锁执行一条命令，客户端关闭后返回ErrClientClosed
+func call[T any](f *Fake, fn func(s *store) (T, error)) (T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		var zero T
+		return zero, cache.ErrClientClosed
+	}
+	return fn(f.store)
+}
+
+// key 按调用选项为键添加前缀
+func (f *Fake) key(ctx context.Context, key string) string {
+	return f.config.PrefixKey(ctx, key)
+}
+
+// keys 为多个键添加前缀
+func (f *Fake) keys(ctx context.Context, keys []string) []string {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = f.key(ctx, key)
+	}
+	return prefixedKeys
+}
+
+// pairs 为键值对中的键添加前缀
+func (f *Fake) pairs(ctx context.Context, pairs []interface{}) []interface{} {
+	prefixedPairs := make([]interface{}, len(pairs))
+	for i := 0; i < len(pairs); i += 2 {
+		if i+1 < len(pairs) {
+			key, _ := pairs[i].(string)
+			prefixedPairs[i] = f.key(ctx, key)
+			prefixedPairs[i+1] = pairs[i+1]
+		}
+	}
+	return prefixedPairs
+}
+
+// 基础操作
+
+// Close 关闭客户端，之后的命令返回ErrClientClosed
+func (f *Fake) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return cache.ErrClientClosed
+	}
+	f.closed = true
+	return nil
+}
+
+// Ping 测试连接
+func (f *Fake) Ping(ctx context.Context) error {
+	_, err := call(f, func(s *store) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// Ready 检查客户端是否可以处理请求
+func (f *Fake) Ready(ctx context.Context) error {
+	return f.Ping(ctx)
+}
+
+// Stats 获取连接池统计，内存实现没有连接池，总是返回零值
+func (f *Fake) Stats() *cache.PoolStats {
+	return &cache.PoolStats{}
+}
+
+// Scan 按字典序迭代键，返回的键不包含前缀
+func (f *Fake) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	prefix := f.config.KeyPrefix(ctx)
+	match = f.key(ctx, match)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, 0, cache.ErrClientClosed
+	}
+	return f.store.scan(cursor, match, count, prefix)
+}
+
+// DeleteByPattern 按模式删除键，内存实现一次删除所有匹配的键，忽略BatchSize和RateLimit
+func (f *Fake) DeleteByPattern(ctx context.Context, pattern string, opts *cache.DeleteOptions) (int64, error) {
+	pattern = f.key(ctx, pattern)
+	return call(f, func(s *store) (int64, error) {
+		keys, err := s.keys(pattern, "")
+		if err != nil {
+			return 0, err
+		}
+		if opts != nil && opts.DryRun {
+			return int64(len(keys)), nil
+		}
+		return s.del(keys)
+	})
+}
+
+// Lua脚本操作
+
+// Eval 执行Lua脚本并缓存，脚本返回nil或false时返回redis.Nil
+func (f *Fake) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	keys = f.keys(ctx, keys)
+	return call(f, func(s *store) (interface{}, error) {
+		return f.eval(s, script, keys, args)
+	})
+}
+
+// EvalSha 通过SHA1执行已缓存的Lua脚本，脚本未缓存时返回ErrScriptNotFound
+func (f *Fake) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) (interface{}, error) {
+	keys = f.keys(ctx, keys)
+	return call(f, func(s *store) (interface{}, error) {
+		return f.evalSha(s, sha1, keys, args)
+	})
+}
+
+// eval 在持有锁时执行脚本，keys已添加前缀
+func (f *Fake) eval(s *store, script string, keys []string, args []interface{}) (interface{}, error) {
+	strs, err := formatAll(args)
+	if err != nil {
+		return nil, err
+	}
+	f.scripts[scriptHash(script)] = script
+	return scriptResult(runScript(s, script, keys, strs, nil))
+}
+
+// evalSha 在持有锁时通过SHA1执行脚本，keys已添加前缀
+func (f *Fake) evalSha(s *store, sha1 string, keys []string, args []interface{}) (interface{}, error) {
+	script, ok := f.scripts[strings.ToLower(sha1)]
+	if !ok {
+		return nil, cache.ErrScriptNotFound
+	}
+	return f.eval(s, script, keys, args)
+}
+
+// ScriptExists 检查脚本是否已加载
+func (f *Fake) ScriptExists(ctx context.Context, hashes ...string) ([]bool, error) {
+	return call(f, func(s *store) ([]bool, error) {
+		exists := make([]bool, len(hashes))
+		for i, hash := range hashes {
+			_, exists[i] = f.scripts[strings.ToLower(hash)]
+		}
+		return exists, nil
+	})
+}
+
+// ScriptFlush 清空脚本缓存
+func (f *Fake) ScriptFlush(ctx context.Context) error {
+	_, err := call(f, func(s *store) (struct{}, error) {
+		f.scripts = make(map[string]string)
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// ScriptKill 终止正在执行的脚本，脚本同步执行，总是返回NOTBUSY
+func (f *Fake) ScriptKill(ctx context.Context) error {
+	_, err := call(f, func(s *store) (struct{}, error) {
+		return struct{}{}, redisError("NOTBUSY No scripts in execution right now.")
+	})
+	return err
+}
+
+// ScriptLoad 缓存脚本并返回SHA1，之后可以通过EvalSha执行
+func (f *Fake) ScriptLoad(ctx context.Context, script string) (string, error) {
+	return call(f, func(s *store) (string, error) {
+		hash := scriptHash(script)
+		f.scripts[hash] = script
+		return hash, nil
+	})
+}
+
+// Redis Functions操作，内存实现都不支持
+
+// FunctionLoad 加载函数库
+func (f *Fake) FunctionLoad(ctx context.Context, code string, replace bool) (string, error) {
+	return "", ErrNotSupported
+}
+
+// FunctionDelete 删除函数库
+func (f *Fake) FunctionDelete(ctx context.Context, library string) error {
+	return ErrNotSupported
+}
+
+// FunctionList 列出函数库
+func (f *Fake) FunctionList(ctx context.Context, pattern string, withCode bool) ([]cache.FunctionLibrary, error) {
+	return nil, ErrNotSupported
+}
+
+// FunctionDump 导出所有函数库
+func (f *Fake) FunctionDump(ctx context.Context) (string, error) {
+	return "", ErrNotSupported
+}
+
+// FunctionRestore 恢复函数库
+func (f *Fake) FunctionRestore(ctx context.Context, payload string) error {
+	return ErrNotSupported
+}
+
+// FCall 调用函数
+func (f *Fake) FCall(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	return nil, ErrNotSupported
+}
+
+// FCallRO 调用只读函数
+func (f *Fake) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	return nil, ErrNotSupported
+}
+
+// 管道操作
+
+// Pipeline 创建管道，Exec时所有命令一次执行完
+func (f *Fake) Pipeline() cache.Pipeliner {
+	return &pipeline{f: f}
+}
+
+// TxPipeline 创建事务管道，Exec时所有命令一次执行完
+func (f *Fake) TxPipeline() cache.Pipeliner {
+	return &pipeline{f: f}
+}
+
+// 字符串操作
+
+// Get 获取字符串值
+func (f *Fake) Get(ctx context.Context, key string) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.get(key)
+	})
+}
+
+// Set 设置字符串值
+func (f *Fake) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	key = f.key(ctx, key)
+	expiration = f.config.TTL(ctx, expiration)
+	_, err := call(f, func(s *store) (string, error) {
+		return s.set(key, value, expiration)
+	})
+	return err
+}
+
+// SetNX 仅当键不存在时设置值
+func (f *Fake) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	key = f.key(ctx, key)
+	expiration = f.config.TTL(ctx, expiration)
+	return call(f, func(s *store) (bool, error) {
+		return s.setNX(key, value, expiration)
+	})
+}
+
+// GetSet 设置新值并返回旧值
+func (f *Fake) GetSet(ctx context.Context, key string, value interface{}) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.getSet(key, value)
+	})
+}
+
+// MGet 批量获取多个键的值
+func (f *Fake) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	keys = f.keys(ctx, keys)
+	return call(f, func(s *store) ([]interface{}, error) {
+		return s.mget(keys)
+	})
+}
+
+// MSet 批量设置多个键值对
+func (f *Fake) MSet(ctx context.Context, pairs ...interface{}) error {
+	pairs = f.pairs(ctx, pairs)
+	_, err := call(f, func(s *store) (string, error) {
+		return s.mset(pairs)
+	})
+	return err
+}
+
+// Incr 递增计数器
+func (f *Fake) Incr(ctx context.Context, key string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.incrBy(key, 1)
+	})
+}
+
+// IncrBy 按指定值递增计数器
+func (f *Fake) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.incrBy(key, value)
+	})
+}
+
+// Decr 递减计数器
+func (f *Fake) Decr(ctx context.Context, key string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.incrBy(key, -1)
+	})
+}
+
+// DecrBy 按指定值递减计数器
+func (f *Fake) DecrBy(ctx context.Context, key string, value int64) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.incrBy(key, -value)
+	})
+}
+
+// 哈希表操作
+
+// HGet 获取哈希表字段值
+func (f *Fake) HGet(ctx context.Context, key, field string) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.hget(key, field)
+	})
+}
+
+// HSet 设置哈希表字段值
+func (f *Fake) HSet(ctx context.Context, key, field string, value interface{}) error {
+	key = f.key(ctx, key)
+	_, err := call(f, func(s *store) (int64, error) {
+		return s.hset(key, field, value)
+	})
+	return err
+}
+
+// HSetNX 仅当字段不存在时设置哈希表字段值
+func (f *Fake) HSetNX(ctx context.Context, key, field string, value interface{}) (bool, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (bool, error) {
+		return s.hsetNX(key, field, value)
+	})
+}
+
+// HDel 删除哈希表字段
+func (f *Fake) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.hdel(key, fields)
+	})
+}
+
+// HExists 检查哈希表字段是否存在
+func (f *Fake) HExists(ctx context.Context, key, field string) (bool, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (bool, error) {
+		return s.hexists(key, field)
+	})
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (f *Fake) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (map[string]string, error) {
+		return s.hgetAll(key)
+	})
+}
+
+// HKeys 获取哈希表所有字段
+func (f *Fake) HKeys(ctx context.Context, key string) ([]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]string, error) {
+		return s.hkeys(key)
+	})
+}
+
+// HVals 获取哈希表所有值
+func (f *Fake) HVals(ctx context.Context, key string) ([]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]string, error) {
+		return s.hvals(key)
+	})
+}
+
+// HLen 获取哈希表字段数量
+func (f *Fake) HLen(ctx context.Context, key string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.hlen(key)
+	})
+}
+
+// HMGet 批量获取哈希表字段值
+func (f *Fake) HMGet(ctx context.Context, key string, fields ...string) ([]interface{}, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]interface{}, error) {
+		return s.hmget(key, fields)
+	})
+}
+
+// HMSet 批量设置哈希表字段值
+func (f *Fake) HMSet(ctx context.Context, key string, pairs ...interface{}) error {
+	key = f.key(ctx, key)
+	_, err := call(f, func(s *store) (bool, error) {
+		return s.hmset(key, pairs)
+	})
+	return err
+}
+
+// HIncrBy 递增哈希表字段值
+func (f *Fake) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.hincrBy(key, field, incr)
+	})
+}
+
+// 列表操作
+
+// LPush 从列表左侧推入元素
+func (f *Fake) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.push(key, values, true)
+	})
+}
+
+// RPush 从列表右侧推入元素
+func (f *Fake) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.push(key, values, false)
+	})
+}
+
+// LPop 从列表左侧弹出元素
+func (f *Fake) LPop(ctx context.Context, key string) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.pop(key, true)
+	})
+}
+
+// RPop 从列表右侧弹出元素
+func (f *Fake) RPop(ctx context.Context, key string) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.pop(key, false)
+	})
+}
+
+// LLen 获取列表长度
+func (f *Fake) LLen(ctx context.Context, key string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.llen(key)
+	})
+}
+
+// LRange 获取列表指定范围的元素
+func (f *Fake) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]string, error) {
+		return s.lrange(key, start, stop)
+	})
+}
+
+// LIndex 获取列表指定索引的元素
+func (f *Fake) LIndex(ctx context.Context, key string, index int64) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.lindex(key, index)
+	})
+}
+
+// LSet 设置列表指定索引的元素值
+func (f *Fake) LSet(ctx context.Context, key string, index int64, value interface{}) error {
+	key = f.key(ctx, key)
+	_, err := call(f, func(s *store) (string, error) {
+		return s.lset(key, index, value)
+	})
+	return err
+}
+
+// LRem 从列表中移除元素
+func (f *Fake) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.lrem(key, count, value)
+	})
+}
+
+// LTrim 修剪列表，只保留指定范围的元素
+func (f *Fake) LTrim(ctx context.Context, key string, start, stop int64) error {
+	key = f.key(ctx, key)
+	_, err := call(f, func(s *store) (string, error) {
+		return s.ltrim(key, start, stop)
+	})
+	return err
+}
+
+// 集合操作
+
+// SAdd 向集合添加成员
+func (f *Fake) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.sadd(key, members)
+	})
+}
+
+// SRem 从集合移除成员
+func (f *Fake) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.srem(key, members)
+	})
+}
+
+// SMembers 获取集合所有成员，按字典序排列
+func (f *Fake) SMembers(ctx context.Context, key string) ([]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]string, error) {
+		return s.smembers(key)
+	})
+}
+
+// SIsMember 检查成员是否在集合中
+func (f *Fake) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (bool, error) {
+		return s.sismember(key, member)
+	})
+}
+
+// SCard 获取集合成员数量
+func (f *Fake) SCard(ctx context.Context, key string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.scard(key)
+	})
+}
+
+// SPop 随机移除并返回集合中的一个成员
+func (f *Fake) SPop(ctx context.Context, key string) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.spop(key)
+	})
+}
+
+// SRandMember 随机返回集合中的一个成员
+func (f *Fake) SRandMember(ctx context.Context, key string) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.srandmember(key)
+	})
+}
+
+// SInter 计算多个集合的交集
+func (f *Fake) SInter(ctx context.Context, keys ...string) ([]string, error) {
+	keys = f.keys(ctx, keys)
+	return call(f, func(s *store) ([]string, error) {
+		return s.sinter(keys)
+	})
+}
+
+// SUnion 计算多个集合的并集
+func (f *Fake) SUnion(ctx context.Context, keys ...string) ([]string, error) {
+	keys = f.keys(ctx, keys)
+	return call(f, func(s *store) ([]string, error) {
+		return s.sunion(keys)
+	})
+}
+
+// SDiff 计算多个集合的差集
+func (f *Fake) SDiff(ctx context.Context, keys ...string) ([]string, error) {
+	keys = f.keys(ctx, keys)
+	return call(f, func(s *store) ([]string, error) {
+		return s.sdiff(keys)
+	})
+}
+
+// 有序集合操作
+
+// ZAdd 向有序集合添加成员
+func (f *Fake) ZAdd(ctx context.Context, key string, members ...cache.ZMember) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.zadd(key, members)
+	})
+}
+
+// ZRem 从有序集合移除成员
+func (f *Fake) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.zrem(key, members)
+	})
+}
+
+// ZScore 获取有序集合成员的分数
+func (f *Fake) ZScore(ctx context.Context, key, member string) (float64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (float64, error) {
+		return s.zscore(key, member)
+	})
+}
+
+// ZRank 获取有序集合成员的排名（从小到大）
+func (f *Fake) ZRank(ctx context.Context, key, member string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.zrank(key, member, false)
+	})
+}
+
+// ZRevRank 获取有序集合成员的排名（从大到小）
+func (f *Fake) ZRevRank(ctx context.Context, key, member string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.zrank(key, member, true)
+	})
+}
+
+// ZRange 获取有序集合指定范围的成员（从小到大）
+func (f *Fake) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]string, error) {
+		return s.zrange(key, start, stop, false)
+	})
+}
+
+// ZRevRange 获取有序集合指定范围的成员（从大到小）
+func (f *Fake) ZRevRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]string, error) {
+		return s.zrange(key, start, stop, true)
+	})
+}
+
+// ZRangeWithScores 获取有序集合指定范围的成员和分数（从小到大）
+func (f *Fake) ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]cache.ZMember, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]cache.ZMember, error) {
+		return s.zrangeWithScores(key, start, stop, false)
+	})
+}
+
+// ZRevRangeWithScores 获取有序集合指定范围的成员和分数（从大到小）
+func (f *Fake) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]cache.ZMember, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]cache.ZMember, error) {
+		return s.zrangeWithScores(key, start, stop, true)
+	})
+}
+
+// ZRangeByScore 根据分数范围获取有序集合成员
+func (f *Fake) ZRangeByScore(ctx context.Context, key string, min, max string) ([]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]string, error) {
+		return s.zrangeByScore(key, min, max, false)
+	})
+}
+
+// ZRevRangeByScore 根据分数范围获取有序集合成员（逆序）
+func (f *Fake) ZRevRangeByScore(ctx context.Context, key string, max, min string) ([]string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) ([]string, error) {
+		return s.zrangeByScore(key, min, max, true)
+	})
+}
+
+// ZCard 获取有序集合成员数量
+func (f *Fake) ZCard(ctx context.Context, key string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.zcard(key)
+	})
+}
+
+// ZCount 计算指定分数范围内的成员数量
+func (f *Fake) ZCount(ctx context.Context, key, min, max string) (int64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (int64, error) {
+		return s.zcount(key, min, max)
+	})
+}
+
+// ZIncrBy 增加有序集合成员的分数
+func (f *Fake) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (float64, error) {
+		return s.zincrBy(key, increment, member)
+	})
+}
+
+// 通用键操作
+
+// Del 删除键
+func (f *Fake) Del(ctx context.Context, keys ...string) (int64, error) {
+	keys = f.keys(ctx, keys)
+	return call(f, func(s *store) (int64, error) {
+		return s.del(keys)
+	})
+}
+
+// Exists 检查键是否存在
+func (f *Fake) Exists(ctx context.Context, keys ...string) (int64, error) {
+	keys = f.keys(ctx, keys)
+	return call(f, func(s *store) (int64, error) {
+		return s.exists(keys)
+	})
+}
+
+// Expire 设置键的过期时间
+func (f *Fake) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	key = f.key(ctx, key)
+	expiration = cache.JitterTTL(ctx, expiration)
+	return call(f, func(s *store) (bool, error) {
+		return s.expire(key, expiration)
+	})
+}
+
+// ExpireAt 设置键在指定时间过期
+func (f *Fake) ExpireAt(ctx context.Context, key string, tm time.Time) (bool, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (bool, error) {
+		return s.expireAt(key, tm)
+	})
+}
+
+// TTL 获取键的剩余生存时间
+func (f *Fake) TTL(ctx context.Context, key string) (time.Duration, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (time.Duration, error) {
+		return s.ttl(key)
+	})
+}
+
+// Type 获取键的数据类型
+func (f *Fake) Type(ctx context.Context, key string) (string, error) {
+	key = f.key(ctx, key)
+	return call(f, func(s *store) (string, error) {
+		return s.typ(key)
+	})
+}
+
+// Keys 查找匹配模式的键，返回的键不包含前缀
+func (f *Fake) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if f.config.Common.DisableKeys {
+		return nil, cache.ErrKeysDisabled
+	}
+	prefix := f.config.KeyPrefix(ctx)
+	pattern = f.key(ctx, pattern)
+	return call(f, func(s *store) ([]string, error) {
+		return s.keys(pattern, prefix)
+	})
+}
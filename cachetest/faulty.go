@@ -0,0 +1,862 @@
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"cache"
+)
+
+// ErrInjectedTimeout 注入的读写超时错误
+// 实现net.Error且Timeout返回true，cache.IsUnavailableError和cache.IsRetryableError将其视为超时
+var ErrInjectedTimeout error = injectedTimeout{}
+
+// injectedTimeout 模拟网络读写超时
+type injectedTimeout struct{}
+
+func (injectedTimeout) Error() string   { return "cachetest: injected i/o timeout" }
+func (injectedTimeout) Timeout() bool   { return true }
+func (injectedTimeout) Temporary() bool { return true }
+
+// Fault 故障注入规则
+// 命令同时匹配Commands和KeyPattern时按Rate触发：先等待Latency，再返回Err或丢弃写命令，
+// 两者都未设置时只注入延迟。多条规则按添加顺序检查，延迟累加，第一条返回错误或丢弃写命令的规则生效后不再检查后面的规则
+type Fault struct {
+	// 匹配的命令名，不区分大小写，使用Redis命令名（如"get"、"hset"、"zrange"），
+	// "exec"匹配整个管道的执行；为空时匹配所有命令
+	Commands []string
+	// 匹配键的glob模式，键不含前缀，多键命令的任意一个键匹配即可；为空时匹配所有命令（包括没有键的命令）
+	KeyPattern string
+	// 匹配的命令触发故障的概率，取值(0, 1)时使用带种子的随机数决定，其他值总是触发
+	Rate float64
+	// 最多触发的次数，为0时不限制
+	Times int
+
+	// 执行命令前注入的延迟，上下文先结束时返回上下文的错误
+	Latency time.Duration
+	// 命令返回的错误，命令不会被执行
+	Err error
+	// 丢弃写命令：命令不会被执行，但返回成功和零值；对读命令无效
+	DropWrite bool
+}
+
+// FaultyOptions 故障注入客户端配置
+type FaultyOptions struct {
+	// 随机数种子，相同的种子和调用顺序产生相同的故障序列
+	Seed int64
+	// 初始的故障规则
+	Faults []Fault
+}
+
+// FaultStats 故障注入统计
+type FaultStats struct {
+	// 经过检查的命令数量，管道中的每条命令和每次执行分别计数
+	Calls int64
+	// 被注入延迟的命令数量
+	Delayed int64
+	// 被注入错误的命令数量
+	Errors int64
+	// 被丢弃的写命令数量
+	Dropped int64
+}
+
+// FaultyClient 故障注入客户端，用于混沌测试
+// 包装任意cache.Client（通常是Fake），按规则为命令注入延迟、错误（如ErrInjectedTimeout、
+// cache.ErrPoolExhausted、cache.ErrClusterDown）和丢弃的写命令，不需要真实的服务端就能确定性地
+// 测试降级、熔断和重试等逻辑。管道中的命令逐条匹配规则，被注入错误的命令失败而其余命令正常执行，
+// 模拟管道部分失败；匹配"exec"的规则使整个管道失败。乐观事务只在Watch调用上注入故障
+type FaultyClient struct {
+	cache.Client
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	faults []*faultRule
+	stats  FaultStats
+}
+
+// faultRule 故障规则及其触发次数
+type faultRule struct {
+	Fault
+	commands map[string]bool
+	hits     int
+}
+
+// faultResult 命令匹配规则的结果
+type faultResult struct {
+	latency time.Duration
+	err     error
+	drop    bool
+}
+
+// 确保实现了cache.Client接口
+var _ cache.Client = (*FaultyClient)(nil)
+
+// NewFaultyClient 创建故障注入客户端
+func NewFaultyClient(client cache.Client, opts *FaultyOptions) *FaultyClient {
+	c := &FaultyClient{Client: client}
+	var seed int64
+	if opts != nil {
+		seed = opts.Seed
+		for _, fault := range opts.Faults {
+			c.AddFault(fault)
+		}
+	}
+	c.rand = rand.New(rand.NewSource(seed))
+	return c
+}
+
+// Unwrap 获取被包装的客户端
+func (c *FaultyClient) Unwrap() cache.Client {
+	return c.Client
+}
+
+// AddFault 添加故障规则
+func (c *FaultyClient) AddFault(fault Fault) {
+	rule := &faultRule{Fault: fault}
+	if len(fault.Commands) > 0 {
+		rule.commands = make(map[string]bool, len(fault.Commands))
+		for _, name := range fault.Commands {
+			rule.commands[strings.ToLower(name)] = true
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.faults = append(c.faults, rule)
+}
+
+// ClearFaults 移除所有故障规则，之后的命令直接调用被包装的客户端
+func (c *FaultyClient) ClearFaults() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.faults = nil
+}
+
+// FaultStats 获取故障注入统计
+func (c *FaultyClient) FaultStats() FaultStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// decide 按规则决定命令的故障
+func (c *FaultyClient) decide(name string, keys []string) faultResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Calls++
+
+	var result faultResult
+	for _, rule := range c.faults {
+		if !rule.matches(name, keys) {
+			continue
+		}
+		if rule.Rate > 0 && rule.Rate < 1 && c.rand.Float64() >= rule.Rate {
+			continue
+		}
+		rule.hits++
+
+		result.latency += rule.Latency
+		if rule.Err != nil {
+			result.err = rule.Err
+			break
+		}
+		if rule.DropWrite && isWriteCommand(name) {
+			result.drop = true
+			break
+		}
+	}
+
+	if result.latency > 0 {
+		c.stats.Delayed++
+	}
+	if result.err != nil {
+		c.stats.Errors++
+	}
+	if result.drop {
+		c.stats.Dropped++
+	}
+	return result
+}
+
+// matches 判断命令是否匹配规则且规则还能触发
+func (r *faultRule) matches(name string, keys []string) bool {
+	if r.Times > 0 && r.hits >= r.Times {
+		return false
+	}
+	if r.commands != nil && !r.commands[name] {
+		return false
+	}
+	if r.KeyPattern == "" {
+		return true
+	}
+	for _, key := range keys {
+		if matchPattern(r.KeyPattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWriteCommand 判断命令是否修改数据
+func isWriteCommand(name string) bool {
+	switch name {
+	case "eval", "evalsha", "fcall":
+		return true
+	}
+	return commands[name].write
+}
+
+// sleep 等待注入的延迟，上下文先结束时返回上下文的错误
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// inject 按规则为命令注入故障，未被注入错误或丢弃时调用fn执行命令
+func inject[T any](c *FaultyClient, ctx context.Context, name string, keys []string, fn func() (T, error)) (T, error) {
+	var zero T
+	result := c.decide(name, keys)
+	if err := sleep(ctx, result.latency); err != nil {
+		return zero, err
+	}
+	if result.err != nil {
+		return zero, result.err
+	}
+	if result.drop {
+		return zero, nil
+	}
+	return fn()
+}
+
+// injectErr 为只返回错误的命令注入故障
+func injectErr(c *FaultyClient, ctx context.Context, name string, keys []string, fn func() error) error {
+	_, err := inject(c, ctx, name, keys, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// keyList 单个键的键列表
+func keyList(key string) []string {
+	return []string{key}
+}
+
+// pairKeys 键值对中的键
+func pairKeys(pairs []interface{}) []string {
+	keys := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		keys = append(keys, fmt.Sprint(pairs[i]))
+	}
+	return keys
+}
+
+// 基础操作
+
+// Ping 检查连接
+func (c *FaultyClient) Ping(ctx context.Context) error {
+	return injectErr(c, ctx, "ping", nil, func() error {
+		return c.Client.Ping(ctx)
+	})
+}
+
+// 字符串操作
+
+// Get 获取字符串值
+func (c *FaultyClient) Get(ctx context.Context, key string) (string, error) {
+	return inject(c, ctx, "get", keyList(key), func() (string, error) {
+		return c.Client.Get(ctx, key)
+	})
+}
+
+// Set 设置字符串值
+func (c *FaultyClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return injectErr(c, ctx, "set", keyList(key), func() error {
+		return c.Client.Set(ctx, key, value, expiration)
+	})
+}
+
+// SetNX 键不存在时设置字符串值
+func (c *FaultyClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return inject(c, ctx, "setnx", keyList(key), func() (bool, error) {
+		return c.Client.SetNX(ctx, key, value, expiration)
+	})
+}
+
+// GetSet 设置新值并返回旧值
+func (c *FaultyClient) GetSet(ctx context.Context, key string, value interface{}) (string, error) {
+	return inject(c, ctx, "getset", keyList(key), func() (string, error) {
+		return c.Client.GetSet(ctx, key, value)
+	})
+}
+
+// MGet 批量获取多个键的值
+func (c *FaultyClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return inject(c, ctx, "mget", keys, func() ([]interface{}, error) {
+		return c.Client.MGet(ctx, keys...)
+	})
+}
+
+// MSet 批量设置多个键值对
+func (c *FaultyClient) MSet(ctx context.Context, pairs ...interface{}) error {
+	return injectErr(c, ctx, "mset", pairKeys(pairs), func() error {
+		return c.Client.MSet(ctx, pairs...)
+	})
+}
+
+// Incr 将键的整数值加1
+func (c *FaultyClient) Incr(ctx context.Context, key string) (int64, error) {
+	return inject(c, ctx, "incr", keyList(key), func() (int64, error) {
+		return c.Client.Incr(ctx, key)
+	})
+}
+
+// IncrBy 将键的整数值增加指定值
+func (c *FaultyClient) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	return inject(c, ctx, "incrby", keyList(key), func() (int64, error) {
+		return c.Client.IncrBy(ctx, key, value)
+	})
+}
+
+// Decr 将键的整数值减1
+func (c *FaultyClient) Decr(ctx context.Context, key string) (int64, error) {
+	return inject(c, ctx, "decr", keyList(key), func() (int64, error) {
+		return c.Client.Decr(ctx, key)
+	})
+}
+
+// DecrBy 将键的整数值减少指定值
+func (c *FaultyClient) DecrBy(ctx context.Context, key string, value int64) (int64, error) {
+	return inject(c, ctx, "decrby", keyList(key), func() (int64, error) {
+		return c.Client.DecrBy(ctx, key, value)
+	})
+}
+
+// 哈希表操作
+
+// HGet 获取哈希表字段值
+func (c *FaultyClient) HGet(ctx context.Context, key, field string) (string, error) {
+	return inject(c, ctx, "hget", keyList(key), func() (string, error) {
+		return c.Client.HGet(ctx, key, field)
+	})
+}
+
+// HSet 设置哈希表字段值
+func (c *FaultyClient) HSet(ctx context.Context, key, field string, value interface{}) error {
+	return injectErr(c, ctx, "hset", keyList(key), func() error {
+		return c.Client.HSet(ctx, key, field, value)
+	})
+}
+
+// HSetNX 字段不存在时设置哈希表字段值
+func (c *FaultyClient) HSetNX(ctx context.Context, key, field string, value interface{}) (bool, error) {
+	return inject(c, ctx, "hsetnx", keyList(key), func() (bool, error) {
+		return c.Client.HSetNX(ctx, key, field, value)
+	})
+}
+
+// HDel 删除哈希表字段
+func (c *FaultyClient) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	return inject(c, ctx, "hdel", keyList(key), func() (int64, error) {
+		return c.Client.HDel(ctx, key, fields...)
+	})
+}
+
+// HExists 判断哈希表字段是否存在
+func (c *FaultyClient) HExists(ctx context.Context, key, field string) (bool, error) {
+	return inject(c, ctx, "hexists", keyList(key), func() (bool, error) {
+		return c.Client.HExists(ctx, key, field)
+	})
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (c *FaultyClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return inject(c, ctx, "hgetall", keyList(key), func() (map[string]string, error) {
+		return c.Client.HGetAll(ctx, key)
+	})
+}
+
+// HKeys 获取哈希表所有字段
+func (c *FaultyClient) HKeys(ctx context.Context, key string) ([]string, error) {
+	return inject(c, ctx, "hkeys", keyList(key), func() ([]string, error) {
+		return c.Client.HKeys(ctx, key)
+	})
+}
+
+// HVals 获取哈希表所有值
+func (c *FaultyClient) HVals(ctx context.Context, key string) ([]string, error) {
+	return inject(c, ctx, "hvals", keyList(key), func() ([]string, error) {
+		return c.Client.HVals(ctx, key)
+	})
+}
+
+// HLen 获取哈希表字段数量
+func (c *FaultyClient) HLen(ctx context.Context, key string) (int64, error) {
+	return inject(c, ctx, "hlen", keyList(key), func() (int64, error) {
+		return c.Client.HLen(ctx, key)
+	})
+}
+
+// HMGet 批量获取哈希表字段值
+func (c *FaultyClient) HMGet(ctx context.Context, key string, fields ...string) ([]interface{}, error) {
+	return inject(c, ctx, "hmget", keyList(key), func() ([]interface{}, error) {
+		return c.Client.HMGet(ctx, key, fields...)
+	})
+}
+
+// HMSet 批量设置哈希表字段值
+func (c *FaultyClient) HMSet(ctx context.Context, key string, pairs ...interface{}) error {
+	return injectErr(c, ctx, "hmset", keyList(key), func() error {
+		return c.Client.HMSet(ctx, key, pairs...)
+	})
+}
+
+// HIncrBy 将哈希表字段的整数值增加指定值
+func (c *FaultyClient) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	return inject(c, ctx, "hincrby", keyList(key), func() (int64, error) {
+		return c.Client.HIncrBy(ctx, key, field, incr)
+	})
+}
+
+// 列表操作
+
+// LPush 从列表左侧插入元素
+func (c *FaultyClient) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	return inject(c, ctx, "lpush", keyList(key), func() (int64, error) {
+		return c.Client.LPush(ctx, key, values...)
+	})
+}
+
+// RPush 从列表右侧插入元素
+func (c *FaultyClient) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	return inject(c, ctx, "rpush", keyList(key), func() (int64, error) {
+		return c.Client.RPush(ctx, key, values...)
+	})
+}
+
+// LPop 从列表左侧弹出元素
+func (c *FaultyClient) LPop(ctx context.Context, key string) (string, error) {
+	return inject(c, ctx, "lpop", keyList(key), func() (string, error) {
+		return c.Client.LPop(ctx, key)
+	})
+}
+
+// RPop 从列表右侧弹出元素
+func (c *FaultyClient) RPop(ctx context.Context, key string) (string, error) {
+	return inject(c, ctx, "rpop", keyList(key), func() (string, error) {
+		return c.Client.RPop(ctx, key)
+	})
+}
+
+// LLen 获取列表长度
+func (c *FaultyClient) LLen(ctx context.Context, key string) (int64, error) {
+	return inject(c, ctx, "llen", keyList(key), func() (int64, error) {
+		return c.Client.LLen(ctx, key)
+	})
+}
+
+// LRange 获取列表指定范围的元素
+func (c *FaultyClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return inject(c, ctx, "lrange", keyList(key), func() ([]string, error) {
+		return c.Client.LRange(ctx, key, start, stop)
+	})
+}
+
+// LIndex 获取列表指定位置的元素
+func (c *FaultyClient) LIndex(ctx context.Context, key string, index int64) (string, error) {
+	return inject(c, ctx, "lindex", keyList(key), func() (string, error) {
+		return c.Client.LIndex(ctx, key, index)
+	})
+}
+
+// LSet 设置列表指定位置的元素
+func (c *FaultyClient) LSet(ctx context.Context, key string, index int64, value interface{}) error {
+	return injectErr(c, ctx, "lset", keyList(key), func() error {
+		return c.Client.LSet(ctx, key, index, value)
+	})
+}
+
+// LRem 删除列表中的元素
+func (c *FaultyClient) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
+	return inject(c, ctx, "lrem", keyList(key), func() (int64, error) {
+		return c.Client.LRem(ctx, key, count, value)
+	})
+}
+
+// LTrim 裁剪列表
+func (c *FaultyClient) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return injectErr(c, ctx, "ltrim", keyList(key), func() error {
+		return c.Client.LTrim(ctx, key, start, stop)
+	})
+}
+
+// 集合操作
+
+// SAdd 向集合添加成员
+func (c *FaultyClient) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return inject(c, ctx, "sadd", keyList(key), func() (int64, error) {
+		return c.Client.SAdd(ctx, key, members...)
+	})
+}
+
+// SRem 从集合删除成员
+func (c *FaultyClient) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return inject(c, ctx, "srem", keyList(key), func() (int64, error) {
+		return c.Client.SRem(ctx, key, members...)
+	})
+}
+
+// SMembers 获取集合所有成员
+func (c *FaultyClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	return inject(c, ctx, "smembers", keyList(key), func() ([]string, error) {
+		return c.Client.SMembers(ctx, key)
+	})
+}
+
+// SIsMember 判断是否为集合成员
+func (c *FaultyClient) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
+	return inject(c, ctx, "sismember", keyList(key), func() (bool, error) {
+		return c.Client.SIsMember(ctx, key, member)
+	})
+}
+
+// SCard 获取集合成员数量
+func (c *FaultyClient) SCard(ctx context.Context, key string) (int64, error) {
+	return inject(c, ctx, "scard", keyList(key), func() (int64, error) {
+		return c.Client.SCard(ctx, key)
+	})
+}
+
+// SPop 随机弹出集合成员
+func (c *FaultyClient) SPop(ctx context.Context, key string) (string, error) {
+	return inject(c, ctx, "spop", keyList(key), func() (string, error) {
+		return c.Client.SPop(ctx, key)
+	})
+}
+
+// SRandMember 随机获取集合成员
+func (c *FaultyClient) SRandMember(ctx context.Context, key string) (string, error) {
+	return inject(c, ctx, "srandmember", keyList(key), func() (string, error) {
+		return c.Client.SRandMember(ctx, key)
+	})
+}
+
+// SInter 获取多个集合的交集
+func (c *FaultyClient) SInter(ctx context.Context, keys ...string) ([]string, error) {
+	return inject(c, ctx, "sinter", keys, func() ([]string, error) {
+		return c.Client.SInter(ctx, keys...)
+	})
+}
+
+// SUnion 获取多个集合的并集
+func (c *FaultyClient) SUnion(ctx context.Context, keys ...string) ([]string, error) {
+	return inject(c, ctx, "sunion", keys, func() ([]string, error) {
+		return c.Client.SUnion(ctx, keys...)
+	})
+}
+
+// SDiff 获取多个集合的差集
+func (c *FaultyClient) SDiff(ctx context.Context, keys ...string) ([]string, error) {
+	return inject(c, ctx, "sdiff", keys, func() ([]string, error) {
+		return c.Client.SDiff(ctx, keys...)
+	})
+}
+
+// 有序集合操作
+
+// ZAdd 向有序集合添加成员
+func (c *FaultyClient) ZAdd(ctx context.Context, key string, members ...cache.ZMember) (int64, error) {
+	return inject(c, ctx, "zadd", keyList(key), func() (int64, error) {
+		return c.Client.ZAdd(ctx, key, members...)
+	})
+}
+
+// ZRem 从有序集合删除成员
+func (c *FaultyClient) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return inject(c, ctx, "zrem", keyList(key), func() (int64, error) {
+		return c.Client.ZRem(ctx, key, members...)
+	})
+}
+
+// ZScore 获取有序集合成员的分数
+func (c *FaultyClient) ZScore(ctx context.Context, key, member string) (float64, error) {
+	return inject(c, ctx, "zscore", keyList(key), func() (float64, error) {
+		return c.Client.ZScore(ctx, key, member)
+	})
+}
+
+// ZRank 获取有序集合成员的排名
+func (c *FaultyClient) ZRank(ctx context.Context, key, member string) (int64, error) {
+	return inject(c, ctx, "zrank", keyList(key), func() (int64, error) {
+		return c.Client.ZRank(ctx, key, member)
+	})
+}
+
+// ZRevRank 获取有序集合成员的倒序排名
+func (c *FaultyClient) ZRevRank(ctx context.Context, key, member string) (int64, error) {
+	return inject(c, ctx, "zrevrank", keyList(key), func() (int64, error) {
+		return c.Client.ZRevRank(ctx, key, member)
+	})
+}
+
+// ZRange 按排名范围获取有序集合成员
+func (c *FaultyClient) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return inject(c, ctx, "zrange", keyList(key), func() ([]string, error) {
+		return c.Client.ZRange(ctx, key, start, stop)
+	})
+}
+
+// ZRevRange 按排名范围倒序获取有序集合成员
+func (c *FaultyClient) ZRevRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return inject(c, ctx, "zrevrange", keyList(key), func() ([]string, error) {
+		return c.Client.ZRevRange(ctx, key, start, stop)
+	})
+}
+
+// ZRangeWithScores 按排名范围获取有序集合成员及分数
+func (c *FaultyClient) ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]cache.ZMember, error) {
+	return inject(c, ctx, "zrange", keyList(key), func() ([]cache.ZMember, error) {
+		return c.Client.ZRangeWithScores(ctx, key, start, stop)
+	})
+}
+
+// ZRevRangeWithScores 按排名范围倒序获取有序集合成员及分数
+func (c *FaultyClient) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]cache.ZMember, error) {
+	return inject(c, ctx, "zrevrange", keyList(key), func() ([]cache.ZMember, error) {
+		return c.Client.ZRevRangeWithScores(ctx, key, start, stop)
+	})
+}
+
+// ZRangeByScore 按分数范围获取有序集合成员
+func (c *FaultyClient) ZRangeByScore(ctx context.Context, key string, min, max string) ([]string, error) {
+	return inject(c, ctx, "zrangebyscore", keyList(key), func() ([]string, error) {
+		return c.Client.ZRangeByScore(ctx, key, min, max)
+	})
+}
+
+// ZRevRangeByScore 按分数范围倒序获取有序集合成员
+func (c *FaultyClient) ZRevRangeByScore(ctx context.Context, key string, max, min string) ([]string, error) {
+	return inject(c, ctx, "zrevrangebyscore", keyList(key), func() ([]string, error) {
+		return c.Client.ZRevRangeByScore(ctx, key, max, min)
+	})
+}
+
+// ZCard 获取有序集合成员数量
+func (c *FaultyClient) ZCard(ctx context.Context, key string) (int64, error) {
+	return inject(c, ctx, "zcard", keyList(key), func() (int64, error) {
+		return c.Client.ZCard(ctx, key)
+	})
+}
+
+// ZCount 统计分数范围内的成员数量
+func (c *FaultyClient) ZCount(ctx context.Context, key, min, max string) (int64, error) {
+	return inject(c, ctx, "zcount", keyList(key), func() (int64, error) {
+		return c.Client.ZCount(ctx, key, min, max)
+	})
+}
+
+// ZIncrBy 增加有序集合成员的分数
+func (c *FaultyClient) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
+	return inject(c, ctx, "zincrby", keyList(key), func() (float64, error) {
+		return c.Client.ZIncrBy(ctx, key, increment, member)
+	})
+}
+
+// 通用键操作
+
+// Del 删除键
+func (c *FaultyClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	return inject(c, ctx, "del", keys, func() (int64, error) {
+		return c.Client.Del(ctx, keys...)
+	})
+}
+
+// Exists 统计存在的键数量
+func (c *FaultyClient) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return inject(c, ctx, "exists", keys, func() (int64, error) {
+		return c.Client.Exists(ctx, keys...)
+	})
+}
+
+// Expire 设置键的过期时间
+func (c *FaultyClient) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	return inject(c, ctx, "expire", keyList(key), func() (bool, error) {
+		return c.Client.Expire(ctx, key, expiration)
+	})
+}
+
+// ExpireAt 设置键的过期时间点
+func (c *FaultyClient) ExpireAt(ctx context.Context, key string, tm time.Time) (bool, error) {
+	return inject(c, ctx, "expireat", keyList(key), func() (bool, error) {
+		return c.Client.ExpireAt(ctx, key, tm)
+	})
+}
+
+// TTL 获取键的剩余过期时间
+func (c *FaultyClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return inject(c, ctx, "ttl", keyList(key), func() (time.Duration, error) {
+		return c.Client.TTL(ctx, key)
+	})
+}
+
+// Type 获取键的类型
+func (c *FaultyClient) Type(ctx context.Context, key string) (string, error) {
+	return inject(c, ctx, "type", keyList(key), func() (string, error) {
+		return c.Client.Type(ctx, key)
+	})
+}
+
+// Keys 查找匹配模式的键，模式作为键参与KeyPattern匹配
+func (c *FaultyClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return inject(c, ctx, "keys", keyList(pattern), func() ([]string, error) {
+		return c.Client.Keys(ctx, pattern)
+	})
+}
+
+// Scan 迭代键，匹配模式作为键参与KeyPattern匹配
+func (c *FaultyClient) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	type scanResult struct {
+		keys   []string
+		cursor uint64
+	}
+	result, err := inject(c, ctx, "scan", keyList(match), func() (scanResult, error) {
+		keys, next, err := c.Client.Scan(ctx, cursor, match, count)
+		return scanResult{keys, next}, err
+	})
+	return result.keys, result.cursor, err
+}
+
+// DeleteByPattern 按模式删除键，作为unlink命令注入故障，模式作为键参与KeyPattern匹配
+func (c *FaultyClient) DeleteByPattern(ctx context.Context, pattern string, opts *cache.DeleteOptions) (int64, error) {
+	return inject(c, ctx, "unlink", keyList(pattern), func() (int64, error) {
+		return c.Client.DeleteByPattern(ctx, pattern, opts)
+	})
+}
+
+// Lua脚本操作
+
+// Eval 执行Lua脚本
+func (c *FaultyClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return inject(c, ctx, "eval", keys, func() (interface{}, error) {
+		return c.Client.Eval(ctx, script, keys, args...)
+	})
+}
+
+// EvalSha 通过SHA1执行Lua脚本
+func (c *FaultyClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) (interface{}, error) {
+	return inject(c, ctx, "evalsha", keys, func() (interface{}, error) {
+		return c.Client.EvalSha(ctx, sha1, keys, args...)
+	})
+}
+
+// ScriptExists 检查脚本是否存在
+func (c *FaultyClient) ScriptExists(ctx context.Context, hashes ...string) ([]bool, error) {
+	return inject(c, ctx, "script", nil, func() ([]bool, error) {
+		return c.Client.ScriptExists(ctx, hashes...)
+	})
+}
+
+// ScriptFlush 清空脚本缓存
+func (c *FaultyClient) ScriptFlush(ctx context.Context) error {
+	return injectErr(c, ctx, "script", nil, func() error {
+		return c.Client.ScriptFlush(ctx)
+	})
+}
+
+// ScriptKill 终止正在执行的脚本
+func (c *FaultyClient) ScriptKill(ctx context.Context) error {
+	return injectErr(c, ctx, "script", nil, func() error {
+		return c.Client.ScriptKill(ctx)
+	})
+}
+
+// ScriptLoad 加载脚本
+func (c *FaultyClient) ScriptLoad(ctx context.Context, script string) (string, error) {
+	return inject(c, ctx, "script", nil, func() (string, error) {
+		return c.Client.ScriptLoad(ctx, script)
+	})
+}
+
+// Redis Functions操作
+
+// FunctionLoad 加载函数库
+func (c *FaultyClient) FunctionLoad(ctx context.Context, code string, replace bool) (string, error) {
+	return inject(c, ctx, "function", nil, func() (string, error) {
+		return c.Client.FunctionLoad(ctx, code, replace)
+	})
+}
+
+// FunctionDelete 删除函数库
+func (c *FaultyClient) FunctionDelete(ctx context.Context, library string) error {
+	return injectErr(c, ctx, "function", nil, func() error {
+		return c.Client.FunctionDelete(ctx, library)
+	})
+}
+
+// FunctionList 列出函数库
+func (c *FaultyClient) FunctionList(ctx context.Context, pattern string, withCode bool) ([]cache.FunctionLibrary, error) {
+	return inject(c, ctx, "function", nil, func() ([]cache.FunctionLibrary, error) {
+		return c.Client.FunctionList(ctx, pattern, withCode)
+	})
+}
+
+// FunctionDump 导出所有函数库
+func (c *FaultyClient) FunctionDump(ctx context.Context) (string, error) {
+	return inject(c, ctx, "function", nil, func() (string, error) {
+		return c.Client.FunctionDump(ctx)
+	})
+}
+
+// FunctionRestore 恢复函数库
+func (c *FaultyClient) FunctionRestore(ctx context.Context, payload string) error {
+	return injectErr(c, ctx, "function", nil, func() error {
+		return c.Client.FunctionRestore(ctx, payload)
+	})
+}
+
+// FCall 调用函数
+func (c *FaultyClient) FCall(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	return inject(c, ctx, "fcall", keys, func() (interface{}, error) {
+		return c.Client.FCall(ctx, function, keys, args...)
+	})
+}
+
+// FCallRO 调用只读函数
+func (c *FaultyClient) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	return inject(c, ctx, "fcall_ro", keys, func() (interface{}, error) {
+		return c.Client.FCallRO(ctx, function, keys, args...)
+	})
+}
+
+// 管道操作
+
+// Pipeline 创建管道，管道中的命令逐条匹配故障规则
+func (c *FaultyClient) Pipeline() cache.Pipeliner {
+	return &faultyPipeline{c: c, pipe: c.Client.Pipeline()}
+}
+
+// TxPipeline 创建事务管道，管道中的命令逐条匹配故障规则
+func (c *FaultyClient) TxPipeline() cache.Pipeliner {
+	return &faultyPipeline{c: c, pipe: c.Client.TxPipeline()}
+}
+
+// 乐观事务
+
+// Watch 执行乐观事务，故障只在Watch调用上注入，回调中的读写不经过故障规则
+func (c *FaultyClient) Watch(ctx context.Context, fn func(tx cache.Tx) error, keys ...string) error {
+	return injectErr(c, ctx, "watch", keys, func() error {
+		return c.Client.Watch(ctx, fn, keys...)
+	})
+}
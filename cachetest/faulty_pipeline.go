@@ -0,0 +1,631 @@
+package cachetest
+
+import (
+	"context"
+	"time"
+
+	"cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// faultyPipeline 故障注入管道
+// 命令加入管道时逐条匹配故障规则，被注入错误或丢弃的命令不发送给被包装的管道，
+// Exec时等待管道中命令的最大延迟，再执行其余命令
+type faultyPipeline struct {
+	c    *FaultyClient
+	pipe cache.Pipeliner
+	ops  []*faultyOp
+	// 管道中命令被注入的最大延迟
+	latency time.Duration
+}
+
+// faultyOp 管道中的一条命令
+type faultyOp struct {
+	cmd interface{}
+	// 注入的错误，整个管道失败时为管道的错误
+	err error
+	// 被包装管道中的命令，被注入错误或丢弃时为nil
+	inner interface{ Err() error }
+}
+
+// 确保实现了cache.Pipeliner接口
+var _ cache.Pipeliner = (*faultyPipeline)(nil)
+
+// resultCmd 被包装管道返回的命令对象
+type resultCmd[T any] interface {
+	Result() (T, error)
+	Err() error
+}
+
+// add 按规则决定命令的故障并记录到管道中，返回命令是否需要发送给被包装的管道
+func (p *faultyPipeline) add(name string, keys []string) (*faultyOp, bool) {
+	result := p.c.decide(name, keys)
+	if result.latency > p.latency {
+		p.latency = result.latency
+	}
+	op := &faultyOp{err: result.err}
+	p.ops = append(p.ops, op)
+	return op, result.err == nil && !result.drop
+}
+
+// queue 将命令加入管道，被注入错误的命令返回该错误，被丢弃的写命令返回零值
+func queue[T any, C any](p *faultyPipeline, name string, keys []string, newCmd func(result func() (T, error)) *C, send func() *C) *C {
+	op, ok := p.add(name, keys)
+	var inner resultCmd[T]
+	if ok {
+		inner = any(send()).(resultCmd[T])
+		op.inner = inner
+	}
+	cmd := newCmd(func() (T, error) {
+		if op.err != nil || inner == nil {
+			var zero T
+			return zero, op.err
+		}
+		return inner.Result()
+	})
+	op.cmd = cmd
+	return cmd
+}
+
+// Exec 执行管道，返回各命令的结果对象和第一个失败命令的错误
+// 匹配"exec"的规则使整个管道失败，此时所有命令返回该错误
+func (p *faultyPipeline) Exec(ctx context.Context) ([]interface{}, error) {
+	ops, latency := p.ops, p.latency
+	p.ops, p.latency = nil, 0
+
+	result := p.c.decide("exec", nil)
+	failed := sleep(ctx, latency+result.latency)
+	if failed == nil {
+		failed = result.err
+	}
+	if failed != nil {
+		p.pipe.Discard()
+		for _, op := range ops {
+			op.err = failed
+		}
+		return nil, failed
+	}
+
+	var execErr error
+	for _, op := range ops {
+		if op.inner != nil {
+			_, execErr = p.pipe.Exec(ctx)
+			break
+		}
+	}
+
+	results := make([]interface{}, len(ops))
+	var firstErr error
+	for i, op := range ops {
+		results[i] = op.cmd
+		if firstErr != nil {
+			continue
+		}
+		if op.err != nil {
+			firstErr = op.err
+		} else if op.inner != nil {
+			firstErr = op.inner.Err()
+		}
+	}
+	if firstErr == cache.ErrKeyNotFound {
+		firstErr = redis.Nil
+	}
+	if firstErr == nil && execErr != redis.Nil {
+		firstErr = execErr
+	}
+	return results, firstErr
+}
+
+// Discard 丢弃管道中的所有命令
+func (p *faultyPipeline) Discard() error {
+	p.ops, p.latency = nil, 0
+	return p.pipe.Discard()
+}
+
+// Close 关闭管道
+func (p *faultyPipeline) Close() error {
+	return p.pipe.Close()
+}
+
+// 字符串操作
+
+// Get 获取字符串值
+func (p *faultyPipeline) Get(ctx context.Context, key string) *cache.StringCmd {
+	return queue(p, "get", keyList(key), cache.NewStringCmd, func() *cache.StringCmd {
+		return p.pipe.Get(ctx, key)
+	})
+}
+
+// Set 设置字符串值
+func (p *faultyPipeline) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *cache.StatusCmd {
+	return queue(p, "set", keyList(key), cache.NewStatusCmd, func() *cache.StatusCmd {
+		return p.pipe.Set(ctx, key, value, expiration)
+	})
+}
+
+// SetNX 键不存在时设置字符串值
+func (p *faultyPipeline) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *cache.BoolCmd {
+	return queue(p, "setnx", keyList(key), cache.NewBoolCmd, func() *cache.BoolCmd {
+		return p.pipe.SetNX(ctx, key, value, expiration)
+	})
+}
+
+// GetSet 设置新值并返回旧值
+func (p *faultyPipeline) GetSet(ctx context.Context, key string, value interface{}) *cache.StringCmd {
+	return queue(p, "getset", keyList(key), cache.NewStringCmd, func() *cache.StringCmd {
+		return p.pipe.GetSet(ctx, key, value)
+	})
+}
+
+// MGet 批量获取多个键的值
+func (p *faultyPipeline) MGet(ctx context.Context, keys ...string) *cache.SliceCmd {
+	return queue(p, "mget", keys, cache.NewSliceCmd, func() *cache.SliceCmd {
+		return p.pipe.MGet(ctx, keys...)
+	})
+}
+
+// MSet 批量设置多个键值对
+func (p *faultyPipeline) MSet(ctx context.Context, pairs ...interface{}) *cache.StatusCmd {
+	return queue(p, "mset", pairKeys(pairs), cache.NewStatusCmd, func() *cache.StatusCmd {
+		return p.pipe.MSet(ctx, pairs...)
+	})
+}
+
+// Incr 将键的整数值加1
+func (p *faultyPipeline) Incr(ctx context.Context, key string) *cache.IntCmd {
+	return queue(p, "incr", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.Incr(ctx, key)
+	})
+}
+
+// IncrBy 将键的整数值增加指定值
+func (p *faultyPipeline) IncrBy(ctx context.Context, key string, value int64) *cache.IntCmd {
+	return queue(p, "incrby", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.IncrBy(ctx, key, value)
+	})
+}
+
+// Decr 将键的整数值减1
+func (p *faultyPipeline) Decr(ctx context.Context, key string) *cache.IntCmd {
+	return queue(p, "decr", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.Decr(ctx, key)
+	})
+}
+
+// DecrBy 将键的整数值减少指定值
+func (p *faultyPipeline) DecrBy(ctx context.Context, key string, value int64) *cache.IntCmd {
+	return queue(p, "decrby", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.DecrBy(ctx, key, value)
+	})
+}
+
+// 哈希表操作
+
+// HGet 获取哈希表字段值
+func (p *faultyPipeline) HGet(ctx context.Context, key, field string) *cache.StringCmd {
+	return queue(p, "hget", keyList(key), cache.NewStringCmd, func() *cache.StringCmd {
+		return p.pipe.HGet(ctx, key, field)
+	})
+}
+
+// HSet 设置哈希表字段值
+func (p *faultyPipeline) HSet(ctx context.Context, key, field string, value interface{}) *cache.IntCmd {
+	return queue(p, "hset", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.HSet(ctx, key, field, value)
+	})
+}
+
+// HSetNX 字段不存在时设置哈希表字段值
+func (p *faultyPipeline) HSetNX(ctx context.Context, key, field string, value interface{}) *cache.BoolCmd {
+	return queue(p, "hsetnx", keyList(key), cache.NewBoolCmd, func() *cache.BoolCmd {
+		return p.pipe.HSetNX(ctx, key, field, value)
+	})
+}
+
+// HDel 删除哈希表字段
+func (p *faultyPipeline) HDel(ctx context.Context, key string, fields ...string) *cache.IntCmd {
+	return queue(p, "hdel", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.HDel(ctx, key, fields...)
+	})
+}
+
+// HExists 判断哈希表字段是否存在
+func (p *faultyPipeline) HExists(ctx context.Context, key, field string) *cache.BoolCmd {
+	return queue(p, "hexists", keyList(key), cache.NewBoolCmd, func() *cache.BoolCmd {
+		return p.pipe.HExists(ctx, key, field)
+	})
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (p *faultyPipeline) HGetAll(ctx context.Context, key string) *cache.MapStringStringCmd {
+	return queue(p, "hgetall", keyList(key), cache.NewMapStringStringCmd, func() *cache.MapStringStringCmd {
+		return p.pipe.HGetAll(ctx, key)
+	})
+}
+
+// HKeys 获取哈希表所有字段
+func (p *faultyPipeline) HKeys(ctx context.Context, key string) *cache.StringSliceCmd {
+	return queue(p, "hkeys", keyList(key), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.HKeys(ctx, key)
+	})
+}
+
+// HVals 获取哈希表所有值
+func (p *faultyPipeline) HVals(ctx context.Context, key string) *cache.StringSliceCmd {
+	return queue(p, "hvals", keyList(key), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.HVals(ctx, key)
+	})
+}
+
+// HLen 获取哈希表字段数量
+func (p *faultyPipeline) HLen(ctx context.Context, key string) *cache.IntCmd {
+	return queue(p, "hlen", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.HLen(ctx, key)
+	})
+}
+
+// HMGet 批量获取哈希表字段值
+func (p *faultyPipeline) HMGet(ctx context.Context, key string, fields ...string) *cache.SliceCmd {
+	return queue(p, "hmget", keyList(key), cache.NewSliceCmd, func() *cache.SliceCmd {
+		return p.pipe.HMGet(ctx, key, fields...)
+	})
+}
+
+// HMSet 批量设置哈希表字段值
+func (p *faultyPipeline) HMSet(ctx context.Context, key string, pairs ...interface{}) *cache.BoolCmd {
+	return queue(p, "hmset", keyList(key), cache.NewBoolCmd, func() *cache.BoolCmd {
+		return p.pipe.HMSet(ctx, key, pairs...)
+	})
+}
+
+// HIncrBy 将哈希表字段的整数值增加指定值
+func (p *faultyPipeline) HIncrBy(ctx context.Context, key, field string, incr int64) *cache.IntCmd {
+	return queue(p, "hincrby", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.HIncrBy(ctx, key, field, incr)
+	})
+}
+
+// 列表操作
+
+// LPush 从列表左侧插入元素
+func (p *faultyPipeline) LPush(ctx context.Context, key string, values ...interface{}) *cache.IntCmd {
+	return queue(p, "lpush", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.LPush(ctx, key, values...)
+	})
+}
+
+// RPush 从列表右侧插入元素
+func (p *faultyPipeline) RPush(ctx context.Context, key string, values ...interface{}) *cache.IntCmd {
+	return queue(p, "rpush", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.RPush(ctx, key, values...)
+	})
+}
+
+// LPop 从列表左侧弹出元素
+func (p *faultyPipeline) LPop(ctx context.Context, key string) *cache.StringCmd {
+	return queue(p, "lpop", keyList(key), cache.NewStringCmd, func() *cache.StringCmd {
+		return p.pipe.LPop(ctx, key)
+	})
+}
+
+// RPop 从列表右侧弹出元素
+func (p *faultyPipeline) RPop(ctx context.Context, key string) *cache.StringCmd {
+	return queue(p, "rpop", keyList(key), cache.NewStringCmd, func() *cache.StringCmd {
+		return p.pipe.RPop(ctx, key)
+	})
+}
+
+// LLen 获取列表长度
+func (p *faultyPipeline) LLen(ctx context.Context, key string) *cache.IntCmd {
+	return queue(p, "llen", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.LLen(ctx, key)
+	})
+}
+
+// LRange 获取列表指定范围的元素
+func (p *faultyPipeline) LRange(ctx context.Context, key string, start, stop int64) *cache.StringSliceCmd {
+	return queue(p, "lrange", keyList(key), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.LRange(ctx, key, start, stop)
+	})
+}
+
+// LIndex 获取列表指定位置的元素
+func (p *faultyPipeline) LIndex(ctx context.Context, key string, index int64) *cache.StringCmd {
+	return queue(p, "lindex", keyList(key), cache.NewStringCmd, func() *cache.StringCmd {
+		return p.pipe.LIndex(ctx, key, index)
+	})
+}
+
+// LSet 设置列表指定位置的元素
+func (p *faultyPipeline) LSet(ctx context.Context, key string, index int64, value interface{}) *cache.StatusCmd {
+	return queue(p, "lset", keyList(key), cache.NewStatusCmd, func() *cache.StatusCmd {
+		return p.pipe.LSet(ctx, key, index, value)
+	})
+}
+
+// LRem 删除列表中的元素
+func (p *faultyPipeline) LRem(ctx context.Context, key string, count int64, value interface{}) *cache.IntCmd {
+	return queue(p, "lrem", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.LRem(ctx, key, count, value)
+	})
+}
+
+// LTrim 裁剪列表
+func (p *faultyPipeline) LTrim(ctx context.Context, key string, start, stop int64) *cache.StatusCmd {
+	return queue(p, "ltrim", keyList(key), cache.NewStatusCmd, func() *cache.StatusCmd {
+		return p.pipe.LTrim(ctx, key, start, stop)
+	})
+}
+
+// 集合操作
+
+// SAdd 向集合添加成员
+func (p *faultyPipeline) SAdd(ctx context.Context, key string, members ...interface{}) *cache.IntCmd {
+	return queue(p, "sadd", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.SAdd(ctx, key, members...)
+	})
+}
+
+// SRem 从集合删除成员
+func (p *faultyPipeline) SRem(ctx context.Context, key string, members ...interface{}) *cache.IntCmd {
+	return queue(p, "srem", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.SRem(ctx, key, members...)
+	})
+}
+
+// SMembers 获取集合所有成员
+func (p *faultyPipeline) SMembers(ctx context.Context, key string) *cache.StringSliceCmd {
+	return queue(p, "smembers", keyList(key), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.SMembers(ctx, key)
+	})
+}
+
+// SIsMember 判断是否为集合成员
+func (p *faultyPipeline) SIsMember(ctx context.Context, key string, member interface{}) *cache.BoolCmd {
+	return queue(p, "sismember", keyList(key), cache.NewBoolCmd, func() *cache.BoolCmd {
+		return p.pipe.SIsMember(ctx, key, member)
+	})
+}
+
+// SCard 获取集合成员数量
+func (p *faultyPipeline) SCard(ctx context.Context, key string) *cache.IntCmd {
+	return queue(p, "scard", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.SCard(ctx, key)
+	})
+}
+
+// SPop 随机弹出集合成员
+func (p *faultyPipeline) SPop(ctx context.Context, key string) *cache.StringCmd {
+	return queue(p, "spop", keyList(key), cache.NewStringCmd, func() *cache.StringCmd {
+		return p.pipe.SPop(ctx, key)
+	})
+}
+
+// SRandMember 随机获取集合成员
+func (p *faultyPipeline) SRandMember(ctx context.Context, key string) *cache.StringCmd {
+	return queue(p, "srandmember", keyList(key), cache.NewStringCmd, func() *cache.StringCmd {
+		return p.pipe.SRandMember(ctx, key)
+	})
+}
+
+// SInter 获取多个集合的交集
+func (p *faultyPipeline) SInter(ctx context.Context, keys ...string) *cache.StringSliceCmd {
+	return queue(p, "sinter", keys, cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.SInter(ctx, keys...)
+	})
+}
+
+// SUnion 获取多个集合的并集
+func (p *faultyPipeline) SUnion(ctx context.Context, keys ...string) *cache.StringSliceCmd {
+	return queue(p, "sunion", keys, cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.SUnion(ctx, keys...)
+	})
+}
+
+// SDiff 获取多个集合的差集
+func (p *faultyPipeline) SDiff(ctx context.Context, keys ...string) *cache.StringSliceCmd {
+	return queue(p, "sdiff", keys, cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.SDiff(ctx, keys...)
+	})
+}
+
+// 有序集合操作
+
+// ZAdd 向有序集合添加成员
+func (p *faultyPipeline) ZAdd(ctx context.Context, key string, members ...cache.ZMember) *cache.IntCmd {
+	return queue(p, "zadd", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.ZAdd(ctx, key, members...)
+	})
+}
+
+// ZRem 从有序集合删除成员
+func (p *faultyPipeline) ZRem(ctx context.Context, key string, members ...interface{}) *cache.IntCmd {
+	return queue(p, "zrem", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.ZRem(ctx, key, members...)
+	})
+}
+
+// ZScore 获取有序集合成员的分数
+func (p *faultyPipeline) ZScore(ctx context.Context, key, member string) *cache.FloatCmd {
+	return queue(p, "zscore", keyList(key), cache.NewFloatCmd, func() *cache.FloatCmd {
+		return p.pipe.ZScore(ctx, key, member)
+	})
+}
+
+// ZRank 获取有序集合成员的排名
+func (p *faultyPipeline) ZRank(ctx context.Context, key, member string) *cache.IntCmd {
+	return queue(p, "zrank", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.ZRank(ctx, key, member)
+	})
+}
+
+// ZRevRank 获取有序集合成员的倒序排名
+func (p *faultyPipeline) ZRevRank(ctx context.Context, key, member string) *cache.IntCmd {
+	return queue(p, "zrevrank", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.ZRevRank(ctx, key, member)
+	})
+}
+
+// ZRange 按排名范围获取有序集合成员
+func (p *faultyPipeline) ZRange(ctx context.Context, key string, start, stop int64) *cache.StringSliceCmd {
+	return queue(p, "zrange", keyList(key), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.ZRange(ctx, key, start, stop)
+	})
+}
+
+// ZRevRange 按排名范围倒序获取有序集合成员
+func (p *faultyPipeline) ZRevRange(ctx context.Context, key string, start, stop int64) *cache.StringSliceCmd {
+	return queue(p, "zrevrange", keyList(key), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.ZRevRange(ctx, key, start, stop)
+	})
+}
+
+// ZRangeWithScores 按排名范围获取有序集合成员及分数
+func (p *faultyPipeline) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *cache.ZSliceCmd {
+	return queue(p, "zrange", keyList(key), cache.NewZSliceCmd, func() *cache.ZSliceCmd {
+		return p.pipe.ZRangeWithScores(ctx, key, start, stop)
+	})
+}
+
+// ZRevRangeWithScores 按排名范围倒序获取有序集合成员及分数
+func (p *faultyPipeline) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) *cache.ZSliceCmd {
+	return queue(p, "zrevrange", keyList(key), cache.NewZSliceCmd, func() *cache.ZSliceCmd {
+		return p.pipe.ZRevRangeWithScores(ctx, key, start, stop)
+	})
+}
+
+// ZRangeByScore 按分数范围获取有序集合成员
+func (p *faultyPipeline) ZRangeByScore(ctx context.Context, key string, min, max string) *cache.StringSliceCmd {
+	return queue(p, "zrangebyscore", keyList(key), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.ZRangeByScore(ctx, key, min, max)
+	})
+}
+
+// ZRevRangeByScore 按分数范围倒序获取有序集合成员
+func (p *faultyPipeline) ZRevRangeByScore(ctx context.Context, key string, max, min string) *cache.StringSliceCmd {
+	return queue(p, "zrevrangebyscore", keyList(key), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.ZRevRangeByScore(ctx, key, max, min)
+	})
+}
+
+// ZCard 获取有序集合成员数量
+func (p *faultyPipeline) ZCard(ctx context.Context, key string) *cache.IntCmd {
+	return queue(p, "zcard", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.ZCard(ctx, key)
+	})
+}
+
+// ZCount 统计分数范围内的成员数量
+func (p *faultyPipeline) ZCount(ctx context.Context, key, min, max string) *cache.IntCmd {
+	return queue(p, "zcount", keyList(key), cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.ZCount(ctx, key, min, max)
+	})
+}
+
+// ZIncrBy 增加有序集合成员的分数
+func (p *faultyPipeline) ZIncrBy(ctx context.Context, key string, increment float64, member string) *cache.FloatCmd {
+	return queue(p, "zincrby", keyList(key), cache.NewFloatCmd, func() *cache.FloatCmd {
+		return p.pipe.ZIncrBy(ctx, key, increment, member)
+	})
+}
+
+// 通用操作
+
+// Del 删除键
+func (p *faultyPipeline) Del(ctx context.Context, keys ...string) *cache.IntCmd {
+	return queue(p, "del", keys, cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.Del(ctx, keys...)
+	})
+}
+
+// Exists 统计存在的键数量
+func (p *faultyPipeline) Exists(ctx context.Context, keys ...string) *cache.IntCmd {
+	return queue(p, "exists", keys, cache.NewIntCmd, func() *cache.IntCmd {
+		return p.pipe.Exists(ctx, keys...)
+	})
+}
+
+// Expire 设置键的过期时间
+func (p *faultyPipeline) Expire(ctx context.Context, key string, expiration time.Duration) *cache.BoolCmd {
+	return queue(p, "expire", keyList(key), cache.NewBoolCmd, func() *cache.BoolCmd {
+		return p.pipe.Expire(ctx, key, expiration)
+	})
+}
+
+// ExpireAt 设置键的过期时间点
+func (p *faultyPipeline) ExpireAt(ctx context.Context, key string, tm time.Time) *cache.BoolCmd {
+	return queue(p, "expireat", keyList(key), cache.NewBoolCmd, func() *cache.BoolCmd {
+		return p.pipe.ExpireAt(ctx, key, tm)
+	})
+}
+
+// TTL 获取键的剩余过期时间
+func (p *faultyPipeline) TTL(ctx context.Context, key string) *cache.DurationCmd {
+	return queue(p, "ttl", keyList(key), cache.NewDurationCmd, func() *cache.DurationCmd {
+		return p.pipe.TTL(ctx, key)
+	})
+}
+
+// Type 获取键的类型
+func (p *faultyPipeline) Type(ctx context.Context, key string) *cache.StatusCmd {
+	return queue(p, "type", keyList(key), cache.NewStatusCmd, func() *cache.StatusCmd {
+		return p.pipe.Type(ctx, key)
+	})
+}
+
+// Keys 查找匹配模式的键，模式作为键参与KeyPattern匹配
+func (p *faultyPipeline) Keys(ctx context.Context, pattern string) *cache.StringSliceCmd {
+	return queue(p, "keys", keyList(pattern), cache.NewStringSliceCmd, func() *cache.StringSliceCmd {
+		return p.pipe.Keys(ctx, pattern)
+	})
+}
+
+// Scan 迭代键，匹配模式作为键参与KeyPattern匹配
+func (p *faultyPipeline) Scan(ctx context.Context, cursor uint64, match string, count int64) *cache.ScanCmd {
+	op, ok := p.add("scan", keyList(match))
+	var inner *cache.ScanCmd
+	if ok {
+		inner = p.pipe.Scan(ctx, cursor, match, count)
+		op.inner = inner
+	}
+	cmd := cache.NewScanCmd(func() ([]string, uint64, error) {
+		if op.err != nil || inner == nil {
+			return nil, 0, op.err
+		}
+		return inner.Result()
+	})
+	op.cmd = cmd
+	return cmd
+}
+
+// Lua脚本操作
+
+// Eval 执行Lua脚本
+func (p *faultyPipeline) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *cache.Cmd {
+	return queue(p, "eval", keys, cache.NewCmd, func() *cache.Cmd {
+		return p.pipe.Eval(ctx, script, keys, args...)
+	})
+}
+
+// EvalSha 通过SHA1执行Lua脚本
+func (p *faultyPipeline) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *cache.Cmd {
+	return queue(p, "evalsha", keys, cache.NewCmd, func() *cache.Cmd {
+		return p.pipe.EvalSha(ctx, sha1, keys, args...)
+	})
+}
+
+// Redis Functions操作
+
+// FCall 调用函数
+func (p *faultyPipeline) FCall(ctx context.Context, function string, keys []string, args ...interface{}) *cache.Cmd {
+	return queue(p, "fcall", keys, cache.NewCmd, func() *cache.Cmd {
+		return p.pipe.FCall(ctx, function, keys, args...)
+	})
+}
+
+// FCallRO 调用只读函数
+func (p *faultyPipeline) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) *cache.Cmd {
+	return queue(p, "fcall_ro", keys, cache.NewCmd, func() *cache.Cmd {
+		return p.pipe.FCallRO(ctx, function, keys, args...)
+	})
+}
@@ -0,0 +1,2094 @@
+package cachetest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 内嵌的Lua解释器，实现Redis脚本常用的Lua 5.1子集：
+// local变量和赋值、if/elseif/else、while、repeat、数值for和for in（ipairs、pairs）、do、break、return，
+// 算术、比较、逻辑、..和#运算，表的构造和索引，以及基础库中常用的函数。
+// 不支持定义函数、元表、变长参数和字符串的方法调用（如s:sub(1, 2)）
+
+// luaValue Lua的值：nil、bool、float64（Lua 5.1的数字都是双精度浮点数）、string、*luaTable或*luaFunction
+type luaValue interface{}
+
+// luaTable Lua的表，从1开始的连续整数键保存在数组部分
+type luaTable struct {
+	array []luaValue
+	hash  map[luaValue]luaValue
+}
+
+// luaFunction 内置函数
+type luaFunction struct {
+	name string
+	fn   func(args []luaValue) ([]luaValue, error)
+}
+
+// luaError 脚本运行时的错误，value为error的参数或错误消息，line为出错的行号
+type luaError struct {
+	value luaValue
+	line  int
+}
+
+func (e *luaError) Error() string {
+	if msg, ok := e.value.(string); ok {
+		return msg
+	}
+	return luaTypeName(e.value) + " error"
+}
+
+// luaErrorf 创建运行时错误，行号在执行语句时补上
+func luaErrorf(format string, args ...interface{}) error {
+	return &luaError{value: fmt.Sprintf(format, args...)}
+}
+
+// luaMaxSteps 脚本最多执行的语句数，超过时报错，避免死循环使测试挂起
+const luaMaxSteps = 10_000_000
+
+func newLuaTable(items ...luaValue) *luaTable {
+	return &luaTable{array: items}
+}
+
+// get 读取表中的值，键不存在时返回nil
+func (t *luaTable) get(key luaValue) luaValue {
+	if n, ok := key.(float64); ok {
+		if i := int(n); float64(i) == n && i >= 1 && i <= len(t.array) {
+			return t.array[i-1]
+		}
+	}
+	if t.hash == nil {
+		return nil
+	}
+	return t.hash[key]
+}
+
+// set 写入表中的值，值为nil时删除键
+func (t *luaTable) set(key, val luaValue) error {
+	switch k := key.(type) {
+	case nil:
+		return luaErrorf("table index is nil")
+	case float64:
+		if math.IsNaN(k) {
+			return luaErrorf("table index is NaN")
+		}
+		if i := int(k); float64(i) == k && i >= 1 && i <= len(t.array)+1 {
+			if i <= len(t.array) {
+				t.array[i-1] = val
+				for len(t.array) > 0 && t.array[len(t.array)-1] == nil {
+					t.array = t.array[:len(t.array)-1]
+				}
+				return nil
+			}
+			if val == nil {
+				delete(t.hash, key)
+				return nil
+			}
+			t.array = append(t.array, val)
+			delete(t.hash, key)
+			// 数组部分变长后，把哈希部分中紧接着的整数键移入数组部分
+			for len(t.hash) > 0 {
+				next := float64(len(t.array) + 1)
+				v, ok := t.hash[next]
+				if !ok {
+					break
+				}
+				t.array = append(t.array, v)
+				delete(t.hash, next)
+			}
+			return nil
+		}
+	}
+	if val == nil {
+		delete(t.hash, key)
+		return nil
+	}
+	if t.hash == nil {
+		t.hash = make(map[luaValue]luaValue)
+	}
+	t.hash[key] = val
+	return nil
+}
+
+// keys 按确定的顺序返回表中的所有键：先数组部分，再按类型和值排序的哈希部分
+func (t *luaTable) keys() []luaValue {
+	keys := make([]luaValue, 0, len(t.array)+len(t.hash))
+	for i, v := range t.array {
+		if v != nil {
+			keys = append(keys, float64(i+1))
+		}
+	}
+	hashKeys := make([]luaValue, 0, len(t.hash))
+	for k := range t.hash {
+		hashKeys = append(hashKeys, k)
+	}
+	sort.Slice(hashKeys, func(i, j int) bool {
+		a, b := hashKeys[i], hashKeys[j]
+		if ta, tb := luaTypeName(a), luaTypeName(b); ta != tb {
+			return ta < tb
+		}
+		switch a := a.(type) {
+		case float64:
+			return a < b.(float64)
+		case string:
+			return a < b.(string)
+		case bool:
+			return !a && b.(bool)
+		}
+		return false
+	})
+	return append(keys, hashKeys...)
+}
+
+// 词法分析
+
+// 词法单元的类型，关键字和运算符都是luaSymbol
+const (
+	luaEOF = iota
+	luaName
+	luaNumber
+	luaString
+	luaSymbol
+)
+
+type luaToken struct {
+	kind int
+	text string
+	num  float64
+	line int
+}
+
+var luaKeywords = map[string]bool{
+	"and": true, "break": true, "do": true, "else": true, "elseif": true, "end": true,
+	"false": true, "for": true, "function": true, "if": true, "in": true, "local": true,
+	"nil": true, "not": true, "or": true, "repeat": true, "return": true, "then": true,
+	"true": true, "until": true, "while": true,
+}
+
+// luaSyntaxError 编译错误
+type luaSyntaxError struct {
+	msg  string
+	line int
+}
+
+func (e *luaSyntaxError) Error() string {
+	return fmt.Sprintf("user_script:%d: %s", e.line, e.msg)
+}
+
+// luaTokenize 将源码切分为词法单元
+func luaTokenize(src string) ([]luaToken, error) {
+	var tokens []luaToken
+	line := 1
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case strings.HasPrefix(src[i:], "--"):
+			i += 2
+			if level, ok := luaLongBracket(src[i:]); ok {
+				body, n, err := luaReadLong(src[i:], level)
+				if err != nil {
+					return nil, &luaSyntaxError{msg: "unfinished long comment", line: line}
+				}
+				line += strings.Count(body, "\n")
+				i += n
+				continue
+			}
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			word := src[start:i]
+			kind := luaName
+			if luaKeywords[word] {
+				kind = luaSymbol
+			}
+			tokens = append(tokens, luaToken{kind: kind, text: word, line: line})
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			if strings.HasPrefix(src[i:], "0x") || strings.HasPrefix(src[i:], "0X") {
+				i += 2
+				for i < len(src) && isHexDigit(src[i]) {
+					i++
+				}
+			} else {
+				for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+					i++
+				}
+				if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+					i++
+					if i < len(src) && (src[i] == '+' || src[i] == '-') {
+						i++
+					}
+					for i < len(src) && isDigit(src[i]) {
+						i++
+					}
+				}
+			}
+			// 数字后紧跟字母说明格式错误，如3x
+			for i < len(src) && (src[i] == '_' || isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			num, ok := luaParseNumber(src[start:i])
+			if !ok {
+				return nil, &luaSyntaxError{msg: fmt.Sprintf("malformed number near '%s'", src[start:i]), line: line}
+			}
+			tokens = append(tokens, luaToken{kind: luaNumber, text: src[start:i], num: num, line: line})
+		case c == '"' || c == '\'':
+			str, n, err := luaReadString(src[i:])
+			if err != nil {
+				return nil, &luaSyntaxError{msg: err.Error(), line: line}
+			}
+			tokens = append(tokens, luaToken{kind: luaString, text: str, line: line})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case c == '[':
+			if level, ok := luaLongBracket(src[i:]); ok {
+				body, n, err := luaReadLong(src[i:], level)
+				if err != nil {
+					return nil, &luaSyntaxError{msg: "unfinished long string", line: line}
+				}
+				tokens = append(tokens, luaToken{kind: luaString, text: strings.TrimPrefix(body, "\n"), line: line})
+				line += strings.Count(src[i:i+n], "\n")
+				i += n
+				continue
+			}
+			tokens = append(tokens, luaToken{kind: luaSymbol, text: "[", line: line})
+			i++
+		default:
+			symbol := ""
+			for _, s := range []string{"...", "==", "~=", "<=", ">=", ".."} {
+				if strings.HasPrefix(src[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				if !strings.ContainsRune("+-*/%^#<>=(){}];:,.", rune(c)) {
+					return nil, &luaSyntaxError{msg: fmt.Sprintf("unexpected symbol near '%c'", c), line: line}
+				}
+				symbol = string(c)
+			}
+			tokens = append(tokens, luaToken{kind: luaSymbol, text: symbol, line: line})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, luaToken{kind: luaEOF, text: "<eof>", line: line}), nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// luaLongBracket 检查是否以长括号[[或[==[开头，返回等号的个数
+func luaLongBracket(s string) (int, bool) {
+	if !strings.HasPrefix(s, "[") {
+		return 0, false
+	}
+	level := 1
+	for level < len(s) && s[level] == '=' {
+		level++
+	}
+	if level < len(s) && s[level] == '[' {
+		return level - 1, true
+	}
+	return 0, false
+}
+
+// luaReadLong 读取长括号中的内容，返回内容和消耗的字节数
+func luaReadLong(s string, level int) (string, int, error) {
+	open := level + 2
+	closing := "]" + strings.Repeat("=", level) + "]"
+	end := strings.Index(s[open:], closing)
+	if end < 0 {
+		return "", 0, fmt.Errorf("unfinished long bracket")
+	}
+	return s[open : open+end], open + end + len(closing), nil
+}
+
+// luaReadString 读取引号中的字符串并处理转义，返回字符串和消耗的字节数
+func luaReadString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	i := 1
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\n':
+			return "", 0, fmt.Errorf("unfinished string")
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch e := s[i]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'a':
+				b.WriteByte('\a')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'v':
+				b.WriteByte('\v')
+			case '\\', '"', '\'', '\n':
+				b.WriteByte(e)
+			default:
+				if !isDigit(e) {
+					return "", 0, fmt.Errorf("invalid escape sequence '\\%c'", e)
+				}
+				n := 0
+				for j := 0; j < 3 && i < len(s) && isDigit(s[i]); j++ {
+					n = n*10 + int(s[i]-'0')
+					i++
+				}
+				if n > 255 {
+					return "", 0, fmt.Errorf("escape sequence too large")
+				}
+				b.WriteByte(byte(n))
+				continue
+			}
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("unfinished string")
+}
+
+// 语法树
+
+type (
+	luaStmt interface{}
+	luaExpr interface{}
+)
+
+type (
+	// luaLocal local a, b = x, y
+	luaLocal struct {
+		names []string
+		exprs []luaExpr
+		line  int
+	}
+	// luaAssign a, t[k] = x, y
+	luaAssign struct {
+		targets []luaExpr
+		exprs   []luaExpr
+		line    int
+	}
+	// luaCallStmt 作为语句的函数调用
+	luaCallStmt struct {
+		call *luaCall
+		line int
+	}
+	// luaIf if/elseif/else，conds和blocks一一对应
+	luaIf struct {
+		conds     []luaExpr
+		blocks    [][]luaStmt
+		elseBlock []luaStmt
+		line      int
+	}
+	luaWhile struct {
+		cond luaExpr
+		body []luaStmt
+		line int
+	}
+	luaRepeat struct {
+		body []luaStmt
+		cond luaExpr
+		line int
+	}
+	// luaNumericFor for i = start, limit, step do
+	luaNumericFor struct {
+		name               string
+		start, limit, step luaExpr
+		body               []luaStmt
+		line               int
+	}
+	// luaGenericFor for k, v in f, s, c do
+	luaGenericFor struct {
+		names []string
+		exprs []luaExpr
+		body  []luaStmt
+		line  int
+	}
+	luaDo struct {
+		body []luaStmt
+		line int
+	}
+	luaReturn struct {
+		exprs []luaExpr
+		line  int
+	}
+	luaBreak struct {
+		line int
+	}
+)
+
+type (
+	// luaConst nil、true、false、数字和字符串常量
+	luaConst struct {
+		val luaValue
+	}
+	luaNameExpr struct {
+		name string
+	}
+	luaIndex struct {
+		obj, key luaExpr
+	}
+	luaCall struct {
+		fn   luaExpr
+		args []luaExpr
+	}
+	// luaParen 括号中的表达式，函数调用只取第一个返回值
+	luaParen struct {
+		expr luaExpr
+	}
+	luaBinary struct {
+		op          string
+		left, right luaExpr
+	}
+	luaUnary struct {
+		op   string
+		expr luaExpr
+	}
+	luaTableExpr struct {
+		fields []luaField
+	}
+	// luaField 表构造中的一项，key为nil时按位置追加
+	luaField struct {
+		key, val luaExpr
+	}
+)
+
+// 语法分析
+
+type luaParser struct {
+	tokens []luaToken
+	pos    int
+	loops  int
+}
+
+// parseLua 编译脚本，返回顶层语句块
+func parseLua(src string) ([]luaStmt, error) {
+	tokens, err := luaTokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &luaParser{tokens: tokens}
+	block, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != luaEOF {
+		return nil, p.errorf("'<eof>' expected near '%s'", tok.text)
+	}
+	return block, nil
+}
+
+func (p *luaParser) peek() luaToken {
+	return p.tokens[p.pos]
+}
+
+func (p *luaParser) next() luaToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != luaEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// check 当前词法单元是否为指定的关键字或运算符
+func (p *luaParser) check(symbol string) bool {
+	tok := p.peek()
+	return tok.kind == luaSymbol && tok.text == symbol
+}
+
+func (p *luaParser) accept(symbol string) bool {
+	if p.check(symbol) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *luaParser) expect(symbol string) error {
+	if !p.accept(symbol) {
+		return p.errorf("'%s' expected near '%s'", symbol, p.peek().text)
+	}
+	return nil
+}
+
+func (p *luaParser) errorf(format string, args ...interface{}) error {
+	return &luaSyntaxError{msg: fmt.Sprintf(format, args...), line: p.peek().line}
+}
+
+func (p *luaParser) name() (string, error) {
+	tok := p.peek()
+	if tok.kind != luaName {
+		return "", p.errorf("<name> expected near '%s'", tok.text)
+	}
+	p.next()
+	return tok.text, nil
+}
+
+// blockEnd 当前词法单元是否结束语句块
+func (p *luaParser) blockEnd() bool {
+	tok := p.peek()
+	if tok.kind == luaEOF {
+		return true
+	}
+	if tok.kind != luaSymbol {
+		return false
+	}
+	switch tok.text {
+	case "end", "else", "elseif", "until":
+		return true
+	}
+	return false
+}
+
+func (p *luaParser) block() ([]luaStmt, error) {
+	var block []luaStmt
+	for !p.blockEnd() {
+		if p.accept(";") {
+			continue
+		}
+		if p.check("return") {
+			line := p.next().line
+			var exprs []luaExpr
+			if !p.blockEnd() && !p.check(";") {
+				var err error
+				if exprs, err = p.exprList(); err != nil {
+					return nil, err
+				}
+			}
+			p.accept(";")
+			if !p.blockEnd() {
+				return nil, p.errorf("'end' expected near '%s'", p.peek().text)
+			}
+			return append(block, &luaReturn{exprs: exprs, line: line}), nil
+		}
+		stmt, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		block = append(block, stmt)
+	}
+	return block, nil
+}
+
+// loopBody 解析循环体，记录循环的层数用于检查break
+func (p *luaParser) loopBody() ([]luaStmt, error) {
+	p.loops++
+	defer func() { p.loops-- }()
+	return p.block()
+}
+
+func (p *luaParser) statement() (luaStmt, error) {
+	line := p.peek().line
+	switch {
+	case p.accept("local"):
+		if p.check("function") {
+			return nil, p.errorf("function definitions are not supported")
+		}
+		stmt := &luaLocal{line: line}
+		for {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			stmt.names = append(stmt.names, name)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if p.accept("=") {
+			exprs, err := p.exprList()
+			if err != nil {
+				return nil, err
+			}
+			stmt.exprs = exprs
+		}
+		return stmt, nil
+
+	case p.accept("if"):
+		stmt := &luaIf{line: line}
+		for {
+			cond, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("then"); err != nil {
+				return nil, err
+			}
+			block, err := p.block()
+			if err != nil {
+				return nil, err
+			}
+			stmt.conds = append(stmt.conds, cond)
+			stmt.blocks = append(stmt.blocks, block)
+			if !p.accept("elseif") {
+				break
+			}
+		}
+		if p.accept("else") {
+			block, err := p.block()
+			if err != nil {
+				return nil, err
+			}
+			stmt.elseBlock = block
+		}
+		return stmt, p.expect("end")
+
+	case p.accept("while"):
+		cond, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("do"); err != nil {
+			return nil, err
+		}
+		body, err := p.loopBody()
+		if err != nil {
+			return nil, err
+		}
+		return &luaWhile{cond: cond, body: body, line: line}, p.expect("end")
+
+	case p.accept("repeat"):
+		body, err := p.loopBody()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("until"); err != nil {
+			return nil, err
+		}
+		cond, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return &luaRepeat{body: body, cond: cond, line: line}, nil
+
+	case p.accept("for"):
+		return p.forStatement(line)
+
+	case p.accept("do"):
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		return &luaDo{body: body, line: line}, p.expect("end")
+
+	case p.accept("break"):
+		if p.loops == 0 {
+			return nil, p.errorf("no loop to break")
+		}
+		return &luaBreak{line: line}, nil
+
+	case p.check("function"):
+		return nil, p.errorf("function definitions are not supported")
+	}
+
+	expr, err := p.suffixedExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.check("=") || p.check(",") {
+		targets := []luaExpr{expr}
+		for p.accept(",") {
+			target, err := p.suffixedExpr()
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, target)
+		}
+		for _, target := range targets {
+			switch target.(type) {
+			case *luaNameExpr, *luaIndex:
+			default:
+				return nil, p.errorf("syntax error near '='")
+			}
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		exprs, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		return &luaAssign{targets: targets, exprs: exprs, line: line}, nil
+	}
+	call, ok := expr.(*luaCall)
+	if !ok {
+		return nil, p.errorf("syntax error near '%s'", p.peek().text)
+	}
+	return &luaCallStmt{call: call, line: line}, nil
+}
+
+func (p *luaParser) forStatement(line int) (luaStmt, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("=") {
+		stmt := &luaNumericFor{name: name, step: &luaConst{val: float64(1)}, line: line}
+		if stmt.start, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		if stmt.limit, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if p.accept(",") {
+			if stmt.step, err = p.expr(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("do"); err != nil {
+			return nil, err
+		}
+		if stmt.body, err = p.loopBody(); err != nil {
+			return nil, err
+		}
+		return stmt, p.expect("end")
+	}
+
+	stmt := &luaGenericFor{names: []string{name}, line: line}
+	for p.accept(",") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		stmt.names = append(stmt.names, name)
+	}
+	if err := p.expect("in"); err != nil {
+		return nil, err
+	}
+	if stmt.exprs, err = p.exprList(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("do"); err != nil {
+		return nil, err
+	}
+	if stmt.body, err = p.loopBody(); err != nil {
+		return nil, err
+	}
+	return stmt, p.expect("end")
+}
+
+func (p *luaParser) exprList() ([]luaExpr, error) {
+	var exprs []luaExpr
+	for {
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.accept(",") {
+			return exprs, nil
+		}
+	}
+}
+
+// luaBinaryPriority 二元运算符的左、右优先级，与Lua 5.1一致；..和^右结合
+var luaBinaryPriority = map[string][2]int{
+	"or": {1, 1}, "and": {2, 2},
+	"<": {3, 3}, ">": {3, 3}, "<=": {3, 3}, ">=": {3, 3}, "~=": {3, 3}, "==": {3, 3},
+	"..": {5, 4}, "+": {6, 6}, "-": {6, 6}, "*": {7, 7}, "/": {7, 7}, "%": {7, 7},
+	"^": {10, 9},
+}
+
+// luaUnaryPriority 一元运算符的优先级
+const luaUnaryPriority = 8
+
+func (p *luaParser) expr() (luaExpr, error) {
+	return p.subExpr(0)
+}
+
+// subExpr 解析优先级高于limit的二元运算
+func (p *luaParser) subExpr(limit int) (luaExpr, error) {
+	var left luaExpr
+	if p.check("not") || p.check("-") || p.check("#") {
+		op := p.next().text
+		expr, err := p.subExpr(luaUnaryPriority)
+		if err != nil {
+			return nil, err
+		}
+		left = &luaUnary{op: op, expr: expr}
+	} else {
+		var err error
+		if left, err = p.simpleExpr(); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != luaSymbol {
+			return left, nil
+		}
+		priority, ok := luaBinaryPriority[tok.text]
+		if !ok || priority[0] <= limit {
+			return left, nil
+		}
+		p.next()
+		right, err := p.subExpr(priority[1])
+		if err != nil {
+			return nil, err
+		}
+		left = &luaBinary{op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *luaParser) simpleExpr() (luaExpr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case luaNumber:
+		p.next()
+		return &luaConst{val: tok.num}, nil
+	case luaString:
+		p.next()
+		return &luaConst{val: tok.text}, nil
+	case luaSymbol:
+		switch tok.text {
+		case "nil":
+			p.next()
+			return &luaConst{}, nil
+		case "true":
+			p.next()
+			return &luaConst{val: true}, nil
+		case "false":
+			p.next()
+			return &luaConst{val: false}, nil
+		case "{":
+			return p.tableExpr()
+		case "function":
+			return nil, p.errorf("function definitions are not supported")
+		case "...":
+			return nil, p.errorf("varargs are not supported")
+		}
+	}
+	return p.suffixedExpr()
+}
+
+func (p *luaParser) primaryExpr() (luaExpr, error) {
+	tok := p.peek()
+	if tok.kind == luaName {
+		p.next()
+		return &luaNameExpr{name: tok.text}, nil
+	}
+	if p.accept("(") {
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return &luaParen{expr: expr}, p.expect(")")
+	}
+	return nil, p.errorf("unexpected symbol near '%s'", tok.text)
+}
+
+func (p *luaParser) suffixedExpr() (luaExpr, error) {
+	expr, err := p.primaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case p.accept("."):
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			expr = &luaIndex{obj: expr, key: &luaConst{val: name}}
+		case p.accept("["):
+			key, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			expr = &luaIndex{obj: expr, key: key}
+		case p.check(":"):
+			return nil, p.errorf("method calls are not supported")
+		case p.accept("("):
+			var args []luaExpr
+			if !p.check(")") {
+				if args, err = p.exprList(); err != nil {
+					return nil, err
+				}
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			expr = &luaCall{fn: expr, args: args}
+		case tok.kind == luaString:
+			p.next()
+			expr = &luaCall{fn: expr, args: []luaExpr{&luaConst{val: tok.text}}}
+		case p.check("{"):
+			table, err := p.tableExpr()
+			if err != nil {
+				return nil, err
+			}
+			expr = &luaCall{fn: expr, args: []luaExpr{table}}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *luaParser) tableExpr() (luaExpr, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	table := &luaTableExpr{}
+	for !p.check("}") {
+		var field luaField
+		switch {
+		case p.accept("["):
+			key, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			field.key = key
+		case p.peek().kind == luaName && p.tokens[p.pos+1].kind == luaSymbol && p.tokens[p.pos+1].text == "=":
+			field.key = &luaConst{val: p.next().text}
+			p.next()
+		}
+		val, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		field.val = val
+		table.fields = append(table.fields, field)
+		if !p.accept(",") && !p.accept(";") {
+			break
+		}
+	}
+	return table, p.expect("}")
+}
+
+// 执行
+
+// luaFlow 语句执行后的控制流
+type luaFlow int
+
+const (
+	luaNormal luaFlow = iota
+	luaBreakFlow
+	luaReturnFlow
+)
+
+// luaScope 局部变量的作用域，每个语句块一个
+type luaScope struct {
+	vars   map[string]luaValue
+	parent *luaScope
+}
+
+// declare 在当前作用域中声明局部变量
+func (s *luaScope) declare(name string, val luaValue) {
+	if s.vars == nil {
+		s.vars = make(map[string]luaValue)
+	}
+	s.vars[name] = val
+}
+
+// lookup 查找局部变量所在的作用域
+func (s *luaScope) lookup(name string) *luaScope {
+	for ; s != nil; s = s.parent {
+		if _, ok := s.vars[name]; ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// luaInterpreter 执行语法树，全局变量只读
+type luaInterpreter struct {
+	globals map[string]luaValue
+	line    int
+	steps   int
+}
+
+// runLua 编译并执行脚本，返回return语句的值
+func runLua(src string, globals map[string]luaValue) ([]luaValue, error) {
+	block, err := parseLua(src)
+	if err != nil {
+		return nil, err
+	}
+	in := &luaInterpreter{globals: globals}
+	_, vals, err := in.execBlock(block, nil)
+	return vals, err
+}
+
+func (in *luaInterpreter) execBlock(block []luaStmt, parent *luaScope) (luaFlow, []luaValue, error) {
+	scope := &luaScope{parent: parent}
+	for _, stmt := range block {
+		flow, vals, err := in.exec(stmt, scope)
+		if err != nil {
+			return luaNormal, nil, err
+		}
+		if flow != luaNormal {
+			return flow, vals, nil
+		}
+	}
+	return luaNormal, nil, nil
+}
+
+// tick 记录执行的步数，语句和每轮循环各算一步
+func (in *luaInterpreter) tick() error {
+	in.steps++
+	if in.steps > luaMaxSteps {
+		return &luaError{value: "script exceeded the step limit", line: in.line}
+	}
+	return nil
+}
+
+// exec 执行一条语句，错误记录语句所在的行
+func (in *luaInterpreter) exec(stmt luaStmt, scope *luaScope) (luaFlow, []luaValue, error) {
+	if err := in.tick(); err != nil {
+		return luaNormal, nil, err
+	}
+	flow, vals, err := in.execStmt(stmt, scope)
+	if e, ok := err.(*luaError); ok && e.line == 0 {
+		e.line = in.line
+	}
+	return flow, vals, err
+}
+
+func (in *luaInterpreter) execStmt(stmt luaStmt, scope *luaScope) (luaFlow, []luaValue, error) {
+	switch s := stmt.(type) {
+	case *luaLocal:
+		in.line = s.line
+		vals, err := in.evalList(s.exprs, scope, len(s.names))
+		if err != nil {
+			return luaNormal, nil, err
+		}
+		for i, name := range s.names {
+			scope.declare(name, vals[i])
+		}
+
+	case *luaAssign:
+		in.line = s.line
+		vals, err := in.evalList(s.exprs, scope, len(s.targets))
+		if err != nil {
+			return luaNormal, nil, err
+		}
+		for i, target := range s.targets {
+			if err := in.assign(target, vals[i], scope); err != nil {
+				return luaNormal, nil, err
+			}
+		}
+
+	case *luaCallStmt:
+		in.line = s.line
+		if _, err := in.call(s.call, scope); err != nil {
+			return luaNormal, nil, err
+		}
+
+	case *luaIf:
+		in.line = s.line
+		for i, cond := range s.conds {
+			val, err := in.eval(cond, scope)
+			if err != nil {
+				return luaNormal, nil, err
+			}
+			if luaTruthy(val) {
+				return in.execBlock(s.blocks[i], scope)
+			}
+		}
+		if s.elseBlock != nil {
+			return in.execBlock(s.elseBlock, scope)
+		}
+
+	case *luaWhile:
+		for {
+			in.line = s.line
+			if err := in.tick(); err != nil {
+				return luaNormal, nil, err
+			}
+			val, err := in.eval(s.cond, scope)
+			if err != nil || !luaTruthy(val) {
+				return luaNormal, nil, err
+			}
+			flow, vals, err := in.execBlock(s.body, scope)
+			if err != nil || flow == luaReturnFlow {
+				return flow, vals, err
+			}
+			if flow == luaBreakFlow {
+				break
+			}
+		}
+
+	case *luaRepeat:
+		for {
+			if err := in.tick(); err != nil {
+				return luaNormal, nil, err
+			}
+			// until中的条件可以使用循环体中的局部变量
+			body := &luaScope{parent: scope}
+			for _, stmt := range s.body {
+				flow, vals, err := in.exec(stmt, body)
+				if err != nil || flow == luaReturnFlow {
+					return flow, vals, err
+				}
+				if flow == luaBreakFlow {
+					return luaNormal, nil, nil
+				}
+			}
+			in.line = s.line
+			val, err := in.eval(s.cond, body)
+			if err != nil || luaTruthy(val) {
+				return luaNormal, nil, err
+			}
+		}
+
+	case *luaNumericFor:
+		in.line = s.line
+		var nums [3]float64
+		for i, e := range []luaExpr{s.start, s.limit, s.step} {
+			val, err := in.eval(e, scope)
+			if err != nil {
+				return luaNormal, nil, err
+			}
+			n, ok := luaToNumber(val)
+			if !ok {
+				return luaNormal, nil, luaErrorf("'for' %s must be a number", [...]string{"initial value", "limit", "step"}[i])
+			}
+			nums[i] = n
+		}
+		for i := nums[0]; (nums[2] > 0 && i <= nums[1]) || (nums[2] <= 0 && i >= nums[1]); i += nums[2] {
+			if err := in.tick(); err != nil {
+				return luaNormal, nil, err
+			}
+			body := &luaScope{parent: scope}
+			body.declare(s.name, i)
+			flow, vals, err := in.execBlock(s.body, body)
+			if err != nil || flow == luaReturnFlow {
+				return flow, vals, err
+			}
+			if flow == luaBreakFlow {
+				break
+			}
+		}
+
+	case *luaGenericFor:
+		in.line = s.line
+		state, err := in.evalList(s.exprs, scope, 3)
+		if err != nil {
+			return luaNormal, nil, err
+		}
+		for {
+			in.line = s.line
+			if err := in.tick(); err != nil {
+				return luaNormal, nil, err
+			}
+			vals, err := in.callValue(state[0], []luaValue{state[1], state[2]})
+			if err != nil {
+				return luaNormal, nil, err
+			}
+			if len(vals) == 0 || vals[0] == nil {
+				break
+			}
+			state[2] = vals[0]
+			body := &luaScope{parent: scope}
+			for i, name := range s.names {
+				var val luaValue
+				if i < len(vals) {
+					val = vals[i]
+				}
+				body.declare(name, val)
+			}
+			flow, rets, err := in.execBlock(s.body, body)
+			if err != nil || flow == luaReturnFlow {
+				return flow, rets, err
+			}
+			if flow == luaBreakFlow {
+				break
+			}
+		}
+
+	case *luaDo:
+		return in.execBlock(s.body, scope)
+
+	case *luaReturn:
+		in.line = s.line
+		vals, err := in.evalMulti(s.exprs, scope)
+		if err != nil {
+			return luaNormal, nil, err
+		}
+		return luaReturnFlow, vals, nil
+
+	case *luaBreak:
+		return luaBreakFlow, nil, nil
+	}
+	return luaNormal, nil, nil
+}
+
+// assign 给变量或表中的键赋值，全局变量只读
+func (in *luaInterpreter) assign(target luaExpr, val luaValue, scope *luaScope) error {
+	switch t := target.(type) {
+	case *luaNameExpr:
+		if s := scope.lookup(t.name); s != nil {
+			s.vars[t.name] = val
+			return nil
+		}
+		if _, ok := in.globals[t.name]; ok {
+			return luaErrorf("Attempt to modify a readonly table")
+		}
+		return luaErrorf("Script attempted to create global variable '%s'", t.name)
+	case *luaIndex:
+		obj, err := in.eval(t.obj, scope)
+		if err != nil {
+			return err
+		}
+		key, err := in.eval(t.key, scope)
+		if err != nil {
+			return err
+		}
+		table, ok := obj.(*luaTable)
+		if !ok {
+			return luaErrorf("attempt to index a %s value", luaTypeName(obj))
+		}
+		return table.set(key, val)
+	}
+	return luaErrorf("cannot assign")
+}
+
+// evalList 求值表达式列表并补齐或截断为n个值
+func (in *luaInterpreter) evalList(exprs []luaExpr, scope *luaScope, n int) ([]luaValue, error) {
+	vals, err := in.evalMulti(exprs, scope)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) < n {
+		vals = append(vals, make([]luaValue, n-len(vals))...)
+	}
+	return vals[:n], nil
+}
+
+// evalMulti 求值表达式列表，最后一个函数调用的所有返回值都被展开
+func (in *luaInterpreter) evalMulti(exprs []luaExpr, scope *luaScope) ([]luaValue, error) {
+	vals := make([]luaValue, 0, len(exprs))
+	for i, expr := range exprs {
+		if call, ok := expr.(*luaCall); ok && i == len(exprs)-1 {
+			rets, err := in.call(call, scope)
+			if err != nil {
+				return nil, err
+			}
+			return append(vals, rets...), nil
+		}
+		val, err := in.eval(expr, scope)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
+
+// eval 求值表达式，函数调用只取第一个返回值
+func (in *luaInterpreter) eval(expr luaExpr, scope *luaScope) (luaValue, error) {
+	switch e := expr.(type) {
+	case *luaConst:
+		return e.val, nil
+
+	case *luaNameExpr:
+		if s := scope.lookup(e.name); s != nil {
+			return s.vars[e.name], nil
+		}
+		val, ok := in.globals[e.name]
+		if !ok {
+			return nil, luaErrorf("Script attempted to access nonexistent global variable '%s'", e.name)
+		}
+		return val, nil
+
+	case *luaIndex:
+		obj, err := in.eval(e.obj, scope)
+		if err != nil {
+			return nil, err
+		}
+		key, err := in.eval(e.key, scope)
+		if err != nil {
+			return nil, err
+		}
+		table, ok := obj.(*luaTable)
+		if !ok {
+			return nil, luaErrorf("attempt to index a %s value", luaTypeName(obj))
+		}
+		return table.get(key), nil
+
+	case *luaCall:
+		vals, err := in.call(e, scope)
+		if err != nil || len(vals) == 0 {
+			return nil, err
+		}
+		return vals[0], nil
+
+	case *luaParen:
+		return in.eval(e.expr, scope)
+
+	case *luaUnary:
+		val, err := in.eval(e.expr, scope)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "not":
+			return !luaTruthy(val), nil
+		case "-":
+			n, ok := luaToNumber(val)
+			if !ok {
+				return nil, luaErrorf("attempt to perform arithmetic on a %s value", luaTypeName(val))
+			}
+			return -n, nil
+		default:
+			switch v := val.(type) {
+			case string:
+				return float64(len(v)), nil
+			case *luaTable:
+				return float64(len(v.array)), nil
+			}
+			return nil, luaErrorf("attempt to get length of a %s value", luaTypeName(val))
+		}
+
+	case *luaBinary:
+		left, err := in.eval(e.left, scope)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "and":
+			if !luaTruthy(left) {
+				return left, nil
+			}
+			return in.eval(e.right, scope)
+		case "or":
+			if luaTruthy(left) {
+				return left, nil
+			}
+			return in.eval(e.right, scope)
+		}
+		right, err := in.eval(e.right, scope)
+		if err != nil {
+			return nil, err
+		}
+		return luaBinaryOp(e.op, left, right)
+
+	case *luaTableExpr:
+		table := newLuaTable()
+		for i, field := range e.fields {
+			if field.key == nil {
+				// 最后一项是函数调用时展开所有返回值
+				if call, ok := field.val.(*luaCall); ok && i == len(e.fields)-1 {
+					vals, err := in.call(call, scope)
+					if err != nil {
+						return nil, err
+					}
+					for _, val := range vals {
+						if err := table.set(float64(len(table.array)+1), val); err != nil {
+							return nil, err
+						}
+					}
+					continue
+				}
+				val, err := in.eval(field.val, scope)
+				if err != nil {
+					return nil, err
+				}
+				// 位置项的下标按出现顺序计算，中间的nil也占位
+				if err := table.set(float64(in.position(e, i)), val); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			key, err := in.eval(field.key, scope)
+			if err != nil {
+				return nil, err
+			}
+			val, err := in.eval(field.val, scope)
+			if err != nil {
+				return nil, err
+			}
+			if err := table.set(key, val); err != nil {
+				return nil, err
+			}
+		}
+		return table, nil
+	}
+	return nil, luaErrorf("unsupported expression")
+}
+
+// position 表构造中第i项是第几个位置项
+func (in *luaInterpreter) position(table *luaTableExpr, i int) int {
+	n := 0
+	for _, field := range table.fields[:i+1] {
+		if field.key == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (in *luaInterpreter) call(call *luaCall, scope *luaScope) ([]luaValue, error) {
+	fn, err := in.eval(call.fn, scope)
+	if err != nil {
+		return nil, err
+	}
+	args, err := in.evalMulti(call.args, scope)
+	if err != nil {
+		return nil, err
+	}
+	return in.callValue(fn, args)
+}
+
+func (in *luaInterpreter) callValue(fn luaValue, args []luaValue) ([]luaValue, error) {
+	f, ok := fn.(*luaFunction)
+	if !ok {
+		return nil, luaErrorf("attempt to call a %s value", luaTypeName(fn))
+	}
+	return f.fn(args)
+}
+
+// luaBinaryOp 算术、比较和..运算
+func luaBinaryOp(op string, left, right luaValue) (luaValue, error) {
+	switch op {
+	case "==":
+		return left == right, nil
+	case "~=":
+		return left != right, nil
+	case "<", ">", "<=", ">=":
+		if op == ">" || op == ">=" {
+			left, right = right, left
+		}
+		switch l := left.(type) {
+		case float64:
+			if r, ok := right.(float64); ok {
+				if op == "<" || op == ">" {
+					return l < r, nil
+				}
+				return l <= r, nil
+			}
+		case string:
+			if r, ok := right.(string); ok {
+				if op == "<" || op == ">" {
+					return l < r, nil
+				}
+				return l <= r, nil
+			}
+		}
+		if luaTypeName(left) == luaTypeName(right) {
+			return nil, luaErrorf("attempt to compare two %s values", luaTypeName(left))
+		}
+		return nil, luaErrorf("attempt to compare %s with %s", luaTypeName(left), luaTypeName(right))
+	case "..":
+		l, lok := luaToString(left)
+		r, rok := luaToString(right)
+		if !lok || !rok {
+			bad := left
+			if lok {
+				bad = right
+			}
+			return nil, luaErrorf("attempt to concatenate a %s value", luaTypeName(bad))
+		}
+		return l + r, nil
+	}
+
+	l, lok := luaToNumber(left)
+	r, rok := luaToNumber(right)
+	if !lok || !rok {
+		bad := left
+		if lok {
+			bad = right
+		}
+		return nil, luaErrorf("attempt to perform arithmetic on a %s value", luaTypeName(bad))
+	}
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		return l / r, nil
+	case "%":
+		return l - math.Floor(l/r)*r, nil
+	default:
+		return math.Pow(l, r), nil
+	}
+}
+
+// luaTruthy nil和false为假，其余都为真
+func luaTruthy(v luaValue) bool {
+	return v != nil && v != false
+}
+
+func luaTypeName(v luaValue) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *luaTable:
+		return "table"
+	case *luaFunction:
+		return "function"
+	}
+	return "userdata"
+}
+
+// luaToNumber 将数字或可以解析为数字的字符串转换为数字
+func luaToNumber(v luaValue) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		return luaParseNumber(v)
+	}
+	return 0, false
+}
+
+// luaToString 将字符串或数字转换为字符串
+func luaToString(v luaValue) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return luaFormatNumber(v), true
+	}
+	return "", false
+}
+
+// luaParseNumber 按Lua的规则解析数字，支持十六进制整数和首尾空白
+func luaParseNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsRune(s, '_') {
+		return 0, false
+	}
+	digits := strings.TrimLeft(s, "+-")
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		n, err := strconv.ParseUint(digits[2:], 16, 64)
+		if err != nil || len(s)-len(digits) > 1 {
+			return 0, false
+		}
+		if s[0] == '-' {
+			return -float64(n), true
+		}
+		return float64(n), true
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil && !isRangeError(err) {
+		return 0, false
+	}
+	return n, true
+}
+
+func isRangeError(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+	return ok && numErr.Err == strconv.ErrRange
+}
+
+// luaFormatNumber 与Lua 5.1一致地按%.14g格式化数字
+func luaFormatNumber(n float64) string {
+	switch {
+	case math.IsInf(n, 1):
+		return "inf"
+	case math.IsInf(n, -1):
+		return "-inf"
+	case math.IsNaN(n):
+		return "nan"
+	}
+	return fmt.Sprintf("%.14g", n)
+}
+
+// luaTostring tostring的结果
+func luaTostring(v luaValue) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return luaFormatNumber(v)
+	case string:
+		return v
+	case *luaTable:
+		return fmt.Sprintf("table: %p", v)
+	case *luaFunction:
+		return "function: builtin: " + v.name
+	}
+	return luaTypeName(v)
+}
+
+// 基础库
+
+// luaLibrary 创建基础库的全局变量
+func luaLibrary() map[string]luaValue {
+	globals := map[string]luaValue{
+		"tonumber": luaFunc("tonumber", luaTonumber),
+		"tostring": luaFunc("tostring", func(args []luaValue) ([]luaValue, error) {
+			if len(args) == 0 {
+				return nil, luaErrorf("bad argument #1 to 'tostring' (value expected)")
+			}
+			return []luaValue{luaTostring(args[0])}, nil
+		}),
+		"type": luaFunc("type", func(args []luaValue) ([]luaValue, error) {
+			if len(args) == 0 {
+				return nil, luaErrorf("bad argument #1 to 'type' (value expected)")
+			}
+			return []luaValue{luaTypeName(args[0])}, nil
+		}),
+		"error": luaFunc("error", func(args []luaValue) ([]luaValue, error) {
+			var val luaValue
+			if len(args) > 0 {
+				val = args[0]
+			}
+			return nil, &luaError{value: val}
+		}),
+		"assert": luaFunc("assert", func(args []luaValue) ([]luaValue, error) {
+			if len(args) == 0 || !luaTruthy(args[0]) {
+				msg := luaValue("assertion failed!")
+				if len(args) > 1 {
+					msg = args[1]
+				}
+				return nil, &luaError{value: msg}
+			}
+			return args, nil
+		}),
+		"ipairs": luaFunc("ipairs", luaIpairs),
+		"pairs":  luaFunc("pairs", luaPairs),
+		"unpack": luaFunc("unpack", luaUnpack),
+	}
+	globals["string"] = luaLib(map[string]func([]luaValue) ([]luaValue, error){
+		"format": luaStringFormat,
+		"len": func(args []luaValue) ([]luaValue, error) {
+			s, err := luaCheckString(args, 1, "len")
+			return []luaValue{float64(len(s))}, err
+		},
+		"sub":   luaStringSub,
+		"upper": luaStringMap("upper", strings.ToUpper),
+		"lower": luaStringMap("lower", strings.ToLower),
+		"rep": func(args []luaValue) ([]luaValue, error) {
+			s, err := luaCheckString(args, 1, "rep")
+			if err != nil {
+				return nil, err
+			}
+			n, err := luaCheckNumber(args, 2, "rep")
+			if err != nil || n < 1 {
+				return []luaValue{""}, err
+			}
+			return []luaValue{strings.Repeat(s, int(n))}, nil
+		},
+	})
+	globals["math"] = luaLib(map[string]func([]luaValue) ([]luaValue, error){
+		"floor": luaMathFunc("floor", math.Floor),
+		"ceil":  luaMathFunc("ceil", math.Ceil),
+		"abs":   luaMathFunc("abs", math.Abs),
+		"sqrt":  luaMathFunc("sqrt", math.Sqrt),
+		"fmod": func(args []luaValue) ([]luaValue, error) {
+			a, err := luaCheckNumber(args, 1, "fmod")
+			if err != nil {
+				return nil, err
+			}
+			b, err := luaCheckNumber(args, 2, "fmod")
+			return []luaValue{math.Mod(a, b)}, err
+		},
+		"max": luaMathReduce("max", math.Max),
+		"min": luaMathReduce("min", math.Min),
+	})
+	globals["math"].(*luaTable).set("huge", math.Inf(1))
+	globals["table"] = luaLib(map[string]func([]luaValue) ([]luaValue, error){
+		"insert": luaTableInsert,
+		"remove": luaTableRemove,
+		"concat": luaTableConcat,
+		"getn": func(args []luaValue) ([]luaValue, error) {
+			t, err := luaCheckTable(args, 1, "getn")
+			if err != nil {
+				return nil, err
+			}
+			return []luaValue{float64(len(t.array))}, nil
+		},
+	})
+	return globals
+}
+
+func luaFunc(name string, fn func(args []luaValue) ([]luaValue, error)) *luaFunction {
+	return &luaFunction{name: name, fn: fn}
+}
+
+// luaLib 创建函数库的表
+func luaLib(funcs map[string]func([]luaValue) ([]luaValue, error)) *luaTable {
+	lib := newLuaTable()
+	for name, fn := range funcs {
+		lib.set(name, luaFunc(name, fn))
+	}
+	return lib
+}
+
+func luaArg(args []luaValue, n int) luaValue {
+	if n <= len(args) {
+		return args[n-1]
+	}
+	return nil
+}
+
+func luaCheckNumber(args []luaValue, n int, fn string) (float64, error) {
+	v, ok := luaToNumber(luaArg(args, n))
+	if !ok {
+		return 0, luaErrorf("bad argument #%d to '%s' (number expected, got %s)", n, fn, luaTypeName(luaArg(args, n)))
+	}
+	return v, nil
+}
+
+func luaCheckString(args []luaValue, n int, fn string) (string, error) {
+	s, ok := luaToString(luaArg(args, n))
+	if !ok {
+		return "", luaErrorf("bad argument #%d to '%s' (string expected, got %s)", n, fn, luaTypeName(luaArg(args, n)))
+	}
+	return s, nil
+}
+
+func luaCheckTable(args []luaValue, n int, fn string) (*luaTable, error) {
+	t, ok := luaArg(args, n).(*luaTable)
+	if !ok {
+		return nil, luaErrorf("bad argument #%d to '%s' (table expected, got %s)", n, fn, luaTypeName(luaArg(args, n)))
+	}
+	return t, nil
+}
+
+func luaTonumber(args []luaValue) ([]luaValue, error) {
+	if len(args) == 0 {
+		return nil, luaErrorf("bad argument #1 to 'tonumber' (value expected)")
+	}
+	if len(args) > 1 && args[1] != nil {
+		base, err := luaCheckNumber(args, 2, "tonumber")
+		if err != nil {
+			return nil, err
+		}
+		s, ok := luaToString(args[0])
+		if !ok {
+			return []luaValue{nil}, nil
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(s), int(base), 64)
+		if err != nil {
+			return []luaValue{nil}, nil
+		}
+		return []luaValue{float64(n)}, nil
+	}
+	if n, ok := luaToNumber(args[0]); ok {
+		return []luaValue{n}, nil
+	}
+	return []luaValue{nil}, nil
+}
+
+func luaIpairs(args []luaValue) ([]luaValue, error) {
+	t, err := luaCheckTable(args, 1, "ipairs")
+	if err != nil {
+		return nil, err
+	}
+	next := luaFunc("ipairs_iterator", func(args []luaValue) ([]luaValue, error) {
+		i, _ := luaToNumber(luaArg(args, 2))
+		val := t.get(i + 1)
+		if val == nil {
+			return []luaValue{nil}, nil
+		}
+		return []luaValue{i + 1, val}, nil
+	})
+	return []luaValue{next, t, float64(0)}, nil
+}
+
+// luaPairs 按确定的顺序遍历表：先数组部分，再按类型和值排序的其他键
+func luaPairs(args []luaValue) ([]luaValue, error) {
+	t, err := luaCheckTable(args, 1, "pairs")
+	if err != nil {
+		return nil, err
+	}
+	keys := t.keys()
+	i := 0
+	next := luaFunc("pairs_iterator", func(args []luaValue) ([]luaValue, error) {
+		for i < len(keys) {
+			key := keys[i]
+			i++
+			if val := t.get(key); val != nil {
+				return []luaValue{key, val}, nil
+			}
+		}
+		return []luaValue{nil}, nil
+	})
+	return []luaValue{next, t, nil}, nil
+}
+
+func luaUnpack(args []luaValue) ([]luaValue, error) {
+	t, err := luaCheckTable(args, 1, "unpack")
+	if err != nil {
+		return nil, err
+	}
+	start, end := 1.0, float64(len(t.array))
+	if v, ok := luaToNumber(luaArg(args, 2)); ok {
+		start = v
+	}
+	if v, ok := luaToNumber(luaArg(args, 3)); ok {
+		end = v
+	}
+	var vals []luaValue
+	for i := start; i <= end; i++ {
+		vals = append(vals, t.get(i))
+	}
+	return vals, nil
+}
+
+func luaStringMap(name string, fn func(string) string) func([]luaValue) ([]luaValue, error) {
+	return func(args []luaValue) ([]luaValue, error) {
+		s, err := luaCheckString(args, 1, name)
+		return []luaValue{fn(s)}, err
+	}
+}
+
+// luaStringSub string.sub，下标从1开始，负数从末尾计数
+func luaStringSub(args []luaValue) ([]luaValue, error) {
+	s, err := luaCheckString(args, 1, "sub")
+	if err != nil {
+		return nil, err
+	}
+	start, err := luaCheckNumber(args, 2, "sub")
+	if err != nil {
+		return nil, err
+	}
+	end := -1.0
+	if luaArg(args, 3) != nil {
+		if end, err = luaCheckNumber(args, 3, "sub"); err != nil {
+			return nil, err
+		}
+	}
+	n := len(s)
+	i, j := int(start), int(end)
+	if i < 0 {
+		i += n + 1
+	}
+	if j < 0 {
+		j += n + 1
+	}
+	if i < 1 {
+		i = 1
+	}
+	if j > n {
+		j = n
+	}
+	if i > j {
+		return []luaValue{""}, nil
+	}
+	return []luaValue{s[i-1 : j]}, nil
+}
+
+// luaStringFormat string.format，支持C语言printf的标志、宽度、精度以及d、i、u、c、o、x、X、e、E、f、g、G、q、s
+func luaStringFormat(args []luaValue) ([]luaValue, error) {
+	format, err := luaCheckString(args, 1, "format")
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	arg := 1
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			b.WriteByte('%')
+			continue
+		}
+		start := i
+		for i < len(format) && strings.IndexByte("-+ #0.123456789", format[i]) >= 0 {
+			i++
+		}
+		if i >= len(format) {
+			return nil, luaErrorf("invalid option '%%' to 'format'")
+		}
+		spec, verb := "%"+format[start:i], format[i]
+		arg++
+		switch verb {
+		case 'd', 'i', 'u':
+			n, err := luaCheckNumber(args, arg, "format")
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&b, spec+"d", int64(n))
+		case 'c':
+			n, err := luaCheckNumber(args, arg, "format")
+			if err != nil {
+				return nil, err
+			}
+			b.WriteByte(byte(n))
+		case 'o', 'x', 'X':
+			n, err := luaCheckNumber(args, arg, "format")
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&b, spec+string(verb), int64(n))
+		case 'e', 'E', 'f', 'g', 'G':
+			n, err := luaCheckNumber(args, arg, "format")
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&b, spec+string(verb), n)
+		case 'q':
+			s, err := luaCheckString(args, arg, "format")
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(strconv.Quote(s))
+		case 's':
+			if arg > len(args) {
+				return nil, luaErrorf("bad argument #%d to 'format' (no value)", arg)
+			}
+			fmt.Fprintf(&b, spec+"s", luaTostring(args[arg-1]))
+		default:
+			return nil, luaErrorf("invalid option '%%%c' to 'format'", verb)
+		}
+	}
+	return []luaValue{b.String()}, nil
+}
+
+func luaMathFunc(name string, fn func(float64) float64) func([]luaValue) ([]luaValue, error) {
+	return func(args []luaValue) ([]luaValue, error) {
+		n, err := luaCheckNumber(args, 1, name)
+		return []luaValue{fn(n)}, err
+	}
+}
+
+func luaMathReduce(name string, fn func(a, b float64) float64) func([]luaValue) ([]luaValue, error) {
+	return func(args []luaValue) ([]luaValue, error) {
+		result, err := luaCheckNumber(args, 1, name)
+		if err != nil {
+			return nil, err
+		}
+		for i := 2; i <= len(args); i++ {
+			n, err := luaCheckNumber(args, i, name)
+			if err != nil {
+				return nil, err
+			}
+			result = fn(result, n)
+		}
+		return []luaValue{result}, nil
+	}
+}
+
+// luaTableInsert table.insert(t, v)追加到末尾，table.insert(t, pos, v)插入到指定位置
+func luaTableInsert(args []luaValue) ([]luaValue, error) {
+	t, err := luaCheckTable(args, 1, "insert")
+	if err != nil {
+		return nil, err
+	}
+	switch len(args) {
+	case 2:
+		return nil, t.set(float64(len(t.array)+1), args[1])
+	case 3:
+		pos, err := luaCheckNumber(args, 2, "insert")
+		if err != nil {
+			return nil, err
+		}
+		n := len(t.array)
+		for i := float64(n); i >= pos; i-- {
+			if err := t.set(i+1, t.get(i)); err != nil {
+				return nil, err
+			}
+		}
+		return nil, t.set(pos, args[2])
+	}
+	return nil, luaErrorf("wrong number of arguments to 'insert'")
+}
+
+// luaTableRemove table.remove(t[, pos])删除并返回指定位置（默认最后一个）的元素
+func luaTableRemove(args []luaValue) ([]luaValue, error) {
+	t, err := luaCheckTable(args, 1, "remove")
+	if err != nil {
+		return nil, err
+	}
+	n := float64(len(t.array))
+	if n == 0 {
+		return []luaValue{nil}, nil
+	}
+	pos := n
+	if luaArg(args, 2) != nil {
+		if pos, err = luaCheckNumber(args, 2, "remove"); err != nil {
+			return nil, err
+		}
+	}
+	val := t.get(pos)
+	for i := pos; i < n; i++ {
+		if err := t.set(i, t.get(i+1)); err != nil {
+			return nil, err
+		}
+	}
+	return []luaValue{val}, t.set(n, nil)
+}
+
+// luaTableConcat table.concat(t[, sep[, i[, j]]])
+func luaTableConcat(args []luaValue) ([]luaValue, error) {
+	t, err := luaCheckTable(args, 1, "concat")
+	if err != nil {
+		return nil, err
+	}
+	sep := ""
+	if luaArg(args, 2) != nil {
+		if sep, err = luaCheckString(args, 2, "concat"); err != nil {
+			return nil, err
+		}
+	}
+	start, end := 1.0, float64(len(t.array))
+	if v, ok := luaToNumber(luaArg(args, 3)); ok {
+		start = v
+	}
+	if v, ok := luaToNumber(luaArg(args, 4)); ok {
+		end = v
+	}
+	var parts []string
+	for i := start; i <= end; i++ {
+		s, ok := luaToString(t.get(i))
+		if !ok {
+			return nil, luaErrorf("invalid value (at index %d) in table for 'concat'", int(i))
+		}
+		parts = append(parts, s)
+	}
+	return []luaValue{strings.Join(parts, sep)}, nil
+}
@@ -0,0 +1,682 @@
+package cachetest
+
+import (
+	"context"
+	"time"
+
+	"cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// pipeline 内存管道，命令在Exec时一次执行完
+type pipeline struct {
+	f   *Fake
+	ops []pipelineOp
+	// 乐观事务中被监视的键及其版本，为nil时不检查
+	watched map[string]uint64
+}
+
+// pipelineOp 管道中的一条命令
+type pipelineOp struct {
+	cmd  interface{}
+	run  func(s *store) error
+	fail func(err error)
+}
+
+// 确保实现了cache.Pipeliner接口
+var _ cache.Pipeliner = (*pipeline)(nil)
+
+// add 将命令加入管道，返回的命令对象在Exec之后可以读取结果
+func add[T any, C any](p *pipeline, newCmd func(result func() (T, error)) *C, fn func(s *store) (T, error)) *C {
+	var val T
+	var err error
+	cmd := newCmd(func() (T, error) {
+		return val, err
+	})
+	p.ops = append(p.ops, pipelineOp{
+		cmd: cmd,
+		run: func(s *store) error {
+			val, err = fn(s)
+			return err
+		},
+		fail: func(e error) {
+			err = e
+		},
+	})
+	return cmd
+}
+
+// Exec 执行管道中的所有命令，返回各命令的结果对象和第一个失败命令的错误
+// 与go-redis一致，未命中的命令使结果对象返回ErrKeyNotFound，Exec返回redis.Nil
+func (p *pipeline) Exec(ctx context.Context) ([]interface{}, error) {
+	ops := p.ops
+	p.ops = nil
+
+	p.f.mu.Lock()
+	defer p.f.mu.Unlock()
+
+	var failed error
+	switch {
+	case p.f.closed:
+		failed = cache.ErrClientClosed
+	case p.watched != nil:
+		for key, version := range p.watched {
+			if p.f.store.versions[key] != version {
+				failed = cache.ErrTxFailed
+				break
+			}
+		}
+	}
+	if failed != nil {
+		for _, op := range ops {
+			op.fail(failed)
+		}
+		return nil, failed
+	}
+
+	results := make([]interface{}, len(ops))
+	var firstErr error
+	for i, op := range ops {
+		results[i] = op.cmd
+		if err := op.run(p.f.store); err != nil && firstErr == nil {
+			firstErr = err
+			if err == cache.ErrKeyNotFound {
+				firstErr = redis.Nil
+			}
+		}
+	}
+	return results, firstErr
+}
+
+// Discard 丢弃管道中的所有命令
+func (p *pipeline) Discard() error {
+	p.ops = nil
+	return nil
+}
+
+// Close 关闭管道
+func (p *pipeline) Close() error {
+	return nil
+}
+
+// Scan 按字典序迭代键，返回的键不包含前缀
+func (p *pipeline) Scan(ctx context.Context, cursor uint64, match string, count int64) *cache.ScanCmd {
+	prefix := p.f.config.KeyPrefix(ctx)
+	match = p.f.key(ctx, match)
+
+	var keys []string
+	var next uint64
+	var err error
+	cmd := cache.NewScanCmd(func() ([]string, uint64, error) {
+		return keys, next, err
+	})
+	p.ops = append(p.ops, pipelineOp{
+		cmd: cmd,
+		run: func(s *store) error {
+			keys, next, err = s.scan(cursor, match, count, prefix)
+			return err
+		},
+		fail: func(e error) {
+			err = e
+		},
+	})
+	return cmd
+}
+
+// notSupported 加入一条执行时返回ErrNotSupported的命令
+func (p *pipeline) notSupported() *cache.Cmd {
+	return add(p, cache.NewCmd, func(s *store) (interface{}, error) {
+		return nil, ErrNotSupported
+	})
+}
+
+// Eval 执行Lua脚本并缓存
+func (p *pipeline) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *cache.Cmd {
+	keys = p.f.keys(ctx, keys)
+	return add(p, cache.NewCmd, func(s *store) (interface{}, error) {
+		return p.f.eval(s, script, keys, args)
+	})
+}
+
+// EvalSha 通过SHA1执行已缓存的Lua脚本，脚本未缓存时结果返回ErrScriptNotFound
+func (p *pipeline) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *cache.Cmd {
+	keys = p.f.keys(ctx, keys)
+	return add(p, cache.NewCmd, func(s *store) (interface{}, error) {
+		return p.f.evalSha(s, sha1, keys, args)
+	})
+}
+
+// FCall 调用函数，内存实现不支持
+func (p *pipeline) FCall(ctx context.Context, function string, keys []string, args ...interface{}) *cache.Cmd {
+	return p.notSupported()
+}
+
+// FCallRO 调用只读函数，内存实现不支持
+func (p *pipeline) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) *cache.Cmd {
+	return p.notSupported()
+}
+
+// 字符串操作
+
+// Get 获取字符串值
+func (p *pipeline) Get(ctx context.Context, key string) *cache.StringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringCmd, func(s *store) (string, error) {
+		return s.get(key)
+	})
+}
+
+// Set 设置字符串值
+func (p *pipeline) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *cache.StatusCmd {
+	key = p.f.key(ctx, key)
+	expiration = p.f.config.TTL(ctx, expiration)
+	return add(p, cache.NewStatusCmd, func(s *store) (string, error) {
+		return s.set(key, value, expiration)
+	})
+}
+
+// SetNX 仅当键不存在时设置值
+func (p *pipeline) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *cache.BoolCmd {
+	key = p.f.key(ctx, key)
+	expiration = p.f.config.TTL(ctx, expiration)
+	return add(p, cache.NewBoolCmd, func(s *store) (bool, error) {
+		return s.setNX(key, value, expiration)
+	})
+}
+
+// GetSet 设置新值并返回旧值
+func (p *pipeline) GetSet(ctx context.Context, key string, value interface{}) *cache.StringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringCmd, func(s *store) (string, error) {
+		return s.getSet(key, value)
+	})
+}
+
+// MGet 批量获取多个键的值
+func (p *pipeline) MGet(ctx context.Context, keys ...string) *cache.SliceCmd {
+	keys = p.f.keys(ctx, keys)
+	return add(p, cache.NewSliceCmd, func(s *store) ([]interface{}, error) {
+		return s.mget(keys)
+	})
+}
+
+// MSet 批量设置多个键值对
+func (p *pipeline) MSet(ctx context.Context, pairs ...interface{}) *cache.StatusCmd {
+	pairs = p.f.pairs(ctx, pairs)
+	return add(p, cache.NewStatusCmd, func(s *store) (string, error) {
+		return s.mset(pairs)
+	})
+}
+
+// Incr 递增计数器
+func (p *pipeline) Incr(ctx context.Context, key string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.incrBy(key, 1)
+	})
+}
+
+// IncrBy 按指定值递增计数器
+func (p *pipeline) IncrBy(ctx context.Context, key string, value int64) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.incrBy(key, value)
+	})
+}
+
+// Decr 递减计数器
+func (p *pipeline) Decr(ctx context.Context, key string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.incrBy(key, -1)
+	})
+}
+
+// DecrBy 按指定值递减计数器
+func (p *pipeline) DecrBy(ctx context.Context, key string, value int64) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.incrBy(key, -value)
+	})
+}
+
+// 哈希表操作
+
+// HGet 获取哈希表字段值
+func (p *pipeline) HGet(ctx context.Context, key, field string) *cache.StringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringCmd, func(s *store) (string, error) {
+		return s.hget(key, field)
+	})
+}
+
+// HSet 设置哈希表字段值
+func (p *pipeline) HSet(ctx context.Context, key, field string, value interface{}) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.hset(key, field, value)
+	})
+}
+
+// HSetNX 仅当字段不存在时设置哈希表字段值
+func (p *pipeline) HSetNX(ctx context.Context, key, field string, value interface{}) *cache.BoolCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewBoolCmd, func(s *store) (bool, error) {
+		return s.hsetNX(key, field, value)
+	})
+}
+
+// HDel 删除哈希表字段
+func (p *pipeline) HDel(ctx context.Context, key string, fields ...string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.hdel(key, fields)
+	})
+}
+
+// HExists 检查哈希表字段是否存在
+func (p *pipeline) HExists(ctx context.Context, key, field string) *cache.BoolCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewBoolCmd, func(s *store) (bool, error) {
+		return s.hexists(key, field)
+	})
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (p *pipeline) HGetAll(ctx context.Context, key string) *cache.MapStringStringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewMapStringStringCmd, func(s *store) (map[string]string, error) {
+		return s.hgetAll(key)
+	})
+}
+
+// HKeys 获取哈希表所有字段
+func (p *pipeline) HKeys(ctx context.Context, key string) *cache.StringSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.hkeys(key)
+	})
+}
+
+// HVals 获取哈希表所有值
+func (p *pipeline) HVals(ctx context.Context, key string) *cache.StringSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.hvals(key)
+	})
+}
+
+// HLen 获取哈希表字段数量
+func (p *pipeline) HLen(ctx context.Context, key string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.hlen(key)
+	})
+}
+
+// HMGet 批量获取哈希表字段值
+func (p *pipeline) HMGet(ctx context.Context, key string, fields ...string) *cache.SliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewSliceCmd, func(s *store) ([]interface{}, error) {
+		return s.hmget(key, fields)
+	})
+}
+
+// HMSet 批量设置哈希表字段值
+func (p *pipeline) HMSet(ctx context.Context, key string, pairs ...interface{}) *cache.BoolCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewBoolCmd, func(s *store) (bool, error) {
+		return s.hmset(key, pairs)
+	})
+}
+
+// HIncrBy 递增哈希表字段值
+func (p *pipeline) HIncrBy(ctx context.Context, key, field string, incr int64) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.hincrBy(key, field, incr)
+	})
+}
+
+// 列表操作
+
+// LPush 从列表左侧推入元素
+func (p *pipeline) LPush(ctx context.Context, key string, values ...interface{}) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.push(key, values, true)
+	})
+}
+
+// RPush 从列表右侧推入元素
+func (p *pipeline) RPush(ctx context.Context, key string, values ...interface{}) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.push(key, values, false)
+	})
+}
+
+// LPop 从列表左侧弹出元素
+func (p *pipeline) LPop(ctx context.Context, key string) *cache.StringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringCmd, func(s *store) (string, error) {
+		return s.pop(key, true)
+	})
+}
+
+// RPop 从列表右侧弹出元素
+func (p *pipeline) RPop(ctx context.Context, key string) *cache.StringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringCmd, func(s *store) (string, error) {
+		return s.pop(key, false)
+	})
+}
+
+// LLen 获取列表长度
+func (p *pipeline) LLen(ctx context.Context, key string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.llen(key)
+	})
+}
+
+// LRange 获取列表指定范围的元素
+func (p *pipeline) LRange(ctx context.Context, key string, start, stop int64) *cache.StringSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.lrange(key, start, stop)
+	})
+}
+
+// LIndex 获取列表指定索引的元素
+func (p *pipeline) LIndex(ctx context.Context, key string, index int64) *cache.StringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringCmd, func(s *store) (string, error) {
+		return s.lindex(key, index)
+	})
+}
+
+// LSet 设置列表指定索引的元素值
+func (p *pipeline) LSet(ctx context.Context, key string, index int64, value interface{}) *cache.StatusCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStatusCmd, func(s *store) (string, error) {
+		return s.lset(key, index, value)
+	})
+}
+
+// LRem 从列表中移除元素
+func (p *pipeline) LRem(ctx context.Context, key string, count int64, value interface{}) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.lrem(key, count, value)
+	})
+}
+
+// LTrim 修剪列表，只保留指定范围的元素
+func (p *pipeline) LTrim(ctx context.Context, key string, start, stop int64) *cache.StatusCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStatusCmd, func(s *store) (string, error) {
+		return s.ltrim(key, start, stop)
+	})
+}
+
+// 集合操作
+
+// SAdd 向集合添加成员
+func (p *pipeline) SAdd(ctx context.Context, key string, members ...interface{}) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.sadd(key, members)
+	})
+}
+
+// SRem 从集合移除成员
+func (p *pipeline) SRem(ctx context.Context, key string, members ...interface{}) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.srem(key, members)
+	})
+}
+
+// SMembers 获取集合所有成员，按字典序排列
+func (p *pipeline) SMembers(ctx context.Context, key string) *cache.StringSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.smembers(key)
+	})
+}
+
+// SIsMember 检查成员是否在集合中
+func (p *pipeline) SIsMember(ctx context.Context, key string, member interface{}) *cache.BoolCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewBoolCmd, func(s *store) (bool, error) {
+		return s.sismember(key, member)
+	})
+}
+
+// SCard 获取集合成员数量
+func (p *pipeline) SCard(ctx context.Context, key string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.scard(key)
+	})
+}
+
+// SPop 随机移除并返回集合中的一个成员
+func (p *pipeline) SPop(ctx context.Context, key string) *cache.StringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringCmd, func(s *store) (string, error) {
+		return s.spop(key)
+	})
+}
+
+// SRandMember 随机返回集合中的一个成员
+func (p *pipeline) SRandMember(ctx context.Context, key string) *cache.StringCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringCmd, func(s *store) (string, error) {
+		return s.srandmember(key)
+	})
+}
+
+// SInter 计算多个集合的交集
+func (p *pipeline) SInter(ctx context.Context, keys ...string) *cache.StringSliceCmd {
+	keys = p.f.keys(ctx, keys)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.sinter(keys)
+	})
+}
+
+// SUnion 计算多个集合的并集
+func (p *pipeline) SUnion(ctx context.Context, keys ...string) *cache.StringSliceCmd {
+	keys = p.f.keys(ctx, keys)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.sunion(keys)
+	})
+}
+
+// SDiff 计算多个集合的差集
+func (p *pipeline) SDiff(ctx context.Context, keys ...string) *cache.StringSliceCmd {
+	keys = p.f.keys(ctx, keys)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.sdiff(keys)
+	})
+}
+
+// 有序集合操作
+
+// ZAdd 向有序集合添加成员
+func (p *pipeline) ZAdd(ctx context.Context, key string, members ...cache.ZMember) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.zadd(key, members)
+	})
+}
+
+// ZRem 从有序集合移除成员
+func (p *pipeline) ZRem(ctx context.Context, key string, members ...interface{}) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.zrem(key, members)
+	})
+}
+
+// ZScore 获取有序集合成员的分数
+func (p *pipeline) ZScore(ctx context.Context, key, member string) *cache.FloatCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewFloatCmd, func(s *store) (float64, error) {
+		return s.zscore(key, member)
+	})
+}
+
+// ZRank 获取有序集合成员的排名（从小到大）
+func (p *pipeline) ZRank(ctx context.Context, key, member string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.zrank(key, member, false)
+	})
+}
+
+// ZRevRank 获取有序集合成员的排名（从大到小）
+func (p *pipeline) ZRevRank(ctx context.Context, key, member string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.zrank(key, member, true)
+	})
+}
+
+// ZRange 获取有序集合指定范围的成员（从小到大）
+func (p *pipeline) ZRange(ctx context.Context, key string, start, stop int64) *cache.StringSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.zrange(key, start, stop, false)
+	})
+}
+
+// ZRevRange 获取有序集合指定范围的成员（从大到小）
+func (p *pipeline) ZRevRange(ctx context.Context, key string, start, stop int64) *cache.StringSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.zrange(key, start, stop, true)
+	})
+}
+
+// ZRangeWithScores 获取有序集合指定范围的成员和分数（从小到大）
+func (p *pipeline) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *cache.ZSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewZSliceCmd, func(s *store) ([]cache.ZMember, error) {
+		return s.zrangeWithScores(key, start, stop, false)
+	})
+}
+
+// ZRevRangeWithScores 获取有序集合指定范围的成员和分数（从大到小）
+func (p *pipeline) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) *cache.ZSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewZSliceCmd, func(s *store) ([]cache.ZMember, error) {
+		return s.zrangeWithScores(key, start, stop, true)
+	})
+}
+
+// ZRangeByScore 根据分数范围获取有序集合成员
+func (p *pipeline) ZRangeByScore(ctx context.Context, key string, min, max string) *cache.StringSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.zrangeByScore(key, min, max, false)
+	})
+}
+
+// ZRevRangeByScore 根据分数范围获取有序集合成员（逆序）
+func (p *pipeline) ZRevRangeByScore(ctx context.Context, key string, max, min string) *cache.StringSliceCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.zrangeByScore(key, min, max, true)
+	})
+}
+
+// ZCard 获取有序集合成员数量
+func (p *pipeline) ZCard(ctx context.Context, key string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.zcard(key)
+	})
+}
+
+// ZCount 计算指定分数范围内的成员数量
+func (p *pipeline) ZCount(ctx context.Context, key, min, max string) *cache.IntCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.zcount(key, min, max)
+	})
+}
+
+// ZIncrBy 增加有序集合成员的分数
+func (p *pipeline) ZIncrBy(ctx context.Context, key string, increment float64, member string) *cache.FloatCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewFloatCmd, func(s *store) (float64, error) {
+		return s.zincrBy(key, increment, member)
+	})
+}
+
+// 通用键操作
+
+// Del 删除键
+func (p *pipeline) Del(ctx context.Context, keys ...string) *cache.IntCmd {
+	keys = p.f.keys(ctx, keys)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.del(keys)
+	})
+}
+
+// Exists 检查键是否存在
+func (p *pipeline) Exists(ctx context.Context, keys ...string) *cache.IntCmd {
+	keys = p.f.keys(ctx, keys)
+	return add(p, cache.NewIntCmd, func(s *store) (int64, error) {
+		return s.exists(keys)
+	})
+}
+
+// Expire 设置键的过期时间
+func (p *pipeline) Expire(ctx context.Context, key string, expiration time.Duration) *cache.BoolCmd {
+	key = p.f.key(ctx, key)
+	expiration = cache.JitterTTL(ctx, expiration)
+	return add(p, cache.NewBoolCmd, func(s *store) (bool, error) {
+		return s.expire(key, expiration)
+	})
+}
+
+// ExpireAt 设置键在指定时间过期
+func (p *pipeline) ExpireAt(ctx context.Context, key string, tm time.Time) *cache.BoolCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewBoolCmd, func(s *store) (bool, error) {
+		return s.expireAt(key, tm)
+	})
+}
+
+// TTL 获取键的剩余生存时间
+func (p *pipeline) TTL(ctx context.Context, key string) *cache.DurationCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewDurationCmd, func(s *store) (time.Duration, error) {
+		return s.ttl(key)
+	})
+}
+
+// Type 获取键的数据类型
+func (p *pipeline) Type(ctx context.Context, key string) *cache.StatusCmd {
+	key = p.f.key(ctx, key)
+	return add(p, cache.NewStatusCmd, func(s *store) (string, error) {
+		return s.typ(key)
+	})
+}
+
+// Keys 查找匹配模式的键，返回的键不包含前缀
+func (p *pipeline) Keys(ctx context.Context, pattern string) *cache.StringSliceCmd {
+	if p.f.config.Common.DisableKeys {
+		return cache.NewStringSliceCmd(func() ([]string, error) {
+			return nil, cache.ErrKeysDisabled
+		})
+	}
+	prefix := p.f.config.KeyPrefix(ctx)
+	pattern = p.f.key(ctx, pattern)
+	return add(p, cache.NewStringSliceCmd, func(s *store) ([]string, error) {
+		return s.keys(pattern, prefix)
+	})
+}
@@ -0,0 +1,221 @@
+package cachetest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 回复的类型，按连接协商的协议版本写入
+type (
+	// simpleString 状态回复，如+OK
+	simpleString string
+	// nilArray 空数组回复，RESP2为*-1，如EXEC因WATCH失败
+	nilArray struct{}
+	// respMap 键值对回复，按平铺的键值对保存；RESP2写为数组，RESP3写为%
+	respMap []interface{}
+	// respPush 推送消息，RESP2写为数组，RESP3写为>
+	respPush []interface{}
+	// multiReply 一条命令的多个回复，如SUBSCRIBE每个频道一个确认
+	multiReply []interface{}
+)
+
+// errProtocol 无法解析的请求
+var errProtocol = errors.New("cachetest: protocol error")
+
+// respReader 读取客户端发送的命令，支持RESP数组和内联命令
+type respReader struct {
+	r *bufio.Reader
+}
+
+// readLine 读取一行并去掉行尾的\r\n
+func (r *respReader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readCommand 读取一条命令，返回命令名和参数
+func (r *respReader) readCommand() ([]string, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			continue
+		}
+		if line[0] != '*' {
+			return strings.Fields(line), nil
+		}
+
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, errProtocol
+		}
+		if n == 0 {
+			continue
+		}
+		args := make([]string, n)
+		for i := range args {
+			header, err := r.readLine()
+			if err != nil {
+				return nil, err
+			}
+			if header == "" || header[0] != '$' {
+				return nil, errProtocol
+			}
+			size, err := strconv.Atoi(header[1:])
+			if err != nil || size < 0 {
+				return nil, errProtocol
+			}
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(r.r, buf); err != nil {
+				return nil, err
+			}
+			args[i] = string(buf[:size])
+		}
+		return args, nil
+	}
+}
+
+// buffered 是否还有已读取但未处理的数据，用于管道请求结束后再刷新写缓冲
+func (r *respReader) buffered() bool {
+	return r.r.Buffered() > 0
+}
+
+// respWriter 按协议版本写入回复
+type respWriter struct {
+	w     *bufio.Writer
+	proto int
+}
+
+// write 写入一个回复
+func (w *respWriter) write(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		if w.proto == 3 {
+			w.w.WriteString("_\r\n")
+		} else {
+			w.w.WriteString("$-1\r\n")
+		}
+	case nilArray:
+		if w.proto == 3 {
+			w.w.WriteString("_\r\n")
+		} else {
+			w.w.WriteString("*-1\r\n")
+		}
+	case simpleString:
+		w.w.WriteString("+" + string(v) + "\r\n")
+	case error:
+		w.writeError(v)
+	case string:
+		w.writeBulk(v)
+	case int:
+		w.writeInt(int64(v))
+	case int64:
+		w.writeInt(v)
+	case bool:
+		if v {
+			w.writeInt(1)
+		} else {
+			w.writeInt(0)
+		}
+	case float64:
+		if w.proto == 3 {
+			w.w.WriteString("," + formatFloat(v) + "\r\n")
+		} else {
+			w.writeBulk(formatFloat(v))
+		}
+	case []string:
+		w.writeHeader('*', len(v))
+		for _, s := range v {
+			w.writeBulk(s)
+		}
+	case []interface{}:
+		w.writeArray('*', v)
+	case respPush:
+		if w.proto == 3 {
+			w.writeArray('>', v)
+		} else {
+			w.writeArray('*', v)
+		}
+	case respMap:
+		if w.proto == 3 {
+			w.writeHeader('%', len(v)/2)
+			for _, item := range v {
+				w.write(item)
+			}
+		} else {
+			w.writeArray('*', v)
+		}
+	case map[string]string:
+		fields := make([]string, 0, len(v))
+		for field := range v {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		m := make(respMap, 0, len(v)*2)
+		for _, field := range fields {
+			m = append(m, field, v[field])
+		}
+		w.write(m)
+	case multiReply:
+		for _, item := range v {
+			w.write(item)
+		}
+	default:
+		w.writeError(fmt.Errorf("cachetest: unsupported reply type %T", v))
+	}
+}
+
+// writeError 写入错误回复，非Redis错误添加ERR前缀
+func (w *respWriter) writeError(err error) {
+	msg := err.Error()
+	var redisErr redisError
+	if !errors.As(err, &redisErr) {
+		msg = "ERR " + msg
+	}
+	w.w.WriteString("-" + strings.ReplaceAll(msg, "\r\n", " ") + "\r\n")
+}
+
+func (w *respWriter) writeHeader(prefix byte, n int) {
+	w.w.WriteByte(prefix)
+	w.w.WriteString(strconv.Itoa(n))
+	w.w.WriteString("\r\n")
+}
+
+func (w *respWriter) writeBulk(s string) {
+	w.writeHeader('$', len(s))
+	w.w.WriteString(s)
+	w.w.WriteString("\r\n")
+}
+
+func (w *respWriter) writeInt(n int64) {
+	w.w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func (w *respWriter) writeArray(prefix byte, items []interface{}) {
+	w.writeHeader(prefix, len(items))
+	for _, item := range items {
+		w.write(item)
+	}
+}
+
+// formatFloat 与Redis一致地格式化分数
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,246 @@
+package cachetest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scriptHash 计算脚本的SHA1
+func scriptHash(src string) string {
+	sum := sha1.Sum([]byte(src))
+	return hex.EncodeToString(sum[:])
+}
+
+// runScript 在持有数据锁时执行脚本，返回与服务端一致的回复
+// keys已添加前缀；check不为空时在redis.call执行每条命令前调用，用于集群路由和从节点只读检查
+func runScript(s *store, src string, keys, args []string, check func(cmd *command, args []string) error) interface{} {
+	globals := luaLibrary()
+	globals["KEYS"] = luaStrings(keys)
+	globals["ARGV"] = luaStrings(args)
+	globals["redis"] = redisLibrary(s, check)
+
+	vals, err := runLua(src, globals)
+	if err != nil {
+		return scriptError(err, src)
+	}
+	if len(vals) == 0 {
+		return nil
+	}
+	return luaToReply(vals[0])
+}
+
+// scriptError 将编译和运行时的错误转换为错误回复，redis.call返回的错误原样传递
+func scriptError(err error, src string) error {
+	switch e := err.(type) {
+	case *luaSyntaxError:
+		return redisError(fmt.Sprintf("ERR Error compiling script (new function): %s", e))
+	case *luaError:
+		if table, ok := e.value.(*luaTable); ok {
+			if msg, ok := table.get("err").(string); ok {
+				return redisError(msg)
+			}
+		}
+		return redisError(fmt.Sprintf("ERR user_script:%d: %s script: %s, on @user_script:%d.", e.line, e, scriptHash(src), e.line))
+	}
+	return err
+}
+
+// redisLibrary 创建脚本中的redis表
+func redisLibrary(s *store, check func(cmd *command, args []string) error) *luaTable {
+	call := func(name string, protected bool) func(args []luaValue) ([]luaValue, error) {
+		return func(args []luaValue) ([]luaValue, error) {
+			reply := scriptCommand(s, args, check)
+			if err, ok := reply.(error); ok {
+				table := luaErrorReply(err.Error())
+				if protected {
+					return []luaValue{table}, nil
+				}
+				return nil, &luaError{value: table}
+			}
+			return []luaValue{replyToLua(reply)}, nil
+		}
+	}
+	lib := luaLib(map[string]func([]luaValue) ([]luaValue, error){
+		"call":  call("call", false),
+		"pcall": call("pcall", true),
+		"error_reply": func(args []luaValue) ([]luaValue, error) {
+			msg, err := luaCheckString(args, 1, "error_reply")
+			return []luaValue{luaErrorReply(msg)}, err
+		},
+		"status_reply": func(args []luaValue) ([]luaValue, error) {
+			msg, err := luaCheckString(args, 1, "status_reply")
+			table := newLuaTable()
+			table.set("ok", msg)
+			return []luaValue{table}, err
+		},
+		"sha1hex": func(args []luaValue) ([]luaValue, error) {
+			src, err := luaCheckString(args, 1, "sha1hex")
+			return []luaValue{scriptHash(src)}, err
+		},
+		"log": func(args []luaValue) ([]luaValue, error) {
+			return nil, nil
+		},
+	})
+	for i, level := range []string{"LOG_DEBUG", "LOG_VERBOSE", "LOG_NOTICE", "LOG_WARNING"} {
+		lib.set(level, float64(i))
+	}
+	return lib
+}
+
+// scriptCommand 执行脚本中的一条命令，只支持数据命令
+func scriptCommand(s *store, luaArgs []luaValue, check func(cmd *command, args []string) error) interface{} {
+	if len(luaArgs) == 0 {
+		return redisError("ERR Please specify at least one argument for this redis lib call")
+	}
+	args := make([]string, len(luaArgs))
+	for i, arg := range luaArgs {
+		str, ok := luaToString(arg)
+		if !ok {
+			return redisError("ERR Lua redis lib command arguments must be strings or integers")
+		}
+		args[i] = str
+	}
+	name := strings.ToLower(args[0])
+	cmd, ok := commands[name]
+	if !ok {
+		return redisError("ERR Unknown Redis command called from script")
+	}
+	if err := cmd.checkArity(name, args); err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(cmd, args); err != nil {
+			return err
+		}
+	}
+	return cmd.run(s, args)
+}
+
+func luaErrorReply(msg string) *luaTable {
+	table := newLuaTable()
+	table.set("err", msg)
+	return table
+}
+
+func luaStrings(strs []string) *luaTable {
+	items := make([]luaValue, len(strs))
+	for i, str := range strs {
+		items[i] = str
+	}
+	return newLuaTable(items...)
+}
+
+// replyToLua 按RESP2的规则将命令的回复转换为Lua的值：空值为false，状态回复为{ok=...}
+func replyToLua(reply interface{}) luaValue {
+	switch v := reply.(type) {
+	case nil, nilArray:
+		return false
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case bool:
+		if v {
+			return float64(1)
+		}
+		return float64(0)
+	case float64:
+		return formatFloat(v)
+	case string:
+		return v
+	case simpleString:
+		table := newLuaTable()
+		table.set("ok", string(v))
+		return table
+	case error:
+		return luaErrorReply(v.Error())
+	case []string:
+		return luaStrings(v)
+	case []interface{}:
+		return replyArrayToLua(v)
+	case respMap:
+		return replyArrayToLua(v)
+	case map[string]string:
+		fields := make([]string, 0, len(v))
+		for field := range v {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		items := make([]luaValue, 0, len(v)*2)
+		for _, field := range fields {
+			items = append(items, field, v[field])
+		}
+		return newLuaTable(items...)
+	}
+	return false
+}
+
+func replyArrayToLua(items []interface{}) *luaTable {
+	vals := make([]luaValue, len(items))
+	for i, item := range items {
+		vals[i] = replyToLua(item)
+	}
+	return newLuaTable(vals...)
+}
+
+// luaToReply 按Redis的规则将脚本的返回值转换为回复：数字截断为整数，true为1，false和nil为空值，
+// 表按数组转换到第一个nil为止，带err或ok字段的表转换为错误或状态回复
+func luaToReply(val luaValue) interface{} {
+	switch v := val.(type) {
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return nil
+	case float64:
+		return int64(v)
+	case string:
+		return v
+	case *luaTable:
+		if msg, ok := v.get("err").(string); ok {
+			return redisError(msg)
+		}
+		if msg, ok := v.get("ok").(string); ok {
+			return simpleString(msg)
+		}
+		items := make([]interface{}, 0, len(v.array))
+		for _, item := range v.array {
+			if item == nil {
+				break
+			}
+			items = append(items, luaToReply(item))
+		}
+		return items
+	}
+	return nil
+}
+
+// scriptResult 将脚本的回复转换为go-redis的结果：空值返回redis.Nil，数组中的空值为nil
+func scriptResult(reply interface{}) (interface{}, error) {
+	switch v := reply.(type) {
+	case nil:
+		return nil, redis.Nil
+	case error:
+		return nil, v
+	}
+	return scriptValue(reply), nil
+}
+
+func scriptValue(reply interface{}) interface{} {
+	switch v := reply.(type) {
+	case simpleString:
+		return string(v)
+	case []interface{}:
+		vals := make([]interface{}, len(v))
+		for i, item := range v {
+			vals[i] = scriptValue(item)
+		}
+		return vals
+	}
+	return reply
+}
@@ -0,0 +1,246 @@
+package cachetest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cache"
+)
+
+// errNoSuchMaster 哨兵没有监控该名称的主节点
+const errNoSuchMaster = redisError("ERR No such master with that name")
+
+// Sentinel 模拟的哨兵部署，用于测试哨兵客户端的主节点发现和故障转移
+// 包含一个主节点、若干从节点和若干哨兵。主从节点共享同一份数据（复制是即时的），从节点拒绝写命令；
+// 哨兵响应SENTINEL get-master-addr-by-name、sentinels、replicas等命令，并通过发布订阅推送+switch-master事件。
+// Failover将第一个从节点提升为主节点，原主节点降级为从节点
+type Sentinel struct {
+	name string
+	fake *Fake
+
+	mu        sync.Mutex
+	master    *Server
+	replicas  []*Server
+	sentinels []*Server
+}
+
+// StartSentinel 启动名为masterName的主节点、replicas个从节点和sentinels个哨兵，opts应用于主从节点共享的数据
+func StartSentinel(masterName string, replicas, sentinels int, opts ...Option) (*Sentinel, error) {
+	if sentinels <= 0 {
+		return nil, errors.New("cachetest: sentinel deployment needs at least one sentinel")
+	}
+	s := &Sentinel{name: masterName, fake: NewFake(opts...)}
+
+	var err error
+	if s.master, err = s.startNode(""); err != nil {
+		s.Close()
+		return nil, err
+	}
+	for i := 0; i < replicas; i++ {
+		replica, err := s.startNode(s.master.Addr())
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.replicas = append(s.replicas, replica)
+	}
+	for i := 0; i < sentinels; i++ {
+		sentinel, err := newServer(NewFake())
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		sentinel.sentinel = s
+		sentinel.start()
+		s.sentinels = append(s.sentinels, sentinel)
+	}
+	return s, nil
+}
+
+// startNode 启动共享数据的主节点或从节点
+func (s *Sentinel) startNode(master string) (*Server, error) {
+	node, err := newServer(s.fake)
+	if err != nil {
+		return nil, err
+	}
+	node.group = s
+	node.master = master
+	node.start()
+	return node, nil
+}
+
+// MasterName 获取主节点名称
+func (s *Sentinel) MasterName() string {
+	return s.name
+}
+
+// Master 获取当前的主节点
+func (s *Sentinel) Master() *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.master
+}
+
+// Replicas 获取当前的从节点
+func (s *Sentinel) Replicas() []*Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Server(nil), s.replicas...)
+}
+
+// Addrs 获取所有哨兵的地址
+func (s *Sentinel) Addrs() []string {
+	addrs := make([]string, len(s.sentinels))
+	for i, sentinel := range s.sentinels {
+		addrs[i] = sentinel.Addr()
+	}
+	return addrs
+}
+
+// Sentinels 获取所有哨兵节点
+func (s *Sentinel) Sentinels() []*Server {
+	return s.sentinels
+}
+
+// Fake 获取主从节点共享的数据，用于直接读写数据和拨动时钟
+func (s *Sentinel) Fake() *Fake {
+	return s.fake
+}
+
+// Config 获取连接哨兵部署的配置，键前缀和默认过期时间与Fake一致
+func (s *Sentinel) Config() *cache.Config {
+	return &cache.Config{
+		Mode: cache.ModeSentinel,
+		Sentinel: &cache.SentinelConfig{
+			Addrs:      s.Addrs(),
+			MasterName: s.name,
+		},
+		Common: s.fake.config.Common,
+	}
+}
+
+// Failover 将第一个从节点提升为主节点，原主节点降级为从节点，并由所有哨兵推送+switch-master事件
+func (s *Sentinel) Failover() error {
+	s.mu.Lock()
+	if len(s.replicas) == 0 {
+		s.mu.Unlock()
+		return errors.New("cachetest: no replica to promote")
+	}
+	old, promoted := s.master, s.replicas[0]
+	s.master = promoted
+	s.replicas = append(s.replicas[1:], old)
+	promoted.setMaster("")
+	for _, replica := range s.replicas {
+		replica.setMaster(promoted.Addr())
+	}
+	sentinels := s.sentinels
+	s.mu.Unlock()
+
+	oldHost, oldPort := splitAddr(old.Addr())
+	newHost, newPort := splitAddr(promoted.Addr())
+	payload := fmt.Sprintf("%s %s %d %s %d", s.name, oldHost, oldPort, newHost, newPort)
+	for _, sentinel := range sentinels {
+		sentinel.publish("+switch-master", payload)
+	}
+	return nil
+}
+
+// Close 关闭所有哨兵和主从节点
+func (s *Sentinel) Close() error {
+	s.mu.Lock()
+	servers := append(append(append([]*Server(nil), s.sentinels...), s.replicas...), s.master)
+	s.mu.Unlock()
+
+	var err error
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		if closeErr := server.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// command SENTINEL命令，self为收到命令的哨兵
+func (s *Sentinel) command(self *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return wrongArgs("sentinel")
+	}
+	sub := strings.ToLower(args[1])
+	if sub == "masters" {
+		return []interface{}{s.masterInfo()}
+	}
+	if len(args) < 3 {
+		return wrongArgs("sentinel|" + sub)
+	}
+	if args[2] != s.name {
+		if sub == "get-master-addr-by-name" {
+			return nilArray{}
+		}
+		return errNoSuchMaster
+	}
+
+	switch sub {
+	case "get-master-addr-by-name":
+		host, port := splitAddr(s.Master().Addr())
+		return []string{host, strconv.FormatInt(port, 10)}
+	case "master":
+		return s.masterInfo()
+	case "replicas", "slaves":
+		master := s.Master()
+		infos := []interface{}{}
+		for _, replica := range s.Replicas() {
+			infos = append(infos, nodeInfo(replica, "slave", master))
+		}
+		return infos
+	case "sentinels":
+		infos := []interface{}{}
+		for _, sentinel := range s.sentinels {
+			if sentinel != self {
+				infos = append(infos, nodeInfo(sentinel, "sentinel", nil))
+			}
+		}
+		return infos
+	case "failover":
+		if err := s.Failover(); err != nil {
+			return redisError("NOGOODSLAVE No suitable replica to promote")
+		}
+		return simpleString("OK")
+	}
+	return redisError(fmt.Sprintf("ERR Unknown sentinel subcommand '%s'", args[1]))
+}
+
+// masterInfo SENTINEL MASTER的回复
+func (s *Sentinel) masterInfo() respMap {
+	info := nodeInfo(s.Master(), "master", nil)
+	info[1] = s.name
+	return append(info,
+		"num-slaves", strconv.Itoa(len(s.Replicas())),
+		"num-other-sentinels", strconv.Itoa(len(s.sentinels)-1),
+		"quorum", "1")
+}
+
+// nodeInfo 哨兵回复中节点的信息，master不为空时包含从节点所属的主节点
+func nodeInfo(node *Server, flags string, master *Server) respMap {
+	host, port := splitAddr(node.Addr())
+	info := respMap{
+		"name", node.Addr(),
+		"ip", host,
+		"port", strconv.FormatInt(port, 10),
+		"runid", nodeID(int(port)),
+		"flags", flags,
+	}
+	if master != nil {
+		masterHost, masterPort := splitAddr(master.Addr())
+		info = append(info,
+			"master-link-status", "ok",
+			"master-host", masterHost,
+			"master-port", strconv.FormatInt(masterPort, 10))
+	}
+	return info
+}
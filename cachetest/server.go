@@ -0,0 +1,923 @@
+package cachetest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"cache"
+)
+
+// serverVersion 服务端报告的Redis版本
+const serverVersion = "7.2.0"
+
+// 服务端的错误回复
+const (
+	errSyntax         = redisError("ERR syntax error")
+	errReadOnly       = redisError("READONLY You can't write against a read only replica.")
+	errExecAbort      = redisError("EXECABORT Transaction discarded because of previous errors.")
+	errNestedMulti    = redisError("ERR MULTI calls can not be nested")
+	errExecNoMulti    = redisError("ERR EXEC without MULTI")
+	errDiscardNoMulti = redisError("ERR DISCARD without MULTI")
+	errWatchInMulti   = redisError("ERR WATCH inside MULTI is not allowed")
+	errNoProto        = redisError("NOPROTO unsupported protocol version")
+	errDBIndex        = redisError("ERR DB index is out of range")
+	errNoCluster      = redisError("ERR This instance has cluster support disabled")
+	errNoScript       = redisError("NOSCRIPT No matching script. Please use EVAL.")
+	errNonLocalKey    = redisError("ERR Script attempted to access a non local key in a cluster node")
+)
+
+// Server 内嵌的RESP服务端，用于不依赖外部Redis的集成测试
+// 在本机随机端口上监听，使用与Fake相同的内存数据模型处理命令，支持RESP2和RESP3（通过HELLO协商）、
+// 管道、MULTI/EXEC/WATCH事务和发布订阅，可以用Config返回的配置通过cache.NewClientFromConfig连接。
+// 过期时间按Fake的时钟计算，通过Fake().Advance拨动。
+// Lua脚本与Fake一样在内嵌的解释器中原子地执行；不支持Redis Functions
+type Server struct {
+	fake *Fake
+	ln   net.Listener
+
+	mu       sync.Mutex
+	conns    map[*serverConn]struct{}
+	channels map[string]map[*serverConn]struct{}
+	patterns map[string]map[*serverConn]struct{}
+	// 作为从节点时的主节点地址，从节点拒绝写命令
+	master string
+	closed bool
+	wg     sync.WaitGroup
+	nextID atomic.Int64
+	// 每种命令执行的次数，由mu保护
+	calls map[string]int64
+
+	// 所属的模拟集群及在集群中的序号
+	cluster *Cluster
+	index   int
+	// 所属的哨兵部署，group为数据节点所在的部署，sentinel不为空时节点是哨兵
+	group    *Sentinel
+	sentinel *Sentinel
+}
+
+// StartServer 启动单机服务端，opts用于设置时钟和Config返回的键前缀、默认过期时间
+func StartServer(opts ...Option) (*Server, error) {
+	s, err := newServer(NewFake(opts...))
+	if err != nil {
+		return nil, err
+	}
+	s.start()
+	return s, nil
+}
+
+// newServer 使用指定的数据创建服务端并开始监听，设置集群或哨兵角色后调用start接受连接
+func newServer(fake *Fake) (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		fake:     fake,
+		ln:       ln,
+		conns:    make(map[*serverConn]struct{}),
+		channels: make(map[string]map[*serverConn]struct{}),
+		patterns: make(map[string]map[*serverConn]struct{}),
+		calls:    make(map[string]int64),
+	}
+	return s, nil
+}
+
+// start 开始接受连接
+func (s *Server) start() {
+	s.wg.Add(1)
+	go s.accept()
+}
+
+// Addr 获取监听地址
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Fake 获取服务端使用的数据，用于直接读写数据和拨动时钟
+func (s *Server) Fake() *Fake {
+	return s.fake
+}
+
+// Config 获取连接服务端的单机模式配置，键前缀和默认过期时间与Fake一致
+func (s *Server) Config() *cache.Config {
+	return &cache.Config{
+		Mode:   cache.ModeSingle,
+		Single: &cache.SingleConfig{Addr: s.Addr()},
+		Common: s.fake.config.Common,
+	}
+}
+
+// Calls 获取节点收到的名为name（小写）的命令数量，包括事务中排队的命令，用于检查命令被发往哪个节点
+func (s *Server) Calls(name string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[name]
+}
+
+// Close 停止监听并断开所有连接，数据保留在Fake中
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return cache.ErrClientClosed
+	}
+	s.closed = true
+	err := s.ln.Close()
+	for c := range s.conns {
+		c.conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// setMaster 设置节点的主节点，为空时节点成为主节点
+func (s *Server) setMaster(addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.master = addr
+}
+
+// replicaOf 获取节点的主节点地址，主节点返回空
+func (s *Server) replicaOf() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.master
+}
+
+// accept 接受连接，每个连接一个协程
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		c := &serverConn{
+			s:    s,
+			conn: conn,
+			id:   s.nextID.Add(1),
+			r:    respReader{r: bufio.NewReader(conn)},
+			w:    respWriter{w: bufio.NewWriter(conn), proto: 2},
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serve(c)
+	}
+}
+
+// serve 读取并执行命令，管道中的命令执行完后才刷新写缓冲
+func (s *Server) serve(c *serverConn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.unsubscribeAll(c)
+		s.mu.Unlock()
+		c.conn.Close()
+	}()
+
+	for {
+		args, err := c.r.readCommand()
+		if err != nil {
+			if err == errProtocol {
+				c.reply(redisError("ERR Protocol error"), true)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		reply, quit := c.exec(args)
+		if err := c.reply(reply, quit || !c.r.buffered()); err != nil || quit {
+			return
+		}
+	}
+}
+
+// publish 向订阅了频道的连接推送消息，返回接收消息的连接数
+func (s *Server) publish(channel, message string) int64 {
+	type delivery struct {
+		c   *serverConn
+		msg respPush
+	}
+	var deliveries []delivery
+
+	s.mu.Lock()
+	for c := range s.channels[channel] {
+		deliveries = append(deliveries, delivery{c, respPush{"message", channel, message}})
+	}
+	for pattern, conns := range s.patterns {
+		if matchPattern(pattern, channel) {
+			for c := range conns {
+				deliveries = append(deliveries, delivery{c, respPush{"pmessage", pattern, channel, message}})
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for _, d := range deliveries {
+		d.c.reply(d.msg, true)
+	}
+	return int64(len(deliveries))
+}
+
+// pubsubInfo PUBSUB CHANNELS、NUMSUB和NUMPAT
+func (s *Server) pubsubInfo(args []string) interface{} {
+	if len(args) < 2 {
+		return wrongArgs("pubsub")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToLower(args[1]) {
+	case "channels":
+		channels := []string{}
+		for channel, conns := range s.channels {
+			if len(conns) > 0 && (len(args) < 3 || matchPattern(args[2], channel)) {
+				channels = append(channels, channel)
+			}
+		}
+		sort.Strings(channels)
+		return channels
+	case "numsub":
+		counts := make(respMap, 0, (len(args)-2)*2)
+		for _, channel := range args[2:] {
+			counts = append(counts, channel, int64(len(s.channels[channel])))
+		}
+		return counts
+	case "numpat":
+		var n int64
+		for _, conns := range s.patterns {
+			if len(conns) > 0 {
+				n++
+			}
+		}
+		return n
+	}
+	return redisError(fmt.Sprintf("ERR unknown subcommand '%s'", args[1]))
+}
+
+// unsubscribeAll 取消连接的所有订阅，调用时持有s.mu
+func (s *Server) unsubscribeAll(c *serverConn) {
+	for channel := range c.channels {
+		delete(s.channels[channel], c)
+	}
+	for pattern := range c.patterns {
+		delete(s.patterns[pattern], c)
+	}
+	c.channels, c.patterns = nil, nil
+}
+
+// serverConn 服务端的一个连接
+type serverConn struct {
+	s    *Server
+	conn net.Conn
+	id   int64
+	name string
+	r    respReader
+
+	// 写回复和推送消息的协程共用写缓冲
+	wmu sync.Mutex
+	w   respWriter
+
+	// MULTI之后入队的命令，入队出错时EXEC放弃整个事务
+	multi   bool
+	queued  [][]string
+	aborted bool
+	// WATCH的键及其版本
+	watched map[string]uint64
+	// 上一条命令是ASKING
+	asking bool
+
+	// 订阅的频道和模式，由s.mu保护
+	channels map[string]struct{}
+	patterns map[string]struct{}
+}
+
+// reply 写入回复，flush为true时刷新写缓冲
+func (c *serverConn) reply(v interface{}, flush bool) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.w.write(v)
+	if !flush {
+		return nil
+	}
+	return c.w.w.Flush()
+}
+
+// subscriptions 连接订阅的频道和模式数量
+func (c *serverConn) subscriptions() int {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	return len(c.channels) + len(c.patterns)
+}
+
+// exec 执行一条命令，quit为true时回复后关闭连接
+func (c *serverConn) exec(args []string) (reply interface{}, quit bool) {
+	name := strings.ToLower(args[0])
+	asking := c.asking
+	c.asking = false
+	c.s.mu.Lock()
+	c.s.calls[name]++
+	c.s.mu.Unlock()
+
+	if c.multi {
+		switch name {
+		case "exec", "discard", "multi", "watch", "quit":
+		default:
+			return c.queue(name, args, asking), false
+		}
+	}
+	if c.w.proto == 2 && c.subscriptions() > 0 {
+		switch name {
+		case "subscribe", "unsubscribe", "psubscribe", "punsubscribe", "ping", "quit":
+		default:
+			return redisError(fmt.Sprintf("ERR Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT are allowed in this context", name)), false
+		}
+	}
+
+	switch name {
+	case "ping":
+		return c.ping(args), false
+	case "echo":
+		if len(args) != 2 {
+			return wrongArgs(name), false
+		}
+		return args[1], false
+	case "hello":
+		return c.hello(args), false
+	case "auth":
+		return simpleString("OK"), false
+	case "select":
+		return c.selectDB(args), false
+	case "client":
+		return c.client(args), false
+	case "quit":
+		return simpleString("OK"), true
+	case "command":
+		return commandInfo(args), false
+	case "readonly", "readwrite":
+		return simpleString("OK"), false
+	case "asking":
+		if c.s.cluster == nil {
+			return errNoCluster, false
+		}
+		c.asking = true
+		return simpleString("OK"), false
+	case "multi":
+		return c.startMulti(), false
+	case "exec":
+		return c.execMulti(), false
+	case "discard":
+		return c.discard(), false
+	case "watch":
+		return c.watch(args), false
+	case "unwatch":
+		c.watched = nil
+		return simpleString("OK"), false
+	case "subscribe", "psubscribe":
+		return c.subscribe(args[1:], name == "psubscribe"), false
+	case "unsubscribe", "punsubscribe":
+		return c.unsubscribe(args[1:], name == "punsubscribe"), false
+	case "publish":
+		if len(args) != 3 {
+			return wrongArgs(name), false
+		}
+		return c.s.publish(args[1], args[2]), false
+	case "pubsub":
+		return c.s.pubsubInfo(args), false
+	case "role":
+		return c.role(), false
+	case "info":
+		return c.info(), false
+	case "cluster":
+		if c.s.cluster == nil {
+			return errNoCluster, false
+		}
+		return c.s.cluster.command(c.s, args), false
+	case "sentinel":
+		if c.s.sentinel == nil {
+			return unknownCommand(args), false
+		}
+		return c.s.sentinel.command(c.s, args), false
+	case "eval", "evalsha", "script":
+		if c.s.sentinel != nil {
+			return unknownCommand(args), false
+		}
+		return c.script(name, args, asking), false
+	}
+
+	cmd, ok := commands[name]
+	if !ok || c.s.sentinel != nil {
+		return unknownCommand(args), false
+	}
+	if err := cmd.checkArity(name, args); err != nil {
+		return err, false
+	}
+	return c.call(cmd, args, asking), false
+}
+
+// call 加锁执行数据命令，集群模式下先检查键所在的哈希槽
+func (c *serverConn) call(cmd *command, args []string, asking bool) interface{} {
+	if cmd.write && c.s.replicaOf() != "" {
+		return errReadOnly
+	}
+	if cluster := c.s.cluster; cluster != nil {
+		cluster.mu.RLock()
+		defer cluster.mu.RUnlock()
+	}
+	f := c.s.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c.s.cluster != nil {
+		if err := c.s.cluster.route(c.s, cmd.keys(args), asking); err != nil {
+			return err
+		}
+	}
+	return cmd.run(f.store, args)
+}
+
+func (c *serverConn) ping(args []string) interface{} {
+	if len(args) > 2 {
+		return wrongArgs("ping")
+	}
+	if c.w.proto == 2 && c.subscriptions() > 0 {
+		msg := ""
+		if len(args) == 2 {
+			msg = args[1]
+		}
+		return respPush{"pong", msg}
+	}
+	if len(args) == 2 {
+		return args[1]
+	}
+	return simpleString("PONG")
+}
+
+// hello 协商协议版本，回复按新的协议版本写入
+func (c *serverConn) hello(args []string) interface{} {
+	proto := c.w.proto
+	if len(args) > 1 {
+		v, err := strconv.Atoi(args[1])
+		if err != nil || (v != 2 && v != 3) {
+			return errNoProto
+		}
+		proto = v
+	}
+	for i := 2; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "auth":
+			i += 2
+		case "setname":
+			if i+1 < len(args) {
+				c.name = args[i+1]
+			}
+			i++
+		default:
+			return errSyntax
+		}
+	}
+
+	c.wmu.Lock()
+	c.w.proto = proto
+	c.wmu.Unlock()
+
+	role := "master"
+	if c.s.replicaOf() != "" {
+		role = "replica"
+	}
+	return respMap{
+		"server", "redis",
+		"version", serverVersion,
+		"proto", int64(proto),
+		"id", c.id,
+		"mode", c.s.mode(),
+		"role", role,
+		"modules", []interface{}{},
+	}
+}
+
+// mode 服务端的运行模式，与INFO中的redis_mode一致
+func (s *Server) mode() string {
+	switch {
+	case s.cluster != nil:
+		return "cluster"
+	case s.sentinel != nil:
+		return "sentinel"
+	}
+	return "standalone"
+}
+
+// selectDB 只有一个数据库，选择其他数据库时返回错误
+func (c *serverConn) selectDB(args []string) interface{} {
+	if len(args) != 2 {
+		return wrongArgs("select")
+	}
+	if args[1] != "0" {
+		return errDBIndex
+	}
+	return simpleString("OK")
+}
+
+func (c *serverConn) client(args []string) interface{} {
+	if len(args) < 2 {
+		return wrongArgs("client")
+	}
+	switch strings.ToLower(args[1]) {
+	case "setname":
+		if len(args) != 3 {
+			return wrongArgs("client|setname")
+		}
+		c.name = args[2]
+		return simpleString("OK")
+	case "getname":
+		if c.name == "" {
+			return nil
+		}
+		return c.name
+	case "id":
+		return c.id
+	case "setinfo", "no-evict", "no-touch":
+		return simpleString("OK")
+	case "info":
+		return fmt.Sprintf("id=%d addr=%s name=%s db=0\n", c.id, c.conn.RemoteAddr(), c.name)
+	}
+	return redisError(fmt.Sprintf("ERR unknown subcommand '%s'", args[1]))
+}
+
+// 事务
+
+func (c *serverConn) startMulti() interface{} {
+	if c.multi {
+		return errNestedMulti
+	}
+	c.multi = true
+	return simpleString("OK")
+}
+
+// queue 命令入队，命令不存在、参数错误或不属于本节点时EXEC放弃事务
+func (c *serverConn) queue(name string, args []string, asking bool) interface{} {
+	cmd, ok := commands[name]
+	if !ok || c.s.sentinel != nil {
+		c.aborted = true
+		return unknownCommand(args)
+	}
+	if err := cmd.checkArity(name, args); err != nil {
+		c.aborted = true
+		return err
+	}
+	if cluster := c.s.cluster; cluster != nil {
+		cluster.mu.RLock()
+		c.s.fake.mu.Lock()
+		err := cluster.route(c.s, cmd.keys(args), asking)
+		c.s.fake.mu.Unlock()
+		cluster.mu.RUnlock()
+		if err != nil {
+			c.aborted = true
+			return err
+		}
+	}
+	c.queued = append(c.queued, args)
+	return simpleString("QUEUED")
+}
+
+// execMulti 原子地执行入队的命令，WATCH的键被修改时返回空数组
+func (c *serverConn) execMulti() interface{} {
+	if !c.multi {
+		return errExecNoMulti
+	}
+	queued, aborted, watched := c.queued, c.aborted, c.watched
+	c.multi, c.queued, c.aborted, c.watched = false, nil, false, nil
+	if aborted {
+		return errExecAbort
+	}
+	replica := c.s.replicaOf() != ""
+
+	if cluster := c.s.cluster; cluster != nil {
+		cluster.mu.RLock()
+		defer cluster.mu.RUnlock()
+	}
+	f := c.s.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, version := range watched {
+		f.store.lookup(key)
+		if f.store.versions[key] != version {
+			return nilArray{}
+		}
+	}
+	replies := make([]interface{}, len(queued))
+	for i, args := range queued {
+		cmd := commands[strings.ToLower(args[0])]
+		if cmd.write && replica {
+			replies[i] = errReadOnly
+			continue
+		}
+		replies[i] = cmd.run(f.store, args)
+	}
+	return replies
+}
+
+func (c *serverConn) discard() interface{} {
+	if !c.multi {
+		return errDiscardNoMulti
+	}
+	c.multi, c.queued, c.aborted, c.watched = false, nil, false, nil
+	return simpleString("OK")
+}
+
+// watch 记录键的当前版本，EXEC时版本变化说明键被修改
+func (c *serverConn) watch(args []string) interface{} {
+	if c.multi {
+		return errWatchInMulti
+	}
+	if len(args) < 2 {
+		return wrongArgs("watch")
+	}
+	keys := args[1:]
+
+	if cluster := c.s.cluster; cluster != nil {
+		cluster.mu.RLock()
+		defer cluster.mu.RUnlock()
+	}
+	f := c.s.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c.s.cluster != nil {
+		if err := c.s.cluster.route(c.s, keys, false); err != nil {
+			return err
+		}
+	}
+	if c.watched == nil {
+		c.watched = make(map[string]uint64)
+	}
+	for _, key := range keys {
+		if _, ok := c.watched[key]; !ok {
+			f.store.lookup(key)
+			c.watched[key] = f.store.versions[key]
+		}
+	}
+	return simpleString("OK")
+}
+
+// 发布订阅
+
+// subscribe 订阅频道或模式，每个频道回复一条确认
+func (c *serverConn) subscribe(names []string, pattern bool) interface{} {
+	kind := "subscribe"
+	if pattern {
+		kind = "psubscribe"
+	}
+	if len(names) == 0 {
+		return wrongArgs(kind)
+	}
+
+	s := c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replies := make(multiReply, 0, len(names))
+	for _, name := range names {
+		own, all := &c.channels, s.channels
+		if pattern {
+			own, all = &c.patterns, s.patterns
+		}
+		if *own == nil {
+			*own = make(map[string]struct{})
+		}
+		(*own)[name] = struct{}{}
+		if all[name] == nil {
+			all[name] = make(map[*serverConn]struct{})
+		}
+		all[name][c] = struct{}{}
+		replies = append(replies, respPush{kind, name, int64(len(c.channels) + len(c.patterns))})
+	}
+	return replies
+}
+
+// unsubscribe 取消订阅，未指定频道时取消所有订阅
+func (c *serverConn) unsubscribe(names []string, pattern bool) interface{} {
+	kind := "unsubscribe"
+	if pattern {
+		kind = "punsubscribe"
+	}
+
+	s := c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	own, all := c.channels, s.channels
+	if pattern {
+		own, all = c.patterns, s.patterns
+	}
+	if len(names) == 0 {
+		for name := range own {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return respPush{kind, nil, int64(len(c.channels) + len(c.patterns))}
+	}
+
+	replies := make(multiReply, 0, len(names))
+	for _, name := range names {
+		delete(own, name)
+		delete(all[name], c)
+		replies = append(replies, respPush{kind, name, int64(len(c.channels) + len(c.patterns))})
+	}
+	return replies
+}
+
+// 服务端信息
+
+// role 与ROLE命令一致的角色信息
+func (c *serverConn) role() interface{} {
+	s := c.s
+	if s.sentinel != nil {
+		return []interface{}{"sentinel", []interface{}{s.sentinel.name}}
+	}
+	if master := s.replicaOf(); master != "" {
+		host, port := splitAddr(master)
+		return []interface{}{"slave", host, port, "connected", int64(0)}
+	}
+	replicas := []interface{}{}
+	if s.group != nil {
+		for _, replica := range s.group.Replicas() {
+			host, port := splitAddr(replica.Addr())
+			replicas = append(replicas, []interface{}{host, strconv.FormatInt(port, 10), "0"})
+		}
+	}
+	return []interface{}{"master", int64(0), replicas}
+}
+
+// info 精简的INFO回复
+func (c *serverConn) info() interface{} {
+	s := c.s
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Server\r\nredis_version:%s\r\nredis_mode:%s\r\ntcp_port:%d\r\n", serverVersion, s.mode(), addrPort(s.Addr()))
+	if s.sentinel != nil {
+		return b.String()
+	}
+
+	b.WriteString("\r\n# Replication\r\n")
+	if master := s.replicaOf(); master != "" {
+		host, port := splitAddr(master)
+		fmt.Fprintf(&b, "role:slave\r\nmaster_host:%s\r\nmaster_port:%d\r\nmaster_link_status:up\r\n", host, port)
+	} else {
+		b.WriteString("role:master\r\n")
+	}
+	cluster := 0
+	if s.cluster != nil {
+		cluster = 1
+	}
+	fmt.Fprintf(&b, "\r\n# Cluster\r\ncluster_enabled:%d\r\n", cluster)
+
+	f := s.fake
+	f.mu.Lock()
+	var keys, expires int
+	for _, key := range f.store.sortedKeys() {
+		keys++
+		if !f.store.data[key].expireAt.IsZero() {
+			expires++
+		}
+	}
+	f.mu.Unlock()
+	b.WriteString("\r\n# Keyspace\r\n")
+	if keys > 0 {
+		fmt.Fprintf(&b, "db0:keys=%d,expires=%d\r\n", keys, expires)
+	}
+	return b.String()
+}
+
+// script EVAL、EVALSHA和SCRIPT命令
+func (c *serverConn) script(name string, args []string, asking bool) interface{} {
+	if name == "eval" || name == "evalsha" {
+		return c.eval(name, args, asking)
+	}
+
+	if len(args) < 2 {
+		return wrongArgs(name)
+	}
+	f := c.s.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch strings.ToLower(args[1]) {
+	case "load":
+		if len(args) != 3 {
+			return wrongArgs("script|load")
+		}
+		sha := scriptHash(args[2])
+		f.scripts[sha] = args[2]
+		return sha
+	case "exists":
+		exists := make([]interface{}, len(args)-2)
+		for i, sha := range args[2:] {
+			_, ok := f.scripts[strings.ToLower(sha)]
+			exists[i] = ok
+		}
+		return exists
+	case "flush":
+		f.scripts = make(map[string]string)
+		return simpleString("OK")
+	case "kill":
+		return redisError("NOTBUSY No scripts in execution right now.")
+	}
+	return redisError(fmt.Sprintf("ERR unknown subcommand '%s'", args[1]))
+}
+
+// eval 在数据锁中原子地执行脚本，集群模式下KEYS需要属于本节点，脚本中访问的键也不能属于其他节点
+func (c *serverConn) eval(name string, args []string, asking bool) interface{} {
+	if len(args) < 3 {
+		return wrongArgs(name)
+	}
+	numKeys, err := strconv.Atoi(args[2])
+	switch {
+	case err != nil:
+		return errNotInteger
+	case numKeys < 0:
+		return redisError("ERR Number of keys can't be negative")
+	case numKeys > len(args)-3:
+		return redisError("ERR Number of keys can't be greater than number of args")
+	}
+	keys, argv := args[3:3+numKeys], args[3+numKeys:]
+	replica := c.s.replicaOf() != ""
+
+	cluster := c.s.cluster
+	if cluster != nil {
+		cluster.mu.RLock()
+		defer cluster.mu.RUnlock()
+	}
+	f := c.s.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	src := args[1]
+	if name == "evalsha" {
+		var ok bool
+		if src, ok = f.scripts[strings.ToLower(src)]; !ok {
+			return errNoScript
+		}
+	} else {
+		f.scripts[scriptHash(src)] = src
+	}
+	if cluster != nil {
+		if err := cluster.route(c.s, keys, asking); err != nil {
+			return err
+		}
+	}
+	return runScript(f.store, src, keys, argv, func(cmd *command, args []string) error {
+		if cmd.write && replica {
+			return errReadOnly
+		}
+		if cluster != nil {
+			for _, key := range cmd.keys(args) {
+				slot := cache.KeySlot(key)
+				to, migrating := cluster.migrating[slot]
+				if cluster.owners[slot] != c.s.index && !(migrating && to == c.s.index && asking) {
+					return errNonLocalKey
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// wrongArgs 参数个数错误
+func wrongArgs(name string) error {
+	return redisError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+}
+
+// unknownCommand 命令不存在
+func unknownCommand(args []string) error {
+	return redisError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+}
+
+// splitAddr 拆分地址为主机和端口
+func splitAddr(addr string) (string, int64) {
+	host, port, _ := net.SplitHostPort(addr)
+	n, _ := strconv.ParseInt(port, 10, 64)
+	return host, n
+}
+
+// addrPort 获取地址中的端口
+func addrPort(addr string) int64 {
+	_, port := splitAddr(addr)
+	return port
+}
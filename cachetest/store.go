@@ -0,0 +1,1321 @@
+package cachetest
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisError 服务端返回的错误，与go-redis一样实现redis.Error接口
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+func (redisError) RedisError() {}
+
+// 与Redis一致的错误
+const (
+	errWrongType    = redisError("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInteger   = redisError("ERR value is not an integer or out of range")
+	errNoSuchKey    = redisError("ERR no such key")
+	errOutOfRange   = redisError("ERR index out of range")
+	errMinMax       = redisError("ERR min or max is not a float")
+	errWrongArgsNum = redisError("ERR wrong number of arguments")
+)
+
+// 键的数据类型，与TYPE命令的返回值一致
+const (
+	typeString = "string"
+	typeHash   = "hash"
+	typeList   = "list"
+	typeSet    = "set"
+	typeZSet   = "zset"
+)
+
+// entry 一个键的值
+type entry struct {
+	kind     string
+	str      string
+	hash     map[string]string
+	list     []string
+	set      map[string]struct{}
+	zset     map[string]float64
+	expireAt time.Time
+}
+
+// store 内存数据库，不加锁，键已经添加了前缀
+type store struct {
+	data map[string]*entry
+	// 每个键的修改版本，用于WATCH
+	versions map[string]uint64
+	// SCAN游标对应的下一个要检查的键
+	cursors    map[uint64]string
+	nextCursor uint64
+	now        func() time.Time
+}
+
+// maxScanCursors 保留的SCAN游标数量，超过时清空，之后使用旧游标的遍历从头开始
+const maxScanCursors = 1024
+
+func newStore(now func() time.Time) *store {
+	return &store{
+		data:     make(map[string]*entry),
+		versions: make(map[string]uint64),
+		cursors:  make(map[uint64]string),
+		now:      now,
+	}
+}
+
+// touch 记录键被修改
+func (s *store) touch(key string) {
+	s.versions[key]++
+}
+
+// lookup 获取未过期的键，过期的键被删除
+func (s *store) lookup(key string) *entry {
+	e, ok := s.data[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !s.now().Before(e.expireAt) {
+		delete(s.data, key)
+		s.touch(key)
+		return nil
+	}
+	return e
+}
+
+// lookupKind 获取指定类型的键，类型不符时返回WRONGTYPE
+func (s *store) lookupKind(key, kind string) (*entry, error) {
+	e := s.lookup(key)
+	if e != nil && e.kind != kind {
+		return nil, errWrongType
+	}
+	return e, nil
+}
+
+// create 获取指定类型的键，不存在时创建
+func (s *store) create(key, kind string) (*entry, error) {
+	e, err := s.lookupKind(key, kind)
+	if err != nil || e != nil {
+		return e, err
+	}
+	e = &entry{kind: kind}
+	switch kind {
+	case typeHash:
+		e.hash = make(map[string]string)
+	case typeSet:
+		e.set = make(map[string]struct{})
+	case typeZSet:
+		e.zset = make(map[string]float64)
+	}
+	s.data[key] = e
+	return e, nil
+}
+
+// removeIfEmpty 集合类型的键没有元素时删除
+func (s *store) removeIfEmpty(key string, e *entry) {
+	var n int
+	switch e.kind {
+	case typeHash:
+		n = len(e.hash)
+	case typeList:
+		n = len(e.list)
+	case typeSet:
+		n = len(e.set)
+	case typeZSet:
+		n = len(e.zset)
+	default:
+		return
+	}
+	if n == 0 {
+		delete(s.data, key)
+	}
+}
+
+// expireAfter 将过期时间换算为过期时刻，不大于0时不过期
+func (s *store) expireAfter(expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return s.now().Add(expiration)
+}
+
+// format 按go-redis的规则将参数转换为字符串
+func format(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10), nil
+	case net.IP:
+		return string(v), nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("redis: can't marshal %T (implement encoding.BinaryMarshaler)", v)
+	}
+}
+
+// formatAll 转换多个参数
+func formatAll(values []interface{}) ([]string, error) {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		str, err := format(v)
+		if err != nil {
+			return nil, err
+		}
+		strs[i] = str
+	}
+	return strs, nil
+}
+
+// flattenPairs 按go-redis的规则展开键值对参数，支持平铺的键值对、[]string和map
+func flattenPairs(pairs []interface{}) ([]string, error) {
+	if len(pairs) == 1 {
+		switch v := pairs[0].(type) {
+		case []string:
+			if len(v)%2 != 0 {
+				return nil, errWrongArgsNum
+			}
+			return v, nil
+		case map[string]interface{}:
+			flat := make([]string, 0, len(v)*2)
+			for field, value := range v {
+				str, err := format(value)
+				if err != nil {
+					return nil, err
+				}
+				flat = append(flat, field, str)
+			}
+			return flat, nil
+		case map[string]string:
+			flat := make([]string, 0, len(v)*2)
+			for field, value := range v {
+				flat = append(flat, field, value)
+			}
+			return flat, nil
+		}
+	}
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return nil, errWrongArgsNum
+	}
+	return formatAll(pairs)
+}
+
+// 字符串操作
+
+func (s *store) get(key string) (string, error) {
+	e, err := s.lookupKind(key, typeString)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", cache.ErrKeyNotFound
+	}
+	return e.str, nil
+}
+
+func (s *store) set(key string, value interface{}, expiration time.Duration) (string, error) {
+	str, err := format(value)
+	if err != nil {
+		return "", err
+	}
+	s.data[key] = &entry{kind: typeString, str: str, expireAt: s.expireAfter(expiration)}
+	s.touch(key)
+	return "OK", nil
+}
+
+func (s *store) setNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	if s.lookup(key) != nil {
+		return false, nil
+	}
+	if _, err := s.set(key, value, expiration); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *store) getSet(key string, value interface{}) (string, error) {
+	old, err := s.get(key)
+	if err != nil && err != cache.ErrKeyNotFound {
+		return "", err
+	}
+	if _, setErr := s.set(key, value, 0); setErr != nil {
+		return "", setErr
+	}
+	return old, err
+}
+
+func (s *store) mget(keys []string) ([]interface{}, error) {
+	vals := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if e := s.lookup(key); e != nil && e.kind == typeString {
+			vals[i] = e.str
+		}
+	}
+	return vals, nil
+}
+
+func (s *store) mset(pairs []interface{}) (string, error) {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return "", errWrongArgsNum
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		key, err := format(pairs[i])
+		if err != nil {
+			return "", err
+		}
+		if _, err := s.set(key, pairs[i+1], 0); err != nil {
+			return "", err
+		}
+	}
+	return "OK", nil
+}
+
+func (s *store) incrBy(key string, delta int64) (int64, error) {
+	e, err := s.lookupKind(key, typeString)
+	if err != nil {
+		return 0, err
+	}
+	var current int64
+	if e != nil {
+		current, err = strconv.ParseInt(e.str, 10, 64)
+		if err != nil {
+			return 0, errNotInteger
+		}
+	} else {
+		e = &entry{kind: typeString}
+		s.data[key] = e
+	}
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, redisError("ERR increment or decrement would overflow")
+	}
+	current += delta
+	e.str = strconv.FormatInt(current, 10)
+	s.touch(key)
+	return current, nil
+}
+
+// 哈希表操作
+
+func (s *store) hget(key, field string) (string, error) {
+	e, err := s.lookupKind(key, typeHash)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", cache.ErrKeyNotFound
+	}
+	val, ok := e.hash[field]
+	if !ok {
+		return "", cache.ErrKeyNotFound
+	}
+	return val, nil
+}
+
+func (s *store) hsetPairs(key string, flat []string) (int64, error) {
+	e, err := s.create(key, typeHash)
+	if err != nil {
+		return 0, err
+	}
+	var added int64
+	for i := 0; i < len(flat); i += 2 {
+		if _, ok := e.hash[flat[i]]; !ok {
+			added++
+		}
+		e.hash[flat[i]] = flat[i+1]
+	}
+	s.touch(key)
+	return added, nil
+}
+
+func (s *store) hset(key, field string, value interface{}) (int64, error) {
+	str, err := format(value)
+	if err != nil {
+		return 0, err
+	}
+	return s.hsetPairs(key, []string{field, str})
+}
+
+func (s *store) hsetNX(key, field string, value interface{}) (bool, error) {
+	e, err := s.lookupKind(key, typeHash)
+	if err != nil {
+		return false, err
+	}
+	if e != nil {
+		if _, ok := e.hash[field]; ok {
+			return false, nil
+		}
+	}
+	if _, err := s.hset(key, field, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *store) hdel(key string, fields []string) (int64, error) {
+	e, err := s.lookupKind(key, typeHash)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	var removed int64
+	for _, field := range fields {
+		if _, ok := e.hash[field]; ok {
+			delete(e.hash, field)
+			removed++
+		}
+	}
+	if removed > 0 {
+		s.removeIfEmpty(key, e)
+		s.touch(key)
+	}
+	return removed, nil
+}
+
+func (s *store) hexists(key, field string) (bool, error) {
+	e, err := s.lookupKind(key, typeHash)
+	if err != nil || e == nil {
+		return false, err
+	}
+	_, ok := e.hash[field]
+	return ok, nil
+}
+
+func (s *store) hgetAll(key string) (map[string]string, error) {
+	e, err := s.lookupKind(key, typeHash)
+	if err != nil {
+		return nil, err
+	}
+	vals := make(map[string]string)
+	if e != nil {
+		for field, val := range e.hash {
+			vals[field] = val
+		}
+	}
+	return vals, nil
+}
+
+func (s *store) hkeys(key string) ([]string, error) {
+	e, err := s.lookupKind(key, typeHash)
+	if err != nil {
+		return nil, err
+	}
+	fields := []string{}
+	if e != nil {
+		for field := range e.hash {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func (s *store) hvals(key string) ([]string, error) {
+	fields, err := s.hkeys(key)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]string, len(fields))
+	if e := s.lookup(key); e != nil {
+		for i, field := range fields {
+			vals[i] = e.hash[field]
+		}
+	}
+	return vals, nil
+}
+
+func (s *store) hlen(key string) (int64, error) {
+	e, err := s.lookupKind(key, typeHash)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	return int64(len(e.hash)), nil
+}
+
+func (s *store) hmget(key string, fields []string) ([]interface{}, error) {
+	e, err := s.lookupKind(key, typeHash)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]interface{}, len(fields))
+	if e != nil {
+		for i, field := range fields {
+			if val, ok := e.hash[field]; ok {
+				vals[i] = val
+			}
+		}
+	}
+	return vals, nil
+}
+
+func (s *store) hmset(key string, pairs []interface{}) (bool, error) {
+	flat, err := flattenPairs(pairs)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.hsetPairs(key, flat); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *store) hincrBy(key, field string, delta int64) (int64, error) {
+	e, err := s.create(key, typeHash)
+	if err != nil {
+		return 0, err
+	}
+	var current int64
+	if val, ok := e.hash[field]; ok {
+		current, err = strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return 0, redisError("ERR hash value is not an integer")
+		}
+	}
+	current += delta
+	e.hash[field] = strconv.FormatInt(current, 10)
+	s.touch(key)
+	return current, nil
+}
+
+// 列表操作
+
+// listRange 将可能为负数的下标转换为[start, end)范围
+func listRange(start, stop int64, n int) (int, int) {
+	if start < 0 {
+		start += int64(n)
+	}
+	if stop < 0 {
+		stop += int64(n)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= int64(n) {
+		stop = int64(n) - 1
+	}
+	if start > stop {
+		return 0, 0
+	}
+	return int(start), int(stop) + 1
+}
+
+func (s *store) push(key string, values []interface{}, left bool) (int64, error) {
+	strs, err := formatAll(values)
+	if err != nil {
+		return 0, err
+	}
+	e, err := s.create(key, typeList)
+	if err != nil {
+		return 0, err
+	}
+	for _, str := range strs {
+		if left {
+			e.list = append([]string{str}, e.list...)
+		} else {
+			e.list = append(e.list, str)
+		}
+	}
+	s.touch(key)
+	return int64(len(e.list)), nil
+}
+
+func (s *store) pop(key string, left bool) (string, error) {
+	e, err := s.lookupKind(key, typeList)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", cache.ErrKeyNotFound
+	}
+	var val string
+	if left {
+		val, e.list = e.list[0], e.list[1:]
+	} else {
+		val, e.list = e.list[len(e.list)-1], e.list[:len(e.list)-1]
+	}
+	s.removeIfEmpty(key, e)
+	s.touch(key)
+	return val, nil
+}
+
+func (s *store) llen(key string) (int64, error) {
+	e, err := s.lookupKind(key, typeList)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	return int64(len(e.list)), nil
+}
+
+func (s *store) lrange(key string, start, stop int64) ([]string, error) {
+	e, err := s.lookupKind(key, typeList)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return []string{}, nil
+	}
+	from, to := listRange(start, stop, len(e.list))
+	return append([]string{}, e.list[from:to]...), nil
+}
+
+func (s *store) lindex(key string, index int64) (string, error) {
+	e, err := s.lookupKind(key, typeList)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", cache.ErrKeyNotFound
+	}
+	if index < 0 {
+		index += int64(len(e.list))
+	}
+	if index < 0 || index >= int64(len(e.list)) {
+		return "", cache.ErrKeyNotFound
+	}
+	return e.list[index], nil
+}
+
+func (s *store) lset(key string, index int64, value interface{}) (string, error) {
+	str, err := format(value)
+	if err != nil {
+		return "", err
+	}
+	e, err := s.lookupKind(key, typeList)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", errNoSuchKey
+	}
+	if index < 0 {
+		index += int64(len(e.list))
+	}
+	if index < 0 || index >= int64(len(e.list)) {
+		return "", errOutOfRange
+	}
+	e.list[index] = str
+	s.touch(key)
+	return "OK", nil
+}
+
+func (s *store) lrem(key string, count int64, value interface{}) (int64, error) {
+	str, err := format(value)
+	if err != nil {
+		return 0, err
+	}
+	e, err := s.lookupKind(key, typeList)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	var removed int64
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	keep := make([]bool, len(e.list))
+	for i := range keep {
+		keep[i] = true
+	}
+	for n := 0; n < len(e.list); n++ {
+		i := n
+		if count < 0 {
+			i = len(e.list) - 1 - n
+		}
+		if e.list[i] == str && (limit == 0 || removed < limit) {
+			keep[i] = false
+			removed++
+		}
+	}
+	list := e.list[:0]
+	for i, val := range e.list {
+		if keep[i] {
+			list = append(list, val)
+		}
+	}
+	e.list = list
+	if removed > 0 {
+		s.removeIfEmpty(key, e)
+		s.touch(key)
+	}
+	return removed, nil
+}
+
+func (s *store) ltrim(key string, start, stop int64) (string, error) {
+	e, err := s.lookupKind(key, typeList)
+	if err != nil {
+		return "", err
+	}
+	if e != nil {
+		from, to := listRange(start, stop, len(e.list))
+		e.list = append([]string{}, e.list[from:to]...)
+		s.removeIfEmpty(key, e)
+		s.touch(key)
+	}
+	return "OK", nil
+}
+
+// 集合操作
+
+func (s *store) sadd(key string, members []interface{}) (int64, error) {
+	strs, err := formatAll(members)
+	if err != nil {
+		return 0, err
+	}
+	e, err := s.create(key, typeSet)
+	if err != nil {
+		return 0, err
+	}
+	var added int64
+	for _, str := range strs {
+		if _, ok := e.set[str]; !ok {
+			e.set[str] = struct{}{}
+			added++
+		}
+	}
+	s.removeIfEmpty(key, e)
+	s.touch(key)
+	return added, nil
+}
+
+func (s *store) srem(key string, members []interface{}) (int64, error) {
+	strs, err := formatAll(members)
+	if err != nil {
+		return 0, err
+	}
+	e, err := s.lookupKind(key, typeSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	var removed int64
+	for _, str := range strs {
+		if _, ok := e.set[str]; ok {
+			delete(e.set, str)
+			removed++
+		}
+	}
+	if removed > 0 {
+		s.removeIfEmpty(key, e)
+		s.touch(key)
+	}
+	return removed, nil
+}
+
+// members 集合的成员，按字典序排列
+func (e *entry) members() []string {
+	members := make([]string, 0, len(e.set))
+	for member := range e.set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func (s *store) smembers(key string) ([]string, error) {
+	e, err := s.lookupKind(key, typeSet)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return []string{}, nil
+	}
+	return e.members(), nil
+}
+
+func (s *store) sismember(key string, member interface{}) (bool, error) {
+	str, err := format(member)
+	if err != nil {
+		return false, err
+	}
+	e, err := s.lookupKind(key, typeSet)
+	if err != nil || e == nil {
+		return false, err
+	}
+	_, ok := e.set[str]
+	return ok, nil
+}
+
+func (s *store) scard(key string) (int64, error) {
+	e, err := s.lookupKind(key, typeSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	return int64(len(e.set)), nil
+}
+
+func (s *store) srandmember(key string) (string, error) {
+	e, err := s.lookupKind(key, typeSet)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", cache.ErrKeyNotFound
+	}
+	members := e.members()
+	return members[rand.Intn(len(members))], nil
+}
+
+func (s *store) spop(key string) (string, error) {
+	member, err := s.srandmember(key)
+	if err != nil {
+		return "", err
+	}
+	e := s.data[key]
+	delete(e.set, member)
+	s.removeIfEmpty(key, e)
+	s.touch(key)
+	return member, nil
+}
+
+// sets 获取多个集合，不存在的键视为空集合
+func (s *store) sets(keys []string) ([]map[string]struct{}, error) {
+	sets := make([]map[string]struct{}, len(keys))
+	for i, key := range keys {
+		e, err := s.lookupKind(key, typeSet)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			sets[i] = e.set
+		}
+	}
+	return sets, nil
+}
+
+// sortedMembers 将成员集合转换为按字典序排列的列表
+func sortedMembers(set map[string]struct{}) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func (s *store) sinter(keys []string) ([]string, error) {
+	sets, err := s.sets(keys)
+	if err != nil || len(sets) == 0 {
+		return []string{}, err
+	}
+	result := make(map[string]struct{})
+	for member := range sets[0] {
+		inAll := true
+		for _, set := range sets[1:] {
+			if _, ok := set[member]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			result[member] = struct{}{}
+		}
+	}
+	return sortedMembers(result), nil
+}
+
+func (s *store) sunion(keys []string) ([]string, error) {
+	sets, err := s.sets(keys)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]struct{})
+	for _, set := range sets {
+		for member := range set {
+			result[member] = struct{}{}
+		}
+	}
+	return sortedMembers(result), nil
+}
+
+func (s *store) sdiff(keys []string) ([]string, error) {
+	sets, err := s.sets(keys)
+	if err != nil || len(sets) == 0 {
+		return []string{}, err
+	}
+	result := make(map[string]struct{})
+	for member := range sets[0] {
+		result[member] = struct{}{}
+	}
+	for _, set := range sets[1:] {
+		for member := range set {
+			delete(result, member)
+		}
+	}
+	return sortedMembers(result), nil
+}
+
+// 有序集合操作
+
+// sorted 按分数和成员排序的有序集合
+func (e *entry) sorted() []cache.ZMember {
+	members := make([]cache.ZMember, 0, len(e.zset))
+	for member, score := range e.zset {
+		members = append(members, cache.ZMember{Score: score, Member: member})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Score != members[j].Score {
+			return members[i].Score < members[j].Score
+		}
+		return members[i].Member.(string) < members[j].Member.(string)
+	})
+	return members
+}
+
+func (s *store) zadd(key string, members []cache.ZMember) (int64, error) {
+	strs := make([]string, len(members))
+	for i, member := range members {
+		str, err := format(member.Member)
+		if err != nil {
+			return 0, err
+		}
+		strs[i] = str
+	}
+	e, err := s.create(key, typeZSet)
+	if err != nil {
+		return 0, err
+	}
+	var added int64
+	for i, str := range strs {
+		if _, ok := e.zset[str]; !ok {
+			added++
+		}
+		e.zset[str] = members[i].Score
+	}
+	s.removeIfEmpty(key, e)
+	s.touch(key)
+	return added, nil
+}
+
+func (s *store) zrem(key string, members []interface{}) (int64, error) {
+	strs, err := formatAll(members)
+	if err != nil {
+		return 0, err
+	}
+	e, err := s.lookupKind(key, typeZSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	var removed int64
+	for _, str := range strs {
+		if _, ok := e.zset[str]; ok {
+			delete(e.zset, str)
+			removed++
+		}
+	}
+	if removed > 0 {
+		s.removeIfEmpty(key, e)
+		s.touch(key)
+	}
+	return removed, nil
+}
+
+func (s *store) zscore(key, member string) (float64, error) {
+	e, err := s.lookupKind(key, typeZSet)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		return 0, redis.Nil
+	}
+	score, ok := e.zset[member]
+	if !ok {
+		return 0, redis.Nil
+	}
+	return score, nil
+}
+
+func (s *store) zrank(key, member string, reverse bool) (int64, error) {
+	e, err := s.lookupKind(key, typeZSet)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		return 0, redis.Nil
+	}
+	members := e.sorted()
+	for i, m := range members {
+		if m.Member == member {
+			if reverse {
+				return int64(len(members) - 1 - i), nil
+			}
+			return int64(i), nil
+		}
+	}
+	return 0, redis.Nil
+}
+
+func (s *store) zrangeWithScores(key string, start, stop int64, reverse bool) ([]cache.ZMember, error) {
+	e, err := s.lookupKind(key, typeZSet)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return []cache.ZMember{}, nil
+	}
+	members := e.sorted()
+	if reverse {
+		for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+			members[i], members[j] = members[j], members[i]
+		}
+	}
+	from, to := listRange(start, stop, len(members))
+	return members[from:to], nil
+}
+
+func (s *store) zrange(key string, start, stop int64, reverse bool) ([]string, error) {
+	members, err := s.zrangeWithScores(key, start, stop, reverse)
+	if err != nil {
+		return nil, err
+	}
+	return memberNames(members), nil
+}
+
+// memberNames 获取有序集合成员的名称
+func memberNames(members []cache.ZMember) []string {
+	names := make([]string, len(members))
+	for i, member := range members {
+		names[i] = member.Member.(string)
+	}
+	return names
+}
+
+// scoreBound 分数范围的一端，支持-inf、+inf和(开头的开区间
+type scoreBound struct {
+	value     float64
+	exclusive bool
+}
+
+func parseScoreBound(s string) (scoreBound, error) {
+	var b scoreBound
+	if strings.HasPrefix(s, "(") {
+		b.exclusive = true
+		s = s[1:]
+	}
+	switch strings.ToLower(s) {
+	case "-inf":
+		b.value = math.Inf(-1)
+	case "+inf", "inf":
+		b.value = math.Inf(1)
+	default:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return b, errMinMax
+		}
+		b.value = v
+	}
+	return b, nil
+}
+
+// inScoreRange 判断分数是否在范围内
+func inScoreRange(score float64, min, max scoreBound) bool {
+	if score < min.value || (min.exclusive && score == min.value) {
+		return false
+	}
+	if score > max.value || (max.exclusive && score == max.value) {
+		return false
+	}
+	return true
+}
+
+func (s *store) zrangeByScore(key, min, max string, reverse bool) ([]string, error) {
+	minBound, err := parseScoreBound(min)
+	if err != nil {
+		return nil, err
+	}
+	maxBound, err := parseScoreBound(max)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.zrangeWithScores(key, 0, -1, reverse)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, member := range members {
+		if inScoreRange(member.Score, minBound, maxBound) {
+			names = append(names, member.Member.(string))
+		}
+	}
+	return names, nil
+}
+
+func (s *store) zcard(key string) (int64, error) {
+	e, err := s.lookupKind(key, typeZSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	return int64(len(e.zset)), nil
+}
+
+func (s *store) zcount(key, min, max string) (int64, error) {
+	members, err := s.zrangeByScore(key, min, max, false)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(members)), nil
+}
+
+func (s *store) zincrBy(key string, increment float64, member string) (float64, error) {
+	e, err := s.create(key, typeZSet)
+	if err != nil {
+		return 0, err
+	}
+	score := e.zset[member] + increment
+	if math.IsNaN(score) {
+		s.removeIfEmpty(key, e)
+		return 0, redisError("ERR resulting score is not a number (NaN)")
+	}
+	e.zset[member] = score
+	s.touch(key)
+	return score, nil
+}
+
+// 通用操作
+
+func (s *store) del(keys []string) (int64, error) {
+	var removed int64
+	for _, key := range keys {
+		if s.lookup(key) != nil {
+			delete(s.data, key)
+			s.touch(key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (s *store) exists(keys []string) (int64, error) {
+	var n int64
+	for _, key := range keys {
+		if s.lookup(key) != nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *store) expireAt(key string, tm time.Time) (bool, error) {
+	e := s.lookup(key)
+	if e == nil {
+		return false, nil
+	}
+	if !s.now().Before(tm) {
+		delete(s.data, key)
+	} else {
+		e.expireAt = tm
+	}
+	s.touch(key)
+	return true, nil
+}
+
+func (s *store) expire(key string, expiration time.Duration) (bool, error) {
+	return s.expireAt(key, s.now().Add(expiration))
+}
+
+// ttl 与go-redis一致：键不存在时返回-2，没有过期时间时返回-1，否则按秒取整
+func (s *store) ttl(key string) (time.Duration, error) {
+	e := s.lookup(key)
+	if e == nil {
+		return -2, nil
+	}
+	if e.expireAt.IsZero() {
+		return -1, nil
+	}
+	remaining := e.expireAt.Sub(s.now())
+	return (remaining + 500*time.Millisecond) / time.Second * time.Second, nil
+}
+
+func (s *store) typ(key string) (string, error) {
+	e := s.lookup(key)
+	if e == nil {
+		return "none", nil
+	}
+	return e.kind, nil
+}
+
+// sortedKeys 所有未过期的键，按字典序排列
+func (s *store) sortedKeys() []string {
+	keys := make([]string, 0, len(s.data))
+	for key := range s.data {
+		if s.lookup(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// trimPrefix 移除键前缀
+func trimPrefix(keys []string, prefix string) []string {
+	if prefix != "" {
+		for i, key := range keys {
+			if len(key) > len(prefix) {
+				keys[i] = key[len(prefix):]
+			}
+		}
+	}
+	return keys
+}
+
+func (s *store) keys(pattern, prefix string) ([]string, error) {
+	keys := []string{}
+	for _, key := range s.sortedKeys() {
+		if matchPattern(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	return trimPrefix(keys, prefix), nil
+}
+
+// scan 按字典序遍历键，游标对应下一个要检查的键，遍历期间删除键不会使其他键被跳过
+func (s *store) scan(cursor uint64, match string, count int64, prefix string) ([]string, uint64, error) {
+	if count <= 0 {
+		count = 10
+	}
+	all := s.sortedKeys()
+	start := 0
+	if next, ok := s.cursors[cursor]; ok && cursor != 0 {
+		start = sort.SearchStrings(all, next)
+	}
+
+	keys := []string{}
+	i := start
+	for ; i < len(all) && i < start+int(count); i++ {
+		if match == "" || matchPattern(match, all[i]) {
+			keys = append(keys, all[i])
+		}
+	}
+	if i >= len(all) {
+		return trimPrefix(keys, prefix), 0, nil
+	}
+
+	if len(s.cursors) >= maxScanCursors {
+		s.cursors = make(map[uint64]string)
+	}
+	s.nextCursor++
+	s.cursors[s.nextCursor] = all[i]
+	return trimPrefix(keys, prefix), s.nextCursor, nil
+}
+
+// matchPattern Redis的glob匹配，支持*、?、[...]和\转义
+func matchPattern(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchPattern(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				// 没有闭合的[按普通字符处理
+				if s[0] != '[' {
+					return false
+				}
+				s = s[1:]
+				pattern = pattern[1:]
+				continue
+			}
+			class := pattern[1 : end+1]
+			pattern = pattern[end+2:]
+			negate := strings.HasPrefix(class, "^")
+			if negate {
+				class = class[1:]
+			}
+			matched := false
+			for i := 0; i < len(class); i++ {
+				if i+2 < len(class) && class[i+1] == '-' {
+					if class[i] <= s[0] && s[0] <= class[i+2] {
+						matched = true
+					}
+					i += 2
+				} else if class[i] == s[0] {
+					matched = true
+				}
+			}
+			if matched == negate {
+				return false
+			}
+			s = s[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		}
+	}
+	return len(s) == 0
+}
@@ -0,0 +1,135 @@
+package cachetest
+
+import (
+	"context"
+	"time"
+
+	"cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Watch 监视键并执行乐观事务，被监视的键被修改时按配置的WatchRetries重试
+// 重试后仍然失败时返回包装了ErrTxFailed的RetryError，与cache的客户端一致
+func (f *Fake) Watch(ctx context.Context, fn func(tx cache.Tx) error, keys ...string) error {
+	retries := f.config.Common.WatchRetries
+	switch {
+	case retries < 0:
+		retries = 0
+	case retries == 0:
+		retries = 10
+	}
+
+	keys = f.keys(ctx, keys)
+	for attempt := 0; ; attempt++ {
+		tx := &fakeTx{f: f, watched: make(map[string]uint64, len(keys))}
+		if err := tx.watch(keys); err != nil {
+			return err
+		}
+
+		err := fn(tx)
+		if err != cache.ErrTxFailed {
+			return err
+		}
+		if attempt >= retries {
+			if attempt == 0 {
+				return err
+			}
+			return &cache.RetryError{Cmd: "watch", Attempts: attempt + 1, Err: err}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// fakeTx 内存乐观事务
+type fakeTx struct {
+	f       *Fake
+	watched map[string]uint64
+}
+
+// 确保实现了cache.Tx接口
+var _ cache.Tx = (*fakeTx)(nil)
+
+// watch 记录被监视键的当前版本
+func (t *fakeTx) watch(keys []string) error {
+	_, err := call(t.f, func(s *store) (struct{}, error) {
+		for _, key := range keys {
+			s.lookup(key)
+			t.watched[key] = s.versions[key]
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// Get 获取字符串值
+func (t *fakeTx) Get(ctx context.Context, key string) (string, error) {
+	return t.f.Get(ctx, key)
+}
+
+// MGet 批量获取字符串值
+func (t *fakeTx) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return t.f.MGet(ctx, keys...)
+}
+
+// HGet 获取哈希表字段值
+func (t *fakeTx) HGet(ctx context.Context, key, field string) (string, error) {
+	return t.f.HGet(ctx, key, field)
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (t *fakeTx) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return t.f.HGetAll(ctx, key)
+}
+
+// Exists 检查键是否存在
+func (t *fakeTx) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return t.f.Exists(ctx, keys...)
+}
+
+// TTL 获取键的剩余生存时间
+func (t *fakeTx) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return t.f.TTL(ctx, key)
+}
+
+// LRange 获取列表指定范围的元素
+func (t *fakeTx) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return t.f.LRange(ctx, key, start, stop)
+}
+
+// SMembers 获取集合所有成员
+func (t *fakeTx) SMembers(ctx context.Context, key string) ([]string, error) {
+	return t.f.SMembers(ctx, key)
+}
+
+// ZScore 获取有序集合成员的分数，成员不存在时返回ErrKeyNotFound
+func (t *fakeTx) ZScore(ctx context.Context, key, member string) (float64, error) {
+	score, err := t.f.ZScore(ctx, key, member)
+	if err == redis.Nil {
+		return 0, cache.ErrKeyNotFound
+	}
+	return score, err
+}
+
+// TxPipelined 执行fn排队的写命令，被监视的键已被修改时不执行并返回ErrTxFailed
+func (t *fakeTx) TxPipelined(ctx context.Context, fn func(pipe cache.Pipeliner) error) error {
+	pipe := &pipeline{f: t.f, watched: t.watched}
+	if err := fn(pipe); err != nil {
+		return err
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Unwatch 取消监视
+func (t *fakeTx) Unwatch(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		t.watched = map[string]uint64{}
+		return nil
+	}
+	for _, key := range t.f.keys(ctx, keys) {
+		delete(t.watched, key)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package cache
 import (
 	"context"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // Client Redis客户端统一接口
@@ -11,6 +13,8 @@ type Client interface {
 	// 基础操作
 	Close() error
 	Ping(ctx context.Context) error
+	Ready(ctx context.Context) error
+	Stats() *PoolStats
 
 	// 字符串操作
 	Get(ctx context.Context, key string) (string, error)
@@ -87,6 +91,7 @@ type Client interface {
 	Type(ctx context.Context, key string) (string, error)
 	Keys(ctx context.Context, pattern string) ([]string, error)
 	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+	DeleteByPattern(ctx context.Context, pattern string, opts *DeleteOptions) (int64, error)
 
 	// Lua脚本操作
 	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
@@ -96,12 +101,26 @@ type Client interface {
 	ScriptKill(ctx context.Context) error
 	ScriptLoad(ctx context.Context, script string) (string, error)
 
+	// Redis Functions操作（Redis 7.0+）
+	FunctionLoad(ctx context.Context, code string, replace bool) (string, error)
+	FunctionDelete(ctx context.Context, library string) error
+	FunctionList(ctx context.Context, pattern string, withCode bool) ([]FunctionLibrary, error)
+	FunctionDump(ctx context.Context) (string, error)
+	FunctionRestore(ctx context.Context, payload string) error
+	FCall(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error)
+	FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error)
+
 	// 管道操作
 	Pipeline() Pipeliner
 	TxPipeline() Pipeliner
+
+	// 乐观事务
+	Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error
 }
 
 // Pipeliner 管道操作接口
+// 与Client的数据命令一一对应，命令在Exec之后通过返回的命令对象读取结果；
+// 脚本与函数的管理命令（ScriptLoad、FunctionLoad等）不支持在管道中执行
 type Pipeliner interface {
 	// 执行管道中的所有命令
 	Exec(ctx context.Context) ([]interface{}, error)
@@ -114,34 +133,85 @@ type Pipeliner interface {
 	Get(ctx context.Context, key string) *StringCmd
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd
 	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd
+	GetSet(ctx context.Context, key string, value interface{}) *StringCmd
+	MGet(ctx context.Context, keys ...string) *SliceCmd
+	MSet(ctx context.Context, pairs ...interface{}) *StatusCmd
 	Incr(ctx context.Context, key string) *IntCmd
+	IncrBy(ctx context.Context, key string, value int64) *IntCmd
 	Decr(ctx context.Context, key string) *IntCmd
+	DecrBy(ctx context.Context, key string, value int64) *IntCmd
 
 	// 哈希表操作
 	HGet(ctx context.Context, key, field string) *StringCmd
 	HSet(ctx context.Context, key, field string, value interface{}) *IntCmd
+	HSetNX(ctx context.Context, key, field string, value interface{}) *BoolCmd
 	HDel(ctx context.Context, key string, fields ...string) *IntCmd
+	HExists(ctx context.Context, key, field string) *BoolCmd
+	HGetAll(ctx context.Context, key string) *MapStringStringCmd
+	HKeys(ctx context.Context, key string) *StringSliceCmd
+	HVals(ctx context.Context, key string) *StringSliceCmd
+	HLen(ctx context.Context, key string) *IntCmd
+	HMGet(ctx context.Context, key string, fields ...string) *SliceCmd
+	HMSet(ctx context.Context, key string, pairs ...interface{}) *BoolCmd
+	HIncrBy(ctx context.Context, key, field string, incr int64) *IntCmd
 
 	// 列表操作
 	LPush(ctx context.Context, key string, values ...interface{}) *IntCmd
 	RPush(ctx context.Context, key string, values ...interface{}) *IntCmd
 	LPop(ctx context.Context, key string) *StringCmd
 	RPop(ctx context.Context, key string) *StringCmd
+	LLen(ctx context.Context, key string) *IntCmd
+	LRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd
+	LIndex(ctx context.Context, key string, index int64) *StringCmd
+	LSet(ctx context.Context, key string, index int64, value interface{}) *StatusCmd
+	LRem(ctx context.Context, key string, count int64, value interface{}) *IntCmd
+	LTrim(ctx context.Context, key string, start, stop int64) *StatusCmd
 
 	// 集合操作
 	SAdd(ctx context.Context, key string, members ...interface{}) *IntCmd
 	SRem(ctx context.Context, key string, members ...interface{}) *IntCmd
 	SMembers(ctx context.Context, key string) *StringSliceCmd
+	SIsMember(ctx context.Context, key string, member interface{}) *BoolCmd
+	SCard(ctx context.Context, key string) *IntCmd
+	SPop(ctx context.Context, key string) *StringCmd
+	SRandMember(ctx context.Context, key string) *StringCmd
+	SInter(ctx context.Context, keys ...string) *StringSliceCmd
+	SUnion(ctx context.Context, keys ...string) *StringSliceCmd
+	SDiff(ctx context.Context, keys ...string) *StringSliceCmd
 
 	// 有序集合操作
 	ZAdd(ctx context.Context, key string, members ...ZMember) *IntCmd
 	ZRem(ctx context.Context, key string, members ...interface{}) *IntCmd
+	ZScore(ctx context.Context, key, member string) *FloatCmd
+	ZRank(ctx context.Context, key, member string) *IntCmd
+	ZRevRank(ctx context.Context, key, member string) *IntCmd
 	ZRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd
+	ZRevRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd
+	ZRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd
+	ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd
+	ZRangeByScore(ctx context.Context, key string, min, max string) *StringSliceCmd
+	ZRevRangeByScore(ctx context.Context, key string, max, min string) *StringSliceCmd
+	ZCard(ctx context.Context, key string) *IntCmd
+	ZCount(ctx context.Context, key, min, max string) *IntCmd
+	ZIncrBy(ctx context.Context, key string, increment float64, member string) *FloatCmd
 
 	// 通用操作
 	Del(ctx context.Context, keys ...string) *IntCmd
 	Exists(ctx context.Context, keys ...string) *IntCmd
 	Expire(ctx context.Context, key string, expiration time.Duration) *BoolCmd
+	ExpireAt(ctx context.Context, key string, tm time.Time) *BoolCmd
+	TTL(ctx context.Context, key string) *DurationCmd
+	Type(ctx context.Context, key string) *StatusCmd
+	Keys(ctx context.Context, pattern string) *StringSliceCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd
+
+	// Lua脚本操作
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *Cmd
+	EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *Cmd
+
+	// Redis Functions操作
+	FCall(ctx context.Context, function string, keys []string, args ...interface{}) *Cmd
+	FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) *Cmd
 }
 
 // ZMember 有序集合成员
@@ -151,87 +221,416 @@ type ZMember struct {
 }
 
 // 命令结果类型
+// 管道中的命令在Exec之后才有结果，结果在调用Result、Val、Err时读取
+
+// StringCmd 字符串结果
 type StringCmd struct {
-	val string
-	err error
+	result func() (string, error)
 }
 
 func (cmd *StringCmd) Result() (string, error) {
-	return cmd.val, cmd.err
+	return cmd.result()
 }
 
 func (cmd *StringCmd) Val() string {
-	return cmd.val
+	val, _ := cmd.result()
+	return val
 }
 
 func (cmd *StringCmd) Err() error {
-	return cmd.err
+	_, err := cmd.result()
+	return err
 }
 
+// StatusCmd 状态回复结果
 type StatusCmd struct {
-	val string
-	err error
+	result func() (string, error)
 }
 
 func (cmd *StatusCmd) Result() (string, error) {
-	return cmd.val, cmd.err
+	return cmd.result()
 }
 
 func (cmd *StatusCmd) Val() string {
-	return cmd.val
+	val, _ := cmd.result()
+	return val
 }
 
 func (cmd *StatusCmd) Err() error {
-	return cmd.err
+	_, err := cmd.result()
+	return err
 }
 
+// IntCmd 整数结果
 type IntCmd struct {
-	val int64
-	err error
+	result func() (int64, error)
 }
 
 func (cmd *IntCmd) Result() (int64, error) {
-	return cmd.val, cmd.err
+	return cmd.result()
 }
 
 func (cmd *IntCmd) Val() int64 {
-	return cmd.val
+	val, _ := cmd.result()
+	return val
 }
 
 func (cmd *IntCmd) Err() error {
-	return cmd.err
+	_, err := cmd.result()
+	return err
 }
 
+// BoolCmd 布尔结果
 type BoolCmd struct {
-	val bool
-	err error
+	result func() (bool, error)
 }
 
 func (cmd *BoolCmd) Result() (bool, error) {
-	return cmd.val, cmd.err
+	return cmd.result()
 }
 
 func (cmd *BoolCmd) Val() bool {
-	return cmd.val
+	val, _ := cmd.result()
+	return val
 }
 
 func (cmd *BoolCmd) Err() error {
-	return cmd.err
+	_, err := cmd.result()
+	return err
+}
+
+// FloatCmd 浮点数结果
+type FloatCmd struct {
+	result func() (float64, error)
+}
+
+func (cmd *FloatCmd) Result() (float64, error) {
+	return cmd.result()
+}
+
+func (cmd *FloatCmd) Val() float64 {
+	val, _ := cmd.result()
+	return val
+}
+
+func (cmd *FloatCmd) Err() error {
+	_, err := cmd.result()
+	return err
 }
 
+// StringSliceCmd 字符串列表结果
 type StringSliceCmd struct {
-	val []string
-	err error
+	result func() ([]string, error)
 }
 
 func (cmd *StringSliceCmd) Result() ([]string, error) {
-	return cmd.val, cmd.err
+	return cmd.result()
 }
 
 func (cmd *StringSliceCmd) Val() []string {
-	return cmd.val
+	val, _ := cmd.result()
+	return val
 }
 
 func (cmd *StringSliceCmd) Err() error {
-	return cmd.err
-}
\ No newline at end of file
+	_, err := cmd.result()
+	return err
+}
+
+// SliceCmd 任意类型列表结果
+type SliceCmd struct {
+	result func() ([]interface{}, error)
+}
+
+func (cmd *SliceCmd) Result() ([]interface{}, error) {
+	return cmd.result()
+}
+
+func (cmd *SliceCmd) Val() []interface{} {
+	val, _ := cmd.result()
+	return val
+}
+
+func (cmd *SliceCmd) Err() error {
+	_, err := cmd.result()
+	return err
+}
+
+// MapStringStringCmd 哈希表结果
+type MapStringStringCmd struct {
+	result func() (map[string]string, error)
+}
+
+func (cmd *MapStringStringCmd) Result() (map[string]string, error) {
+	return cmd.result()
+}
+
+func (cmd *MapStringStringCmd) Val() map[string]string {
+	val, _ := cmd.result()
+	return val
+}
+
+func (cmd *MapStringStringCmd) Err() error {
+	_, err := cmd.result()
+	return err
+}
+
+// DurationCmd 时长结果
+type DurationCmd struct {
+	result func() (time.Duration, error)
+}
+
+func (cmd *DurationCmd) Result() (time.Duration, error) {
+	return cmd.result()
+}
+
+func (cmd *DurationCmd) Val() time.Duration {
+	val, _ := cmd.result()
+	return val
+}
+
+func (cmd *DurationCmd) Err() error {
+	_, err := cmd.result()
+	return err
+}
+
+// ZSliceCmd 有序集合成员结果
+type ZSliceCmd struct {
+	result func() ([]ZMember, error)
+}
+
+func (cmd *ZSliceCmd) Result() ([]ZMember, error) {
+	return cmd.result()
+}
+
+func (cmd *ZSliceCmd) Val() []ZMember {
+	val, _ := cmd.result()
+	return val
+}
+
+func (cmd *ZSliceCmd) Err() error {
+	_, err := cmd.result()
+	return err
+}
+
+// Cmd 任意类型结果
+type Cmd struct {
+	result func() (interface{}, error)
+}
+
+func (cmd *Cmd) Result() (interface{}, error) {
+	return cmd.result()
+}
+
+func (cmd *Cmd) Val() interface{} {
+	val, _ := cmd.result()
+	return val
+}
+
+func (cmd *Cmd) Err() error {
+	_, err := cmd.result()
+	return err
+}
+
+// ScanCmd SCAN结果
+type ScanCmd struct {
+	result func() ([]string, uint64, error)
+}
+
+func (cmd *ScanCmd) Result() ([]string, uint64, error) {
+	return cmd.result()
+}
+
+func (cmd *ScanCmd) Val() ([]string, uint64) {
+	keys, cursor, _ := cmd.result()
+	return keys, cursor
+}
+
+func (cmd *ScanCmd) Err() error {
+	_, _, err := cmd.result()
+	return err
+}
+
+// 命令结果构造函数，供自定义的Pipeliner实现（如测试替身）使用
+// result在每次读取结果时调用，管道执行前后可以返回不同的值
+
+// NewStringCmd 创建StringCmd
+func NewStringCmd(result func() (string, error)) *StringCmd {
+	return &StringCmd{result: result}
+}
+
+// NewStatusCmd 创建StatusCmd
+func NewStatusCmd(result func() (string, error)) *StatusCmd {
+	return &StatusCmd{result: result}
+}
+
+// NewIntCmd 创建IntCmd
+func NewIntCmd(result func() (int64, error)) *IntCmd {
+	return &IntCmd{result: result}
+}
+
+// NewBoolCmd 创建BoolCmd
+func NewBoolCmd(result func() (bool, error)) *BoolCmd {
+	return &BoolCmd{result: result}
+}
+
+// NewFloatCmd 创建FloatCmd
+func NewFloatCmd(result func() (float64, error)) *FloatCmd {
+	return &FloatCmd{result: result}
+}
+
+// NewStringSliceCmd 创建StringSliceCmd
+func NewStringSliceCmd(result func() ([]string, error)) *StringSliceCmd {
+	return &StringSliceCmd{result: result}
+}
+
+// NewSliceCmd 创建SliceCmd
+func NewSliceCmd(result func() ([]interface{}, error)) *SliceCmd {
+	return &SliceCmd{result: result}
+}
+
+// NewMapStringStringCmd 创建MapStringStringCmd
+func NewMapStringStringCmd(result func() (map[string]string, error)) *MapStringStringCmd {
+	return &MapStringStringCmd{result: result}
+}
+
+// NewDurationCmd 创建DurationCmd
+func NewDurationCmd(result func() (time.Duration, error)) *DurationCmd {
+	return &DurationCmd{result: result}
+}
+
+// NewZSliceCmd 创建ZSliceCmd
+func NewZSliceCmd(result func() ([]ZMember, error)) *ZSliceCmd {
+	return &ZSliceCmd{result: result}
+}
+
+// NewCmd 创建Cmd
+func NewCmd(result func() (interface{}, error)) *Cmd {
+	return &Cmd{result: result}
+}
+
+// NewScanCmd 创建ScanCmd
+func NewScanCmd(result func() ([]string, uint64, error)) *ScanCmd {
+	return &ScanCmd{result: result}
+}
+
+// wrapStringCmd 将go-redis的StringCmd包装为StringCmd，键不存在时返回ErrKeyNotFound
+func wrapStringCmd(cmd *redis.StringCmd) *StringCmd {
+	return &StringCmd{result: func() (string, error) {
+		if cmd.Err() == redis.Nil {
+			return "", ErrKeyNotFound
+		}
+		return cmd.Result()
+	}}
+}
+
+// wrapStatusCmd 将go-redis的StatusCmd包装为StatusCmd
+func wrapStatusCmd(cmd *redis.StatusCmd) *StatusCmd {
+	return &StatusCmd{result: cmd.Result}
+}
+
+// wrapIntCmd 将go-redis的IntCmd包装为IntCmd
+func wrapIntCmd(cmd *redis.IntCmd) *IntCmd {
+	return &IntCmd{result: cmd.Result}
+}
+
+// wrapBoolCmd 将go-redis的BoolCmd包装为BoolCmd
+func wrapBoolCmd(cmd *redis.BoolCmd) *BoolCmd {
+	return &BoolCmd{result: cmd.Result}
+}
+
+// wrapFloatCmd 将go-redis的FloatCmd包装为FloatCmd
+func wrapFloatCmd(cmd *redis.FloatCmd) *FloatCmd {
+	return &FloatCmd{result: cmd.Result}
+}
+
+// wrapStringSliceCmd 将go-redis的StringSliceCmd包装为StringSliceCmd
+func wrapStringSliceCmd(cmd *redis.StringSliceCmd) *StringSliceCmd {
+	return &StringSliceCmd{result: cmd.Result}
+}
+
+// wrapSliceCmd 将go-redis的SliceCmd包装为SliceCmd
+func wrapSliceCmd(cmd *redis.SliceCmd) *SliceCmd {
+	return &SliceCmd{result: cmd.Result}
+}
+
+// wrapMapStringStringCmd 将go-redis的MapStringStringCmd包装为MapStringStringCmd
+func wrapMapStringStringCmd(cmd *redis.MapStringStringCmd) *MapStringStringCmd {
+	return &MapStringStringCmd{result: cmd.Result}
+}
+
+// wrapDurationCmd 将go-redis的DurationCmd包装为DurationCmd
+func wrapDurationCmd(cmd *redis.DurationCmd) *DurationCmd {
+	return &DurationCmd{result: cmd.Result}
+}
+
+// wrapZSliceCmd 将go-redis的ZSliceCmd包装为ZSliceCmd，成员转换为ZMember
+func wrapZSliceCmd(cmd *redis.ZSliceCmd) *ZSliceCmd {
+	return &ZSliceCmd{result: func() ([]ZMember, error) {
+		vals, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		members := make([]ZMember, len(vals))
+		for i, val := range vals {
+			members[i] = ZMember{
+				Score:  val.Score,
+				Member: val.Member,
+			}
+		}
+		return members, nil
+	}}
+}
+
+// wrapCmd 将go-redis的Cmd包装为Cmd
+func wrapCmd(cmd *redis.Cmd) *Cmd {
+	return &Cmd{result: cmd.Result}
+}
+
+// wrapEvalShaCmd 包装EVALSHA的结果，脚本不在服务端缓存中时返回ErrScriptNotFound
+func wrapEvalShaCmd(cmd *redis.Cmd) *Cmd {
+	return &Cmd{result: func() (interface{}, error) {
+		if redis.HasErrorPrefix(cmd.Err(), "NOSCRIPT") {
+			return nil, ErrScriptNotFound
+		}
+		return cmd.Result()
+	}}
+}
+
+// wrapKeysCmd 包装KEYS的结果，读取结果时移除键前缀
+func wrapKeysCmd(cmd *redis.StringSliceCmd, prefix string) *StringSliceCmd {
+	return &StringSliceCmd{result: func() ([]string, error) {
+		keys, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		return trimKeyPrefix(keys, prefix), nil
+	}}
+}
+
+// wrapScanCmd 包装SCAN的结果，读取结果时移除键前缀
+func wrapScanCmd(cmd *redis.ScanCmd, prefix string) *ScanCmd {
+	return &ScanCmd{result: func() ([]string, uint64, error) {
+		keys, cursor, err := cmd.Result()
+		if err != nil {
+			return nil, cursor, err
+		}
+		return trimKeyPrefix(keys, prefix), cursor, nil
+	}}
+}
+
+// trimKeyPrefix 移除键前缀，结果写入新的切片，go-redis缓存的结果保持不变，可以重复读取
+func trimKeyPrefix(keys []string, prefix string) []string {
+	if prefix == "" {
+		return keys
+	}
+	prefixLen := len(prefix)
+	trimmed := make([]string, len(keys))
+	for i, key := range keys {
+		if len(key) > prefixLen {
+			key = key[prefixLen:]
+		}
+		trimmed[i] = key
+	}
+	return trimmed
+}
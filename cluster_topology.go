@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ClusterSlotCount 集群哈希槽数量
+const ClusterSlotCount = 16384
+
+// 集群节点角色
+const (
+	// RoleMaster 主节点
+	RoleMaster = "master"
+	// RoleReplica 从节点
+	RoleReplica = "replica"
+)
+
+// ClusterNode 集群节点信息
+type ClusterNode struct {
+	// 节点ID
+	ID string
+	// 节点地址
+	Addr string
+	// 节点角色：master或replica
+	Role string
+}
+
+// SlotRange 哈希槽范围，包含Start和End
+type SlotRange struct {
+	Start int
+	End   int
+}
+
+// ClusterShard 集群分片，一个主节点及其从节点负责的槽位
+type ClusterShard struct {
+	// 分片负责的槽位范围
+	Slots []SlotRange
+	// 主节点
+	Master ClusterNode
+	// 从节点
+	Replicas []ClusterNode
+}
+
+// KeySlot 计算键所在的哈希槽，键包含{hashtag}时只计算hashtag部分
+// 传入的键需要包含KeyPrefix
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % ClusterSlotCount)
+}
+
+// crc16 集群使用的CRC16-CCITT（XMODEM）校验
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// buildShards 根据CLUSTER SLOTS结果按主节点聚合分片
+func buildShards(slots []redis.ClusterSlot) []ClusterShard {
+	shards := make([]ClusterShard, 0)
+	index := make(map[string]int)
+
+	for _, slot := range slots {
+		if len(slot.Nodes) == 0 {
+			continue
+		}
+
+		master := slot.Nodes[0]
+		i, ok := index[master.Addr]
+		if !ok {
+			shard := ClusterShard{
+				Master: ClusterNode{ID: master.ID, Addr: master.Addr, Role: RoleMaster},
+			}
+			for _, replica := range slot.Nodes[1:] {
+				shard.Replicas = append(shard.Replicas, ClusterNode{ID: replica.ID, Addr: replica.Addr, Role: RoleReplica})
+			}
+			shards = append(shards, shard)
+			i = len(shards) - 1
+			index[master.Addr] = i
+		}
+		shards[i].Slots = append(shards[i].Slots, SlotRange{Start: slot.Start, End: slot.End})
+	}
+
+	for i := range shards {
+		sort.Slice(shards[i].Slots, func(a, b int) bool {
+			return shards[i].Slots[a].Start < shards[i].Slots[b].Start
+		})
+		sort.Slice(shards[i].Replicas, func(a, b int) bool {
+			return shards[i].Replicas[a].Addr < shards[i].Replicas[b].Addr
+		})
+	}
+	sort.Slice(shards, func(a, b int) bool {
+		return shards[a].Master.Addr < shards[b].Master.Addr
+	})
+	return shards
+}
+
+// sameTopology 判断两次拓扑是否相同
+func sameTopology(a, b []ClusterShard) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Master != b[i].Master || len(a[i].Slots) != len(b[i].Slots) || len(a[i].Replicas) != len(b[i].Replicas) {
+			return false
+		}
+		for j := range a[i].Slots {
+			if a[i].Slots[j] != b[i].Slots[j] {
+				return false
+			}
+		}
+		for j := range a[i].Replicas {
+			if a[i].Replicas[j] != b[i].Replicas[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// clusterTopology 缓存集群拓扑，并定期检查拓扑变化
+type clusterTopology struct {
+	client   *redis.ClusterClient
+	interval time.Duration
+	logger   Logger
+
+	mu       sync.Mutex
+	shards   []ClusterShard
+	handlers map[uint64]func(old, new []ClusterShard)
+	nextID   uint64
+	started  bool
+	closed   bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// load 查询集群拓扑并更新缓存，返回更新前的拓扑
+func (t *clusterTopology) load(ctx context.Context) (old, shards []ClusterShard, err error) {
+	slots, err := t.client.ClusterSlots(ctx).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	shards = buildShards(slots)
+
+	t.mu.Lock()
+	old = t.shards
+	t.shards = shards
+	t.mu.Unlock()
+	return old, shards, nil
+}
+
+// cached 获取缓存的拓扑，未加载过时查询
+func (t *clusterTopology) cached(ctx context.Context) ([]ClusterShard, error) {
+	t.mu.Lock()
+	shards := t.shards
+	t.mu.Unlock()
+	if shards != nil {
+		return shards, nil
+	}
+
+	_, shards, err := t.load(ctx)
+	return shards, err
+}
+
+// subscribe 注册拓扑变化回调，首次注册时开始定期检查
+func (t *clusterTopology) subscribe(fn func(old, new []ClusterShard)) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return func() {}
+	}
+	if t.handlers == nil {
+		t.handlers = make(map[uint64]func(old, new []ClusterShard))
+	}
+	id := t.nextID
+	t.nextID++
+	t.handlers[id] = fn
+
+	if !t.started {
+		t.started = true
+		ctx, cancel := context.WithCancel(context.Background())
+		t.cancel = cancel
+		t.done = make(chan struct{})
+		go t.run(ctx)
+	}
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.handlers, id)
+	}
+}
+
+// run 定期检查拓扑变化
+func (t *clusterTopology) run(ctx context.Context) {
+	defer close(t.done)
+
+	// 记录初始拓扑作为比较基准
+	_, last, err := t.load(ctx)
+	if err != nil && ctx.Err() == nil {
+		t.logger.Printf(ctx, "cache: cluster topology check failed: %v", err)
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, shards, err := t.load(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				t.logger.Printf(ctx, "cache: cluster topology check failed: %v", err)
+			}
+			continue
+		}
+		old := last
+		last = shards
+		if old == nil || sameTopology(old, shards) {
+			continue
+		}
+
+		// 拓扑变化后刷新go-redis的槽位路由
+		t.client.ReloadState(ctx)
+
+		t.mu.Lock()
+		handlers := make([]func(old, new []ClusterShard), 0, len(t.handlers))
+		for _, fn := range t.handlers {
+			handlers = append(handlers, fn)
+		}
+		t.mu.Unlock()
+
+		for _, fn := range handlers {
+			fn(old, shards)
+		}
+	}
+}
+
+// close 停止检查
+func (t *clusterTopology) close() {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	started := t.started
+	t.handlers = nil
+	t.mu.Unlock()
+
+	if started {
+		t.cancel()
+		<-t.done
+	}
+}
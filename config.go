@@ -34,6 +34,18 @@ const (
 	ModeSentinel Mode = "sentinel"
 )
 
+// StartupMode 客户端创建时的连接检查方式
+type StartupMode string
+
+const (
+	// StartupEager 创建时Ping一次，失败则创建失败
+	StartupEager StartupMode = "eager"
+	// StartupLazy 创建时不检查连接，首次执行命令时建立连接
+	StartupLazy StartupMode = "lazy"
+	// StartupWait 创建时按退避间隔重复Ping，直到成功或超过StartupTimeout
+	StartupWait StartupMode = "wait"
+)
+
 // SingleConfig 单机模式配置
 type SingleConfig struct {
 	// Redis服务器地址
@@ -53,6 +65,8 @@ type ClusterConfig struct {
 	// 路由模式
 	RouteByLatency bool `json:"route_by_latency" yaml:"route_by_latency"`
 	RouteRandomly  bool `json:"route_randomly" yaml:"route_randomly"`
+	// 拓扑变化检查间隔，注册拓扑变化回调后生效，默认10秒
+	TopologyCheckInterval time.Duration `json:"topology_check_interval" yaml:"topology_check_interval"`
 }
 
 // SentinelConfig 哨兵模式配置
@@ -65,6 +79,22 @@ type SentinelConfig struct {
 	DB int `json:"db" yaml:"db"`
 	// 哨兵密码
 	SentinelPassword string `json:"sentinel_password,omitempty" yaml:"sentinel_password,omitempty"`
+
+	// 从节点读路由：开启以下任一项后只读命令默认路由到从节点，写命令仍发往主节点
+	// 注意：从节点异步复制，读到的数据可能落后于主节点，不适合读自己刚写入的数据
+	// Keys和Scan需要在同一个节点上迭代，不受读路由影响，始终在主节点执行
+
+	// 只读命令只路由到从节点（没有可用从节点时使用主节点）
+	ReplicaOnly bool `json:"replica_only" yaml:"replica_only"`
+	// 只读命令路由到延迟最低的主节点或从节点
+	RouteByLatency bool `json:"route_by_latency" yaml:"route_by_latency"`
+	// 只读命令随机路由到主节点或从节点
+	RouteRandomly bool `json:"route_randomly" yaml:"route_randomly"`
+}
+
+// routesReplicas 判断是否开启了从节点读路由
+func (c *SentinelConfig) routesReplicas() bool {
+	return c.ReplicaOnly || c.RouteByLatency || c.RouteRandomly
 }
 
 // CommonConfig 通用配置
@@ -88,13 +118,25 @@ type CommonConfig struct {
 	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"` // 写入超时时间
 
 	// 重试配置
-	MaxRetries      int           `json:"max_retries" yaml:"max_retries"`           // 最大重试次数
-	MinRetryBackoff time.Duration `json:"min_retry_backoff" yaml:"min_retry_backoff"` // 最小重试间隔
-	MaxRetryBackoff time.Duration `json:"max_retry_backoff" yaml:"max_retry_backoff"` // 最大重试间隔
+	MaxRetries      int           `json:"max_retries" yaml:"max_retries"`                           // 最大重试次数
+	MinRetryBackoff time.Duration `json:"min_retry_backoff" yaml:"min_retry_backoff"`               // 最小重试间隔
+	MaxRetryBackoff time.Duration `json:"max_retry_backoff" yaml:"max_retry_backoff"`               // 最大重试间隔
+	RetryStrategy   RetryStrategy `json:"retry_strategy,omitempty" yaml:"retry_strategy,omitempty"` // 重试策略，默认带抖动的指数退避
+
+	// 乐观事务配置
+	WatchRetries int           `json:"watch_retries" yaml:"watch_retries"` // 被监视的键被修改后的最大重试次数，0使用默认的10次，-1不重试
+	WatchBackoff time.Duration `json:"watch_backoff" yaml:"watch_backoff"` // 乐观事务重试的最小退避时间，最大退避时间为MaxRetryBackoff
+
+	// 启动配置
+	StartupMode    StartupMode   `json:"startup_mode,omitempty" yaml:"startup_mode,omitempty"` // 连接检查方式，默认eager
+	StartupTimeout time.Duration `json:"startup_timeout" yaml:"startup_timeout"`               // 连接检查的总超时时间，默认5秒
 
 	// 键前缀配置
 	KeyPrefix string `json:"key_prefix,omitempty" yaml:"key_prefix,omitempty"`
 
+	// 禁用Keys，开启后Keys返回ErrKeysDisabled，避免在生产环境阻塞Redis；按模式删除使用DeleteByPattern
+	DisableKeys bool `json:"disable_keys,omitempty" yaml:"disable_keys,omitempty"`
+
 	// 默认TTL配置
 	DefaultTTL time.Duration `json:"default_ttl" yaml:"default_ttl"`
 
@@ -104,6 +146,33 @@ type CommonConfig struct {
 	// 监控配置
 	EnableMetrics bool `json:"enable_metrics" yaml:"enable_metrics"`
 	EnableTracing bool `json:"enable_tracing" yaml:"enable_tracing"`
+
+	// 降级配置：开启后读操作遇到不可用错误视为未命中，写操作仅记录日志
+	FallbackOnUnavailable bool `json:"fallback_on_unavailable" yaml:"fallback_on_unavailable"`
+
+	// 熔断器配置
+	CircuitBreaker *CircuitBreakerConfig `json:"circuit_breaker,omitempty" yaml:"circuit_breaker,omitempty"`
+}
+
+// CircuitBreakerConfig 熔断器配置
+// 单机和哨兵模式下每个客户端一个熔断器，集群模式下每个节点一个熔断器
+type CircuitBreakerConfig struct {
+	// 启用熔断器
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// 统计窗口长度，窗口结束后计数清零
+	Window time.Duration `json:"window" yaml:"window"`
+	// 窗口内触发熔断所需的最少请求数
+	MinRequests int `json:"min_requests" yaml:"min_requests"`
+	// 错误率阈值（0-1）
+	FailureRateThreshold float64 `json:"failure_rate_threshold" yaml:"failure_rate_threshold"`
+	// 慢调用耗时阈值，为0时不统计慢调用
+	SlowCallDuration time.Duration `json:"slow_call_duration" yaml:"slow_call_duration"`
+	// 慢调用率阈值（0-1）
+	SlowCallRateThreshold float64 `json:"slow_call_rate_threshold" yaml:"slow_call_rate_threshold"`
+	// 熔断打开后进入半开状态的等待时间
+	OpenTimeout time.Duration `json:"open_timeout" yaml:"open_timeout"`
+	// 半开状态允许通过的探测请求数
+	HalfOpenMaxRequests int `json:"half_open_max_requests" yaml:"half_open_max_requests"`
 }
 
 // TLSConfig TLS配置
@@ -143,7 +212,11 @@ func DefaultConfig() *Config {
 			MaxRetries:      3,
 			MinRetryBackoff: time.Millisecond * 8,
 			MaxRetryBackoff: time.Millisecond * 512,
+			WatchRetries:    10,
+			WatchBackoff:    time.Millisecond * 2,
 			DefaultTTL:      time.Hour * 24,
+			StartupMode:     StartupEager,
+			StartupTimeout:  time.Second * 5,
 			EnableMetrics:   false,
 			EnableTracing:   false,
 		},
@@ -185,6 +258,61 @@ func (c *Config) Validate() error {
 		return ErrInvalidMode
 	}
 
+	if err := c.Common.validate(); err != nil {
+		return err
+	}
+
+	if cb := c.Common.CircuitBreaker; cb != nil && cb.Enabled {
+		if err := cb.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate 验证连接池和超时配置，数值为0时使用go-redis的默认值
+func (c *CommonConfig) validate() error {
+	if c.PoolSize < 0 || c.MinIdleConns < 0 || c.MaxIdleConns < 0 {
+		return ErrInvalidPoolSize
+	}
+	if c.PoolSize > 0 && c.MinIdleConns > c.PoolSize {
+		return ErrMinIdleExceedsPoolSize
+	}
+	if c.MaxIdleConns > 0 && c.MaxIdleConns < c.MinIdleConns {
+		return ErrMaxIdleBelowMinIdle
+	}
+
+	switch c.StartupMode {
+	case "", StartupEager, StartupLazy, StartupWait:
+	default:
+		return ErrInvalidStartupMode
+	}
+
+	timeouts := []time.Duration{
+		c.ConnMaxAge, c.PoolTimeout, c.IdleTimeout,
+		c.DialTimeout, c.ReadTimeout, c.WriteTimeout,
+		c.MinRetryBackoff, c.MaxRetryBackoff, c.WatchBackoff, c.DefaultTTL,
+		c.StartupTimeout,
+	}
+	for _, timeout := range timeouts {
+		if timeout < 0 {
+			return ErrNegativeTimeout
+		}
+	}
+	return nil
+}
+
+// validate 验证熔断器配置
+func (c *CircuitBreakerConfig) validate() error {
+	if c.FailureRateThreshold < 0 || c.FailureRateThreshold > 1 ||
+		c.SlowCallRateThreshold < 0 || c.SlowCallRateThreshold > 1 {
+		return ErrInvalidCircuitBreaker
+	}
+	if c.Window < 0 || c.SlowCallDuration < 0 || c.OpenTimeout < 0 ||
+		c.MinRequests < 0 || c.HalfOpenMaxRequests < 0 {
+		return ErrInvalidCircuitBreaker
+	}
 	return nil
 }
 
@@ -196,6 +324,11 @@ func (c *Config) GetKeyWithPrefix(key string) string {
 	return c.Common.KeyPrefix + key
 }
 
+// GetKeyWithNamespace 获取命名空间指定代数下带前缀的键名，与WithNamespace调用时实际使用的键相同
+func (c *Config) GetKeyWithNamespace(namespace string, generation int64, key string) string {
+	return c.GetKeyWithPrefix(namespacePrefix(namespace, generation) + key)
+}
+
 // GetTTL 获取TTL，如果指定了TTL则使用指定值，否则使用默认值
 func (c *Config) GetTTL(ttl time.Duration) time.Duration {
 	if ttl > 0 {
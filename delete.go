@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DeleteOptions 按模式删除的配置
+type DeleteOptions struct {
+	// 每次SCAN的COUNT参数，默认500
+	BatchSize int64
+	// 每秒最多删除的键数，集群模式下为所有主节点的合计，为0时不限制
+	RateLimit int
+	// 只统计匹配的键，不删除；SCAN可能返回重复的键，统计结果为近似值
+	DryRun bool
+}
+
+// patternDelete 一次按模式删除的执行状态，集群模式下被多个主节点并发使用
+type patternDelete struct {
+	batchSize int64
+	dryRun    bool
+	rate      int
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newPatternDelete 按配置创建删除状态
+func newPatternDelete(opts *DeleteOptions) *patternDelete {
+	d := &patternDelete{batchSize: 500}
+	if opts != nil {
+		if opts.BatchSize > 0 {
+			d.batchSize = opts.BatchSize
+		}
+		d.dryRun = opts.DryRun
+		d.rate = opts.RateLimit
+	}
+	return d
+}
+
+// run 在一个节点上用SCAN迭代匹配的键并逐批UNLINK，返回删除的键数，DryRun时返回匹配的键数
+func (d *patternDelete) run(ctx context.Context, node *redis.Client, match string) (int64, error) {
+	var total int64
+	var cursor uint64
+	for {
+		keys, next, err := node.Scan(ctx, cursor, match, d.batchSize).Result()
+		if err != nil {
+			return total, err
+		}
+		if len(keys) > 0 {
+			if d.dryRun {
+				total += int64(len(keys))
+			} else {
+				if err := d.wait(ctx, len(keys)); err != nil {
+					return total, err
+				}
+				n, err := unlinkKeys(ctx, node, keys)
+				total += n
+				if err != nil {
+					return total, err
+				}
+			}
+		}
+		if next == 0 {
+			return total, nil
+		}
+		cursor = next
+	}
+}
+
+// wait 按RateLimit等待删除n个键的配额
+func (d *patternDelete) wait(ctx context.Context, n int) error {
+	if d.rate <= 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	now := time.Now()
+	if d.next.Before(now) {
+		d.next = now
+	}
+	at := d.next
+	d.next = d.next.Add(time.Duration(n) * time.Second / time.Duration(d.rate))
+	d.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// unlinkKeys 在一个管道中逐个UNLINK键，集群模式下同一节点的键可以在不同槽位
+func unlinkKeys(ctx context.Context, node *redis.Client, keys []string) (int64, error) {
+	cmds, err := node.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Unlink(ctx, key)
+		}
+		return nil
+	})
+	var n int64
+	for _, cmd := range cmds {
+		if cmd, ok := cmd.(*redis.IntCmd); ok {
+			n += cmd.Val()
+		}
+	}
+	return n, err
+}
+
+// keysDisabledCmd 禁用Keys时管道中Keys返回的命令结果
+func keysDisabledCmd() *StringSliceCmd {
+	return &StringSliceCmd{result: func() ([]string, error) {
+		return nil, ErrKeysDisabled
+	}}
+}
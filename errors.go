@@ -1,6 +1,13 @@
 package cache
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+)
 
 // 配置相关错误
 var (
@@ -18,6 +25,18 @@ var (
 	ErrMissingAddrs = errors.New("missing server addresses")
 	// ErrMissingMasterName 缺少主节点名称
 	ErrMissingMasterName = errors.New("missing master name")
+	// ErrInvalidCircuitBreaker 无效的熔断器配置
+	ErrInvalidCircuitBreaker = errors.New("invalid circuit breaker config")
+	// ErrInvalidPoolSize 连接池大小或空闲连接数为负数
+	ErrInvalidPoolSize = errors.New("invalid pool size: must not be negative")
+	// ErrMinIdleExceedsPoolSize 最小空闲连接数大于连接池大小
+	ErrMinIdleExceedsPoolSize = errors.New("invalid pool config: min idle conns exceeds pool size")
+	// ErrMaxIdleBelowMinIdle 最大空闲连接数小于最小空闲连接数
+	ErrMaxIdleBelowMinIdle = errors.New("invalid pool config: max idle conns is less than min idle conns")
+	// ErrNegativeTimeout 超时时间为负数
+	ErrNegativeTimeout = errors.New("invalid timeout: must not be negative")
+	// ErrInvalidStartupMode 无效的启动模式
+	ErrInvalidStartupMode = errors.New("invalid startup mode")
 )
 
 // 客户端操作相关错误
@@ -32,6 +51,8 @@ var (
 	ErrInvalidType = errors.New("redis: invalid data type")
 	// ErrScriptNotFound Lua脚本不存在
 	ErrScriptNotFound = errors.New("redis: script not found")
+	// ErrKeysDisabled 配置禁用了Keys
+	ErrKeysDisabled = errors.New("redis: KEYS is disabled by config")
 )
 
 // 连接相关错误
@@ -66,12 +87,20 @@ var (
 	ErrNoSentinelAvailable = errors.New("redis: no sentinel available")
 )
 
+// 熔断相关错误
+var (
+	// ErrCircuitOpen 熔断器处于打开状态，请求被快速拒绝
+	ErrCircuitOpen = errors.New("redis: circuit breaker is open")
+)
+
 // 管道相关错误
 var (
 	// ErrPipelineEmpty 管道为空
 	ErrPipelineEmpty = errors.New("redis: pipeline is empty")
 	// ErrPipelineClosed 管道已关闭
 	ErrPipelineClosed = errors.New("redis: pipeline is closed")
+	// ErrTxFailed 乐观事务执行期间被监视的键被修改
+	ErrTxFailed = errors.New("redis: transaction failed: watched keys changed")
 )
 
 // IsRedisError 判断是否为Redis相关错误
@@ -84,12 +113,15 @@ func IsRedisError(err error) bool {
 	redisErrors := []error{
 		ErrInvalidMode, ErrMissingSingleConfig, ErrMissingClusterConfig,
 		ErrMissingSentinelConfig, ErrMissingAddr, ErrMissingAddrs,
-		ErrMissingMasterName, ErrClientClosed, ErrNilResult,
+		ErrMissingMasterName, ErrInvalidCircuitBreaker, ErrInvalidPoolSize,
+		ErrMinIdleExceedsPoolSize, ErrMaxIdleBelowMinIdle, ErrNegativeTimeout, ErrInvalidStartupMode,
+		ErrClientClosed, ErrNilResult,
 		ErrKeyNotFound, ErrInvalidType, ErrScriptNotFound,
 		ErrConnectionFailed, ErrConnectionTimeout, ErrPoolExhausted,
 		ErrAuthFailed, ErrClusterDown, ErrNoReachableNode,
 		ErrTooManyRedirects, ErrSentinelNoMaster, ErrSentinelMasterDown,
-		ErrNoSentinelAvailable, ErrPipelineEmpty, ErrPipelineClosed,
+		ErrNoSentinelAvailable, ErrCircuitOpen, ErrPipelineEmpty, ErrPipelineClosed,
+		ErrTxFailed,
 	}
 
 	for _, redisErr := range redisErrors {
@@ -157,4 +189,36 @@ func IsSentinelError(err error) bool {
 	}
 
 	return false
-}
\ No newline at end of file
+}
+
+// IsUnavailableError 判断错误是否表示Redis暂时不可用
+// 包括连接类错误、超时、熔断、连接池耗尽以及集群和哨兵不可用，不包括键不存在和普通的命令错误
+func IsUnavailableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if IsConnectionError(err) || IsClusterError(err) || IsSentinelError(err) {
+		return true
+	}
+
+	unavailableErrors := []error{
+		ErrCircuitOpen, ErrClientClosed, redis.ErrClosed, redis.ErrPoolTimeout,
+		redis.ErrPoolExhausted, io.EOF, io.ErrUnexpectedEOF, context.DeadlineExceeded,
+	}
+
+	for _, unavailableErr := range unavailableErrors {
+		if errors.Is(err, unavailableErr) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return redis.HasErrorPrefix(err, "LOADING") ||
+		redis.HasErrorPrefix(err, "CLUSTERDOWN") ||
+		redis.HasErrorPrefix(err, "MASTERDOWN")
+}
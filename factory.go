@@ -11,7 +11,11 @@ import (
 
 // Factory Redis客户端工厂
 type Factory struct {
-	config *Config
+	config      *Config
+	metrics     *Metrics
+	logger      Logger
+	retryPolicy RetryPolicy
+	recorder    *Recorder
 }
 
 // NewFactory 创建新的工厂实例
@@ -26,21 +30,43 @@ func NewFactory(config *Config) (*Factory, error) {
 
 	return &Factory{
 		config: config,
+		logger: defaultLogger,
 	}, nil
 }
 
 // CreateClient 根据配置创建Redis客户端
 func (f *Factory) CreateClient() (Client, error) {
+	var (
+		client Client
+		err    error
+	)
+
 	switch f.config.Mode {
 	case ModeSingle:
-		return f.createSingleClient()
+		client, err = f.createSingleClient()
 	case ModeCluster:
-		return f.createClusterClient()
+		client, err = f.createClusterClient()
 	case ModeSentinel:
-		return f.createSentinelClient()
+		client, err = f.createSentinelClient()
 	default:
 		return nil, fmt.Errorf("unsupported mode: %s", f.config.Mode)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 录制在降级之前，记录Redis返回的原始错误
+	if f.recorder != nil {
+		client = NewRecordingClient(client, f.recorder)
+	}
+	if f.config.Common.FallbackOnUnavailable {
+		opts := &FallbackOptions{Logger: f.logger}
+		if f.metrics.enabled(f.config) {
+			opts.OnFallback = f.metrics.OnFallback
+		}
+		client = NewFallbackClient(client, opts)
+	}
+	return client, nil
 }
 
 // createSingleClient 创建单机模式客户端
@@ -52,20 +78,22 @@ func (f *Factory) createSingleClient() (Client, error) {
 		Password: f.config.Common.Password,
 
 		// 连接池配置
-		PoolSize:     f.config.Common.PoolSize,
-		MinIdleConns: f.config.Common.MinIdleConns,
-		MaxIdleConns: f.config.Common.MaxIdleConns,
-		PoolTimeout:  f.config.Common.PoolTimeout,
+		PoolSize:        f.config.Common.PoolSize,
+		MinIdleConns:    f.config.Common.MinIdleConns,
+		MaxIdleConns:    f.config.Common.MaxIdleConns,
+		PoolTimeout:     f.config.Common.PoolTimeout,
+		ConnMaxLifetime: f.config.Common.ConnMaxAge,
+		ConnMaxIdleTime: f.config.Common.IdleTimeout,
 
 		// 网络配置
 		DialTimeout:  f.config.Common.DialTimeout,
 		ReadTimeout:  f.config.Common.ReadTimeout,
 		WriteTimeout: f.config.Common.WriteTimeout,
+		// 读写超时不晚于ctx的截止时间，WithTimeout作用于网络读写
+		ContextTimeoutEnabled: true,
 
-		// 重试配置
-		MaxRetries:      f.config.Common.MaxRetries,
-		MinRetryBackoff: f.config.Common.MinRetryBackoff,
-		MaxRetryBackoff: f.config.Common.MaxRetryBackoff,
+		// 重试由retryHook按重试策略处理
+		MaxRetries: -1,
 	}
 
 	// 配置TLS
@@ -78,12 +106,13 @@ func (f *Factory) createSingleClient() (Client, error) {
 	}
 
 	rdb := redis.NewClient(opts)
+	f.addCommandHooks(rdb)
+	f.addNodeHooks(rdb, "single:"+opts.Addr)
+	// 最后安装，紧贴连接处理管道回复；集群模式逐条读取回复，不需要
+	rdb.AddHook(pipelineReplyHook{})
 
-	// 测试连接
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := rdb.Ping(ctx).Err(); err != nil {
+	// 按启动模式检查连接
+	if err := f.checkStartup(rdb); err != nil {
 		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
@@ -108,20 +137,22 @@ func (f *Factory) createClusterClient() (Client, error) {
 		RouteRandomly:  f.config.Cluster.RouteRandomly,
 
 		// 连接池配置
-		PoolSize:     f.config.Common.PoolSize,
-		MinIdleConns: f.config.Common.MinIdleConns,
-		MaxIdleConns: f.config.Common.MaxIdleConns,
-		PoolTimeout:  f.config.Common.PoolTimeout,
+		PoolSize:        f.config.Common.PoolSize,
+		MinIdleConns:    f.config.Common.MinIdleConns,
+		MaxIdleConns:    f.config.Common.MaxIdleConns,
+		PoolTimeout:     f.config.Common.PoolTimeout,
+		ConnMaxLifetime: f.config.Common.ConnMaxAge,
+		ConnMaxIdleTime: f.config.Common.IdleTimeout,
 
 		// 网络配置
 		DialTimeout:  f.config.Common.DialTimeout,
 		ReadTimeout:  f.config.Common.ReadTimeout,
 		WriteTimeout: f.config.Common.WriteTimeout,
+		// 读写超时不晚于ctx的截止时间，WithTimeout作用于网络读写
+		ContextTimeoutEnabled: true,
 
-		// 重试配置
-		MaxRetries:      f.config.Common.MaxRetries,
-		MinRetryBackoff: f.config.Common.MinRetryBackoff,
-		MaxRetryBackoff: f.config.Common.MaxRetryBackoff,
+		// 重试由retryHook按重试策略处理
+		MaxRetries: -1,
 	}
 
 	// 配置TLS
@@ -138,20 +169,47 @@ func (f *Factory) createClusterClient() (Client, error) {
 		opts.MaxRedirects = 3
 	}
 
-	rdb := redis.NewClusterClient(opts)
+	// 集群模式下熔断器按节点生效
+	opts.NewClient = func(opt *redis.Options) *redis.Client {
+		node := redis.NewClient(opt)
+		f.addNodeHooks(node, opt.Addr)
+		return node
+	}
 
-	// 测试连接
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	rdb := redis.NewClusterClient(opts)
+	f.addCommandHooks(rdb)
 
-	if err := rdb.Ping(ctx).Err(); err != nil {
+	// 按启动模式检查连接
+	if err := f.checkStartup(rdb); err != nil {
 		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to redis cluster: %w", err)
 	}
 
+	interval := f.config.Cluster.TopologyCheckInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
 	return &ClusterClient{
 		client: rdb,
 		config: f.config,
+		topology: &clusterTopology{
+			client:   rdb,
+			interval: interval,
+			logger:   f.logger,
+		},
+		router: &readRouter{
+			main:            rdb,
+			mainReplicas:    opts.ReadOnly,
+			defaultReplicas: opts.ReadOnly,
+			newAlt: func() redis.UniversalClient {
+				altOpts := *opts
+				altOpts.ReadOnly = !opts.ReadOnly
+				alt := redis.NewClusterClient(&altOpts)
+				f.addCommandHooks(alt)
+				return alt
+			},
+		},
 	}, nil
 }
 
@@ -166,20 +224,22 @@ func (f *Factory) createSentinelClient() (Client, error) {
 		Password:         f.config.Common.Password,
 
 		// 连接池配置
-		PoolSize:     f.config.Common.PoolSize,
-		MinIdleConns: f.config.Common.MinIdleConns,
-		MaxIdleConns: f.config.Common.MaxIdleConns,
-		PoolTimeout:  f.config.Common.PoolTimeout,
+		PoolSize:        f.config.Common.PoolSize,
+		MinIdleConns:    f.config.Common.MinIdleConns,
+		MaxIdleConns:    f.config.Common.MaxIdleConns,
+		PoolTimeout:     f.config.Common.PoolTimeout,
+		ConnMaxLifetime: f.config.Common.ConnMaxAge,
+		ConnMaxIdleTime: f.config.Common.IdleTimeout,
 
 		// 网络配置
 		DialTimeout:  f.config.Common.DialTimeout,
 		ReadTimeout:  f.config.Common.ReadTimeout,
 		WriteTimeout: f.config.Common.WriteTimeout,
+		// 读写超时不晚于ctx的截止时间，WithTimeout作用于网络读写
+		ContextTimeoutEnabled: true,
 
-		// 重试配置
-		MaxRetries:      f.config.Common.MaxRetries,
-		MinRetryBackoff: f.config.Common.MinRetryBackoff,
-		MaxRetryBackoff: f.config.Common.MaxRetryBackoff,
+		// 重试由retryHook按重试策略处理
+		MaxRetries: -1,
 	}
 
 	// 配置TLS
@@ -192,12 +252,12 @@ func (f *Factory) createSentinelClient() (Client, error) {
 	}
 
 	rdb := redis.NewFailoverClient(opts)
+	f.addCommandHooks(rdb)
+	f.addNodeHooks(rdb, "sentinel:"+opts.MasterName)
+	rdb.AddHook(pipelineReplyHook{})
 
-	// 测试连接
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := rdb.Ping(ctx).Err(); err != nil {
+	// 按启动模式检查连接
+	if err := f.checkStartup(rdb); err != nil {
 		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to redis sentinel: %w", err)
 	}
@@ -205,9 +265,125 @@ func (f *Factory) createSentinelClient() (Client, error) {
 	return &SentinelClient{
 		client: rdb,
 		config: f.config,
+		router: &readRouter{
+			main:            rdb,
+			defaultReplicas: f.config.Sentinel.routesReplicas(),
+			newAlt: func() redis.UniversalClient {
+				return f.createSentinelReplicaClient(opts)
+			},
+		},
+		watcher: &sentinelWatcher{
+			addrs:      opts.SentinelAddrs,
+			masterName: opts.MasterName,
+			logger:     f.logger,
+			newClient: func(addr string) *redis.SentinelClient {
+				return redis.NewSentinelClient(&redis.Options{
+					Addr:        addr,
+					Password:    opts.SentinelPassword,
+					DialTimeout: opts.DialTimeout,
+					TLSConfig:   opts.TLSConfig,
+				})
+			},
+		},
 	}, nil
 }
 
+// createSentinelReplicaClient 创建哨兵模式下只读命令使用的客户端
+// 开启从节点读路由时使用go-redis的故障转移集群客户端，按ReplicaOnly、RouteByLatency、RouteRandomly路由只读命令，
+// 否则使用只连接从节点的故障转移客户端
+func (f *Factory) createSentinelReplicaClient(opts *redis.FailoverOptions) redis.UniversalClient {
+	replicaOpts := *opts
+	sentinel := f.config.Sentinel
+
+	if !sentinel.routesReplicas() {
+		replicaOpts.ReplicaOnly = true
+		rdb := redis.NewFailoverClient(&replicaOpts)
+		f.addCommandHooks(rdb)
+		f.addNodeHooks(rdb, "sentinel-replica:"+opts.MasterName)
+		rdb.AddHook(pipelineReplyHook{})
+		return rdb
+	}
+
+	replicaOpts.ReplicaOnly = sentinel.ReplicaOnly
+	replicaOpts.RouteByLatency = sentinel.RouteByLatency
+	replicaOpts.RouteRandomly = sentinel.RouteRandomly
+	rdb := redis.NewFailoverClusterClient(&replicaOpts)
+	f.addCommandHooks(rdb)
+	rdb.OnNewNode(func(node *redis.Client) {
+		f.addNodeHooks(node, node.Options().Addr)
+	})
+	return rdb
+}
+
+// checkStartup 按启动模式检查连接
+func (f *Factory) checkStartup(rdb redis.UniversalClient) error {
+	timeout := f.config.Common.StartupTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	switch f.config.Common.StartupMode {
+	case StartupLazy:
+		return nil
+	case StartupWait:
+		return f.waitStartup(ctx, rdb)
+	default:
+		return rdb.Ping(ctx).Err()
+	}
+}
+
+// waitStartup 按退避间隔重复Ping，直到成功或ctx超时
+func (f *Factory) waitStartup(ctx context.Context, rdb redis.UniversalClient) error {
+	backoff := 100 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := rdb.Ping(WithNoRetry(ctx)).Err()
+		if err == nil {
+			return nil
+		}
+		f.logger.Printf(ctx, "cache: redis not ready (attempt %d): %v", attempt, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if backoff < 2*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// hookable 支持安装钩子的go-redis客户端
+type hookable interface {
+	AddHook(hook redis.Hook)
+}
+
+// addCommandHooks 安装作用于整个客户端的钩子，集群模式下包含重定向
+// 需在addNodeHooks之前调用，保证调用超时覆盖重试，且每次重试都经过熔断器
+func (f *Factory) addCommandHooks(rdb hookable) {
+	policy := f.retryPolicy
+	if policy == nil {
+		policy = NewRetryPolicy(&f.config.Common)
+	}
+	rdb.AddHook(optionsHook{})
+	rdb.AddHook(retryHook{policy: policy})
+}
+
+// addNodeHooks 安装作用于单个节点连接的钩子
+func (f *Factory) addNodeHooks(rdb *redis.Client, name string) {
+	if cb := f.config.Common.CircuitBreaker; cb != nil && cb.Enabled {
+		breaker := NewCircuitBreaker(name, *cb)
+		if f.metrics.enabled(f.config) && f.metrics.OnBreakerStateChange != nil {
+			breaker.OnStateChange(f.metrics.OnBreakerStateChange)
+		}
+		rdb.AddHook(breakerHook{breaker: breaker})
+	}
+}
+
 // buildTLSConfig 构建TLS配置
 func (f *Factory) buildTLSConfig(tlsConfig *TLSConfig) (*tls.Config, error) {
 	config := &tls.Config{
@@ -233,6 +409,31 @@ func (f *Factory) buildTLSConfig(tlsConfig *TLSConfig) (*tls.Config, error) {
 	return config, nil
 }
 
+// SetMetrics 设置指标钩子，对之后创建的客户端生效
+func (f *Factory) SetMetrics(metrics *Metrics) {
+	f.metrics = metrics
+}
+
+// SetRetryPolicy 设置自定义重试策略，对之后创建的客户端生效
+// 未设置时根据CommonConfig的重试配置创建内置策略
+func (f *Factory) SetRetryPolicy(policy RetryPolicy) {
+	f.retryPolicy = policy
+}
+
+// SetRecorder 设置调用录制器，对之后创建的客户端生效，为nil时不录制
+// 客户端被包装为RecordingClient，按录制器的采样率记录调用
+func (f *Factory) SetRecorder(recorder *Recorder) {
+	f.recorder = recorder
+}
+
+// SetLogger 设置日志实现，对之后创建的客户端生效
+func (f *Factory) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = defaultLogger
+	}
+	f.logger = logger
+}
+
 // GetConfig 获取配置
 func (f *Factory) GetConfig() *Config {
 	return f.config
@@ -0,0 +1,318 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// FallbackOptions 降级客户端配置
+type FallbackOptions struct {
+	// 日志实现，为空时使用标准库log
+	Logger Logger
+	// 操作被降级时的回调
+	OnFallback func(op string, err error)
+}
+
+// FallbackStats 降级统计
+type FallbackStats struct {
+	// 被视为未命中的读操作次数
+	Reads int64
+	// 被忽略失败的写操作次数
+	Writes int64
+}
+
+// FallbackClient 降级客户端
+// 缓存不是数据源时，Redis不可用不应导致请求失败：
+// Get、HGetAll、MGet遇到不可用错误时按未命中处理，回填和失效缓存的写操作失败只记录日志并返回零值。
+// 返回值参与调用方判断的写操作不降级，仍然返回错误：Incr、IncrBy、Decr、DecrBy、HIncrBy、ZIncrBy的计数，
+// SetNX、HSetNX的加锁结果，GetSet、LPop、RPop、SPop取出的值，降级后零值会被当作合法结果使用
+type FallbackClient struct {
+	Client
+
+	logger     Logger
+	onFallback func(op string, err error)
+
+	reads  atomic.Int64
+	writes atomic.Int64
+}
+
+// NewFallbackClient 创建降级客户端
+func NewFallbackClient(client Client, opts *FallbackOptions) *FallbackClient {
+	c := &FallbackClient{
+		Client: client,
+		logger: defaultLogger,
+	}
+	if opts != nil {
+		if opts.Logger != nil {
+			c.logger = opts.Logger
+		}
+		c.onFallback = opts.OnFallback
+	}
+	return c
+}
+
+// Unwrap 获取被包装的客户端
+func (c *FallbackClient) Unwrap() Client {
+	return c.Client
+}
+
+// unwrapClient 去除降级客户端、自动批量客户端等包装，获取实际的客户端
+func unwrapClient(client Client) Client {
+	for {
+		wrapper, ok := client.(interface{ Unwrap() Client })
+		if !ok {
+			return client
+		}
+		client = wrapper.Unwrap()
+	}
+}
+
+// FallbackStats 获取降级统计
+func (c *FallbackClient) FallbackStats() FallbackStats {
+	return FallbackStats{
+		Reads:  c.reads.Load(),
+		Writes: c.writes.Load(),
+	}
+}
+
+// Get 获取字符串值，Redis不可用时返回ErrKeyNotFound
+func (c *FallbackClient) Get(ctx context.Context, key string) (string, error) {
+	val, err := c.Client.Get(ctx, key)
+	if c.degradeRead(ctx, "Get", err) {
+		return "", ErrKeyNotFound
+	}
+	return val, err
+}
+
+// HGetAll 获取哈希表所有字段和值，Redis不可用时返回空结果
+func (c *FallbackClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	val, err := c.Client.HGetAll(ctx, key)
+	if c.degradeRead(ctx, "HGetAll", err) {
+		return map[string]string{}, nil
+	}
+	return val, err
+}
+
+// MGet 批量获取多个键的值，Redis不可用时每个键都按未命中返回nil
+func (c *FallbackClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	val, err := c.Client.MGet(ctx, keys...)
+	if c.degradeRead(ctx, "MGet", err) {
+		return make([]interface{}, len(keys)), nil
+	}
+	return val, err
+}
+
+// Set 设置字符串值，Redis不可用时忽略失败
+func (c *FallbackClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	err := c.Client.Set(ctx, key, value, expiration)
+	if c.degradeWrite(ctx, "Set", err) {
+		return nil
+	}
+	return err
+}
+
+// MSet 批量设置多个键值对，Redis不可用时忽略失败
+func (c *FallbackClient) MSet(ctx context.Context, pairs ...interface{}) error {
+	err := c.Client.MSet(ctx, pairs...)
+	if c.degradeWrite(ctx, "MSet", err) {
+		return nil
+	}
+	return err
+}
+
+// HSet 设置哈希表字段值，Redis不可用时忽略失败
+func (c *FallbackClient) HSet(ctx context.Context, key, field string, value interface{}) error {
+	err := c.Client.HSet(ctx, key, field, value)
+	if c.degradeWrite(ctx, "HSet", err) {
+		return nil
+	}
+	return err
+}
+
+// HMSet 批量设置哈希表字段值，Redis不可用时忽略失败
+func (c *FallbackClient) HMSet(ctx context.Context, key string, pairs ...interface{}) error {
+	err := c.Client.HMSet(ctx, key, pairs...)
+	if c.degradeWrite(ctx, "HMSet", err) {
+		return nil
+	}
+	return err
+}
+
+// Del 删除键，Redis不可用时忽略失败
+func (c *FallbackClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	n, err := c.Client.Del(ctx, keys...)
+	if c.degradeWrite(ctx, "Del", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Expire 设置键的过期时间，Redis不可用时忽略失败
+func (c *FallbackClient) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	ok, err := c.Client.Expire(ctx, key, expiration)
+	if c.degradeWrite(ctx, "Expire", err) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// ExpireAt 设置键的过期时间点，Redis不可用时忽略失败
+func (c *FallbackClient) ExpireAt(ctx context.Context, key string, tm time.Time) (bool, error) {
+	ok, err := c.Client.ExpireAt(ctx, key, tm)
+	if c.degradeWrite(ctx, "ExpireAt", err) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// DeleteByPattern 按模式删除键，Redis不可用时忽略失败
+func (c *FallbackClient) DeleteByPattern(ctx context.Context, pattern string, opts *DeleteOptions) (int64, error) {
+	n, err := c.Client.DeleteByPattern(ctx, pattern, opts)
+	if c.degradeWrite(ctx, "DeleteByPattern", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// HDel 删除哈希表字段，Redis不可用时忽略失败
+func (c *FallbackClient) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	n, err := c.Client.HDel(ctx, key, fields...)
+	if c.degradeWrite(ctx, "HDel", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// LPush 从列表左侧插入元素，Redis不可用时忽略失败
+func (c *FallbackClient) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	n, err := c.Client.LPush(ctx, key, values...)
+	if c.degradeWrite(ctx, "LPush", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// RPush 从列表右侧插入元素，Redis不可用时忽略失败
+func (c *FallbackClient) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	n, err := c.Client.RPush(ctx, key, values...)
+	if c.degradeWrite(ctx, "RPush", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// LSet 设置列表指定位置的元素，Redis不可用时忽略失败
+func (c *FallbackClient) LSet(ctx context.Context, key string, index int64, value interface{}) error {
+	err := c.Client.LSet(ctx, key, index, value)
+	if c.degradeWrite(ctx, "LSet", err) {
+		return nil
+	}
+	return err
+}
+
+// LRem 删除列表中的元素，Redis不可用时忽略失败
+func (c *FallbackClient) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
+	n, err := c.Client.LRem(ctx, key, count, value)
+	if c.degradeWrite(ctx, "LRem", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// LTrim 裁剪列表，Redis不可用时忽略失败
+func (c *FallbackClient) LTrim(ctx context.Context, key string, start, stop int64) error {
+	err := c.Client.LTrim(ctx, key, start, stop)
+	if c.degradeWrite(ctx, "LTrim", err) {
+		return nil
+	}
+	return err
+}
+
+// SAdd 向集合添加成员，Redis不可用时忽略失败
+func (c *FallbackClient) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	n, err := c.Client.SAdd(ctx, key, members...)
+	if c.degradeWrite(ctx, "SAdd", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// SRem 从集合删除成员，Redis不可用时忽略失败
+func (c *FallbackClient) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	n, err := c.Client.SRem(ctx, key, members...)
+	if c.degradeWrite(ctx, "SRem", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// ZAdd 向有序集合添加成员，Redis不可用时忽略失败
+func (c *FallbackClient) ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) {
+	n, err := c.Client.ZAdd(ctx, key, members...)
+	if c.degradeWrite(ctx, "ZAdd", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// ZRem 从有序集合删除成员，Redis不可用时忽略失败
+func (c *FallbackClient) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	n, err := c.Client.ZRem(ctx, key, members...)
+	if c.degradeWrite(ctx, "ZRem", err) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// degradeRead 判断读操作是否降级为未命中
+func (c *FallbackClient) degradeRead(ctx context.Context, op string, err error) bool {
+	if !IsUnavailableError(err) {
+		return false
+	}
+	c.reads.Add(1)
+	c.report(ctx, op, err)
+	return true
+}
+
+// degradeWrite 判断写操作失败是否忽略
+func (c *FallbackClient) degradeWrite(ctx context.Context, op string, err error) bool {
+	if !IsUnavailableError(err) {
+		return false
+	}
+	c.writes.Add(1)
+	c.report(ctx, op, err)
+	return true
+}
+
+// report 记录降级日志并回调
+func (c *FallbackClient) report(ctx context.Context, op string, err error) {
+	c.logger.Printf(ctx, "cache: %s degraded, redis unavailable: %v", op, err)
+	if c.onFallback != nil {
+		c.onFallback(op, err)
+	}
+}
+
+// GetOrLoad 旁路缓存读取
+// 先读缓存，未命中或Redis不可用时调用loader加载并回写缓存，回写失败只记录日志，不影响返回结果
+func GetOrLoad(ctx context.Context, client Client, key string, expiration time.Duration, loader func(ctx context.Context) (string, error)) (string, error) {
+	val, err := client.Get(ctx, key)
+	if err == nil {
+		return val, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) && !IsUnavailableError(err) {
+		return "", err
+	}
+
+	val, err = loader(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// 回写只是优化，失败时仍然返回加载的结果
+	if err := client.Set(ctx, key, val, expiration); err != nil && !IsUnavailableError(err) {
+		defaultLogger.Printf(ctx, "cache: GetOrLoad write back %s failed: %v", key, err)
+	}
+	return val, nil
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// FunctionLibrary 函数库信息
+type FunctionLibrary struct {
+	// 函数库名称
+	Name string
+	// 引擎，目前只有LUA
+	Engine string
+	// 函数库中的函数
+	Functions []FunctionInfo
+	// 函数库源码，FunctionList的withCode为true时返回
+	Code string
+}
+
+// FunctionInfo 函数信息
+type FunctionInfo struct {
+	// 函数名
+	Name string
+	// 函数描述
+	Description string
+	// 函数标志，如no-writes
+	Flags []string
+}
+
+// newFunctionLibraries 转换go-redis的函数库信息
+func newFunctionLibraries(libs []redis.Library) []FunctionLibrary {
+	result := make([]FunctionLibrary, len(libs))
+	for i, lib := range libs {
+		functions := make([]FunctionInfo, len(lib.Functions))
+		for j, fn := range lib.Functions {
+			functions[j] = FunctionInfo{
+				Name:        fn.Name,
+				Description: fn.Description,
+				Flags:       fn.Flags,
+			}
+		}
+		result[i] = FunctionLibrary{
+			Name:      lib.Name,
+			Engine:    lib.Engine,
+			Functions: functions,
+			Code:      lib.Code,
+		}
+	}
+	return result
+}
+
+// libraryVersionPrefix 函数库源码中记录版本的注释前缀
+const libraryVersionPrefix = "-- version: "
+
+// LibrarySource 生成带版本的函数库源码，code为不包含#!lua声明的函数库代码
+func LibrarySource(name, version, code string) string {
+	return fmt.Sprintf("#!lua name=%s\n%s%s\n%s", name, libraryVersionPrefix, version, code)
+}
+
+// LibraryVersion 从LibrarySource生成的源码中解析版本，没有版本时返回空字符串
+func LibraryVersion(source string) string {
+	for _, line := range strings.Split(source, "\n") {
+		if version, ok := strings.CutPrefix(strings.TrimSpace(line), libraryVersionPrefix); ok {
+			return strings.TrimSpace(version)
+		}
+	}
+	return ""
+}
+
+// EnsureLibrary 确保函数库以指定版本安装，版本不同或未安装时替换安装
+// 集群模式下检查并安装到每个主节点，单机和哨兵模式安装到主节点，从节点通过复制获得函数库
+// code为不包含#!lua声明的函数库代码，声明由LibrarySource生成
+func EnsureLibrary(ctx context.Context, client Client, name, version, code string) error {
+	source := LibrarySource(name, version, code)
+
+	if cluster, ok := unwrapClient(client).(*ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node Client) error {
+			return ensureLibrary(ctx, node, name, version, source)
+		})
+	}
+	return ensureLibrary(ctx, client, name, version, source)
+}
+
+// ensureLibrary 在单个节点检查并安装函数库
+func ensureLibrary(ctx context.Context, client Client, name, version, source string) error {
+	libs, err := client.FunctionList(ctx, name, true)
+	if err != nil {
+		return err
+	}
+	for _, lib := range libs {
+		if lib.Name == name && LibraryVersion(lib.Code) == version {
+			return nil
+		}
+	}
+
+	_, err = client.FunctionLoad(ctx, source, true)
+	return err
+}
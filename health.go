@@ -0,0 +1,460 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RoleSentinel 哨兵节点角色
+const RoleSentinel = "sentinel"
+
+// HealthState 健康状态
+type HealthState string
+
+const (
+	// HealthUp 所有检查通过
+	HealthUp HealthState = "up"
+	// HealthDegraded 主节点可用，但部分从节点或哨兵不可用
+	HealthDegraded HealthState = "degraded"
+	// HealthDown 无法处理请求
+	HealthDown HealthState = "down"
+)
+
+// NodeHealth 单个节点的检查结果
+type NodeHealth struct {
+	// 节点地址
+	Addr string `json:"addr"`
+	// 节点角色：master、replica或sentinel
+	Role string `json:"role"`
+	// 是否可用
+	Healthy bool `json:"healthy"`
+	// 检查耗时
+	Latency time.Duration `json:"latency"`
+	// 检查失败的错误信息
+	Error string `json:"error,omitempty"`
+}
+
+// HealthStatus 健康检查结果
+type HealthStatus struct {
+	// 健康状态
+	State HealthState `json:"state"`
+	// 部署模式
+	Mode Mode `json:"mode,omitempty"`
+	// 本次检查耗时
+	Latency time.Duration `json:"latency"`
+	// 最近几次成功检查的平均耗时
+	AvgLatency time.Duration `json:"avg_latency"`
+	// 各节点的检查结果
+	Nodes []NodeHealth `json:"nodes,omitempty"`
+	// 集群状态（CLUSTER INFO的cluster_state），仅集群模式
+	ClusterState string `json:"cluster_state,omitempty"`
+	// 哨兵解析到的主节点地址，仅哨兵模式
+	Master string `json:"master,omitempty"`
+	// 检查失败的错误信息
+	Error string `json:"error,omitempty"`
+	// 连续失败次数
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// 检查时间
+	CheckedAt time.Time `json:"checked_at"`
+	// 最近一次检查成功的时间
+	LastSuccess time.Time `json:"last_success,omitempty"`
+}
+
+// Healthy 判断是否可以处理请求，降级状态也视为可用
+func (s HealthStatus) Healthy() bool {
+	return s.State == HealthUp || s.State == HealthDegraded
+}
+
+// HealthCheckerOptions 健康检查配置
+type HealthCheckerOptions struct {
+	// 检查间隔，默认10秒
+	Interval time.Duration
+	// 单次检查超时时间，默认3秒
+	Timeout time.Duration
+	// 计算平均耗时的检查次数，默认10次
+	HistorySize int
+	// 日志实现，为空时使用标准库log
+	Logger Logger
+}
+
+// HealthChecker 定期检查客户端连接的健康状态
+// 单机模式Ping服务器；集群模式Ping每个主从节点并检查集群状态；哨兵模式通过哨兵解析主节点后Ping主节点
+type HealthChecker struct {
+	client   Client
+	interval time.Duration
+	timeout  time.Duration
+	history  int
+	logger   Logger
+
+	mu        sync.RWMutex
+	status    HealthStatus
+	latencies []time.Duration
+	started   bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	// 检查哨兵时使用的客户端，按哨兵地址复用，Stop时关闭
+	sentinelMu sync.Mutex
+	sentinels  map[string]*redis.SentinelClient
+}
+
+// NewHealthChecker 创建健康检查器，调用Start后开始定期检查
+func NewHealthChecker(client Client, opts *HealthCheckerOptions) *HealthChecker {
+	h := &HealthChecker{
+		client:   client,
+		interval: 10 * time.Second,
+		timeout:  3 * time.Second,
+		history:  10,
+		logger:   defaultLogger,
+		status:   HealthStatus{State: HealthDown, Error: "not checked yet"},
+	}
+	if opts != nil {
+		if opts.Interval > 0 {
+			h.interval = opts.Interval
+		}
+		if opts.Timeout > 0 {
+			h.timeout = opts.Timeout
+		}
+		if opts.HistorySize > 0 {
+			h.history = opts.HistorySize
+		}
+		if opts.Logger != nil {
+			h.logger = opts.Logger
+		}
+	}
+	return h
+}
+
+// Start 立即检查一次，之后按间隔定期检查
+func (h *HealthChecker) Start() {
+	h.mu.Lock()
+	if h.started {
+		h.mu.Unlock()
+		return
+	}
+	h.started = true
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.done = make(chan struct{})
+	h.mu.Unlock()
+
+	go h.run(ctx)
+}
+
+// Stop 停止定期检查，并关闭检查哨兵时建立的连接
+func (h *HealthChecker) Stop() {
+	h.mu.Lock()
+	started := h.started
+	h.started = false
+	cancel, done := h.cancel, h.done
+	h.mu.Unlock()
+
+	if started {
+		cancel()
+		<-done
+	}
+	h.closeSentinels()
+}
+
+// run 定期检查，直到停止
+func (h *HealthChecker) run(ctx context.Context) {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		status := h.Check(ctx)
+		if !status.Healthy() && ctx.Err() == nil {
+			h.logger.Printf(ctx, "cache: health check failed: %s", status.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check 立即执行一次检查并更新状态
+func (h *HealthChecker) Check(ctx context.Context) HealthStatus {
+	ctx, cancel := context.WithTimeout(WithNoRetry(ctx), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	status := h.check(ctx)
+	status.Latency = time.Since(start)
+	status.CheckedAt = start
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if status.Healthy() {
+		h.latencies = append(h.latencies, status.Latency)
+		if len(h.latencies) > h.history {
+			h.latencies = h.latencies[len(h.latencies)-h.history:]
+		}
+		status.LastSuccess = status.CheckedAt
+	} else {
+		status.ConsecutiveFailures = h.status.ConsecutiveFailures + 1
+		status.LastSuccess = h.status.LastSuccess
+	}
+	if len(h.latencies) > 0 {
+		var total time.Duration
+		for _, latency := range h.latencies {
+			total += latency
+		}
+		status.AvgLatency = total / time.Duration(len(h.latencies))
+	}
+
+	h.status = status
+	return status
+}
+
+// Status 获取最近一次检查的结果
+func (h *HealthChecker) Status() HealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	status := h.status
+	status.Nodes = append([]NodeHealth(nil), h.status.Nodes...)
+	return status
+}
+
+// ServeHTTP 以JSON返回最近一次检查的结果，可用于就绪探针
+// 状态为up或degraded时返回200，否则返回503
+func (h *HealthChecker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := h.Status()
+	code := http.StatusOK
+	if !status.Healthy() {
+		code = http.StatusServiceUnavailable
+	}
+	writeHealthJSON(w, code, status)
+}
+
+// LivenessHandler 存活探针，定期检查仍在运行且最近检查过时返回200
+// 只反映检查器本身是否卡住，不受Redis可用性影响
+func (h *HealthChecker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.RLock()
+		started := h.started
+		checkedAt := h.status.CheckedAt
+		h.mu.RUnlock()
+
+		alive := started && time.Since(checkedAt) <= 3*h.interval+h.timeout
+		code := http.StatusOK
+		if !alive {
+			code = http.StatusServiceUnavailable
+		}
+		writeHealthJSON(w, code, map[string]interface{}{
+			"alive":      alive,
+			"checked_at": checkedAt,
+		})
+	})
+}
+
+// writeHealthJSON 输出JSON响应
+func writeHealthJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// check 按客户端类型执行检查
+func (h *HealthChecker) check(ctx context.Context) HealthStatus {
+	client := unwrapClient(h.client)
+	switch c := client.(type) {
+	case *SingleClient:
+		node := pingNode(ctx, c.client, RoleMaster)
+		return nodesStatus(ModeSingle, []NodeHealth{node})
+	case *ClusterClient:
+		return checkCluster(ctx, c)
+	case *SentinelClient:
+		return h.checkSentinel(ctx, c)
+	default:
+		start := time.Now()
+		err := client.Ping(ctx)
+		node := NodeHealth{Role: RoleMaster, Healthy: err == nil, Latency: time.Since(start)}
+		if err != nil {
+			node.Error = err.Error()
+		}
+		return nodesStatus("", []NodeHealth{node})
+	}
+}
+
+// pingNode Ping单个节点
+func pingNode(ctx context.Context, node *redis.Client, role string) NodeHealth {
+	start := time.Now()
+	err := node.Ping(ctx).Err()
+	health := NodeHealth{
+		Addr:    node.Options().Addr,
+		Role:    role,
+		Healthy: err == nil,
+		Latency: time.Since(start),
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// nodesStatus 根据节点检查结果汇总状态
+// 任一主节点不可用为down，只有从节点或哨兵不可用为degraded
+func nodesStatus(mode Mode, nodes []NodeHealth) HealthStatus {
+	status := HealthStatus{State: HealthUp, Mode: mode, Nodes: nodes}
+	for _, node := range nodes {
+		if node.Healthy {
+			continue
+		}
+		if node.Role == RoleMaster {
+			status.State = HealthDown
+			status.Error = node.Addr + ": " + node.Error
+			return status
+		}
+		status.State = HealthDegraded
+		if status.Error == "" {
+			status.Error = node.Addr + ": " + node.Error
+		}
+	}
+	return status
+}
+
+// checkCluster 检查集群所有主从节点和集群状态
+func checkCluster(ctx context.Context, c *ClusterClient) HealthStatus {
+	var (
+		mu    sync.Mutex
+		nodes []NodeHealth
+	)
+	collect := func(role string) func(ctx context.Context, node *redis.Client) error {
+		return func(ctx context.Context, node *redis.Client) error {
+			health := pingNode(ctx, node, role)
+			mu.Lock()
+			nodes = append(nodes, health)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	if err := c.client.ForEachMaster(ctx, collect(RoleMaster)); err != nil {
+		return HealthStatus{State: HealthDown, Mode: ModeCluster, Error: err.Error()}
+	}
+	if err := c.client.ForEachSlave(ctx, collect(RoleReplica)); err != nil {
+		return HealthStatus{State: HealthDown, Mode: ModeCluster, Error: err.Error()}
+	}
+
+	status := nodesStatus(ModeCluster, nodes)
+	info, err := c.client.ClusterInfo(ctx).Result()
+	if err != nil {
+		status.State = HealthDown
+		status.Error = err.Error()
+		return status
+	}
+	status.ClusterState = parseClusterState(info)
+	if status.ClusterState != "ok" {
+		status.State = HealthDown
+		status.Error = "cluster state is " + status.ClusterState
+	}
+	return status
+}
+
+// parseClusterState 从CLUSTER INFO结果中解析cluster_state
+func parseClusterState(info string) string {
+	for _, line := range strings.Split(info, "\n") {
+		if state, ok := strings.CutPrefix(strings.TrimSpace(line), "cluster_state:"); ok {
+			return state
+		}
+	}
+	return "unknown"
+}
+
+// checkSentinel 通过哨兵解析主节点并Ping主节点
+func (h *HealthChecker) checkSentinel(ctx context.Context, s *SentinelClient) HealthStatus {
+	var (
+		nodes  []NodeHealth
+		master string
+	)
+
+	if s.watcher != nil {
+		for _, addr := range s.watcher.addrs {
+			start := time.Now()
+			resolved, err := resolveMaster(ctx, h.sentinelClient(s.watcher, addr), s.watcher.masterName)
+			health := NodeHealth{Addr: addr, Role: RoleSentinel, Healthy: err == nil, Latency: time.Since(start)}
+			if err != nil {
+				health.Error = err.Error()
+			} else if master == "" {
+				master = resolved
+			}
+			nodes = append(nodes, health)
+		}
+		if master == "" {
+			status := nodesStatus(ModeSentinel, nodes)
+			status.State = HealthDown
+			status.Error = ErrSentinelNoMaster.Error()
+			return status
+		}
+	}
+
+	start := time.Now()
+	err := s.client.Ping(ctx).Err()
+	health := NodeHealth{Addr: master, Role: RoleMaster, Healthy: err == nil, Latency: time.Since(start)}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	nodes = append(nodes, health)
+
+	status := nodesStatus(ModeSentinel, nodes)
+	status.Master = master
+	return status
+}
+
+// sentinelClient 获取连接哨兵的客户端，首次使用时创建
+func (h *HealthChecker) sentinelClient(w *sentinelWatcher, addr string) *redis.SentinelClient {
+	h.sentinelMu.Lock()
+	defer h.sentinelMu.Unlock()
+
+	client := h.sentinels[addr]
+	if client == nil {
+		if h.sentinels == nil {
+			h.sentinels = make(map[string]*redis.SentinelClient)
+		}
+		client = w.newClient(addr)
+		h.sentinels[addr] = client
+	}
+	return client
+}
+
+// closeSentinels 关闭连接哨兵的客户端，之后的检查会重新创建
+func (h *HealthChecker) closeSentinels() {
+	h.sentinelMu.Lock()
+	sentinels := h.sentinels
+	h.sentinels = nil
+	h.sentinelMu.Unlock()
+
+	for _, client := range sentinels {
+		client.Close()
+	}
+}
+
+// resolveMaster 通过单个哨兵解析主节点地址
+func resolveMaster(ctx context.Context, client *redis.SentinelClient, masterName string) (string, error) {
+	parts, err := client.GetMasterAddrByName(ctx, masterName).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", ErrSentinelNoMaster
+		}
+		return "", err
+	}
+	if len(parts) != 2 {
+		return "", ErrSentinelNoMaster
+	}
+	return net.JoinHostPort(parts[0], parts[1]), nil
+}
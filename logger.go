@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"context"
+	"log"
+)
+
+// Logger 日志接口
+type Logger interface {
+	Printf(ctx context.Context, format string, v ...interface{})
+}
+
+// stdLogger 基于标准库log的默认日志实现
+type stdLogger struct{}
+
+// Printf 输出日志
+func (stdLogger) Printf(ctx context.Context, format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
+
+// defaultLogger 默认日志实例
+var defaultLogger Logger = stdLogger{}
@@ -0,0 +1,15 @@
+package cache
+
+// Metrics 指标钩子
+// 所有回调均为可选，仅在CommonConfig.EnableMetrics开启时触发
+type Metrics struct {
+	// OnBreakerStateChange 熔断器状态变化，name为熔断器名称（集群模式下为节点地址）
+	OnBreakerStateChange func(name string, from, to BreakerState)
+	// OnFallback 降级模式下操作因Redis不可用被降级，op为方法名
+	OnFallback func(op string, err error)
+}
+
+// enabled 判断指标钩子是否生效
+func (m *Metrics) enabled(config *Config) bool {
+	return m != nil && config.Common.EnableMetrics
+}
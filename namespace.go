@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// namespacePrefix 命名空间在指定代数下的键前缀
+func namespacePrefix(namespace string, generation int64) string {
+	return namespace + ":v" + strconv.FormatInt(generation, 10) + ":"
+}
+
+// NamespaceOptions 命名空间代数配置
+type NamespaceOptions struct {
+	// 保存代数的键的前缀，默认"ns:"，同样会添加KeyPrefix
+	GenerationPrefix string
+	// 代数在本地缓存的时间，为0时每次都从Redis读取；
+	// 其他进程递增代数后，本地最多在LocalTTL之后看到新的代数
+	LocalTTL time.Duration
+}
+
+// namespaceEntry 本地缓存的代数
+type namespaceEntry struct {
+	generation int64
+	expireAt   time.Time
+}
+
+// Namespaces 命名空间代数
+// 命名空间中的键带有当前代数，BumpNamespace递增代数后旧代数的键不再被访问，随各自的TTL过期，
+// 代替按模式删除。代数从首次使用时的Unix毫秒时间戳开始，保存代数的键不设置过期时间，
+// 淘汰策略为allkeys-*时可能被淘汰，之后重新以当时的时间戳开始，不会回到已经用过的代数
+type Namespaces struct {
+	client   Client
+	prefix   string
+	localTTL time.Duration
+
+	mu    sync.Mutex
+	local map[string]namespaceEntry
+}
+
+// NewNamespaces 创建命名空间代数
+func NewNamespaces(client Client, opts *NamespaceOptions) *Namespaces {
+	n := &Namespaces{
+		client: client,
+		prefix: "ns:",
+		local:  make(map[string]namespaceEntry),
+	}
+	if opts != nil {
+		if opts.GenerationPrefix != "" {
+			n.prefix = opts.GenerationPrefix
+		}
+		n.localTTL = opts.LocalTTL
+	}
+	return n
+}
+
+// WithNamespace 获取命名空间的当前代数，返回在其下执行调用的上下文
+func (n *Namespaces) WithNamespace(ctx context.Context, namespace string) (context.Context, error) {
+	generation, err := n.Generation(ctx, namespace)
+	if err != nil {
+		return ctx, err
+	}
+	return WithNamespace(ctx, namespace, generation), nil
+}
+
+// Generation 获取命名空间的当前代数
+func (n *Namespaces) Generation(ctx context.Context, namespace string) (int64, error) {
+	if generation, ok := n.cached(namespace); ok {
+		return generation, nil
+	}
+
+	generation, err := n.load(n.generationContext(ctx), n.prefix+namespace)
+	if err != nil {
+		return 0, err
+	}
+	n.store(namespace, generation)
+	return generation, nil
+}
+
+// BumpNamespace 递增命名空间的代数并返回新的代数，命名空间中已有的键立即失效
+func (n *Namespaces) BumpNamespace(ctx context.Context, namespace string) (int64, error) {
+	ctx = n.generationContext(ctx)
+	key := n.prefix + namespace
+	// 代数不存在时先写入初始值，INCR不会从0开始
+	if _, err := n.load(ctx, key); err != nil {
+		return 0, err
+	}
+	generation, err := n.client.Incr(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	n.store(namespace, generation)
+	return generation, nil
+}
+
+// load 读取保存代数的键，不存在时以当前的Unix毫秒时间戳作为初始值写入
+func (n *Namespaces) load(ctx context.Context, key string) (int64, error) {
+	val, err := n.client.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		val, err = n.seed(ctx, key)
+	}
+	if err != nil {
+		return 0, err
+	}
+	generation, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidType
+	}
+	return generation, nil
+}
+
+// seed 在乐观事务中写入代数的初始值，并发写入时使用先写入的值
+// 通过INCRBY写入，键和INCR创建的一样不带过期时间，不受DefaultTTL影响
+func (n *Namespaces) seed(ctx context.Context, key string) (string, error) {
+	var val string
+	err := n.client.Watch(ctx, func(tx Tx) error {
+		current, err := tx.Get(ctx, key)
+		if err == nil {
+			val = current
+			return nil
+		}
+		if !errors.Is(err, ErrKeyNotFound) {
+			return err
+		}
+
+		initial := time.Now().UnixMilli()
+		val = strconv.FormatInt(initial, 10)
+		return tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+			pipe.IncrBy(ctx, key, initial)
+			return nil
+		})
+	}, key)
+	return val, err
+}
+
+// generationContext 读写代数时不使用上下文中的命名空间
+func (n *Namespaces) generationContext(ctx context.Context) context.Context {
+	if callOptionsFromContext(ctx).namespace == "" {
+		return ctx
+	}
+	return withCallOptions(ctx, func(opts *callOptions) {
+		opts.namespace = ""
+	})
+}
+
+// cached 获取本地缓存的代数
+func (n *Namespaces) cached(namespace string) (int64, bool) {
+	if n.localTTL <= 0 {
+		return 0, false
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	entry, ok := n.local[namespace]
+	if !ok || time.Now().After(entry.expireAt) {
+		return 0, false
+	}
+	return entry.generation, true
+}
+
+// store 更新本地缓存的代数，不会用较旧的代数覆盖较新的代数
+func (n *Namespaces) store(namespace string, generation int64) {
+	if n.localTTL <= 0 {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if entry, ok := n.local[namespace]; ok && entry.generation > generation && time.Now().Before(entry.expireAt) {
+		return
+	}
+	n.local[namespace] = namespaceEntry{generation: generation, expireAt: time.Now().Add(n.localTTL)}
+}
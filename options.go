@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReadPreference 读请求路由偏好
+// Keys和Scan不受读路由影响，始终在主节点执行：SCAN游标只在返回它的节点上有效
+type ReadPreference int
+
+const (
+	// ReadDefault 使用客户端配置的默认路由
+	ReadDefault ReadPreference = iota
+	// ReadPrimary 只从主节点读取
+	ReadPrimary
+	// ReplicaPreferred 优先从从节点读取，没有可用从节点时读主节点
+	// 从节点复制存在延迟，可能读到旧数据
+	ReplicaPreferred
+)
+
+// callOptions 单次调用选项
+type callOptions struct {
+	timeout   time.Duration
+	noPrefix  bool
+	readPref  ReadPreference
+	ttlJitter time.Duration
+	namespace string
+}
+
+// callOptionsKey 上下文中调用选项的键
+type callOptionsKey struct{}
+
+// withCallOptions 在上下文中附加调用选项，不修改父上下文中的选项
+func withCallOptions(ctx context.Context, fn func(opts *callOptions)) context.Context {
+	opts := callOptionsFromContext(ctx)
+	fn(&opts)
+	return context.WithValue(ctx, callOptionsKey{}, opts)
+}
+
+// callOptionsFromContext 获取上下文中的调用选项
+func callOptionsFromContext(ctx context.Context) callOptions {
+	opts, _ := ctx.Value(callOptionsKey{}).(callOptions)
+	return opts
+}
+
+// WithTimeout 为本次调用的每条命令（或每次管道执行）设置超时，包括重试的时间
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return withCallOptions(ctx, func(opts *callOptions) {
+		opts.timeout = timeout
+	})
+}
+
+// WithoutKeyPrefix 本次调用不为键添加KeyPrefix，Keys和Scan返回的键也不去除前缀
+func WithoutKeyPrefix(ctx context.Context) context.Context {
+	return withCallOptions(ctx, func(opts *callOptions) {
+		opts.noPrefix = true
+	})
+}
+
+// WithReadPreference 指定本次调用读请求的路由偏好，只对读命令生效，管道、Keys和Scan始终使用主节点
+func WithReadPreference(ctx context.Context, pref ReadPreference) context.Context {
+	return withCallOptions(ctx, func(opts *callOptions) {
+		opts.readPref = pref
+	})
+}
+
+// WithNamespace 本次调用的键添加命名空间及其代数，键为KeyPrefix+namespace+":v"+generation+":"+key，
+// Keys和Scan只匹配并返回该代数下的键；代数通常通过Namespaces.WithNamespace获取
+func WithNamespace(ctx context.Context, namespace string, generation int64) context.Context {
+	return withCallOptions(ctx, func(opts *callOptions) {
+		opts.namespace = namespacePrefix(namespace, generation)
+	})
+}
+
+// WithNoRetry 本次调用失败后不重试
+func WithNoRetry(ctx context.Context) context.Context {
+	return WithRetryPolicy(ctx, NoRetry)
+}
+
+// WithTTLJitter 为本次调用设置的过期时间增加[0, jitter)的随机抖动，避免大量键同时过期
+func WithTTLJitter(ctx context.Context, jitter time.Duration) context.Context {
+	return withCallOptions(ctx, func(opts *callOptions) {
+		opts.ttlJitter = jitter
+	})
+}
+
+// PrefixKey 按ctx中的调用选项为键添加前缀，供自定义的Client实现使用
+func (c *Config) PrefixKey(ctx context.Context, key string) string {
+	return c.prefixKey(ctx, key)
+}
+
+// KeyPrefix 获取ctx中的调用选项下生效的键前缀，供自定义的Client实现使用
+func (c *Config) KeyPrefix(ctx context.Context) string {
+	return c.keyPrefix(ctx)
+}
+
+// TTL 获取ctx中的调用选项下的过期时间，未指定时使用DefaultTTL并增加抖动，供自定义的Client实现使用
+func (c *Config) TTL(ctx context.Context, expiration time.Duration) time.Duration {
+	return c.ttl(ctx, expiration)
+}
+
+// JitterTTL 按ctx中的调用选项为过期时间增加抖动，供自定义的Client实现使用
+func JitterTTL(ctx context.Context, expiration time.Duration) time.Duration {
+	return jitterTTL(ctx, expiration)
+}
+
+// prefixKey 按调用选项为键添加命名空间和前缀
+func (c *Config) prefixKey(ctx context.Context, key string) string {
+	opts := callOptionsFromContext(ctx)
+	key = opts.namespace + key
+	if opts.noPrefix {
+		return key
+	}
+	return c.GetKeyWithPrefix(key)
+}
+
+// keyPrefix 获取本次调用生效的键前缀，包括命名空间
+func (c *Config) keyPrefix(ctx context.Context) string {
+	opts := callOptionsFromContext(ctx)
+	if opts.noPrefix {
+		return opts.namespace
+	}
+	return c.Common.KeyPrefix + opts.namespace
+}
+
+// ttl 获取本次调用的过期时间，未指定时使用默认值，并按调用选项增加抖动
+func (c *Config) ttl(ctx context.Context, expiration time.Duration) time.Duration {
+	return jitterTTL(ctx, c.GetTTL(expiration))
+}
+
+// jitterTTL 按调用选项为过期时间增加抖动
+func jitterTTL(ctx context.Context, expiration time.Duration) time.Duration {
+	jitter := callOptionsFromContext(ctx).ttlJitter
+	if expiration <= 0 || jitter <= 0 {
+		return expiration
+	}
+	return expiration + time.Duration(rand.Int63n(int64(jitter)))
+}
+
+// optionsHook 应用调用选项中的超时设置
+type optionsHook struct{}
+
+// DialHook 建立连接时不做处理
+func (optionsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 为单条命令设置超时
+func (optionsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if timeout := callOptionsFromContext(ctx).timeout; timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook 为管道执行设置超时
+func (optionsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if timeout := callOptionsFromContext(ctx).timeout; timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// readRouter 按读偏好选择读命令使用的客户端
+// main为处理写命令的客户端，mainReplicas表示其只读命令是否路由到从节点，
+// 与main路由方式不同的读请求使用按需创建的alt客户端
+type readRouter struct {
+	main            redis.UniversalClient
+	mainReplicas    bool
+	defaultReplicas bool
+	newAlt          func() redis.UniversalClient
+
+	once sync.Once
+	mu   sync.Mutex
+	alt  redis.UniversalClient
+}
+
+// reader 获取本次调用读命令使用的客户端
+func (r *readRouter) reader(ctx context.Context) redis.Cmdable {
+	replicas := r.defaultReplicas
+	switch callOptionsFromContext(ctx).readPref {
+	case ReadPrimary:
+		replicas = false
+	case ReplicaPreferred:
+		replicas = true
+	}
+
+	if replicas == r.mainReplicas {
+		return r.main
+	}
+	return r.altClient()
+}
+
+// altClient 按需创建alt客户端
+func (r *readRouter) altClient() redis.UniversalClient {
+	if r.newAlt == nil {
+		return r.main
+	}
+	r.once.Do(func() {
+		alt := r.newAlt()
+		r.mu.Lock()
+		r.alt = alt
+		r.mu.Unlock()
+	})
+	return r.alt
+}
+
+// close 关闭已创建的alt客户端
+func (r *readRouter) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.alt == nil {
+		return nil
+	}
+	return r.alt.Close()
+}
@@ -41,169 +41,291 @@ func (p *SinglePipeliner) Close() error {
 
 // 字符串操作
 
-// Get 获取字符串值
+// Get 获取字符串值，键不存在时命令错误为ErrKeyNotFound
 func (p *SinglePipeliner) Get(ctx context.Context, key string) *StringCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.Get(ctx, key)
-	return &StringCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.Get(ctx, key))
 }
 
 // Set 设置字符串值
 func (p *SinglePipeliner) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	expiration = p.config.GetTTL(expiration)
-	cmd := p.pipe.Set(ctx, key, value, expiration)
-	return &StatusCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	expiration = p.config.ttl(ctx, expiration)
+	return wrapStatusCmd(p.pipe.Set(ctx, key, value, expiration))
 }
 
 // SetNX 仅当键不存在时设置值
 func (p *SinglePipeliner) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	expiration = p.config.GetTTL(expiration)
-	cmd := p.pipe.SetNX(ctx, key, value, expiration)
-	return &BoolCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+	key = p.config.prefixKey(ctx, key)
+	expiration = p.config.ttl(ctx, expiration)
+	return wrapBoolCmd(p.pipe.SetNX(ctx, key, value, expiration))
+}
+
+// GetSet 设置新值并返回旧值，键不存在时命令错误为ErrKeyNotFound
+func (p *SinglePipeliner) GetSet(ctx context.Context, key string, value interface{}) *StringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.GetSet(ctx, key, value))
+}
+
+// MGet 批量获取多个键的值
+func (p *SinglePipeliner) MGet(ctx context.Context, keys ...string) *SliceCmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
+	return wrapSliceCmd(p.pipe.MGet(ctx, prefixedKeys...))
+}
+
+// MSet 批量设置多个键值对
+func (p *SinglePipeliner) MSet(ctx context.Context, pairs ...interface{}) *StatusCmd {
+	// 为键添加前缀
+	prefixedPairs := make([]interface{}, len(pairs))
+	for i := 0; i < len(pairs); i += 2 {
+		if i+1 < len(pairs) {
+			key := pairs[i].(string)
+			prefixedPairs[i] = p.config.prefixKey(ctx, key)
+			prefixedPairs[i+1] = pairs[i+1]
+		}
+	}
+	return wrapStatusCmd(p.pipe.MSet(ctx, prefixedPairs...))
 }
 
 // Incr 递增计数器
 func (p *SinglePipeliner) Incr(ctx context.Context, key string) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.Incr(ctx, key)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.Incr(ctx, key))
+}
+
+// IncrBy 按指定值递增计数器
+func (p *SinglePipeliner) IncrBy(ctx context.Context, key string, value int64) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.IncrBy(ctx, key, value))
 }
 
 // Decr 递减计数器
 func (p *SinglePipeliner) Decr(ctx context.Context, key string) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.Decr(ctx, key)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.Decr(ctx, key))
+}
+
+// DecrBy 按指定值递减计数器
+func (p *SinglePipeliner) DecrBy(ctx context.Context, key string, value int64) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.DecrBy(ctx, key, value))
 }
 
 // 哈希表操作
 
-// HGet 获取哈希表字段值
+// HGet 获取哈希表字段值，字段不存在时命令错误为ErrKeyNotFound
 func (p *SinglePipeliner) HGet(ctx context.Context, key, field string) *StringCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.HGet(ctx, key, field)
-	return &StringCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.HGet(ctx, key, field))
 }
 
 // HSet 设置哈希表字段值
 func (p *SinglePipeliner) HSet(ctx context.Context, key, field string, value interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.HSet(ctx, key, field, value)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.HSet(ctx, key, field, value))
+}
+
+// HSetNX 仅当字段不存在时设置哈希表字段值
+func (p *SinglePipeliner) HSetNX(ctx context.Context, key, field string, value interface{}) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.HSetNX(ctx, key, field, value))
 }
 
 // HDel 删除哈希表字段
 func (p *SinglePipeliner) HDel(ctx context.Context, key string, fields ...string) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.HDel(ctx, key, fields...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.HDel(ctx, key, fields...))
+}
+
+// HExists 检查哈希表字段是否存在
+func (p *SinglePipeliner) HExists(ctx context.Context, key, field string) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.HExists(ctx, key, field))
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (p *SinglePipeliner) HGetAll(ctx context.Context, key string) *MapStringStringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapMapStringStringCmd(p.pipe.HGetAll(ctx, key))
+}
+
+// HKeys 获取哈希表所有字段
+func (p *SinglePipeliner) HKeys(ctx context.Context, key string) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.HKeys(ctx, key))
+}
+
+// HVals 获取哈希表所有值
+func (p *SinglePipeliner) HVals(ctx context.Context, key string) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.HVals(ctx, key))
+}
+
+// HLen 获取哈希表字段数量
+func (p *SinglePipeliner) HLen(ctx context.Context, key string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.HLen(ctx, key))
+}
+
+// HMGet 批量获取哈希表字段值
+func (p *SinglePipeliner) HMGet(ctx context.Context, key string, fields ...string) *SliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapSliceCmd(p.pipe.HMGet(ctx, key, fields...))
+}
+
+// HMSet 批量设置哈希表字段值
+func (p *SinglePipeliner) HMSet(ctx context.Context, key string, pairs ...interface{}) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.HMSet(ctx, key, pairs...))
+}
+
+// HIncrBy 递增哈希表字段值
+func (p *SinglePipeliner) HIncrBy(ctx context.Context, key, field string, incr int64) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.HIncrBy(ctx, key, field, incr))
 }
 
 // 列表操作
 
 // LPush 从列表左侧推入元素
 func (p *SinglePipeliner) LPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.LPush(ctx, key, values...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.LPush(ctx, key, values...))
 }
 
 // RPush 从列表右侧推入元素
 func (p *SinglePipeliner) RPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.RPush(ctx, key, values...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.RPush(ctx, key, values...))
 }
 
-// LPop 从列表左侧弹出元素
+// LPop 从列表左侧弹出元素，列表为空时命令错误为ErrKeyNotFound
 func (p *SinglePipeliner) LPop(ctx context.Context, key string) *StringCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.LPop(ctx, key)
-	return &StringCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.LPop(ctx, key))
 }
 
-// RPop 从列表右侧弹出元素
+// RPop 从列表右侧弹出元素，列表为空时命令错误为ErrKeyNotFound
 func (p *SinglePipeliner) RPop(ctx context.Context, key string) *StringCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.RPop(ctx, key)
-	return &StringCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.RPop(ctx, key))
+}
+
+// LLen 获取列表长度
+func (p *SinglePipeliner) LLen(ctx context.Context, key string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.LLen(ctx, key))
+}
+
+// LRange 获取列表指定范围的元素
+func (p *SinglePipeliner) LRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.LRange(ctx, key, start, stop))
+}
+
+// LIndex 获取列表指定索引的元素，索引超出范围时命令错误为ErrKeyNotFound
+func (p *SinglePipeliner) LIndex(ctx context.Context, key string, index int64) *StringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.LIndex(ctx, key, index))
+}
+
+// LSet 设置列表指定索引的元素值
+func (p *SinglePipeliner) LSet(ctx context.Context, key string, index int64, value interface{}) *StatusCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStatusCmd(p.pipe.LSet(ctx, key, index, value))
+}
+
+// LRem 从列表中移除元素
+func (p *SinglePipeliner) LRem(ctx context.Context, key string, count int64, value interface{}) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.LRem(ctx, key, count, value))
+}
+
+// LTrim 修剪列表，只保留指定范围的元素
+func (p *SinglePipeliner) LTrim(ctx context.Context, key string, start, stop int64) *StatusCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStatusCmd(p.pipe.LTrim(ctx, key, start, stop))
 }
 
 // 集合操作
 
 // SAdd 向集合添加成员
 func (p *SinglePipeliner) SAdd(ctx context.Context, key string, members ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.SAdd(ctx, key, members...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.SAdd(ctx, key, members...))
 }
 
 // SRem 从集合移除成员
 func (p *SinglePipeliner) SRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.SRem(ctx, key, members...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.SRem(ctx, key, members...))
 }
 
 // SMembers 获取集合所有成员
 func (p *SinglePipeliner) SMembers(ctx context.Context, key string) *StringSliceCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.SMembers(ctx, key)
-	return &StringSliceCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.SMembers(ctx, key))
+}
+
+// SIsMember 检查成员是否在集合中
+func (p *SinglePipeliner) SIsMember(ctx context.Context, key string, member interface{}) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.SIsMember(ctx, key, member))
+}
+
+// SCard 获取集合成员数量
+func (p *SinglePipeliner) SCard(ctx context.Context, key string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.SCard(ctx, key))
+}
+
+// SPop 随机移除并返回集合中的一个成员，集合为空时命令错误为ErrKeyNotFound
+func (p *SinglePipeliner) SPop(ctx context.Context, key string) *StringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.SPop(ctx, key))
+}
+
+// SRandMember 随机返回集合中的一个成员，集合为空时命令错误为ErrKeyNotFound
+func (p *SinglePipeliner) SRandMember(ctx context.Context, key string) *StringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.SRandMember(ctx, key))
+}
+
+// SInter 计算多个集合的交集
+func (p *SinglePipeliner) SInter(ctx context.Context, keys ...string) *StringSliceCmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
+	return wrapStringSliceCmd(p.pipe.SInter(ctx, prefixedKeys...))
+}
+
+// SUnion 计算多个集合的并集
+func (p *SinglePipeliner) SUnion(ctx context.Context, keys ...string) *StringSliceCmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapStringSliceCmd(p.pipe.SUnion(ctx, prefixedKeys...))
+}
+
+// SDiff 计算多个集合的差集
+func (p *SinglePipeliner) SDiff(ctx context.Context, keys ...string) *StringSliceCmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapStringSliceCmd(p.pipe.SDiff(ctx, prefixedKeys...))
 }
 
 // 有序集合操作
 
 // ZAdd 向有序集合添加成员
 func (p *SinglePipeliner) ZAdd(ctx context.Context, key string, members ...ZMember) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
+	key = p.config.prefixKey(ctx, key)
 	redisMembers := make([]redis.Z, len(members))
 	for i, member := range members {
 		redisMembers[i] = redis.Z{
@@ -211,31 +333,91 @@ func (p *SinglePipeliner) ZAdd(ctx context.Context, key string, members ...ZMemb
 			Member: member.Member,
 		}
 	}
-	cmd := p.pipe.ZAdd(ctx, key, redisMembers...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	return wrapIntCmd(p.pipe.ZAdd(ctx, key, redisMembers...))
 }
 
 // ZRem 从有序集合移除成员
 func (p *SinglePipeliner) ZRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.ZRem(ctx, key, members...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZRem(ctx, key, members...))
+}
+
+// ZScore 获取有序集合成员的分数
+func (p *SinglePipeliner) ZScore(ctx context.Context, key, member string) *FloatCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapFloatCmd(p.pipe.ZScore(ctx, key, member))
+}
+
+// ZRank 获取有序集合成员的排名（从小到大）
+func (p *SinglePipeliner) ZRank(ctx context.Context, key, member string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZRank(ctx, key, member))
+}
+
+// ZRevRank 获取有序集合成员的排名（从大到小）
+func (p *SinglePipeliner) ZRevRank(ctx context.Context, key, member string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZRevRank(ctx, key, member))
 }
 
 // ZRange 获取有序集合指定范围的成员（从小到大）
 func (p *SinglePipeliner) ZRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.ZRange(ctx, key, start, stop)
-	return &StringSliceCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.ZRange(ctx, key, start, stop))
+}
+
+// ZRevRange 获取有序集合指定范围的成员（从大到小）
+func (p *SinglePipeliner) ZRevRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.ZRevRange(ctx, key, start, stop))
+}
+
+// ZRangeWithScores 获取有序集合指定范围的成员和分数（从小到大）
+func (p *SinglePipeliner) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapZSliceCmd(p.pipe.ZRangeWithScores(ctx, key, start, stop))
+}
+
+// ZRevRangeWithScores 获取有序集合指定范围的成员和分数（从大到小）
+func (p *SinglePipeliner) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapZSliceCmd(p.pipe.ZRevRangeWithScores(ctx, key, start, stop))
+}
+
+// ZRangeByScore 根据分数范围获取有序集合成员
+func (p *SinglePipeliner) ZRangeByScore(ctx context.Context, key string, min, max string) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: min,
+		Max: max,
+	}))
+}
+
+// ZRevRangeByScore 根据分数范围获取有序集合成员（逆序）
+func (p *SinglePipeliner) ZRevRangeByScore(ctx context.Context, key string, max, min string) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: min,
+		Max: max,
+	}))
+}
+
+// ZCard 获取有序集合成员数量
+func (p *SinglePipeliner) ZCard(ctx context.Context, key string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZCard(ctx, key))
+}
+
+// ZCount 计算指定分数范围内的成员数量
+func (p *SinglePipeliner) ZCount(ctx context.Context, key, min, max string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZCount(ctx, key, min, max))
+}
+
+// ZIncrBy 增加有序集合成员的分数
+func (p *SinglePipeliner) ZIncrBy(ctx context.Context, key string, increment float64, member string) *FloatCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapFloatCmd(p.pipe.ZIncrBy(ctx, key, increment, member))
 }
 
 // 通用操作
@@ -244,36 +426,97 @@ func (p *SinglePipeliner) ZRange(ctx context.Context, key string, start, stop in
 func (p *SinglePipeliner) Del(ctx context.Context, keys ...string) *IntCmd {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = p.config.GetKeyWithPrefix(key)
-	}
-	cmd := p.pipe.Del(ctx, prefixedKeys...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
+	return wrapIntCmd(p.pipe.Del(ctx, prefixedKeys...))
 }
 
 // Exists 检查键是否存在
 func (p *SinglePipeliner) Exists(ctx context.Context, keys ...string) *IntCmd {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = p.config.GetKeyWithPrefix(key)
-	}
-	cmd := p.pipe.Exists(ctx, prefixedKeys...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
+	return wrapIntCmd(p.pipe.Exists(ctx, prefixedKeys...))
 }
 
 // Expire 设置键的过期时间
 func (p *SinglePipeliner) Expire(ctx context.Context, key string, expiration time.Duration) *BoolCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.Expire(ctx, key, expiration)
-	return &BoolCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.Expire(ctx, key, jitterTTL(ctx, expiration)))
+}
+
+// ExpireAt 设置键在指定时间过期
+func (p *SinglePipeliner) ExpireAt(ctx context.Context, key string, tm time.Time) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.ExpireAt(ctx, key, tm))
+}
+
+// TTL 获取键的剩余生存时间
+func (p *SinglePipeliner) TTL(ctx context.Context, key string) *DurationCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapDurationCmd(p.pipe.TTL(ctx, key))
+}
+
+// Type 获取键的数据类型
+func (p *SinglePipeliner) Type(ctx context.Context, key string) *StatusCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStatusCmd(p.pipe.Type(ctx, key))
+}
+
+// Keys 查找匹配模式的键，返回的键不包含前缀
+func (p *SinglePipeliner) Keys(ctx context.Context, pattern string) *StringSliceCmd {
+	if p.config.Common.DisableKeys {
+		return keysDisabledCmd()
+	}
+	pattern = p.config.prefixKey(ctx, pattern)
+	return wrapKeysCmd(p.pipe.Keys(ctx, pattern), p.config.keyPrefix(ctx))
+}
+
+// Scan 迭代数据库中的键，返回的键不包含前缀
+func (p *SinglePipeliner) Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd {
+	match = p.config.prefixKey(ctx, match)
+	return wrapScanCmd(p.pipe.Scan(ctx, cursor, match, count), p.config.keyPrefix(ctx))
+}
+
+// Lua脚本操作
+
+// Eval 执行Lua脚本
+func (p *SinglePipeliner) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *Cmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapCmd(p.pipe.Eval(ctx, script, prefixedKeys, args...))
+}
+
+// EvalSha 通过SHA1执行Lua脚本，脚本不在服务端缓存中时命令错误为ErrScriptNotFound
+func (p *SinglePipeliner) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *Cmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
+	return wrapEvalShaCmd(p.pipe.EvalSha(ctx, sha1, prefixedKeys, args...))
+}
+
+// Redis Functions操作
+
+// FCall 调用函数
+func (p *SinglePipeliner) FCall(ctx context.Context, function string, keys []string, args ...interface{}) *Cmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapCmd(p.pipe.FCall(ctx, function, prefixedKeys, args...))
+}
+
+// FCallRO 调用只读函数
+func (p *SinglePipeliner) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) *Cmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapCmd(p.pipe.FCallRO(ctx, function, prefixedKeys, args...))
 }
 
 // ClusterPipeliner 集群模式管道实现
@@ -310,169 +553,291 @@ func (p *ClusterPipeliner) Close() error {
 
 // 字符串操作
 
-// Get 获取字符串值
+// Get 获取字符串值，键不存在时命令错误为ErrKeyNotFound
 func (p *ClusterPipeliner) Get(ctx context.Context, key string) *StringCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.Get(ctx, key)
-	return &StringCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.Get(ctx, key))
 }
 
 // Set 设置字符串值
 func (p *ClusterPipeliner) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	expiration = p.config.GetTTL(expiration)
-	cmd := p.pipe.Set(ctx, key, value, expiration)
-	return &StatusCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	expiration = p.config.ttl(ctx, expiration)
+	return wrapStatusCmd(p.pipe.Set(ctx, key, value, expiration))
 }
 
 // SetNX 仅当键不存在时设置值
 func (p *ClusterPipeliner) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	expiration = p.config.GetTTL(expiration)
-	cmd := p.pipe.SetNX(ctx, key, value, expiration)
-	return &BoolCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+	key = p.config.prefixKey(ctx, key)
+	expiration = p.config.ttl(ctx, expiration)
+	return wrapBoolCmd(p.pipe.SetNX(ctx, key, value, expiration))
+}
+
+// GetSet 设置新值并返回旧值，键不存在时命令错误为ErrKeyNotFound
+func (p *ClusterPipeliner) GetSet(ctx context.Context, key string, value interface{}) *StringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.GetSet(ctx, key, value))
+}
+
+// MGet 批量获取多个键的值
+func (p *ClusterPipeliner) MGet(ctx context.Context, keys ...string) *SliceCmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapSliceCmd(p.pipe.MGet(ctx, prefixedKeys...))
+}
+
+// MSet 批量设置多个键值对
+func (p *ClusterPipeliner) MSet(ctx context.Context, pairs ...interface{}) *StatusCmd {
+	// 为键添加前缀
+	prefixedPairs := make([]interface{}, len(pairs))
+	for i := 0; i < len(pairs); i += 2 {
+		if i+1 < len(pairs) {
+			key := pairs[i].(string)
+			prefixedPairs[i] = p.config.prefixKey(ctx, key)
+			prefixedPairs[i+1] = pairs[i+1]
+		}
 	}
+	return wrapStatusCmd(p.pipe.MSet(ctx, prefixedPairs...))
 }
 
 // Incr 递增计数器
 func (p *ClusterPipeliner) Incr(ctx context.Context, key string) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.Incr(ctx, key)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.Incr(ctx, key))
+}
+
+// IncrBy 按指定值递增计数器
+func (p *ClusterPipeliner) IncrBy(ctx context.Context, key string, value int64) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.IncrBy(ctx, key, value))
 }
 
 // Decr 递减计数器
 func (p *ClusterPipeliner) Decr(ctx context.Context, key string) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.Decr(ctx, key)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.Decr(ctx, key))
+}
+
+// DecrBy 按指定值递减计数器
+func (p *ClusterPipeliner) DecrBy(ctx context.Context, key string, value int64) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.DecrBy(ctx, key, value))
 }
 
 // 哈希表操作
 
-// HGet 获取哈希表字段值
+// HGet 获取哈希表字段值，字段不存在时命令错误为ErrKeyNotFound
 func (p *ClusterPipeliner) HGet(ctx context.Context, key, field string) *StringCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.HGet(ctx, key, field)
-	return &StringCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.HGet(ctx, key, field))
 }
 
 // HSet 设置哈希表字段值
 func (p *ClusterPipeliner) HSet(ctx context.Context, key, field string, value interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.HSet(ctx, key, field, value)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.HSet(ctx, key, field, value))
+}
+
+// HSetNX 仅当字段不存在时设置哈希表字段值
+func (p *ClusterPipeliner) HSetNX(ctx context.Context, key, field string, value interface{}) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.HSetNX(ctx, key, field, value))
 }
 
 // HDel 删除哈希表字段
 func (p *ClusterPipeliner) HDel(ctx context.Context, key string, fields ...string) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.HDel(ctx, key, fields...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.HDel(ctx, key, fields...))
+}
+
+// HExists 检查哈希表字段是否存在
+func (p *ClusterPipeliner) HExists(ctx context.Context, key, field string) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.HExists(ctx, key, field))
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (p *ClusterPipeliner) HGetAll(ctx context.Context, key string) *MapStringStringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapMapStringStringCmd(p.pipe.HGetAll(ctx, key))
+}
+
+// HKeys 获取哈希表所有字段
+func (p *ClusterPipeliner) HKeys(ctx context.Context, key string) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.HKeys(ctx, key))
+}
+
+// HVals 获取哈希表所有值
+func (p *ClusterPipeliner) HVals(ctx context.Context, key string) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.HVals(ctx, key))
+}
+
+// HLen 获取哈希表字段数量
+func (p *ClusterPipeliner) HLen(ctx context.Context, key string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.HLen(ctx, key))
+}
+
+// HMGet 批量获取哈希表字段值
+func (p *ClusterPipeliner) HMGet(ctx context.Context, key string, fields ...string) *SliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapSliceCmd(p.pipe.HMGet(ctx, key, fields...))
+}
+
+// HMSet 批量设置哈希表字段值
+func (p *ClusterPipeliner) HMSet(ctx context.Context, key string, pairs ...interface{}) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.HMSet(ctx, key, pairs...))
+}
+
+// HIncrBy 递增哈希表字段值
+func (p *ClusterPipeliner) HIncrBy(ctx context.Context, key, field string, incr int64) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.HIncrBy(ctx, key, field, incr))
 }
 
 // 列表操作
 
 // LPush 从列表左侧推入元素
 func (p *ClusterPipeliner) LPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.LPush(ctx, key, values...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.LPush(ctx, key, values...))
 }
 
 // RPush 从列表右侧推入元素
 func (p *ClusterPipeliner) RPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.RPush(ctx, key, values...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.RPush(ctx, key, values...))
 }
 
-// LPop 从列表左侧弹出元素
+// LPop 从列表左侧弹出元素，列表为空时命令错误为ErrKeyNotFound
 func (p *ClusterPipeliner) LPop(ctx context.Context, key string) *StringCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.LPop(ctx, key)
-	return &StringCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.LPop(ctx, key))
 }
 
-// RPop 从列表右侧弹出元素
+// RPop 从列表右侧弹出元素，列表为空时命令错误为ErrKeyNotFound
 func (p *ClusterPipeliner) RPop(ctx context.Context, key string) *StringCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.RPop(ctx, key)
-	return &StringCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.RPop(ctx, key))
+}
+
+// LLen 获取列表长度
+func (p *ClusterPipeliner) LLen(ctx context.Context, key string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.LLen(ctx, key))
+}
+
+// LRange 获取列表指定范围的元素
+func (p *ClusterPipeliner) LRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.LRange(ctx, key, start, stop))
+}
+
+// LIndex 获取列表指定索引的元素，索引超出范围时命令错误为ErrKeyNotFound
+func (p *ClusterPipeliner) LIndex(ctx context.Context, key string, index int64) *StringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.LIndex(ctx, key, index))
+}
+
+// LSet 设置列表指定索引的元素值
+func (p *ClusterPipeliner) LSet(ctx context.Context, key string, index int64, value interface{}) *StatusCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStatusCmd(p.pipe.LSet(ctx, key, index, value))
+}
+
+// LRem 从列表中移除元素
+func (p *ClusterPipeliner) LRem(ctx context.Context, key string, count int64, value interface{}) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.LRem(ctx, key, count, value))
+}
+
+// LTrim 修剪列表，只保留指定范围的元素
+func (p *ClusterPipeliner) LTrim(ctx context.Context, key string, start, stop int64) *StatusCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStatusCmd(p.pipe.LTrim(ctx, key, start, stop))
 }
 
 // 集合操作
 
 // SAdd 向集合添加成员
 func (p *ClusterPipeliner) SAdd(ctx context.Context, key string, members ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.SAdd(ctx, key, members...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.SAdd(ctx, key, members...))
 }
 
 // SRem 从集合移除成员
 func (p *ClusterPipeliner) SRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.SRem(ctx, key, members...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.SRem(ctx, key, members...))
 }
 
 // SMembers 获取集合所有成员
 func (p *ClusterPipeliner) SMembers(ctx context.Context, key string) *StringSliceCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.SMembers(ctx, key)
-	return &StringSliceCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.SMembers(ctx, key))
+}
+
+// SIsMember 检查成员是否在集合中
+func (p *ClusterPipeliner) SIsMember(ctx context.Context, key string, member interface{}) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.SIsMember(ctx, key, member))
+}
+
+// SCard 获取集合成员数量
+func (p *ClusterPipeliner) SCard(ctx context.Context, key string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.SCard(ctx, key))
+}
+
+// SPop 随机移除并返回集合中的一个成员，集合为空时命令错误为ErrKeyNotFound
+func (p *ClusterPipeliner) SPop(ctx context.Context, key string) *StringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.SPop(ctx, key))
+}
+
+// SRandMember 随机返回集合中的一个成员，集合为空时命令错误为ErrKeyNotFound
+func (p *ClusterPipeliner) SRandMember(ctx context.Context, key string) *StringCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringCmd(p.pipe.SRandMember(ctx, key))
+}
+
+// SInter 计算多个集合的交集
+func (p *ClusterPipeliner) SInter(ctx context.Context, keys ...string) *StringSliceCmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapStringSliceCmd(p.pipe.SInter(ctx, prefixedKeys...))
+}
+
+// SUnion 计算多个集合的并集
+func (p *ClusterPipeliner) SUnion(ctx context.Context, keys ...string) *StringSliceCmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapStringSliceCmd(p.pipe.SUnion(ctx, prefixedKeys...))
+}
+
+// SDiff 计算多个集合的差集
+func (p *ClusterPipeliner) SDiff(ctx context.Context, keys ...string) *StringSliceCmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
+	return wrapStringSliceCmd(p.pipe.SDiff(ctx, prefixedKeys...))
 }
 
 // 有序集合操作
 
 // ZAdd 向有序集合添加成员
 func (p *ClusterPipeliner) ZAdd(ctx context.Context, key string, members ...ZMember) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
+	key = p.config.prefixKey(ctx, key)
 	redisMembers := make([]redis.Z, len(members))
 	for i, member := range members {
 		redisMembers[i] = redis.Z{
@@ -480,31 +845,91 @@ func (p *ClusterPipeliner) ZAdd(ctx context.Context, key string, members ...ZMem
 			Member: member.Member,
 		}
 	}
-	cmd := p.pipe.ZAdd(ctx, key, redisMembers...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	return wrapIntCmd(p.pipe.ZAdd(ctx, key, redisMembers...))
 }
 
 // ZRem 从有序集合移除成员
 func (p *ClusterPipeliner) ZRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.ZRem(ctx, key, members...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZRem(ctx, key, members...))
+}
+
+// ZScore 获取有序集合成员的分数
+func (p *ClusterPipeliner) ZScore(ctx context.Context, key, member string) *FloatCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapFloatCmd(p.pipe.ZScore(ctx, key, member))
+}
+
+// ZRank 获取有序集合成员的排名（从小到大）
+func (p *ClusterPipeliner) ZRank(ctx context.Context, key, member string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZRank(ctx, key, member))
+}
+
+// ZRevRank 获取有序集合成员的排名（从大到小）
+func (p *ClusterPipeliner) ZRevRank(ctx context.Context, key, member string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZRevRank(ctx, key, member))
 }
 
 // ZRange 获取有序集合指定范围的成员（从小到大）
 func (p *ClusterPipeliner) ZRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.ZRange(ctx, key, start, stop)
-	return &StringSliceCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
-	}
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.ZRange(ctx, key, start, stop))
+}
+
+// ZRevRange 获取有序集合指定范围的成员（从大到小）
+func (p *ClusterPipeliner) ZRevRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.ZRevRange(ctx, key, start, stop))
+}
+
+// ZRangeWithScores 获取有序集合指定范围的成员和分数（从小到大）
+func (p *ClusterPipeliner) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapZSliceCmd(p.pipe.ZRangeWithScores(ctx, key, start, stop))
+}
+
+// ZRevRangeWithScores 获取有序集合指定范围的成员和分数（从大到小）
+func (p *ClusterPipeliner) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapZSliceCmd(p.pipe.ZRevRangeWithScores(ctx, key, start, stop))
+}
+
+// ZRangeByScore 根据分数范围获取有序集合成员
+func (p *ClusterPipeliner) ZRangeByScore(ctx context.Context, key string, min, max string) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: min,
+		Max: max,
+	}))
+}
+
+// ZRevRangeByScore 根据分数范围获取有序集合成员（逆序）
+func (p *ClusterPipeliner) ZRevRangeByScore(ctx context.Context, key string, max, min string) *StringSliceCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStringSliceCmd(p.pipe.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: min,
+		Max: max,
+	}))
+}
+
+// ZCard 获取有序集合成员数量
+func (p *ClusterPipeliner) ZCard(ctx context.Context, key string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZCard(ctx, key))
+}
+
+// ZCount 计算指定分数范围内的成员数量
+func (p *ClusterPipeliner) ZCount(ctx context.Context, key, min, max string) *IntCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapIntCmd(p.pipe.ZCount(ctx, key, min, max))
+}
+
+// ZIncrBy 增加有序集合成员的分数
+func (p *ClusterPipeliner) ZIncrBy(ctx context.Context, key string, increment float64, member string) *FloatCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapFloatCmd(p.pipe.ZIncrBy(ctx, key, increment, member))
 }
 
 // 通用操作
@@ -513,34 +938,131 @@ func (p *ClusterPipeliner) ZRange(ctx context.Context, key string, start, stop i
 func (p *ClusterPipeliner) Del(ctx context.Context, keys ...string) *IntCmd {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = p.config.GetKeyWithPrefix(key)
-	}
-	cmd := p.pipe.Del(ctx, prefixedKeys...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
+	return wrapIntCmd(p.pipe.Del(ctx, prefixedKeys...))
 }
 
 // Exists 检查键是否存在
 func (p *ClusterPipeliner) Exists(ctx context.Context, keys ...string) *IntCmd {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = p.config.GetKeyWithPrefix(key)
-	}
-	cmd := p.pipe.Exists(ctx, prefixedKeys...)
-	return &IntCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
+	return wrapIntCmd(p.pipe.Exists(ctx, prefixedKeys...))
 }
 
 // Expire 设置键的过期时间
 func (p *ClusterPipeliner) Expire(ctx context.Context, key string, expiration time.Duration) *BoolCmd {
-	key = p.config.GetKeyWithPrefix(key)
-	cmd := p.pipe.Expire(ctx, key, expiration)
-	return &BoolCmd{
-		val: cmd.Val(),
-		err: cmd.Err(),
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.Expire(ctx, key, jitterTTL(ctx, expiration)))
+}
+
+// ExpireAt 设置键在指定时间过期
+func (p *ClusterPipeliner) ExpireAt(ctx context.Context, key string, tm time.Time) *BoolCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapBoolCmd(p.pipe.ExpireAt(ctx, key, tm))
+}
+
+// TTL 获取键的剩余生存时间
+func (p *ClusterPipeliner) TTL(ctx context.Context, key string) *DurationCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapDurationCmd(p.pipe.TTL(ctx, key))
+}
+
+// Type 获取键的数据类型
+func (p *ClusterPipeliner) Type(ctx context.Context, key string) *StatusCmd {
+	key = p.config.prefixKey(ctx, key)
+	return wrapStatusCmd(p.pipe.Type(ctx, key))
+}
+
+// Keys 查找匹配模式的键，返回的键不包含前缀
+func (p *ClusterPipeliner) Keys(ctx context.Context, pattern string) *StringSliceCmd {
+	if p.config.Common.DisableKeys {
+		return keysDisabledCmd()
+	}
+	pattern = p.config.prefixKey(ctx, pattern)
+	return wrapKeysCmd(p.pipe.Keys(ctx, pattern), p.config.keyPrefix(ctx))
+}
+
+// Scan 迭代数据库中的键，返回的键不包含前缀
+func (p *ClusterPipeliner) Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd {
+	match = p.config.prefixKey(ctx, match)
+	return wrapScanCmd(p.pipe.Scan(ctx, cursor, match, count), p.config.keyPrefix(ctx))
+}
+
+// Lua脚本操作
+
+// Eval 执行Lua脚本
+func (p *ClusterPipeliner) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *Cmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapCmd(p.pipe.Eval(ctx, script, prefixedKeys, args...))
+}
+
+// EvalSha 通过SHA1执行Lua脚本，脚本不在服务端缓存中时命令错误为ErrScriptNotFound
+func (p *ClusterPipeliner) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *Cmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapEvalShaCmd(p.pipe.EvalSha(ctx, sha1, prefixedKeys, args...))
+}
+
+// Redis Functions操作
+
+// FCall 调用函数
+func (p *ClusterPipeliner) FCall(ctx context.Context, function string, keys []string, args ...interface{}) *Cmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
 	}
-}
\ No newline at end of file
+	return wrapCmd(p.pipe.FCall(ctx, function, prefixedKeys, args...))
+}
+
+// FCallRO 调用只读函数
+func (p *ClusterPipeliner) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) *Cmd {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.config.prefixKey(ctx, key)
+	}
+	return wrapCmd(p.pipe.FCallRO(ctx, function, prefixedKeys, args...))
+}
+
+// pipelineReplyHook 保证单机和哨兵模式下管道中每条命令保留自己的结果
+// go-redis读取管道回复后会把第一条命令的错误（如未命中）设置到其余没有错误的命令上，
+// 非事务管道在最前面加一条PING，使第一条命令总是成功，再按原命令返回第一个错误
+type pipelineReplyHook struct{}
+
+// DialHook 建立连接时不做处理
+func (pipelineReplyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 单条命令不做处理
+func (pipelineReplyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return next
+}
+
+// ProcessPipelineHook 在非事务管道前加入PING，执行后返回原命令中的第一个错误
+func (pipelineReplyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if len(cmds) < 2 || cmds[0].Name() == "multi" {
+			return next(ctx, cmds)
+		}
+
+		withPing := make([]redis.Cmder, 0, len(cmds)+1)
+		withPing = append(withPing, redis.NewStatusCmd(ctx, "ping"))
+		if err := next(ctx, append(withPing, cmds...)); err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			if err := cmd.Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,826 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RecordedCall 一次被记录的Client调用
+// 录制文件每行是一条调用的JSON，字段名使用缩写以减小文件体积
+type RecordedCall struct {
+	// 调用开始的时间
+	Time time.Time `json:"t"`
+	// 调用耗时
+	Duration time.Duration `json:"d"`
+	// Client的方法名，如"Get"、"HSet"
+	Op string `json:"op"`
+	// 调用的键，不含KeyPrefix
+	Keys []string `json:"k,omitempty"`
+	// 键以外的参数，按参数顺序转换为字符串
+	Args []string `json:"a,omitempty"`
+	// 调用结果的JSON，出错时为空；无序的结果（如SMembers）按字典序排序
+	Result json.RawMessage `json:"r,omitempty"`
+	// 调用返回的错误信息
+	Err string `json:"e,omitempty"`
+}
+
+// RecorderOptions 录制配置
+type RecorderOptions struct {
+	// 采样率，取值(0, 1]，默认1记录所有调用
+	SampleRate float64
+}
+
+// Recorder 将Client调用录制为JSON Lines格式
+// 通过Factory.SetRecorder安装到工厂创建的客户端，或通过NewRecordingClient包装任意客户端；
+// 录制的文件可以用Replay在另一个客户端上重放并比较结果
+type Recorder struct {
+	rate float64
+
+	mu   sync.Mutex
+	enc  *json.Encoder
+	rand *rand.Rand
+	err  error
+
+	count atomic.Int64
+}
+
+// NewRecorder 创建录制器，调用记录写入w，w需要自行关闭
+func NewRecorder(w io.Writer, opts *RecorderOptions) *Recorder {
+	r := &Recorder{
+		rate: 1,
+		enc:  json.NewEncoder(w),
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if opts != nil && opts.SampleRate > 0 && opts.SampleRate < 1 {
+		r.rate = opts.SampleRate
+	}
+	return r
+}
+
+// sample 判断本次调用是否被记录
+func (r *Recorder) sample() bool {
+	if r.rate >= 1 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64() < r.rate
+}
+
+// Record 写入一条调用记录，写入失败后不再写入，错误通过Err获取
+func (r *Recorder) Record(call *RecordedCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if r.err = r.enc.Encode(call); r.err == nil {
+		r.count.Add(1)
+	}
+}
+
+// Count 获取已记录的调用数量
+func (r *Recorder) Count() int64 {
+	return r.count.Load()
+}
+
+// Err 获取第一次写入失败的错误
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// unorderedOps 结果顺序不确定的方法，记录和比较前对结果排序
+var unorderedOps = map[string]bool{
+	"SMembers": true, "SInter": true, "SUnion": true, "SDiff": true,
+	"HKeys": true, "HVals": true, "Keys": true,
+}
+
+// encodeResult 将调用结果编码为JSON
+func encodeResult(op string, val interface{}) json.RawMessage {
+	if unorderedOps[op] {
+		if vals, ok := val.([]string); ok {
+			sorted := append([]string(nil), vals...)
+			sort.Strings(sorted)
+			val = sorted
+		}
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(val))
+	}
+	return data
+}
+
+// formatArg 将参数转换为字符串，与go-redis发送参数的格式一致
+func formatArg(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Duration:
+		return strconv.FormatInt(int64(v), 10)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// formatArgs 将多个参数转换为字符串
+func formatArgs(values ...interface{}) []string {
+	args := make([]string, len(values))
+	for i, v := range values {
+		args[i] = formatArg(v)
+	}
+	return args
+}
+
+// flattenPairs 展开字段值对，单个map参数按字段排序展开
+func flattenPairs(pairs []interface{}) []string {
+	if len(pairs) == 1 {
+		var m map[string]interface{}
+		switch v := pairs[0].(type) {
+		case map[string]interface{}:
+			m = v
+		case map[string]string:
+			m = make(map[string]interface{}, len(v))
+			for field, val := range v {
+				m[field] = val
+			}
+		}
+		if m != nil {
+			fields := make([]string, 0, len(m))
+			for field := range m {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			args := make([]string, 0, len(m)*2)
+			for _, field := range fields {
+				args = append(args, field, formatArg(m[field]))
+			}
+			return args
+		}
+	}
+	return formatArgs(pairs...)
+}
+
+// RecordingClient 录制调用的客户端
+// 记录每次数据命令、脚本和函数调用的时间、耗时、键、参数和结果，键是调用方传入的不含前缀的键。
+// 管道和乐观事务中的命令以及DeleteByPattern不被记录
+type RecordingClient struct {
+	Client
+
+	recorder *Recorder
+}
+
+// NewRecordingClient 创建录制调用的客户端
+func NewRecordingClient(client Client, recorder *Recorder) *RecordingClient {
+	return &RecordingClient{Client: client, recorder: recorder}
+}
+
+// Unwrap 获取被包装的客户端
+func (c *RecordingClient) Unwrap() Client {
+	return c.Client
+}
+
+// record 执行调用并按采样率记录
+func record[T any](c *RecordingClient, op string, keys, args []string, fn func() (T, error)) (T, error) {
+	if !c.recorder.sample() {
+		return fn()
+	}
+	start := time.Now()
+	val, err := fn()
+	call := &RecordedCall{
+		Time:     start,
+		Duration: time.Since(start),
+		Op:       op,
+		Keys:     keys,
+		Args:     args,
+	}
+	if err != nil {
+		call.Err = err.Error()
+	} else {
+		call.Result = encodeResult(op, val)
+	}
+	c.recorder.Record(call)
+	return val, err
+}
+
+// recordErr 执行只返回错误的调用并按采样率记录
+func recordErr(c *RecordingClient, op string, keys, args []string, fn func() error) error {
+	_, err := record(c, op, keys, args, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// keyArgs 单个键的键列表
+func keyArgs(key string) []string {
+	return []string{key}
+}
+
+// 基础操作
+
+// Ping 检查连接
+func (c *RecordingClient) Ping(ctx context.Context) error {
+	return recordErr(c, "Ping", nil, nil, func() error {
+		return c.Client.Ping(ctx)
+	})
+}
+
+// 字符串操作
+
+// Get 获取字符串值
+func (c *RecordingClient) Get(ctx context.Context, key string) (string, error) {
+	return record(c, "Get", keyArgs(key), nil, func() (string, error) {
+		return c.Client.Get(ctx, key)
+	})
+}
+
+// Set 设置字符串值
+func (c *RecordingClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return recordErr(c, "Set", keyArgs(key), formatArgs(value, expiration), func() error {
+		return c.Client.Set(ctx, key, value, expiration)
+	})
+}
+
+// SetNX 仅当键不存在时设置值
+func (c *RecordingClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return record(c, "SetNX", keyArgs(key), formatArgs(value, expiration), func() (bool, error) {
+		return c.Client.SetNX(ctx, key, value, expiration)
+	})
+}
+
+// GetSet 设置新值并返回旧值
+func (c *RecordingClient) GetSet(ctx context.Context, key string, value interface{}) (string, error) {
+	return record(c, "GetSet", keyArgs(key), formatArgs(value), func() (string, error) {
+		return c.Client.GetSet(ctx, key, value)
+	})
+}
+
+// MGet 批量获取多个键的值
+func (c *RecordingClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return record(c, "MGet", keys, nil, func() ([]interface{}, error) {
+		return c.Client.MGet(ctx, keys...)
+	})
+}
+
+// MSet 批量设置多个键值对，键记录在Keys中，值按顺序记录在Args中
+func (c *RecordingClient) MSet(ctx context.Context, pairs ...interface{}) error {
+	keys := make([]string, 0, len(pairs)/2)
+	values := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		keys = append(keys, formatArg(pairs[i]))
+		values = append(values, formatArg(pairs[i+1]))
+	}
+	return recordErr(c, "MSet", keys, values, func() error {
+		return c.Client.MSet(ctx, pairs...)
+	})
+}
+
+// Incr 递增
+func (c *RecordingClient) Incr(ctx context.Context, key string) (int64, error) {
+	return record(c, "Incr", keyArgs(key), nil, func() (int64, error) {
+		return c.Client.Incr(ctx, key)
+	})
+}
+
+// IncrBy 按指定值递增
+func (c *RecordingClient) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	return record(c, "IncrBy", keyArgs(key), formatArgs(value), func() (int64, error) {
+		return c.Client.IncrBy(ctx, key, value)
+	})
+}
+
+// Decr 递减
+func (c *RecordingClient) Decr(ctx context.Context, key string) (int64, error) {
+	return record(c, "Decr", keyArgs(key), nil, func() (int64, error) {
+		return c.Client.Decr(ctx, key)
+	})
+}
+
+// DecrBy 按指定值递减
+func (c *RecordingClient) DecrBy(ctx context.Context, key string, value int64) (int64, error) {
+	return record(c, "DecrBy", keyArgs(key), formatArgs(value), func() (int64, error) {
+		return c.Client.DecrBy(ctx, key, value)
+	})
+}
+
+// 哈希表操作
+
+// HGet 获取哈希表字段值
+func (c *RecordingClient) HGet(ctx context.Context, key, field string) (string, error) {
+	return record(c, "HGet", keyArgs(key), formatArgs(field), func() (string, error) {
+		return c.Client.HGet(ctx, key, field)
+	})
+}
+
+// HSet 设置哈希表字段值
+func (c *RecordingClient) HSet(ctx context.Context, key, field string, value interface{}) error {
+	return recordErr(c, "HSet", keyArgs(key), formatArgs(field, value), func() error {
+		return c.Client.HSet(ctx, key, field, value)
+	})
+}
+
+// HSetNX 仅当字段不存在时设置哈希表字段值
+func (c *RecordingClient) HSetNX(ctx context.Context, key, field string, value interface{}) (bool, error) {
+	return record(c, "HSetNX", keyArgs(key), formatArgs(field, value), func() (bool, error) {
+		return c.Client.HSetNX(ctx, key, field, value)
+	})
+}
+
+// HDel 删除哈希表字段
+func (c *RecordingClient) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	return record(c, "HDel", keyArgs(key), fields, func() (int64, error) {
+		return c.Client.HDel(ctx, key, fields...)
+	})
+}
+
+// HExists 检查哈希表字段是否存在
+func (c *RecordingClient) HExists(ctx context.Context, key, field string) (bool, error) {
+	return record(c, "HExists", keyArgs(key), formatArgs(field), func() (bool, error) {
+		return c.Client.HExists(ctx, key, field)
+	})
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (c *RecordingClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return record(c, "HGetAll", keyArgs(key), nil, func() (map[string]string, error) {
+		return c.Client.HGetAll(ctx, key)
+	})
+}
+
+// HKeys 获取哈希表所有字段
+func (c *RecordingClient) HKeys(ctx context.Context, key string) ([]string, error) {
+	return record(c, "HKeys", keyArgs(key), nil, func() ([]string, error) {
+		return c.Client.HKeys(ctx, key)
+	})
+}
+
+// HVals 获取哈希表所有值
+func (c *RecordingClient) HVals(ctx context.Context, key string) ([]string, error) {
+	return record(c, "HVals", keyArgs(key), nil, func() ([]string, error) {
+		return c.Client.HVals(ctx, key)
+	})
+}
+
+// HLen 获取哈希表字段数量
+func (c *RecordingClient) HLen(ctx context.Context, key string) (int64, error) {
+	return record(c, "HLen", keyArgs(key), nil, func() (int64, error) {
+		return c.Client.HLen(ctx, key)
+	})
+}
+
+// HMGet 批量获取哈希表字段值
+func (c *RecordingClient) HMGet(ctx context.Context, key string, fields ...string) ([]interface{}, error) {
+	return record(c, "HMGet", keyArgs(key), fields, func() ([]interface{}, error) {
+		return c.Client.HMGet(ctx, key, fields...)
+	})
+}
+
+// HMSet 批量设置哈希表字段值，map参数按字段排序展开为字段值对
+func (c *RecordingClient) HMSet(ctx context.Context, key string, pairs ...interface{}) error {
+	return recordErr(c, "HMSet", keyArgs(key), flattenPairs(pairs), func() error {
+		return c.Client.HMSet(ctx, key, pairs...)
+	})
+}
+
+// HIncrBy 递增哈希表字段值
+func (c *RecordingClient) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	return record(c, "HIncrBy", keyArgs(key), formatArgs(field, incr), func() (int64, error) {
+		return c.Client.HIncrBy(ctx, key, field, incr)
+	})
+}
+
+// 列表操作
+
+// LPush 从列表左侧推入元素
+func (c *RecordingClient) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	return record(c, "LPush", keyArgs(key), formatArgs(values...), func() (int64, error) {
+		return c.Client.LPush(ctx, key, values...)
+	})
+}
+
+// RPush 从列表右侧推入元素
+func (c *RecordingClient) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	return record(c, "RPush", keyArgs(key), formatArgs(values...), func() (int64, error) {
+		return c.Client.RPush(ctx, key, values...)
+	})
+}
+
+// LPop 从列表左侧弹出元素
+func (c *RecordingClient) LPop(ctx context.Context, key string) (string, error) {
+	return record(c, "LPop", keyArgs(key), nil, func() (string, error) {
+		return c.Client.LPop(ctx, key)
+	})
+}
+
+// RPop 从列表右侧弹出元素
+func (c *RecordingClient) RPop(ctx context.Context, key string) (string, error) {
+	return record(c, "RPop", keyArgs(key), nil, func() (string, error) {
+		return c.Client.RPop(ctx, key)
+	})
+}
+
+// LLen 获取列表长度
+func (c *RecordingClient) LLen(ctx context.Context, key string) (int64, error) {
+	return record(c, "LLen", keyArgs(key), nil, func() (int64, error) {
+		return c.Client.LLen(ctx, key)
+	})
+}
+
+// LRange 获取列表指定范围的元素
+func (c *RecordingClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return record(c, "LRange", keyArgs(key), formatArgs(start, stop), func() ([]string, error) {
+		return c.Client.LRange(ctx, key, start, stop)
+	})
+}
+
+// LIndex 获取列表指定位置的元素
+func (c *RecordingClient) LIndex(ctx context.Context, key string, index int64) (string, error) {
+	return record(c, "LIndex", keyArgs(key), formatArgs(index), func() (string, error) {
+		return c.Client.LIndex(ctx, key, index)
+	})
+}
+
+// LSet 设置列表指定位置的元素
+func (c *RecordingClient) LSet(ctx context.Context, key string, index int64, value interface{}) error {
+	return recordErr(c, "LSet", keyArgs(key), formatArgs(index, value), func() error {
+		return c.Client.LSet(ctx, key, index, value)
+	})
+}
+
+// LRem 删除列表中的元素
+func (c *RecordingClient) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
+	return record(c, "LRem", keyArgs(key), formatArgs(count, value), func() (int64, error) {
+		return c.Client.LRem(ctx, key, count, value)
+	})
+}
+
+// LTrim 修剪列表
+func (c *RecordingClient) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return recordErr(c, "LTrim", keyArgs(key), formatArgs(start, stop), func() error {
+		return c.Client.LTrim(ctx, key, start, stop)
+	})
+}
+
+// 集合操作
+
+// SAdd 向集合添加成员
+func (c *RecordingClient) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return record(c, "SAdd", keyArgs(key), formatArgs(members...), func() (int64, error) {
+		return c.Client.SAdd(ctx, key, members...)
+	})
+}
+
+// SRem 从集合删除成员
+func (c *RecordingClient) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return record(c, "SRem", keyArgs(key), formatArgs(members...), func() (int64, error) {
+		return c.Client.SRem(ctx, key, members...)
+	})
+}
+
+// SMembers 获取集合所有成员
+func (c *RecordingClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	return record(c, "SMembers", keyArgs(key), nil, func() ([]string, error) {
+		return c.Client.SMembers(ctx, key)
+	})
+}
+
+// SIsMember 检查是否为集合成员
+func (c *RecordingClient) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
+	return record(c, "SIsMember", keyArgs(key), formatArgs(member), func() (bool, error) {
+		return c.Client.SIsMember(ctx, key, member)
+	})
+}
+
+// SCard 获取集合成员数量
+func (c *RecordingClient) SCard(ctx context.Context, key string) (int64, error) {
+	return record(c, "SCard", keyArgs(key), nil, func() (int64, error) {
+		return c.Client.SCard(ctx, key)
+	})
+}
+
+// SPop 随机弹出集合成员
+func (c *RecordingClient) SPop(ctx context.Context, key string) (string, error) {
+	return record(c, "SPop", keyArgs(key), nil, func() (string, error) {
+		return c.Client.SPop(ctx, key)
+	})
+}
+
+// SRandMember 随机获取集合成员
+func (c *RecordingClient) SRandMember(ctx context.Context, key string) (string, error) {
+	return record(c, "SRandMember", keyArgs(key), nil, func() (string, error) {
+		return c.Client.SRandMember(ctx, key)
+	})
+}
+
+// SInter 获取多个集合的交集
+func (c *RecordingClient) SInter(ctx context.Context, keys ...string) ([]string, error) {
+	return record(c, "SInter", keys, nil, func() ([]string, error) {
+		return c.Client.SInter(ctx, keys...)
+	})
+}
+
+// SUnion 获取多个集合的并集
+func (c *RecordingClient) SUnion(ctx context.Context, keys ...string) ([]string, error) {
+	return record(c, "SUnion", keys, nil, func() ([]string, error) {
+		return c.Client.SUnion(ctx, keys...)
+	})
+}
+
+// SDiff 获取多个集合的差集
+func (c *RecordingClient) SDiff(ctx context.Context, keys ...string) ([]string, error) {
+	return record(c, "SDiff", keys, nil, func() ([]string, error) {
+		return c.Client.SDiff(ctx, keys...)
+	})
+}
+
+// 有序集合操作
+
+// ZAdd 向有序集合添加成员，参数按分数、成员交替记录
+func (c *RecordingClient) ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) {
+	args := make([]string, 0, len(members)*2)
+	for _, member := range members {
+		args = append(args, formatArg(member.Score), formatArg(member.Member))
+	}
+	return record(c, "ZAdd", keyArgs(key), args, func() (int64, error) {
+		return c.Client.ZAdd(ctx, key, members...)
+	})
+}
+
+// ZRem 从有序集合删除成员
+func (c *RecordingClient) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return record(c, "ZRem", keyArgs(key), formatArgs(members...), func() (int64, error) {
+		return c.Client.ZRem(ctx, key, members...)
+	})
+}
+
+// ZScore 获取有序集合成员的分数
+func (c *RecordingClient) ZScore(ctx context.Context, key, member string) (float64, error) {
+	return record(c, "ZScore", keyArgs(key), formatArgs(member), func() (float64, error) {
+		return c.Client.ZScore(ctx, key, member)
+	})
+}
+
+// ZRank 获取有序集合成员的排名
+func (c *RecordingClient) ZRank(ctx context.Context, key, member string) (int64, error) {
+	return record(c, "ZRank", keyArgs(key), formatArgs(member), func() (int64, error) {
+		return c.Client.ZRank(ctx, key, member)
+	})
+}
+
+// ZRevRank 获取有序集合成员的倒序排名
+func (c *RecordingClient) ZRevRank(ctx context.Context, key, member string) (int64, error) {
+	return record(c, "ZRevRank", keyArgs(key), formatArgs(member), func() (int64, error) {
+		return c.Client.ZRevRank(ctx, key, member)
+	})
+}
+
+// ZRange 按排名范围获取有序集合成员
+func (c *RecordingClient) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return record(c, "ZRange", keyArgs(key), formatArgs(start, stop), func() ([]string, error) {
+		return c.Client.ZRange(ctx, key, start, stop)
+	})
+}
+
+// ZRevRange 按排名范围倒序获取有序集合成员
+func (c *RecordingClient) ZRevRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return record(c, "ZRevRange", keyArgs(key), formatArgs(start, stop), func() ([]string, error) {
+		return c.Client.ZRevRange(ctx, key, start, stop)
+	})
+}
+
+// ZRangeWithScores 按排名范围获取有序集合成员及分数
+func (c *RecordingClient) ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
+	return record(c, "ZRangeWithScores", keyArgs(key), formatArgs(start, stop), func() ([]ZMember, error) {
+		return c.Client.ZRangeWithScores(ctx, key, start, stop)
+	})
+}
+
+// ZRevRangeWithScores 按排名范围倒序获取有序集合成员及分数
+func (c *RecordingClient) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
+	return record(c, "ZRevRangeWithScores", keyArgs(key), formatArgs(start, stop), func() ([]ZMember, error) {
+		return c.Client.ZRevRangeWithScores(ctx, key, start, stop)
+	})
+}
+
+// ZRangeByScore 按分数范围获取有序集合成员
+func (c *RecordingClient) ZRangeByScore(ctx context.Context, key string, min, max string) ([]string, error) {
+	return record(c, "ZRangeByScore", keyArgs(key), formatArgs(min, max), func() ([]string, error) {
+		return c.Client.ZRangeByScore(ctx, key, min, max)
+	})
+}
+
+// ZRevRangeByScore 按分数范围倒序获取有序集合成员
+func (c *RecordingClient) ZRevRangeByScore(ctx context.Context, key string, max, min string) ([]string, error) {
+	return record(c, "ZRevRangeByScore", keyArgs(key), formatArgs(max, min), func() ([]string, error) {
+		return c.Client.ZRevRangeByScore(ctx, key, max, min)
+	})
+}
+
+// ZCard 获取有序集合成员数量
+func (c *RecordingClient) ZCard(ctx context.Context, key string) (int64, error) {
+	return record(c, "ZCard", keyArgs(key), nil, func() (int64, error) {
+		return c.Client.ZCard(ctx, key)
+	})
+}
+
+// ZCount 统计分数范围内的成员数量
+func (c *RecordingClient) ZCount(ctx context.Context, key, min, max string) (int64, error) {
+	return record(c, "ZCount", keyArgs(key), formatArgs(min, max), func() (int64, error) {
+		return c.Client.ZCount(ctx, key, min, max)
+	})
+}
+
+// ZIncrBy 增加有序集合成员的分数
+func (c *RecordingClient) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
+	return record(c, "ZIncrBy", keyArgs(key), formatArgs(increment, member), func() (float64, error) {
+		return c.Client.ZIncrBy(ctx, key, increment, member)
+	})
+}
+
+// 通用键操作
+
+// Del 删除键
+func (c *RecordingClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	return record(c, "Del", keys, nil, func() (int64, error) {
+		return c.Client.Del(ctx, keys...)
+	})
+}
+
+// Exists 检查键是否存在
+func (c *RecordingClient) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return record(c, "Exists", keys, nil, func() (int64, error) {
+		return c.Client.Exists(ctx, keys...)
+	})
+}
+
+// Expire 设置键的过期时间
+func (c *RecordingClient) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	return record(c, "Expire", keyArgs(key), formatArgs(expiration), func() (bool, error) {
+		return c.Client.Expire(ctx, key, expiration)
+	})
+}
+
+// ExpireAt 设置键的过期时间点
+func (c *RecordingClient) ExpireAt(ctx context.Context, key string, tm time.Time) (bool, error) {
+	return record(c, "ExpireAt", keyArgs(key), formatArgs(tm), func() (bool, error) {
+		return c.Client.ExpireAt(ctx, key, tm)
+	})
+}
+
+// TTL 获取键的剩余过期时间
+func (c *RecordingClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return record(c, "TTL", keyArgs(key), nil, func() (time.Duration, error) {
+		return c.Client.TTL(ctx, key)
+	})
+}
+
+// Type 获取键的类型
+func (c *RecordingClient) Type(ctx context.Context, key string) (string, error) {
+	return record(c, "Type", keyArgs(key), nil, func() (string, error) {
+		return c.Client.Type(ctx, key)
+	})
+}
+
+// Keys 查找匹配模式的键，模式记录在Args中
+func (c *RecordingClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return record(c, "Keys", nil, formatArgs(pattern), func() ([]string, error) {
+		return c.Client.Keys(ctx, pattern)
+	})
+}
+
+// scanResult Scan的结果，按[键列表, 游标]编码
+type scanResult struct {
+	keys   []string
+	cursor uint64
+}
+
+// MarshalJSON 编码Scan的结果，键按字典序排序
+func (r scanResult) MarshalJSON() ([]byte, error) {
+	keys := append([]string{}, r.keys...)
+	sort.Strings(keys)
+	return json.Marshal([]interface{}{keys, r.cursor})
+}
+
+// Scan 迭代键，游标、模式和数量记录在Args中
+func (c *RecordingClient) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	result, err := record(c, "Scan", nil, formatArgs(cursor, match, count), func() (scanResult, error) {
+		keys, next, err := c.Client.Scan(ctx, cursor, match, count)
+		return scanResult{keys, next}, err
+	})
+	return result.keys, result.cursor, err
+}
+
+// Lua脚本操作
+
+// Eval 执行Lua脚本，脚本和参数记录在Args中
+func (c *RecordingClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return record(c, "Eval", keys, append([]string{script}, formatArgs(args...)...), func() (interface{}, error) {
+		return c.Client.Eval(ctx, script, keys, args...)
+	})
+}
+
+// EvalSha 通过SHA1执行Lua脚本，SHA1和参数记录在Args中
+func (c *RecordingClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) (interface{}, error) {
+	return record(c, "EvalSha", keys, append([]string{sha1}, formatArgs(args...)...), func() (interface{}, error) {
+		return c.Client.EvalSha(ctx, sha1, keys, args...)
+	})
+}
+
+// ScriptExists 检查脚本是否存在
+func (c *RecordingClient) ScriptExists(ctx context.Context, hashes ...string) ([]bool, error) {
+	return record(c, "ScriptExists", nil, hashes, func() ([]bool, error) {
+		return c.Client.ScriptExists(ctx, hashes...)
+	})
+}
+
+// ScriptFlush 清空脚本缓存
+func (c *RecordingClient) ScriptFlush(ctx context.Context) error {
+	return recordErr(c, "ScriptFlush", nil, nil, func() error {
+		return c.Client.ScriptFlush(ctx)
+	})
+}
+
+// ScriptKill 终止正在执行的脚本
+func (c *RecordingClient) ScriptKill(ctx context.Context) error {
+	return recordErr(c, "ScriptKill", nil, nil, func() error {
+		return c.Client.ScriptKill(ctx)
+	})
+}
+
+// ScriptLoad 加载脚本
+func (c *RecordingClient) ScriptLoad(ctx context.Context, script string) (string, error) {
+	return record(c, "ScriptLoad", nil, formatArgs(script), func() (string, error) {
+		return c.Client.ScriptLoad(ctx, script)
+	})
+}
+
+// Redis Functions操作
+
+// FunctionLoad 加载函数库
+func (c *RecordingClient) FunctionLoad(ctx context.Context, code string, replace bool) (string, error) {
+	return record(c, "FunctionLoad", nil, formatArgs(code, replace), func() (string, error) {
+		return c.Client.FunctionLoad(ctx, code, replace)
+	})
+}
+
+// FunctionDelete 删除函数库
+func (c *RecordingClient) FunctionDelete(ctx context.Context, library string) error {
+	return recordErr(c, "FunctionDelete", nil, formatArgs(library), func() error {
+		return c.Client.FunctionDelete(ctx, library)
+	})
+}
+
+// FunctionList 列出函数库
+func (c *RecordingClient) FunctionList(ctx context.Context, pattern string, withCode bool) ([]FunctionLibrary, error) {
+	return record(c, "FunctionList", nil, formatArgs(pattern, withCode), func() ([]FunctionLibrary, error) {
+		return c.Client.FunctionList(ctx, pattern, withCode)
+	})
+}
+
+// FunctionDump 导出所有函数库
+func (c *RecordingClient) FunctionDump(ctx context.Context) (string, error) {
+	return record(c, "FunctionDump", nil, nil, func() (string, error) {
+		return c.Client.FunctionDump(ctx)
+	})
+}
+
+// FunctionRestore 恢复函数库
+func (c *RecordingClient) FunctionRestore(ctx context.Context, payload string) error {
+	return recordErr(c, "FunctionRestore", nil, formatArgs(payload), func() error {
+		return c.Client.FunctionRestore(ctx, payload)
+	})
+}
+
+// FCall 调用函数，函数名和参数记录在Args中
+func (c *RecordingClient) FCall(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	return record(c, "FCall", keys, append([]string{function}, formatArgs(args...)...), func() (interface{}, error) {
+		return c.Client.FCall(ctx, function, keys, args...)
+	})
+}
+
+// FCallRO 调用只读函数，函数名和参数记录在Args中
+func (c *RecordingClient) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	return record(c, "FCallRO", keys, append([]string{function}, formatArgs(args...)...), func() (interface{}, error) {
+		return c.Client.FCallRO(ctx, function, keys, args...)
+	})
+}
@@ -0,0 +1,582 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ReplayOptions 重放配置
+type ReplayOptions struct {
+	// 按录制时的调用间隔重放，默认连续执行
+	KeepTiming bool
+}
+
+// ReplayDiff 重放结果与录制结果不一致的调用
+type ReplayDiff struct {
+	// 调用在录制文件中的序号，从0开始
+	Index int
+	// 录制的调用
+	Call RecordedCall
+	// 重放的结果，出错时为空
+	Result json.RawMessage
+	// 重放返回的错误信息
+	Err string
+}
+
+// String 格式化差异
+func (d ReplayDiff) String() string {
+	return fmt.Sprintf("#%d %s %v %v: recorded %s, replayed %s",
+		d.Index, d.Call.Op, d.Call.Keys, d.Call.Args,
+		describeOutcome(d.Call.Result, d.Call.Err), describeOutcome(d.Result, d.Err))
+}
+
+// describeOutcome 描述调用的结果或错误
+func describeOutcome(result json.RawMessage, err string) string {
+	if err != "" {
+		return "error " + strconv.Quote(err)
+	}
+	return string(result)
+}
+
+// ReplayReport 重放报告
+type ReplayReport struct {
+	// 重放的调用数量
+	Calls int
+	// 结果一致的调用数量
+	Matched int
+	// 结果不一致的调用
+	Diffs []ReplayDiff
+	// 无法重放的调用数量（录制文件中的方法不被支持）
+	Skipped int
+}
+
+// Replay 读取Recorder录制的调用，按顺序在client上重放并比较结果
+// 结果按录制时的方式编码后逐字节比较，错误按错误信息比较。
+// 结果本身不确定的调用（如SPop、SRandMember、TTL）可能出现差异；录制文件格式错误时返回错误
+func Replay(ctx context.Context, client Client, r io.Reader, opts *ReplayOptions) (*ReplayReport, error) {
+	if opts == nil {
+		opts = &ReplayOptions{}
+	}
+	report := &ReplayReport{}
+	dec := json.NewDecoder(r)
+
+	var first, started time.Time
+	for index := 0; ; index++ {
+		var call RecordedCall
+		if err := dec.Decode(&call); err != nil {
+			if errors.Is(err, io.EOF) {
+				return report, nil
+			}
+			return report, fmt.Errorf("cache: replay call %d: %w", index, err)
+		}
+
+		op, ok := replayOps[call.Op]
+		if !ok {
+			report.Skipped++
+			continue
+		}
+
+		if opts.KeepTiming {
+			if first.IsZero() {
+				first, started = call.Time, time.Now()
+			} else if err := sleepUntil(ctx, started.Add(call.Time.Sub(first))); err != nil {
+				return report, err
+			}
+		}
+
+		args := &replayArgs{call: &call}
+		run := op(ctx, client, args)
+		if args.err != nil {
+			return report, fmt.Errorf("cache: replay call %d (%s): %w", index, call.Op, args.err)
+		}
+
+		report.Calls++
+		val, err := run()
+		diff := ReplayDiff{Index: index, Call: call}
+		if err != nil {
+			diff.Err = err.Error()
+		} else {
+			diff.Result = encodeResult(call.Op, val)
+		}
+		if diff.Err == call.Err && bytes.Equal(diff.Result, call.Result) {
+			report.Matched++
+			continue
+		}
+		report.Diffs = append(report.Diffs, diff)
+	}
+}
+
+// sleepUntil 等待到指定时间，上下文先结束时返回上下文的错误
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// replayArgs 解析录制的键和参数，第一个解析错误记录在err中
+type replayArgs struct {
+	call *RecordedCall
+	err  error
+}
+
+// fail 记录解析错误
+func (a *replayArgs) fail(format string, v ...interface{}) {
+	if a.err == nil {
+		a.err = fmt.Errorf(format, v...)
+	}
+}
+
+// key 获取第i个键
+func (a *replayArgs) key(i int) string {
+	if i >= len(a.call.Keys) {
+		a.fail("missing key %d", i)
+		return ""
+	}
+	return a.call.Keys[i]
+}
+
+// keys 获取所有键
+func (a *replayArgs) keys() []string {
+	return a.call.Keys
+}
+
+// str 获取第i个参数
+func (a *replayArgs) str(i int) string {
+	if i >= len(a.call.Args) {
+		a.fail("missing argument %d", i)
+		return ""
+	}
+	return a.call.Args[i]
+}
+
+// strs 获取第from个参数之后的所有参数
+func (a *replayArgs) strs(from int) []string {
+	if from > len(a.call.Args) {
+		a.fail("missing argument %d", from)
+		return nil
+	}
+	return a.call.Args[from:]
+}
+
+// values 获取第from个参数之后的所有参数，用于interface{}类型的可变参数
+func (a *replayArgs) values(from int) []interface{} {
+	args := a.strs(from)
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+	return values
+}
+
+// int 将第i个参数解析为整数
+func (a *replayArgs) int(i int) int64 {
+	n, err := strconv.ParseInt(a.str(i), 10, 64)
+	if err != nil {
+		a.fail("argument %d: %w", i, err)
+	}
+	return n
+}
+
+// uint 将第i个参数解析为无符号整数
+func (a *replayArgs) uint(i int) uint64 {
+	n, err := strconv.ParseUint(a.str(i), 10, 64)
+	if err != nil {
+		a.fail("argument %d: %w", i, err)
+	}
+	return n
+}
+
+// float 将第i个参数解析为浮点数
+func (a *replayArgs) float(i int) float64 {
+	f, err := strconv.ParseFloat(a.str(i), 64)
+	if err != nil {
+		a.fail("argument %d: %w", i, err)
+	}
+	return f
+}
+
+// duration 将第i个参数解析为纳秒表示的时长
+func (a *replayArgs) duration(i int) time.Duration {
+	return time.Duration(a.int(i))
+}
+
+// bool 将第i个参数解析为布尔值
+func (a *replayArgs) bool(i int) bool {
+	return a.str(i) == "1"
+}
+
+// time 将第i个参数解析为RFC3339格式的时间
+func (a *replayArgs) time(i int) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, a.str(i))
+	if err != nil {
+		a.fail("argument %d: %w", i, err)
+	}
+	return t
+}
+
+// replayFunc 解析调用的参数，返回在客户端上执行调用的函数
+type replayFunc func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error)
+
+// replayResult 将调用的结果转换为通用类型
+func replayResult[T any](val T, err error) (interface{}, error) {
+	return val, err
+}
+
+// replayStatus 将只返回错误的调用的结果转换为通用类型，与录制时的编码一致
+func replayStatus(err error) (interface{}, error) {
+	return struct{}{}, err
+}
+
+// replayOps 可以重放的方法，参数的解析方式与RecordingClient的记录方式对应
+var replayOps = map[string]replayFunc{
+	"Ping": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		return func() (interface{}, error) { return replayStatus(c.Ping(ctx)) }
+	},
+
+	// 字符串操作
+	"Get": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.Get(ctx, key)) }
+	},
+	"Set": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, value, expiration := a.key(0), a.str(0), a.duration(1)
+		return func() (interface{}, error) { return replayStatus(c.Set(ctx, key, value, expiration)) }
+	},
+	"SetNX": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, value, expiration := a.key(0), a.str(0), a.duration(1)
+		return func() (interface{}, error) { return replayResult(c.SetNX(ctx, key, value, expiration)) }
+	},
+	"GetSet": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, value := a.key(0), a.str(0)
+		return func() (interface{}, error) { return replayResult(c.GetSet(ctx, key, value)) }
+	},
+	"MGet": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		keys := a.keys()
+		return func() (interface{}, error) { return replayResult(c.MGet(ctx, keys...)) }
+	},
+	"MSet": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		keys := a.keys()
+		pairs := make([]interface{}, 0, len(keys)*2)
+		for i, key := range keys {
+			pairs = append(pairs, key, a.str(i))
+		}
+		return func() (interface{}, error) { return replayStatus(c.MSet(ctx, pairs...)) }
+	},
+	"Incr": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.Incr(ctx, key)) }
+	},
+	"IncrBy": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, value := a.key(0), a.int(0)
+		return func() (interface{}, error) { return replayResult(c.IncrBy(ctx, key, value)) }
+	},
+	"Decr": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.Decr(ctx, key)) }
+	},
+	"DecrBy": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, value := a.key(0), a.int(0)
+		return func() (interface{}, error) { return replayResult(c.DecrBy(ctx, key, value)) }
+	},
+
+	// 哈希表操作
+	"HGet": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, field := a.key(0), a.str(0)
+		return func() (interface{}, error) { return replayResult(c.HGet(ctx, key, field)) }
+	},
+	"HSet": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, field, value := a.key(0), a.str(0), a.str(1)
+		return func() (interface{}, error) { return replayStatus(c.HSet(ctx, key, field, value)) }
+	},
+	"HSetNX": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, field, value := a.key(0), a.str(0), a.str(1)
+		return func() (interface{}, error) { return replayResult(c.HSetNX(ctx, key, field, value)) }
+	},
+	"HDel": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, fields := a.key(0), a.strs(0)
+		return func() (interface{}, error) { return replayResult(c.HDel(ctx, key, fields...)) }
+	},
+	"HExists": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, field := a.key(0), a.str(0)
+		return func() (interface{}, error) { return replayResult(c.HExists(ctx, key, field)) }
+	},
+	"HGetAll": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.HGetAll(ctx, key)) }
+	},
+	"HKeys": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.HKeys(ctx, key)) }
+	},
+	"HVals": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.HVals(ctx, key)) }
+	},
+	"HLen": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.HLen(ctx, key)) }
+	},
+	"HMGet": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, fields := a.key(0), a.strs(0)
+		return func() (interface{}, error) { return replayResult(c.HMGet(ctx, key, fields...)) }
+	},
+	"HMSet": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, pairs := a.key(0), a.values(0)
+		return func() (interface{}, error) { return replayStatus(c.HMSet(ctx, key, pairs...)) }
+	},
+	"HIncrBy": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, field, incr := a.key(0), a.str(0), a.int(1)
+		return func() (interface{}, error) { return replayResult(c.HIncrBy(ctx, key, field, incr)) }
+	},
+
+	// 列表操作
+	"LPush": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, values := a.key(0), a.values(0)
+		return func() (interface{}, error) { return replayResult(c.LPush(ctx, key, values...)) }
+	},
+	"RPush": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, values := a.key(0), a.values(0)
+		return func() (interface{}, error) { return replayResult(c.RPush(ctx, key, values...)) }
+	},
+	"LPop": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.LPop(ctx, key)) }
+	},
+	"RPop": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.RPop(ctx, key)) }
+	},
+	"LLen": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.LLen(ctx, key)) }
+	},
+	"LRange": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, start, stop := a.key(0), a.int(0), a.int(1)
+		return func() (interface{}, error) { return replayResult(c.LRange(ctx, key, start, stop)) }
+	},
+	"LIndex": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, index := a.key(0), a.int(0)
+		return func() (interface{}, error) { return replayResult(c.LIndex(ctx, key, index)) }
+	},
+	"LSet": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, index, value := a.key(0), a.int(0), a.str(1)
+		return func() (interface{}, error) { return replayStatus(c.LSet(ctx, key, index, value)) }
+	},
+	"LRem": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, count, value := a.key(0), a.int(0), a.str(1)
+		return func() (interface{}, error) { return replayResult(c.LRem(ctx, key, count, value)) }
+	},
+	"LTrim": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, start, stop := a.key(0), a.int(0), a.int(1)
+		return func() (interface{}, error) { return replayStatus(c.LTrim(ctx, key, start, stop)) }
+	},
+
+	// 集合操作
+	"SAdd": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, members := a.key(0), a.values(0)
+		return func() (interface{}, error) { return replayResult(c.SAdd(ctx, key, members...)) }
+	},
+	"SRem": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, members := a.key(0), a.values(0)
+		return func() (interface{}, error) { return replayResult(c.SRem(ctx, key, members...)) }
+	},
+	"SMembers": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.SMembers(ctx, key)) }
+	},
+	"SIsMember": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, member := a.key(0), a.str(0)
+		return func() (interface{}, error) { return replayResult(c.SIsMember(ctx, key, member)) }
+	},
+	"SCard": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.SCard(ctx, key)) }
+	},
+	"SPop": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.SPop(ctx, key)) }
+	},
+	"SRandMember": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.SRandMember(ctx, key)) }
+	},
+	"SInter": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		keys := a.keys()
+		return func() (interface{}, error) { return replayResult(c.SInter(ctx, keys...)) }
+	},
+	"SUnion": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		keys := a.keys()
+		return func() (interface{}, error) { return replayResult(c.SUnion(ctx, keys...)) }
+	},
+	"SDiff": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		keys := a.keys()
+		return func() (interface{}, error) { return replayResult(c.SDiff(ctx, keys...)) }
+	},
+
+	// 有序集合操作
+	"ZAdd": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		members := make([]ZMember, 0, len(a.call.Args)/2)
+		for i := 0; i+1 < len(a.call.Args); i += 2 {
+			members = append(members, ZMember{Score: a.float(i), Member: a.str(i + 1)})
+		}
+		return func() (interface{}, error) { return replayResult(c.ZAdd(ctx, key, members...)) }
+	},
+	"ZRem": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, members := a.key(0), a.values(0)
+		return func() (interface{}, error) { return replayResult(c.ZRem(ctx, key, members...)) }
+	},
+	"ZScore": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, member := a.key(0), a.str(0)
+		return func() (interface{}, error) { return replayResult(c.ZScore(ctx, key, member)) }
+	},
+	"ZRank": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, member := a.key(0), a.str(0)
+		return func() (interface{}, error) { return replayResult(c.ZRank(ctx, key, member)) }
+	},
+	"ZRevRank": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, member := a.key(0), a.str(0)
+		return func() (interface{}, error) { return replayResult(c.ZRevRank(ctx, key, member)) }
+	},
+	"ZRange": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, start, stop := a.key(0), a.int(0), a.int(1)
+		return func() (interface{}, error) { return replayResult(c.ZRange(ctx, key, start, stop)) }
+	},
+	"ZRevRange": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, start, stop := a.key(0), a.int(0), a.int(1)
+		return func() (interface{}, error) { return replayResult(c.ZRevRange(ctx, key, start, stop)) }
+	},
+	"ZRangeWithScores": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, start, stop := a.key(0), a.int(0), a.int(1)
+		return func() (interface{}, error) { return replayResult(c.ZRangeWithScores(ctx, key, start, stop)) }
+	},
+	"ZRevRangeWithScores": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, start, stop := a.key(0), a.int(0), a.int(1)
+		return func() (interface{}, error) { return replayResult(c.ZRevRangeWithScores(ctx, key, start, stop)) }
+	},
+	"ZRangeByScore": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, min, max := a.key(0), a.str(0), a.str(1)
+		return func() (interface{}, error) { return replayResult(c.ZRangeByScore(ctx, key, min, max)) }
+	},
+	"ZRevRangeByScore": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, max, min := a.key(0), a.str(0), a.str(1)
+		return func() (interface{}, error) { return replayResult(c.ZRevRangeByScore(ctx, key, max, min)) }
+	},
+	"ZCard": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.ZCard(ctx, key)) }
+	},
+	"ZCount": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, min, max := a.key(0), a.str(0), a.str(1)
+		return func() (interface{}, error) { return replayResult(c.ZCount(ctx, key, min, max)) }
+	},
+	"ZIncrBy": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, increment, member := a.key(0), a.float(0), a.str(1)
+		return func() (interface{}, error) { return replayResult(c.ZIncrBy(ctx, key, increment, member)) }
+	},
+
+	// 通用键操作
+	"Del": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		keys := a.keys()
+		return func() (interface{}, error) { return replayResult(c.Del(ctx, keys...)) }
+	},
+	"Exists": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		keys := a.keys()
+		return func() (interface{}, error) { return replayResult(c.Exists(ctx, keys...)) }
+	},
+	"Expire": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, expiration := a.key(0), a.duration(0)
+		return func() (interface{}, error) { return replayResult(c.Expire(ctx, key, expiration)) }
+	},
+	"ExpireAt": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key, tm := a.key(0), a.time(0)
+		return func() (interface{}, error) { return replayResult(c.ExpireAt(ctx, key, tm)) }
+	},
+	"TTL": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.TTL(ctx, key)) }
+	},
+	"Type": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		key := a.key(0)
+		return func() (interface{}, error) { return replayResult(c.Type(ctx, key)) }
+	},
+	"Keys": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		pattern := a.str(0)
+		return func() (interface{}, error) { return replayResult(c.Keys(ctx, pattern)) }
+	},
+	"Scan": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		cursor, match, count := a.uint(0), a.str(1), a.int(2)
+		return func() (interface{}, error) {
+			keys, next, err := c.Scan(ctx, cursor, match, count)
+			return scanResult{keys, next}, err
+		}
+	},
+
+	// Lua脚本操作
+	"Eval": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		script, keys, args := a.str(0), a.keys(), a.values(1)
+		return func() (interface{}, error) { return c.Eval(ctx, script, keys, args...) }
+	},
+	"EvalSha": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		sha1, keys, args := a.str(0), a.keys(), a.values(1)
+		return func() (interface{}, error) { return c.EvalSha(ctx, sha1, keys, args...) }
+	},
+	"ScriptExists": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		hashes := a.strs(0)
+		return func() (interface{}, error) { return replayResult(c.ScriptExists(ctx, hashes...)) }
+	},
+	"ScriptFlush": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		return func() (interface{}, error) { return replayStatus(c.ScriptFlush(ctx)) }
+	},
+	"ScriptKill": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		return func() (interface{}, error) { return replayStatus(c.ScriptKill(ctx)) }
+	},
+	"ScriptLoad": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		script := a.str(0)
+		return func() (interface{}, error) { return replayResult(c.ScriptLoad(ctx, script)) }
+	},
+
+	// Redis Functions操作
+	"FunctionLoad": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		code, replace := a.str(0), a.bool(1)
+		return func() (interface{}, error) { return replayResult(c.FunctionLoad(ctx, code, replace)) }
+	},
+	"FunctionDelete": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		library := a.str(0)
+		return func() (interface{}, error) { return replayStatus(c.FunctionDelete(ctx, library)) }
+	},
+	"FunctionList": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		pattern, withCode := a.str(0), a.bool(1)
+		return func() (interface{}, error) { return replayResult(c.FunctionList(ctx, pattern, withCode)) }
+	},
+	"FunctionDump": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		return func() (interface{}, error) { return replayResult(c.FunctionDump(ctx)) }
+	},
+	"FunctionRestore": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		payload := a.str(0)
+		return func() (interface{}, error) { return replayStatus(c.FunctionRestore(ctx, payload)) }
+	},
+	"FCall": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		function, keys, args := a.str(0), a.keys(), a.values(1)
+		return func() (interface{}, error) { return c.FCall(ctx, function, keys, args...) }
+	},
+	"FCallRO": func(ctx context.Context, c Client, a *replayArgs) func() (interface{}, error) {
+		function, keys, args := a.str(0), a.keys(), a.values(1)
+		return func() (interface{}, error) { return c.FCallRO(ctx, function, keys, args...) }
+	},
+}
@@ -0,0 +1,351 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RetryPolicy 重试策略
+type RetryPolicy interface {
+	// MaxRetries 最大重试次数，不包括第一次执行
+	MaxRetries() int
+	// Backoff 返回第attempt次重试（从1开始）前的等待时间，last为上一次的等待时间
+	Backoff(attempt int, last time.Duration) time.Duration
+	// ShouldRetry 判断命令执行失败后是否可以重试，cmd为小写的命令名
+	ShouldRetry(cmd string, err error) bool
+}
+
+// RetryStrategy 内置重试策略名称
+type RetryStrategy string
+
+const (
+	// RetryExponential 带抖动的指数退避
+	RetryExponential RetryStrategy = "exponential"
+	// RetryDecorrelated 去相关抖动退避
+	RetryDecorrelated RetryStrategy = "decorrelated"
+	// RetryNone 不重试
+	RetryNone RetryStrategy = "none"
+)
+
+// nonIdempotentCommands 重复执行会改变结果的命令
+var nonIdempotentCommands = map[string]bool{
+	"incr": true, "incrby": true, "incrbyfloat": true, "decr": true, "decrby": true,
+	"hincrby": true, "hincrbyfloat": true, "zincrby": true,
+	"lpush": true, "rpush": true, "lpushx": true, "rpushx": true, "linsert": true,
+	"lpop": true, "rpop": true, "rpoplpush": true, "lmove": true, "lrem": true,
+	"blpop": true, "brpop": true, "spop": true, "smove": true, "zpopmin": true, "zpopmax": true,
+	"getset": true, "getdel": true, "append": true, "setnx": true, "hsetnx": true,
+	"eval": true, "evalsha": true, "fcall": true, "xadd": true, "publish": true,
+}
+
+// IsIdempotent 判断命令重复执行是否安全，cmd为命令名，不区分大小写
+func IsIdempotent(cmd string) bool {
+	return !nonIdempotentCommands[strings.ToLower(cmd)]
+}
+
+// IsRetryableError 判断错误是否值得重试
+// 连接错误、读写超时以及LOADING/TRYAGAIN等临时性服务端错误可以重试，
+// 调用方取消或超时、熔断以及客户端已关闭不重试
+func IsRetryableError(err error) bool {
+	if err == nil || err == redis.Nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrClientClosed) || errors.Is(err, redis.ErrClosed) {
+		return false
+	}
+
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return redis.HasErrorPrefix(err, "LOADING") ||
+			redis.HasErrorPrefix(err, "READONLY") ||
+			redis.HasErrorPrefix(err, "TRYAGAIN") ||
+			redis.HasErrorPrefix(err, "CLUSTERDOWN") ||
+			redis.HasErrorPrefix(err, "MASTERDOWN")
+	}
+	return IsUnavailableError(err)
+}
+
+// isNotSentError 判断命令是否确定没有发送到服务端
+// 只有这类错误对非幂等命令重试是安全的
+func isNotSentError(err error) bool {
+	if errors.Is(err, redis.ErrPoolTimeout) || errors.Is(err, redis.ErrPoolExhausted) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isReplyError 判断错误是否为服务端对某条命令的回复，包括redis.Nil
+func isReplyError(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr)
+}
+
+// shouldRetryCommand 按幂等性判断命令是否可以重试
+func shouldRetryCommand(cmd string, err error, retryNonIdempotent bool) bool {
+	if !IsRetryableError(err) {
+		return false
+	}
+	if retryNonIdempotent || IsIdempotent(cmd) {
+		return true
+	}
+	return isNotSentError(err)
+}
+
+// ExponentialBackoff 带全抖动的指数退避策略
+// 第n次重试的等待时间在[0, min(MaxBackoff, MinBackoff*2^(n-1))]之间随机
+type ExponentialBackoff struct {
+	// 最大重试次数
+	Retries int
+	// 最小退避时间
+	MinBackoff time.Duration
+	// 最大退避时间
+	MaxBackoff time.Duration
+	// 是否重试非幂等命令，默认只在确定命令未发送时重试
+	RetryNonIdempotent bool
+}
+
+// MaxRetries 最大重试次数
+func (p *ExponentialBackoff) MaxRetries() int {
+	return p.Retries
+}
+
+// Backoff 计算退避时间
+func (p *ExponentialBackoff) Backoff(attempt int, last time.Duration) time.Duration {
+	if p.MinBackoff <= 0 {
+		return 0
+	}
+
+	ceiling := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || ceiling < p.MaxBackoff); i++ {
+		ceiling *= 2
+	}
+	if p.MaxBackoff > 0 && ceiling > p.MaxBackoff {
+		ceiling = p.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// ShouldRetry 判断是否重试
+func (p *ExponentialBackoff) ShouldRetry(cmd string, err error) bool {
+	return shouldRetryCommand(cmd, err, p.RetryNonIdempotent)
+}
+
+// DecorrelatedJitter 去相关抖动退避策略
+// 等待时间在[Base, 上一次等待时间*3]之间随机，且不超过Max
+type DecorrelatedJitter struct {
+	// 最大重试次数
+	Retries int
+	// 基础退避时间
+	Base time.Duration
+	// 最大退避时间
+	Max time.Duration
+	// 是否重试非幂等命令，默认只在确定命令未发送时重试
+	RetryNonIdempotent bool
+}
+
+// MaxRetries 最大重试次数
+func (p *DecorrelatedJitter) MaxRetries() int {
+	return p.Retries
+}
+
+// Backoff 计算退避时间
+func (p *DecorrelatedJitter) Backoff(attempt int, last time.Duration) time.Duration {
+	if p.Base <= 0 {
+		return 0
+	}
+	if last < p.Base {
+		last = p.Base
+	}
+
+	upper := last * 3
+	backoff := p.Base + time.Duration(rand.Int63n(int64(upper-p.Base)+1))
+	if p.Max > 0 && backoff > p.Max {
+		backoff = p.Max
+	}
+	return backoff
+}
+
+// ShouldRetry 判断是否重试
+func (p *DecorrelatedJitter) ShouldRetry(cmd string, err error) bool {
+	return shouldRetryCommand(cmd, err, p.RetryNonIdempotent)
+}
+
+// noRetry 不重试策略
+type noRetry struct{}
+
+func (noRetry) MaxRetries() int                                       { return 0 }
+func (noRetry) Backoff(attempt int, last time.Duration) time.Duration { return 0 }
+func (noRetry) ShouldRetry(cmd string, err error) bool                { return false }
+
+// NoRetry 不重试策略
+var NoRetry RetryPolicy = noRetry{}
+
+// NewRetryPolicy 根据配置创建内置重试策略
+// MaxRetries为-1时不重试，为0时使用默认的3次
+func NewRetryPolicy(config *CommonConfig) RetryPolicy {
+	retries := config.MaxRetries
+	switch {
+	case retries < 0:
+		return NoRetry
+	case retries == 0:
+		retries = 3
+	}
+
+	switch config.RetryStrategy {
+	case RetryNone:
+		return NoRetry
+	case RetryDecorrelated:
+		return &DecorrelatedJitter{
+			Retries: retries,
+			Base:    config.MinRetryBackoff,
+			Max:     config.MaxRetryBackoff,
+		}
+	default:
+		return &ExponentialBackoff{
+			Retries:    retries,
+			MinBackoff: config.MinRetryBackoff,
+			MaxBackoff: config.MaxRetryBackoff,
+		}
+	}
+}
+
+// retryPolicyKey 上下文中重试策略的键
+type retryPolicyKey struct{}
+
+// WithRetryPolicy 为本次调用指定重试策略，覆盖客户端的默认策略
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// retryPolicyFromContext 获取上下文中的重试策略
+func retryPolicyFromContext(ctx context.Context, fallback RetryPolicy) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok && policy != nil {
+		return policy
+	}
+	return fallback
+}
+
+// RetryError 重试后仍然失败的错误，记录尝试次数
+type RetryError struct {
+	// 命令名
+	Cmd string
+	// 总执行次数，包括第一次执行
+	Attempts int
+	// 最后一次的错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("redis: %s failed after %d attempts: %v", e.Cmd, e.Attempts, e.Err)
+}
+
+// Unwrap 返回最后一次的错误
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryHook 按重试策略重试命令
+type retryHook struct {
+	policy RetryPolicy
+}
+
+// DialHook 建立连接时不做处理
+func (h retryHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 单条命令按策略重试
+func (h retryHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		policy := retryPolicyFromContext(ctx, h.policy)
+		name := cmd.Name()
+		return runWithRetry(ctx, policy, name, func() error {
+			return next(ctx, cmd)
+		}, func(err error) {
+			cmd.SetErr(err)
+		}, func(err error) bool {
+			return policy.ShouldRetry(name, err)
+		})
+	}
+}
+
+// ProcessPipelineHook 管道只有在所有命令都可重试时才整体重试
+func (h retryHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		policy := retryPolicyFromContext(ctx, h.policy)
+		err := runWithRetry(ctx, policy, "pipeline", func() error {
+			return next(ctx, cmds)
+		}, func(err error) {
+			for _, cmd := range cmds {
+				if cmd.Err() != nil {
+					cmd.SetErr(err)
+				}
+			}
+		}, func(err error) bool {
+			for _, cmd := range cmds {
+				switch name := cmd.Name(); name {
+				case "multi", "exec":
+				default:
+					if !policy.ShouldRetry(name, err) {
+						return false
+					}
+				}
+			}
+			return true
+		})
+		// 连接失败时go-redis不会为未执行的命令设置错误，补充设置以便通过命令对象读取；
+		// 服务端的回复错误（包括redis.Nil）只属于返回它的命令，其余命令已经正常执行
+		if err != nil && !isReplyError(err) {
+			for _, cmd := range cmds {
+				if cmd.Err() == nil {
+					cmd.SetErr(err)
+				}
+			}
+		}
+		return err
+	}
+}
+
+// runWithRetry 执行并按策略重试，重试过的失败结果包装为RetryError，不可重试的回复错误除外
+func runWithRetry(ctx context.Context, policy RetryPolicy, name string, run func() error, setErr func(error), shouldRetry func(error) bool) error {
+	var backoff time.Duration
+	for attempt := 0; ; attempt++ {
+		err := run()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= policy.MaxRetries() || !shouldRetry(err) {
+			// 服务端的回复错误（如redis.Nil）原样返回，调用方按错误值判断未命中等情况
+			if attempt > 0 && (IsRetryableError(err) || !isReplyError(err)) {
+				err = &RetryError{Cmd: name, Attempts: attempt + 1, Err: err}
+				setErr(err)
+			}
+			return err
+		}
+
+		backoff = policy.Backoff(attempt+1, backoff)
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				err = &RetryError{Cmd: name, Attempts: attempt + 1, Err: err}
+				setErr(err)
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
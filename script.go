@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Script Lua脚本，创建时计算SHA1，执行时优先使用EVALSHA
+// 键与Client.Eval一样会添加KeyPrefix，集群模式下所有键需要在同一个槽位（可使用{hashtag}）
+type Script struct {
+	src  string
+	hash string
+}
+
+// NewScript 创建Lua脚本，通常作为包级变量创建一次
+func NewScript(src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	return &Script{
+		src:  src,
+		hash: hex.EncodeToString(sum[:]),
+	}
+}
+
+// Hash 获取脚本的SHA1
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+// Source 获取脚本源码
+func (s *Script) Source() string {
+	return s.src
+}
+
+// Load 将脚本加载到服务端缓存，集群模式下加载到所有节点
+func (s *Script) Load(ctx context.Context, client Client) error {
+	_, err := client.ScriptLoad(ctx, s.src)
+	return err
+}
+
+// Exists 检查脚本是否在服务端缓存中，集群模式下所有节点都存在才返回true
+func (s *Script) Exists(ctx context.Context, client Client) (bool, error) {
+	exists, err := client.ScriptExists(ctx, s.hash)
+	if err != nil {
+		return false, err
+	}
+	return len(exists) == 1 && exists[0], nil
+}
+
+// Run 执行脚本，脚本不在服务端缓存中时改用EVAL执行，EVAL同时会缓存脚本
+func (s *Script) Run(ctx context.Context, client Client, keys []string, args ...interface{}) *ScriptResult {
+	val, err := client.EvalSha(ctx, s.hash, keys, args...)
+	if errors.Is(err, ErrScriptNotFound) {
+		val, err = client.Eval(ctx, s.src, keys, args...)
+	}
+	return newScriptResult(val, err)
+}
+
+// ScriptResult 脚本执行结果，提供按类型解码的方法
+// 脚本返回nil时各方法返回redis.Nil
+type ScriptResult struct {
+	cmd *redis.Cmd
+}
+
+// newScriptResult 创建脚本执行结果
+func newScriptResult(val interface{}, err error) *ScriptResult {
+	return &ScriptResult{cmd: redis.NewCmdResult(val, err)}
+}
+
+// Result 获取原始结果
+func (r *ScriptResult) Result() (interface{}, error) {
+	return r.cmd.Result()
+}
+
+// Err 获取执行错误
+func (r *ScriptResult) Err() error {
+	return r.cmd.Err()
+}
+
+// Text 按字符串解码
+func (r *ScriptResult) Text() (string, error) {
+	return r.cmd.Text()
+}
+
+// Int64 按整数解码
+func (r *ScriptResult) Int64() (int64, error) {
+	return r.cmd.Int64()
+}
+
+// Float64 按浮点数解码，脚本返回的字符串也会被解析
+func (r *ScriptResult) Float64() (float64, error) {
+	return r.cmd.Float64()
+}
+
+// Bool 按布尔值解码，Lua的true返回为1，false返回为nil
+func (r *ScriptResult) Bool() (bool, error) {
+	val, err := r.cmd.Bool()
+	if err == redis.Nil {
+		return false, nil
+	}
+	return val, err
+}
+
+// Slice 按数组解码
+func (r *ScriptResult) Slice() ([]interface{}, error) {
+	return r.cmd.Slice()
+}
+
+// StringSlice 按字符串数组解码
+func (r *ScriptResult) StringSlice() ([]string, error) {
+	return r.cmd.StringSlice()
+}
+
+// Int64Slice 按整数数组解码
+func (r *ScriptResult) Int64Slice() ([]int64, error) {
+	return r.cmd.Int64Slice()
+}
@@ -8,13 +8,23 @@ import (
 )
 
 // SentinelClient 哨兵模式Redis客户端
+// 开启SentinelConfig的从节点读路由后，Get、HGetAll、ZRange等只读方法默认读从节点，
+// 从节点数据可能落后于主节点，需要强一致时使用WithReadPreference(ctx, ReadPrimary)
 type SentinelClient struct {
-	client *redis.Client
-	config *Config
+	client  *redis.Client
+	config  *Config
+	router  *readRouter
+	watcher *sentinelWatcher
 }
 
 // Close 关闭客户端连接
 func (s *SentinelClient) Close() error {
+	if s.watcher != nil {
+		s.watcher.close()
+	}
+	if s.router != nil {
+		s.router.close()
+	}
 	return s.client.Close()
 }
 
@@ -23,12 +33,55 @@ func (s *SentinelClient) Ping(ctx context.Context) error {
 	return s.client.Ping(ctx).Err()
 }
 
+// Ready 检查当前主节点是否可以处理请求，不重试
+func (s *SentinelClient) Ready(ctx context.Context) error {
+	return s.client.Ping(WithNoRetry(ctx)).Err()
+}
+
+// Stats 获取连接池统计，开启从节点读路由后包括从节点连接
+func (s *SentinelClient) Stats() *PoolStats {
+	stats := newPoolStats(s.client.PoolStats())
+	if s.router != nil {
+		if replicaStats := s.router.stats(); replicaStats != nil {
+			stats.add(replicaStats)
+		}
+	}
+	return stats
+}
+
+// OnEvent 注册哨兵事件回调，返回取消注册的函数
+// 首次注册时开始订阅哨兵的事件频道，只回调当前主节点名称相关的事件，回调在订阅协程中执行，不应阻塞
+func (s *SentinelClient) OnEvent(fn func(event SentinelEvent)) func() {
+	if s.watcher == nil {
+		return func() {}
+	}
+	return s.watcher.subscribe(fn)
+}
+
+// Events 返回哨兵事件通道，缓冲区满时丢弃新事件，客户端关闭后通道被关闭
+func (s *SentinelClient) Events() <-chan SentinelEvent {
+	if s.watcher == nil {
+		ch := make(chan SentinelEvent)
+		close(ch)
+		return ch
+	}
+	return s.watcher.events(64)
+}
+
+// reader 按读偏好获取读命令使用的客户端
+func (s *SentinelClient) reader(ctx context.Context) redis.Cmdable {
+	if s.router == nil {
+		return s.client
+	}
+	return s.router.reader(ctx)
+}
+
 // 字符串操作
 
 // Get 获取字符串值
 func (s *SentinelClient) Get(ctx context.Context, key string) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	result := s.client.Get(ctx, key)
+	key = s.config.prefixKey(ctx, key)
+	result := s.reader(ctx).Get(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
 	}
@@ -37,21 +90,21 @@ func (s *SentinelClient) Get(ctx context.Context, key string) (string, error) {
 
 // Set 设置字符串值
 func (s *SentinelClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	key = s.config.GetKeyWithPrefix(key)
-	expiration = s.config.GetTTL(expiration)
+	key = s.config.prefixKey(ctx, key)
+	expiration = s.config.ttl(ctx, expiration)
 	return s.client.Set(ctx, key, value, expiration).Err()
 }
 
 // SetNX 仅当键不存在时设置值
 func (s *SentinelClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	expiration = s.config.GetTTL(expiration)
+	key = s.config.prefixKey(ctx, key)
+	expiration = s.config.ttl(ctx, expiration)
 	return s.client.SetNX(ctx, key, value, expiration).Result()
 }
 
 // GetSet 设置新值并返回旧值
 func (s *SentinelClient) GetSet(ctx context.Context, key string, value interface{}) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	result := s.client.GetSet(ctx, key, value)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
@@ -63,9 +116,9 @@ func (s *SentinelClient) GetSet(ctx context.Context, key string, value interface
 func (s *SentinelClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = s.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
 	}
-	return s.client.MGet(ctx, prefixedKeys...).Result()
+	return s.reader(ctx).MGet(ctx, prefixedKeys...).Result()
 }
 
 // MSet 批量设置多个键值对
@@ -75,7 +128,7 @@ func (s *SentinelClient) MSet(ctx context.Context, pairs ...interface{}) error {
 	for i := 0; i < len(pairs); i += 2 {
 		if i+1 < len(pairs) {
 			key := pairs[i].(string)
-			prefixedPairs[i] = s.config.GetKeyWithPrefix(key)
+			prefixedPairs[i] = s.config.prefixKey(ctx, key)
 			prefixedPairs[i+1] = pairs[i+1]
 		}
 	}
@@ -84,25 +137,25 @@ func (s *SentinelClient) MSet(ctx context.Context, pairs ...interface{}) error {
 
 // Incr 递增计数器
 func (s *SentinelClient) Incr(ctx context.Context, key string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.Incr(ctx, key).Result()
 }
 
 // IncrBy 按指定值递增计数器
 func (s *SentinelClient) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.IncrBy(ctx, key, value).Result()
 }
 
 // Decr 递减计数器
 func (s *SentinelClient) Decr(ctx context.Context, key string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.Decr(ctx, key).Result()
 }
 
 // DecrBy 按指定值递减计数器
 func (s *SentinelClient) DecrBy(ctx context.Context, key string, value int64) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.DecrBy(ctx, key, value).Result()
 }
 
@@ -110,8 +163,8 @@ func (s *SentinelClient) DecrBy(ctx context.Context, key string, value int64) (i
 
 // HGet 获取哈希表字段值
 func (s *SentinelClient) HGet(ctx context.Context, key, field string) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	result := s.client.HGet(ctx, key, field)
+	key = s.config.prefixKey(ctx, key)
+	result := s.reader(ctx).HGet(ctx, key, field)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
 	}
@@ -120,67 +173,67 @@ func (s *SentinelClient) HGet(ctx context.Context, key, field string) (string, e
 
 // HSet 设置哈希表字段值
 func (s *SentinelClient) HSet(ctx context.Context, key, field string, value interface{}) error {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.HSet(ctx, key, field, value).Err()
 }
 
 // HSetNX 仅当字段不存在时设置哈希表字段值
 func (s *SentinelClient) HSetNX(ctx context.Context, key, field string, value interface{}) (bool, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.HSetNX(ctx, key, field, value).Result()
 }
 
 // HDel 删除哈希表字段
 func (s *SentinelClient) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.HDel(ctx, key, fields...).Result()
 }
 
 // HExists 检查哈希表字段是否存在
 func (s *SentinelClient) HExists(ctx context.Context, key, field string) (bool, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.HExists(ctx, key, field).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).HExists(ctx, key, field).Result()
 }
 
 // HGetAll 获取哈希表所有字段和值
 func (s *SentinelClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.HGetAll(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).HGetAll(ctx, key).Result()
 }
 
 // HKeys 获取哈希表所有字段
 func (s *SentinelClient) HKeys(ctx context.Context, key string) ([]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.HKeys(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).HKeys(ctx, key).Result()
 }
 
 // HVals 获取哈希表所有值
 func (s *SentinelClient) HVals(ctx context.Context, key string) ([]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.HVals(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).HVals(ctx, key).Result()
 }
 
 // HLen 获取哈希表字段数量
 func (s *SentinelClient) HLen(ctx context.Context, key string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.HLen(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).HLen(ctx, key).Result()
 }
 
 // HMGet 批量获取哈希表字段值
 func (s *SentinelClient) HMGet(ctx context.Context, key string, fields ...string) ([]interface{}, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.HMGet(ctx, key, fields...).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).HMGet(ctx, key, fields...).Result()
 }
 
 // HMSet 批量设置哈希表字段值
 func (s *SentinelClient) HMSet(ctx context.Context, key string, pairs ...interface{}) error {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.HMSet(ctx, key, pairs...).Err()
 }
 
 // HIncrBy 递增哈希表字段值
 func (s *SentinelClient) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.HIncrBy(ctx, key, field, incr).Result()
 }
 
@@ -188,19 +241,19 @@ func (s *SentinelClient) HIncrBy(ctx context.Context, key, field string, incr in
 
 // LPush 从列表左侧推入元素
 func (s *SentinelClient) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.LPush(ctx, key, values...).Result()
 }
 
 // RPush 从列表右侧推入元素
 func (s *SentinelClient) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.RPush(ctx, key, values...).Result()
 }
 
 // LPop 从列表左侧弹出元素
 func (s *SentinelClient) LPop(ctx context.Context, key string) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	result := s.client.LPop(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
@@ -210,7 +263,7 @@ func (s *SentinelClient) LPop(ctx context.Context, key string) (string, error) {
 
 // RPop 从列表右侧弹出元素
 func (s *SentinelClient) RPop(ctx context.Context, key string) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	result := s.client.RPop(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
@@ -220,20 +273,20 @@ func (s *SentinelClient) RPop(ctx context.Context, key string) (string, error) {
 
 // LLen 获取列表长度
 func (s *SentinelClient) LLen(ctx context.Context, key string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.LLen(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).LLen(ctx, key).Result()
 }
 
 // LRange 获取列表指定范围的元素
 func (s *SentinelClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.LRange(ctx, key, start, stop).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).LRange(ctx, key, start, stop).Result()
 }
 
 // LIndex 获取列表指定索引的元素
 func (s *SentinelClient) LIndex(ctx context.Context, key string, index int64) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	result := s.client.LIndex(ctx, key, index)
+	key = s.config.prefixKey(ctx, key)
+	result := s.reader(ctx).LIndex(ctx, key, index)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
 	}
@@ -242,19 +295,19 @@ func (s *SentinelClient) LIndex(ctx context.Context, key string, index int64) (s
 
 // LSet 设置列表指定索引的元素值
 func (s *SentinelClient) LSet(ctx context.Context, key string, index int64, value interface{}) error {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.LSet(ctx, key, index, value).Err()
 }
 
 // LRem 从列表中移除元素
 func (s *SentinelClient) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.LRem(ctx, key, count, value).Result()
 }
 
 // LTrim 修剪列表，只保留指定范围的元素
 func (s *SentinelClient) LTrim(ctx context.Context, key string, start, stop int64) error {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.LTrim(ctx, key, start, stop).Err()
 }
 
@@ -262,37 +315,37 @@ func (s *SentinelClient) LTrim(ctx context.Context, key string, start, stop int6
 
 // SAdd 向集合添加成员
 func (s *SentinelClient) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.SAdd(ctx, key, members...).Result()
 }
 
 // SRem 从集合移除成员
 func (s *SentinelClient) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.SRem(ctx, key, members...).Result()
 }
 
 // SMembers 获取集合所有成员
 func (s *SentinelClient) SMembers(ctx context.Context, key string) ([]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.SMembers(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).SMembers(ctx, key).Result()
 }
 
 // SIsMember 检查成员是否在集合中
 func (s *SentinelClient) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.SIsMember(ctx, key, member).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).SIsMember(ctx, key, member).Result()
 }
 
 // SCard 获取集合成员数量
 func (s *SentinelClient) SCard(ctx context.Context, key string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.SCard(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).SCard(ctx, key).Result()
 }
 
 // SPop 随机移除并返回集合中的一个成员
 func (s *SentinelClient) SPop(ctx context.Context, key string) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	result := s.client.SPop(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
@@ -302,8 +355,8 @@ func (s *SentinelClient) SPop(ctx context.Context, key string) (string, error) {
 
 // SRandMember 随机返回集合中的一个成员
 func (s *SentinelClient) SRandMember(ctx context.Context, key string) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	result := s.client.SRandMember(ctx, key)
+	key = s.config.prefixKey(ctx, key)
+	result := s.reader(ctx).SRandMember(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
 	}
@@ -314,34 +367,34 @@ func (s *SentinelClient) SRandMember(ctx context.Context, key string) (string, e
 func (s *SentinelClient) SInter(ctx context.Context, keys ...string) ([]string, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = s.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
 	}
-	return s.client.SInter(ctx, prefixedKeys...).Result()
+	return s.reader(ctx).SInter(ctx, prefixedKeys...).Result()
 }
 
 // SUnion 计算多个集合的并集
 func (s *SentinelClient) SUnion(ctx context.Context, keys ...string) ([]string, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = s.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
 	}
-	return s.client.SUnion(ctx, prefixedKeys...).Result()
+	return s.reader(ctx).SUnion(ctx, prefixedKeys...).Result()
 }
 
 // SDiff 计算多个集合的差集
 func (s *SentinelClient) SDiff(ctx context.Context, keys ...string) ([]string, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = s.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
 	}
-	return s.client.SDiff(ctx, prefixedKeys...).Result()
+	return s.reader(ctx).SDiff(ctx, prefixedKeys...).Result()
 }
 
 // 有序集合操作
 
 // ZAdd 向有序集合添加成员
 func (s *SentinelClient) ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	redisMembers := make([]redis.Z, len(members))
 	for i, member := range members {
 		redisMembers[i] = redis.Z{
@@ -354,44 +407,44 @@ func (s *SentinelClient) ZAdd(ctx context.Context, key string, members ...ZMembe
 
 // ZRem 从有序集合移除成员
 func (s *SentinelClient) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.ZRem(ctx, key, members...).Result()
 }
 
 // ZScore 获取有序集合成员的分数
 func (s *SentinelClient) ZScore(ctx context.Context, key, member string) (float64, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZScore(ctx, key, member).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZScore(ctx, key, member).Result()
 }
 
 // ZRank 获取有序集合成员的排名（从小到大）
 func (s *SentinelClient) ZRank(ctx context.Context, key, member string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZRank(ctx, key, member).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZRank(ctx, key, member).Result()
 }
 
 // ZRevRank 获取有序集合成员的排名（从大到小）
 func (s *SentinelClient) ZRevRank(ctx context.Context, key, member string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZRevRank(ctx, key, member).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZRevRank(ctx, key, member).Result()
 }
 
 // ZRange 获取有序集合指定范围的成员（从小到大）
 func (s *SentinelClient) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZRange(ctx, key, start, stop).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZRange(ctx, key, start, stop).Result()
 }
 
 // ZRevRange 获取有序集合指定范围的成员（从大到小）
 func (s *SentinelClient) ZRevRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZRevRange(ctx, key, start, stop).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZRevRange(ctx, key, start, stop).Result()
 }
 
 // ZRangeWithScores 获取有序集合指定范围的成员和分数（从小到大）
 func (s *SentinelClient) ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	result, err := s.client.ZRangeWithScores(ctx, key, start, stop).Result()
+	key = s.config.prefixKey(ctx, key)
+	result, err := s.reader(ctx).ZRangeWithScores(ctx, key, start, stop).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -408,8 +461,8 @@ func (s *SentinelClient) ZRangeWithScores(ctx context.Context, key string, start
 
 // ZRevRangeWithScores 获取有序集合指定范围的成员和分数（从大到小）
 func (s *SentinelClient) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	result, err := s.client.ZRevRangeWithScores(ctx, key, start, stop).Result()
+	key = s.config.prefixKey(ctx, key)
+	result, err := s.reader(ctx).ZRevRangeWithScores(ctx, key, start, stop).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -426,8 +479,8 @@ func (s *SentinelClient) ZRevRangeWithScores(ctx context.Context, key string, st
 
 // ZRangeByScore 根据分数范围获取有序集合成员
 func (s *SentinelClient) ZRangeByScore(ctx context.Context, key string, min, max string) ([]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZRangeByScore(ctx, key, &redis.ZRangeBy{
 		Min: min,
 		Max: max,
 	}).Result()
@@ -435,8 +488,8 @@ func (s *SentinelClient) ZRangeByScore(ctx context.Context, key string, min, max
 
 // ZRevRangeByScore 根据分数范围获取有序集合成员（逆序）
 func (s *SentinelClient) ZRevRangeByScore(ctx context.Context, key string, max, min string) ([]string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
 		Min: min,
 		Max: max,
 	}).Result()
@@ -444,19 +497,19 @@ func (s *SentinelClient) ZRevRangeByScore(ctx context.Context, key string, max,
 
 // ZCard 获取有序集合成员数量
 func (s *SentinelClient) ZCard(ctx context.Context, key string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZCard(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZCard(ctx, key).Result()
 }
 
 // ZCount 计算指定分数范围内的成员数量
 func (s *SentinelClient) ZCount(ctx context.Context, key, min, max string) (int64, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.ZCount(ctx, key, min, max).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).ZCount(ctx, key, min, max).Result()
 }
 
 // ZIncrBy 增加有序集合成员的分数
 func (s *SentinelClient) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.ZIncrBy(ctx, key, increment, member).Result()
 }
 
@@ -466,7 +519,7 @@ func (s *SentinelClient) ZIncrBy(ctx context.Context, key string, increment floa
 func (s *SentinelClient) Del(ctx context.Context, keys ...string) (int64, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = s.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
 	}
 	return s.client.Del(ctx, prefixedKeys...).Result()
 }
@@ -475,46 +528,50 @@ func (s *SentinelClient) Del(ctx context.Context, keys ...string) (int64, error)
 func (s *SentinelClient) Exists(ctx context.Context, keys ...string) (int64, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = s.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
 	}
-	return s.client.Exists(ctx, prefixedKeys...).Result()
+	return s.reader(ctx).Exists(ctx, prefixedKeys...).Result()
 }
 
 // Expire 设置键的过期时间
 func (s *SentinelClient) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.Expire(ctx, key, expiration).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.client.Expire(ctx, key, jitterTTL(ctx, expiration)).Result()
 }
 
 // ExpireAt 设置键在指定时间过期
 func (s *SentinelClient) ExpireAt(ctx context.Context, key string, tm time.Time) (bool, error) {
-	key = s.config.GetKeyWithPrefix(key)
+	key = s.config.prefixKey(ctx, key)
 	return s.client.ExpireAt(ctx, key, tm).Result()
 }
 
 // TTL 获取键的剩余生存时间
 func (s *SentinelClient) TTL(ctx context.Context, key string) (time.Duration, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.TTL(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).TTL(ctx, key).Result()
 }
 
 // Type 获取键的数据类型
 func (s *SentinelClient) Type(ctx context.Context, key string) (string, error) {
-	key = s.config.GetKeyWithPrefix(key)
-	return s.client.Type(ctx, key).Result()
+	key = s.config.prefixKey(ctx, key)
+	return s.reader(ctx).Type(ctx, key).Result()
 }
 
-// Keys 查找匹配模式的键
+// Keys 查找匹配模式的键，配置了DisableKeys时返回ErrKeysDisabled
+// 不使用读路由，始终在主节点执行，与Scan看到同一个节点的键空间
 func (s *SentinelClient) Keys(ctx context.Context, pattern string) ([]string, error) {
-	pattern = s.config.GetKeyWithPrefix(pattern)
+	if s.config.Common.DisableKeys {
+		return nil, ErrKeysDisabled
+	}
+	pattern = s.config.prefixKey(ctx, pattern)
 	keys, err := s.client.Keys(ctx, pattern).Result()
 	if err != nil {
 		return nil, err
 	}
 
 	// 移除前缀
-	if s.config.Common.KeyPrefix != "" {
-		prefixLen := len(s.config.Common.KeyPrefix)
+	if prefix := s.config.keyPrefix(ctx); prefix != "" {
+		prefixLen := len(prefix)
 		for i, key := range keys {
 			if len(key) > prefixLen {
 				keys[i] = key[prefixLen:]
@@ -526,16 +583,17 @@ func (s *SentinelClient) Keys(ctx context.Context, pattern string) ([]string, er
 }
 
 // Scan 迭代数据库中的键
+// 游标只在返回它的节点上有效，而读路由会把每次调用发往不同的节点，因此Scan始终在主节点执行
 func (s *SentinelClient) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
-	match = s.config.GetKeyWithPrefix(match)
+	match = s.config.prefixKey(ctx, match)
 	keys, cursor, err := s.client.Scan(ctx, cursor, match, count).Result()
 	if err != nil {
 		return nil, cursor, err
 	}
 
 	// 移除前缀
-	if s.config.Common.KeyPrefix != "" {
-		prefixLen := len(s.config.Common.KeyPrefix)
+	if prefix := s.config.keyPrefix(ctx); prefix != "" {
+		prefixLen := len(prefix)
 		for i, key := range keys {
 			if len(key) > prefixLen {
 				keys[i] = key[prefixLen:]
@@ -546,24 +604,34 @@ func (s *SentinelClient) Scan(ctx context.Context, cursor uint64, match string,
 	return keys, cursor, nil
 }
 
+// DeleteByPattern 按模式删除键，用SCAN分批迭代匹配的键并以UNLINK删除，返回删除的键数
+func (s *SentinelClient) DeleteByPattern(ctx context.Context, pattern string, opts *DeleteOptions) (int64, error) {
+	match := s.config.prefixKey(ctx, pattern)
+	return newPatternDelete(opts).run(ctx, s.client, match)
+}
+
 // Lua脚本操作
 
 // Eval 执行Lua脚本
 func (s *SentinelClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = s.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
 	}
 	return s.client.Eval(ctx, script, prefixedKeys, args...).Result()
 }
 
-// EvalSha 通过SHA1执行Lua脚本
+// EvalSha 通过SHA1执行Lua脚本，脚本不在服务端缓存中时返回ErrScriptNotFound
 func (s *SentinelClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) (interface{}, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = s.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
 	}
-	return s.client.EvalSha(ctx, sha1, prefixedKeys, args...).Result()
+	result := s.client.EvalSha(ctx, sha1, prefixedKeys, args...)
+	if redis.HasErrorPrefix(result.Err(), "NOSCRIPT") {
+		return nil, ErrScriptNotFound
+	}
+	return result.Result()
 }
 
 // ScriptExists 检查脚本是否存在
@@ -586,6 +654,61 @@ func (s *SentinelClient) ScriptLoad(ctx context.Context, script string) (string,
 	return s.client.ScriptLoad(ctx, script).Result()
 }
 
+// Redis Functions操作
+
+// FunctionLoad 加载函数库，replace为true时替换同名函数库，返回函数库名称
+func (s *SentinelClient) FunctionLoad(ctx context.Context, code string, replace bool) (string, error) {
+	if replace {
+		return s.client.FunctionLoadReplace(ctx, code).Result()
+	}
+	return s.client.FunctionLoad(ctx, code).Result()
+}
+
+// FunctionDelete 删除函数库
+func (s *SentinelClient) FunctionDelete(ctx context.Context, library string) error {
+	return s.client.FunctionDelete(ctx, library).Err()
+}
+
+// FunctionList 列出名称匹配pattern的函数库，pattern为空时列出全部
+func (s *SentinelClient) FunctionList(ctx context.Context, pattern string, withCode bool) ([]FunctionLibrary, error) {
+	libs, err := s.client.FunctionList(ctx, redis.FunctionListQuery{
+		LibraryNamePattern: pattern,
+		WithCode:           withCode,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return newFunctionLibraries(libs), nil
+}
+
+// FunctionDump 导出所有函数库
+func (s *SentinelClient) FunctionDump(ctx context.Context) (string, error) {
+	return s.client.FunctionDump(ctx).Result()
+}
+
+// FunctionRestore 从FunctionDump的结果恢复函数库
+func (s *SentinelClient) FunctionRestore(ctx context.Context, payload string) error {
+	return s.client.FunctionRestore(ctx, payload).Err()
+}
+
+// FCall 调用函数
+func (s *SentinelClient) FCall(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
+	}
+	return s.client.FCall(ctx, function, prefixedKeys, args...).Result()
+}
+
+// FCallRO 调用只读函数，函数需声明no-writes标志，可以路由到从节点
+func (s *SentinelClient) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = s.config.prefixKey(ctx, key)
+	}
+	return s.reader(ctx).FCallRO(ctx, function, prefixedKeys, args...).Result()
+}
+
 // 管道操作
 
 // Pipeline 创建管道
@@ -602,4 +725,9 @@ func (s *SentinelClient) TxPipeline() Pipeliner {
 		pipe:   s.client.TxPipeline(),
 		config: s.config,
 	}
-}
\ No newline at end of file
+}
+
+// Watch 监视键并执行乐观事务，被监视的键被修改时按配置重试
+func (s *SentinelClient) Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error {
+	return watch(ctx, s.client, s.config, fn, keys...)
+}
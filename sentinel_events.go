@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SentinelEventType 哨兵事件类型，取值为哨兵发布事件的频道名
+type SentinelEventType string
+
+const (
+	// EventSwitchMaster 主节点切换完成
+	EventSwitchMaster SentinelEventType = "+switch-master"
+	// EventSDown 实例被当前哨兵判定为主观下线
+	EventSDown SentinelEventType = "+sdown"
+	// EventSDownCleared 实例退出主观下线
+	EventSDownCleared SentinelEventType = "-sdown"
+	// EventODown 主节点被判定为客观下线
+	EventODown SentinelEventType = "+odown"
+	// EventODownCleared 主节点退出客观下线
+	EventODownCleared SentinelEventType = "-odown"
+	// EventReplicaAdded 发现新的从节点
+	EventReplicaAdded SentinelEventType = "+slave"
+	// EventReplicaReconfigured 从节点已切换复制到新的主节点
+	EventReplicaReconfigured SentinelEventType = "+slave-reconf-done"
+	// EventConvertToReplica 旧主节点恢复后被转换为从节点
+	EventConvertToReplica SentinelEventType = "+convert-to-slave"
+)
+
+// sentinelEventTypes 订阅的哨兵事件频道
+var sentinelEventTypes = []SentinelEventType{
+	EventSwitchMaster, EventSDown, EventSDownCleared, EventODown, EventODownCleared,
+	EventReplicaAdded, EventReplicaReconfigured, EventConvertToReplica,
+}
+
+// SentinelEvent 哨兵事件
+type SentinelEvent struct {
+	// 事件类型
+	Type SentinelEventType
+	// 主节点名称
+	MasterName string
+	// 事件涉及的实例类型：master、slave或sentinel，+switch-master事件为master
+	InstanceType string
+	// 事件涉及的实例地址
+	Addr string
+	// 切换前的主节点地址，仅+switch-master事件
+	OldMaster string
+	// 切换后的主节点地址，仅+switch-master事件
+	NewMaster string
+	// 发布事件的哨兵地址
+	Sentinel string
+	// 原始消息内容
+	Payload string
+	// 收到事件的时间
+	Time time.Time
+}
+
+// parseSentinelEvent 解析哨兵事件消息
+// +switch-master格式为"<master-name> <old-ip> <old-port> <new-ip> <new-port>"，
+// 其他事件格式为"<instance-type> <name> <ip> <port> [@ <master-name> <master-ip> <master-port>]"
+func parseSentinelEvent(channel, payload string) (SentinelEvent, bool) {
+	event := SentinelEvent{
+		Type:    SentinelEventType(channel),
+		Payload: payload,
+		Time:    time.Now(),
+	}
+	fields := strings.Fields(payload)
+
+	if event.Type == EventSwitchMaster {
+		if len(fields) != 5 {
+			return event, false
+		}
+		event.MasterName = fields[0]
+		event.InstanceType = "master"
+		event.OldMaster = net.JoinHostPort(fields[1], fields[2])
+		event.NewMaster = net.JoinHostPort(fields[3], fields[4])
+		event.Addr = event.NewMaster
+		return event, true
+	}
+
+	if len(fields) < 4 {
+		return event, false
+	}
+	event.InstanceType = fields[0]
+	event.Addr = net.JoinHostPort(fields[2], fields[3])
+	if len(fields) >= 6 && fields[4] == "@" {
+		event.MasterName = fields[5]
+	} else if event.InstanceType == "master" {
+		event.MasterName = fields[1]
+	}
+	return event, true
+}
+
+// sentinelWatcher 订阅哨兵的事件频道并分发事件
+// 依次连接各个哨兵，当前哨兵断开后切换到下一个
+type sentinelWatcher struct {
+	addrs      []string
+	masterName string
+	newClient  func(addr string) *redis.SentinelClient
+	logger     Logger
+
+	mu       sync.Mutex
+	handlers map[uint64]func(SentinelEvent)
+	chans    []chan SentinelEvent
+	nextID   uint64
+	started  bool
+	closed   bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// subscribe 注册事件回调，首次注册时开始订阅
+func (w *sentinelWatcher) subscribe(fn func(SentinelEvent)) func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return func() {}
+	}
+	if w.handlers == nil {
+		w.handlers = make(map[uint64]func(SentinelEvent))
+	}
+	id := w.nextID
+	w.nextID++
+	w.handlers[id] = fn
+	w.start()
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.handlers, id)
+	}
+}
+
+// events 注册事件通道，缓冲区满时丢弃事件，关闭后通道被关闭
+func (w *sentinelWatcher) events(size int) <-chan SentinelEvent {
+	ch := make(chan SentinelEvent, size)
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		close(ch)
+		return ch
+	}
+	w.chans = append(w.chans, ch)
+	w.mu.Unlock()
+
+	w.subscribe(func(event SentinelEvent) {
+		select {
+		case ch <- event:
+		default:
+		}
+	})
+	return ch
+}
+
+// start 启动订阅协程，调用方需持有锁
+func (w *sentinelWatcher) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go w.run(ctx)
+}
+
+// run 轮流连接哨兵并接收事件，直到关闭
+func (w *sentinelWatcher) run(ctx context.Context) {
+	defer close(w.done)
+
+	const minBackoff = 100 * time.Millisecond
+	backoff := minBackoff
+	for i := 0; ; i = (i + 1) % len(w.addrs) {
+		subscribed, err := w.watch(ctx, w.addrs[i])
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Printf(ctx, "cache: sentinel %s event subscription failed: %v", w.addrs[i], err)
+		// 订阅成功过的连接断开后重新从最短的间隔开始重连
+		if subscribed {
+			backoff = minBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 5*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// watch 连接单个哨兵并接收事件，连接断开时返回错误，subscribed表示断开前是否订阅成功
+func (w *sentinelWatcher) watch(ctx context.Context, addr string) (subscribed bool, err error) {
+	client := w.newClient(addr)
+	defer client.Close()
+
+	channels := make([]string, len(sentinelEventTypes))
+	for i, eventType := range sentinelEventTypes {
+		channels[i] = string(eventType)
+	}
+
+	pubsub := client.Subscribe(ctx, channels...)
+	defer pubsub.Close()
+	// ReceiveMessage不响应ctx取消，关闭时通过关闭订阅连接使其返回
+	stop := context.AfterFunc(ctx, func() {
+		pubsub.Close()
+	})
+	defer stop()
+
+	// 第一条回复是订阅确认
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return false, err
+	}
+
+	for {
+		msg, err := pubsub.ReceiveMessage(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		event, ok := parseSentinelEvent(msg.Channel, msg.Payload)
+		if !ok || event.MasterName != w.masterName {
+			continue
+		}
+		event.Sentinel = addr
+		w.dispatch(event)
+	}
+}
+
+// dispatch 将事件分发给所有回调
+func (w *sentinelWatcher) dispatch(event SentinelEvent) {
+	w.mu.Lock()
+	handlers := make([]func(SentinelEvent), 0, len(w.handlers))
+	for _, fn := range w.handlers {
+		handlers = append(handlers, fn)
+	}
+	w.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(event)
+	}
+}
+
+// close 停止订阅并关闭所有事件通道
+func (w *sentinelWatcher) close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	started := w.started
+	w.mu.Unlock()
+
+	if started {
+		w.cancel()
+		<-w.done
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, ch := range w.chans {
+		close(ch)
+	}
+	w.chans = nil
+	w.handlers = nil
+}
@@ -23,12 +23,27 @@ func (c *SingleClient) Ping(ctx context.Context) error {
 	return c.client.Ping(ctx).Err()
 }
 
+// Ready 检查客户端是否可以处理请求，不重试
+func (c *SingleClient) Ready(ctx context.Context) error {
+	return c.client.Ping(WithNoRetry(ctx)).Err()
+}
+
+// Stats 获取连接池统计
+func (c *SingleClient) Stats() *PoolStats {
+	return newPoolStats(c.client.PoolStats())
+}
+
+// reader 获取读命令使用的客户端，单机模式没有从节点，读偏好不生效
+func (c *SingleClient) reader(ctx context.Context) redis.Cmdable {
+	return c.client
+}
+
 // 字符串操作
 
 // Get 获取字符串值
 func (c *SingleClient) Get(ctx context.Context, key string) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	result := c.client.Get(ctx, key)
+	key = c.config.prefixKey(ctx, key)
+	result := c.reader(ctx).Get(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
 	}
@@ -37,21 +52,21 @@ func (c *SingleClient) Get(ctx context.Context, key string) (string, error) {
 
 // Set 设置字符串值
 func (c *SingleClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	key = c.config.GetKeyWithPrefix(key)
-	expiration = c.config.GetTTL(expiration)
+	key = c.config.prefixKey(ctx, key)
+	expiration = c.config.ttl(ctx, expiration)
 	return c.client.Set(ctx, key, value, expiration).Err()
 }
 
 // SetNX 仅当键不存在时设置值
 func (c *SingleClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	expiration = c.config.GetTTL(expiration)
+	key = c.config.prefixKey(ctx, key)
+	expiration = c.config.ttl(ctx, expiration)
 	return c.client.SetNX(ctx, key, value, expiration).Result()
 }
 
 // GetSet 设置新值并返回旧值
 func (c *SingleClient) GetSet(ctx context.Context, key string, value interface{}) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	result := c.client.GetSet(ctx, key, value)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
@@ -63,9 +78,9 @@ func (c *SingleClient) GetSet(ctx context.Context, key string, value interface{}
 func (c *SingleClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = c.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
 	}
-	return c.client.MGet(ctx, prefixedKeys...).Result()
+	return c.reader(ctx).MGet(ctx, prefixedKeys...).Result()
 }
 
 // MSet 批量设置多个键值对
@@ -75,7 +90,7 @@ func (c *SingleClient) MSet(ctx context.Context, pairs ...interface{}) error {
 	for i := 0; i < len(pairs); i += 2 {
 		if i+1 < len(pairs) {
 			key := pairs[i].(string)
-			prefixedPairs[i] = c.config.GetKeyWithPrefix(key)
+			prefixedPairs[i] = c.config.prefixKey(ctx, key)
 			prefixedPairs[i+1] = pairs[i+1]
 		}
 	}
@@ -84,25 +99,25 @@ func (c *SingleClient) MSet(ctx context.Context, pairs ...interface{}) error {
 
 // Incr 递增计数器
 func (c *SingleClient) Incr(ctx context.Context, key string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.Incr(ctx, key).Result()
 }
 
 // IncrBy 按指定值递增计数器
 func (c *SingleClient) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.IncrBy(ctx, key, value).Result()
 }
 
 // Decr 递减计数器
 func (c *SingleClient) Decr(ctx context.Context, key string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.Decr(ctx, key).Result()
 }
 
 // DecrBy 按指定值递减计数器
 func (c *SingleClient) DecrBy(ctx context.Context, key string, value int64) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.DecrBy(ctx, key, value).Result()
 }
 
@@ -110,8 +125,8 @@ func (c *SingleClient) DecrBy(ctx context.Context, key string, value int64) (int
 
 // HGet 获取哈希表字段值
 func (c *SingleClient) HGet(ctx context.Context, key, field string) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	result := c.client.HGet(ctx, key, field)
+	key = c.config.prefixKey(ctx, key)
+	result := c.reader(ctx).HGet(ctx, key, field)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
 	}
@@ -120,67 +135,67 @@ func (c *SingleClient) HGet(ctx context.Context, key, field string) (string, err
 
 // HSet 设置哈希表字段值
 func (c *SingleClient) HSet(ctx context.Context, key, field string, value interface{}) error {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.HSet(ctx, key, field, value).Err()
 }
 
 // HSetNX 仅当字段不存在时设置哈希表字段值
 func (c *SingleClient) HSetNX(ctx context.Context, key, field string, value interface{}) (bool, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.HSetNX(ctx, key, field, value).Result()
 }
 
 // HDel 删除哈希表字段
 func (c *SingleClient) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.HDel(ctx, key, fields...).Result()
 }
 
 // HExists 检查哈希表字段是否存在
 func (c *SingleClient) HExists(ctx context.Context, key, field string) (bool, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.HExists(ctx, key, field).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).HExists(ctx, key, field).Result()
 }
 
 // HGetAll 获取哈希表所有字段和值
 func (c *SingleClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.HGetAll(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).HGetAll(ctx, key).Result()
 }
 
 // HKeys 获取哈希表所有字段
 func (c *SingleClient) HKeys(ctx context.Context, key string) ([]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.HKeys(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).HKeys(ctx, key).Result()
 }
 
 // HVals 获取哈希表所有值
 func (c *SingleClient) HVals(ctx context.Context, key string) ([]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.HVals(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).HVals(ctx, key).Result()
 }
 
 // HLen 获取哈希表字段数量
 func (c *SingleClient) HLen(ctx context.Context, key string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.HLen(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).HLen(ctx, key).Result()
 }
 
 // HMGet 批量获取哈希表字段值
 func (c *SingleClient) HMGet(ctx context.Context, key string, fields ...string) ([]interface{}, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.HMGet(ctx, key, fields...).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).HMGet(ctx, key, fields...).Result()
 }
 
 // HMSet 批量设置哈希表字段值
 func (c *SingleClient) HMSet(ctx context.Context, key string, pairs ...interface{}) error {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.HMSet(ctx, key, pairs...).Err()
 }
 
 // HIncrBy 递增哈希表字段值
 func (c *SingleClient) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.HIncrBy(ctx, key, field, incr).Result()
 }
 
@@ -188,19 +203,19 @@ func (c *SingleClient) HIncrBy(ctx context.Context, key, field string, incr int6
 
 // LPush 从列表左侧推入元素
 func (c *SingleClient) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.LPush(ctx, key, values...).Result()
 }
 
 // RPush 从列表右侧推入元素
 func (c *SingleClient) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.RPush(ctx, key, values...).Result()
 }
 
 // LPop 从列表左侧弹出元素
 func (c *SingleClient) LPop(ctx context.Context, key string) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	result := c.client.LPop(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
@@ -210,7 +225,7 @@ func (c *SingleClient) LPop(ctx context.Context, key string) (string, error) {
 
 // RPop 从列表右侧弹出元素
 func (c *SingleClient) RPop(ctx context.Context, key string) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	result := c.client.RPop(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
@@ -220,20 +235,20 @@ func (c *SingleClient) RPop(ctx context.Context, key string) (string, error) {
 
 // LLen 获取列表长度
 func (c *SingleClient) LLen(ctx context.Context, key string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.LLen(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).LLen(ctx, key).Result()
 }
 
 // LRange 获取列表指定范围的元素
 func (c *SingleClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.LRange(ctx, key, start, stop).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).LRange(ctx, key, start, stop).Result()
 }
 
 // LIndex 获取列表指定索引的元素
 func (c *SingleClient) LIndex(ctx context.Context, key string, index int64) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	result := c.client.LIndex(ctx, key, index)
+	key = c.config.prefixKey(ctx, key)
+	result := c.reader(ctx).LIndex(ctx, key, index)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
 	}
@@ -242,19 +257,19 @@ func (c *SingleClient) LIndex(ctx context.Context, key string, index int64) (str
 
 // LSet 设置列表指定索引的元素值
 func (c *SingleClient) LSet(ctx context.Context, key string, index int64, value interface{}) error {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.LSet(ctx, key, index, value).Err()
 }
 
 // LRem 从列表中移除元素
 func (c *SingleClient) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.LRem(ctx, key, count, value).Result()
 }
 
 // LTrim 修剪列表，只保留指定范围的元素
 func (c *SingleClient) LTrim(ctx context.Context, key string, start, stop int64) error {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.LTrim(ctx, key, start, stop).Err()
 }
 
@@ -262,37 +277,37 @@ func (c *SingleClient) LTrim(ctx context.Context, key string, start, stop int64)
 
 // SAdd 向集合添加成员
 func (c *SingleClient) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.SAdd(ctx, key, members...).Result()
 }
 
 // SRem 从集合移除成员
 func (c *SingleClient) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.SRem(ctx, key, members...).Result()
 }
 
 // SMembers 获取集合所有成员
 func (c *SingleClient) SMembers(ctx context.Context, key string) ([]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.SMembers(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).SMembers(ctx, key).Result()
 }
 
 // SIsMember 检查成员是否在集合中
 func (c *SingleClient) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.SIsMember(ctx, key, member).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).SIsMember(ctx, key, member).Result()
 }
 
 // SCard 获取集合成员数量
 func (c *SingleClient) SCard(ctx context.Context, key string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.SCard(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).SCard(ctx, key).Result()
 }
 
 // SPop 随机移除并返回集合中的一个成员
 func (c *SingleClient) SPop(ctx context.Context, key string) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	result := c.client.SPop(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
@@ -302,8 +317,8 @@ func (c *SingleClient) SPop(ctx context.Context, key string) (string, error) {
 
 // SRandMember 随机返回集合中的一个成员
 func (c *SingleClient) SRandMember(ctx context.Context, key string) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	result := c.client.SRandMember(ctx, key)
+	key = c.config.prefixKey(ctx, key)
+	result := c.reader(ctx).SRandMember(ctx, key)
 	if result.Err() == redis.Nil {
 		return "", ErrKeyNotFound
 	}
@@ -314,34 +329,34 @@ func (c *SingleClient) SRandMember(ctx context.Context, key string) (string, err
 func (c *SingleClient) SInter(ctx context.Context, keys ...string) ([]string, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = c.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
 	}
-	return c.client.SInter(ctx, prefixedKeys...).Result()
+	return c.reader(ctx).SInter(ctx, prefixedKeys...).Result()
 }
 
 // SUnion 计算多个集合的并集
 func (c *SingleClient) SUnion(ctx context.Context, keys ...string) ([]string, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = c.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
 	}
-	return c.client.SUnion(ctx, prefixedKeys...).Result()
+	return c.reader(ctx).SUnion(ctx, prefixedKeys...).Result()
 }
 
 // SDiff 计算多个集合的差集
 func (c *SingleClient) SDiff(ctx context.Context, keys ...string) ([]string, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = c.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
 	}
-	return c.client.SDiff(ctx, prefixedKeys...).Result()
+	return c.reader(ctx).SDiff(ctx, prefixedKeys...).Result()
 }
 
 // 有序集合操作
 
 // ZAdd 向有序集合添加成员
 func (c *SingleClient) ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	redisMembers := make([]redis.Z, len(members))
 	for i, member := range members {
 		redisMembers[i] = redis.Z{
@@ -354,44 +369,44 @@ func (c *SingleClient) ZAdd(ctx context.Context, key string, members ...ZMember)
 
 // ZRem 从有序集合移除成员
 func (c *SingleClient) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.ZRem(ctx, key, members...).Result()
 }
 
 // ZScore 获取有序集合成员的分数
 func (c *SingleClient) ZScore(ctx context.Context, key, member string) (float64, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZScore(ctx, key, member).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZScore(ctx, key, member).Result()
 }
 
 // ZRank 获取有序集合成员的排名（从小到大）
 func (c *SingleClient) ZRank(ctx context.Context, key, member string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZRank(ctx, key, member).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZRank(ctx, key, member).Result()
 }
 
 // ZRevRank 获取有序集合成员的排名（从大到小）
 func (c *SingleClient) ZRevRank(ctx context.Context, key, member string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZRevRank(ctx, key, member).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZRevRank(ctx, key, member).Result()
 }
 
 // ZRange 获取有序集合指定范围的成员（从小到大）
 func (c *SingleClient) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZRange(ctx, key, start, stop).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZRange(ctx, key, start, stop).Result()
 }
 
 // ZRevRange 获取有序集合指定范围的成员（从大到小）
 func (c *SingleClient) ZRevRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZRevRange(ctx, key, start, stop).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZRevRange(ctx, key, start, stop).Result()
 }
 
 // ZRangeWithScores 获取有序集合指定范围的成员和分数（从小到大）
 func (c *SingleClient) ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	result, err := c.client.ZRangeWithScores(ctx, key, start, stop).Result()
+	key = c.config.prefixKey(ctx, key)
+	result, err := c.reader(ctx).ZRangeWithScores(ctx, key, start, stop).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -408,8 +423,8 @@ func (c *SingleClient) ZRangeWithScores(ctx context.Context, key string, start,
 
 // ZRevRangeWithScores 获取有序集合指定范围的成员和分数（从大到小）
 func (c *SingleClient) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ZMember, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	result, err := c.client.ZRevRangeWithScores(ctx, key, start, stop).Result()
+	key = c.config.prefixKey(ctx, key)
+	result, err := c.reader(ctx).ZRevRangeWithScores(ctx, key, start, stop).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -426,8 +441,8 @@ func (c *SingleClient) ZRevRangeWithScores(ctx context.Context, key string, star
 
 // ZRangeByScore 根据分数范围获取有序集合成员
 func (c *SingleClient) ZRangeByScore(ctx context.Context, key string, min, max string) ([]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZRangeByScore(ctx, key, &redis.ZRangeBy{
 		Min: min,
 		Max: max,
 	}).Result()
@@ -435,8 +450,8 @@ func (c *SingleClient) ZRangeByScore(ctx context.Context, key string, min, max s
 
 // ZRevRangeByScore 根据分数范围获取有序集合成员（逆序）
 func (c *SingleClient) ZRevRangeByScore(ctx context.Context, key string, max, min string) ([]string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
 		Min: min,
 		Max: max,
 	}).Result()
@@ -444,19 +459,19 @@ func (c *SingleClient) ZRevRangeByScore(ctx context.Context, key string, max, mi
 
 // ZCard 获取有序集合成员数量
 func (c *SingleClient) ZCard(ctx context.Context, key string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZCard(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZCard(ctx, key).Result()
 }
 
 // ZCount 计算指定分数范围内的成员数量
 func (c *SingleClient) ZCount(ctx context.Context, key, min, max string) (int64, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.ZCount(ctx, key, min, max).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).ZCount(ctx, key, min, max).Result()
 }
 
 // ZIncrBy 增加有序集合成员的分数
 func (c *SingleClient) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.ZIncrBy(ctx, key, increment, member).Result()
 }
 
@@ -466,7 +481,7 @@ func (c *SingleClient) ZIncrBy(ctx context.Context, key string, increment float6
 func (c *SingleClient) Del(ctx context.Context, keys ...string) (int64, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = c.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
 	}
 	return c.client.Del(ctx, prefixedKeys...).Result()
 }
@@ -475,46 +490,49 @@ func (c *SingleClient) Del(ctx context.Context, keys ...string) (int64, error) {
 func (c *SingleClient) Exists(ctx context.Context, keys ...string) (int64, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = c.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
 	}
-	return c.client.Exists(ctx, prefixedKeys...).Result()
+	return c.reader(ctx).Exists(ctx, prefixedKeys...).Result()
 }
 
 // Expire 设置键的过期时间
 func (c *SingleClient) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.Expire(ctx, key, expiration).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.client.Expire(ctx, key, jitterTTL(ctx, expiration)).Result()
 }
 
 // ExpireAt 设置键在指定时间过期
 func (c *SingleClient) ExpireAt(ctx context.Context, key string, tm time.Time) (bool, error) {
-	key = c.config.GetKeyWithPrefix(key)
+	key = c.config.prefixKey(ctx, key)
 	return c.client.ExpireAt(ctx, key, tm).Result()
 }
 
 // TTL 获取键的剩余生存时间
 func (c *SingleClient) TTL(ctx context.Context, key string) (time.Duration, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.TTL(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).TTL(ctx, key).Result()
 }
 
 // Type 获取键的数据类型
 func (c *SingleClient) Type(ctx context.Context, key string) (string, error) {
-	key = c.config.GetKeyWithPrefix(key)
-	return c.client.Type(ctx, key).Result()
+	key = c.config.prefixKey(ctx, key)
+	return c.reader(ctx).Type(ctx, key).Result()
 }
 
-// Keys 查找匹配模式的键
+// Keys 查找匹配模式的键，配置了DisableKeys时返回ErrKeysDisabled
 func (c *SingleClient) Keys(ctx context.Context, pattern string) ([]string, error) {
-	pattern = c.config.GetKeyWithPrefix(pattern)
-	keys, err := c.client.Keys(ctx, pattern).Result()
+	if c.config.Common.DisableKeys {
+		return nil, ErrKeysDisabled
+	}
+	pattern = c.config.prefixKey(ctx, pattern)
+	keys, err := c.reader(ctx).Keys(ctx, pattern).Result()
 	if err != nil {
 		return nil, err
 	}
 
 	// 移除前缀
-	if c.config.Common.KeyPrefix != "" {
-		prefixLen := len(c.config.Common.KeyPrefix)
+	if prefix := c.config.keyPrefix(ctx); prefix != "" {
+		prefixLen := len(prefix)
 		for i, key := range keys {
 			if len(key) > prefixLen {
 				keys[i] = key[prefixLen:]
@@ -527,15 +545,15 @@ func (c *SingleClient) Keys(ctx context.Context, pattern string) ([]string, erro
 
 // Scan 迭代数据库中的键
 func (c *SingleClient) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
-	match = c.config.GetKeyWithPrefix(match)
-	keys, cursor, err := c.client.Scan(ctx, cursor, match, count).Result()
+	match = c.config.prefixKey(ctx, match)
+	keys, cursor, err := c.reader(ctx).Scan(ctx, cursor, match, count).Result()
 	if err != nil {
 		return nil, cursor, err
 	}
 
 	// 移除前缀
-	if c.config.Common.KeyPrefix != "" {
-		prefixLen := len(c.config.Common.KeyPrefix)
+	if prefix := c.config.keyPrefix(ctx); prefix != "" {
+		prefixLen := len(prefix)
 		for i, key := range keys {
 			if len(key) > prefixLen {
 				keys[i] = key[prefixLen:]
@@ -546,24 +564,34 @@ func (c *SingleClient) Scan(ctx context.Context, cursor uint64, match string, co
 	return keys, cursor, nil
 }
 
+// DeleteByPattern 按模式删除键，用SCAN分批迭代匹配的键并以UNLINK删除，返回删除的键数
+func (c *SingleClient) DeleteByPattern(ctx context.Context, pattern string, opts *DeleteOptions) (int64, error) {
+	match := c.config.prefixKey(ctx, pattern)
+	return newPatternDelete(opts).run(ctx, c.client, match)
+}
+
 // Lua脚本操作
 
 // Eval 执行Lua脚本
 func (c *SingleClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = c.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
 	}
 	return c.client.Eval(ctx, script, prefixedKeys, args...).Result()
 }
 
-// EvalSha 通过SHA1执行Lua脚本
+// EvalSha 通过SHA1执行Lua脚本，脚本不在服务端缓存中时返回ErrScriptNotFound
 func (c *SingleClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) (interface{}, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, key := range keys {
-		prefixedKeys[i] = c.config.GetKeyWithPrefix(key)
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
+	}
+	result := c.client.EvalSha(ctx, sha1, prefixedKeys, args...)
+	if redis.HasErrorPrefix(result.Err(), "NOSCRIPT") {
+		return nil, ErrScriptNotFound
 	}
-	return c.client.EvalSha(ctx, sha1, prefixedKeys, args...).Result()
+	return result.Result()
 }
 
 // ScriptExists 检查脚本是否存在
@@ -586,6 +614,61 @@ func (c *SingleClient) ScriptLoad(ctx context.Context, script string) (string, e
 	return c.client.ScriptLoad(ctx, script).Result()
 }
 
+// Redis Functions操作
+
+// FunctionLoad 加载函数库，replace为true时替换同名函数库，返回函数库名称
+func (c *SingleClient) FunctionLoad(ctx context.Context, code string, replace bool) (string, error) {
+	if replace {
+		return c.client.FunctionLoadReplace(ctx, code).Result()
+	}
+	return c.client.FunctionLoad(ctx, code).Result()
+}
+
+// FunctionDelete 删除函数库
+func (c *SingleClient) FunctionDelete(ctx context.Context, library string) error {
+	return c.client.FunctionDelete(ctx, library).Err()
+}
+
+// FunctionList 列出名称匹配pattern的函数库，pattern为空时列出全部
+func (c *SingleClient) FunctionList(ctx context.Context, pattern string, withCode bool) ([]FunctionLibrary, error) {
+	libs, err := c.client.FunctionList(ctx, redis.FunctionListQuery{
+		LibraryNamePattern: pattern,
+		WithCode:           withCode,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return newFunctionLibraries(libs), nil
+}
+
+// FunctionDump 导出所有函数库
+func (c *SingleClient) FunctionDump(ctx context.Context) (string, error) {
+	return c.client.FunctionDump(ctx).Result()
+}
+
+// FunctionRestore 从FunctionDump的结果恢复函数库
+func (c *SingleClient) FunctionRestore(ctx context.Context, payload string) error {
+	return c.client.FunctionRestore(ctx, payload).Err()
+}
+
+// FCall 调用函数
+func (c *SingleClient) FCall(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
+	}
+	return c.client.FCall(ctx, function, prefixedKeys, args...).Result()
+}
+
+// FCallRO 调用只读函数，函数需声明no-writes标志，可以路由到从节点
+func (c *SingleClient) FCallRO(ctx context.Context, function string, keys []string, args ...interface{}) (interface{}, error) {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = c.config.prefixKey(ctx, key)
+	}
+	return c.reader(ctx).FCallRO(ctx, function, prefixedKeys, args...).Result()
+}
+
 // 管道操作
 
 // Pipeline 创建管道
@@ -602,4 +685,9 @@ func (c *SingleClient) TxPipeline() Pipeliner {
 		pipe:   c.client.TxPipeline(),
 		config: c.config,
 	}
+}
+
+// Watch 监视键并执行乐观事务，被监视的键被修改时按配置重试
+func (c *SingleClient) Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error {
+	return watch(ctx, c.client, c.config, fn, keys...)
 }
\ No newline at end of file
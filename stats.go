@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PoolStats 连接池统计
+type PoolStats struct {
+	// 从连接池取到空闲连接的次数
+	Hits uint32
+	// 连接池中没有空闲连接、需要新建连接的次数
+	Misses uint32
+	// 等待连接超时的次数
+	Timeouts uint32
+	// 等待连接的次数
+	WaitCount uint32
+	// 等待连接的总时间
+	WaitDuration time.Duration
+	// 连接总数
+	TotalConns uint32
+	// 空闲连接数
+	IdleConns uint32
+	// 因过期被移除的连接数
+	StaleConns uint32
+}
+
+// newPoolStats 转换go-redis的连接池统计
+func newPoolStats(stats *redis.PoolStats) *PoolStats {
+	if stats == nil {
+		return &PoolStats{}
+	}
+	return &PoolStats{
+		Hits:         stats.Hits,
+		Misses:       stats.Misses,
+		Timeouts:     stats.Timeouts,
+		WaitCount:    stats.WaitCount,
+		WaitDuration: time.Duration(stats.WaitDurationNs),
+		TotalConns:   stats.TotalConns,
+		IdleConns:    stats.IdleConns,
+		StaleConns:   stats.StaleConns,
+	}
+}
+
+// add 累加另一个连接池的统计
+func (s *PoolStats) add(other *PoolStats) {
+	s.Hits += other.Hits
+	s.Misses += other.Misses
+	s.Timeouts += other.Timeouts
+	s.WaitCount += other.WaitCount
+	s.WaitDuration += other.WaitDuration
+	s.TotalConns += other.TotalConns
+	s.IdleConns += other.IdleConns
+	s.StaleConns += other.StaleConns
+}
+
+// InUseConns 正在使用的连接数
+func (s *PoolStats) InUseConns() uint32 {
+	if s.IdleConns > s.TotalConns {
+		return 0
+	}
+	return s.TotalConns - s.IdleConns
+}
+
+// Saturation 连接池饱和度，即正在使用的连接数占poolSize的比例
+// 接近1时新的请求需要等待连接，持续等待超过PoolTimeout会返回连接池耗尽错误
+func (s *PoolStats) Saturation(poolSize int) float64 {
+	if poolSize <= 0 {
+		return 0
+	}
+	return float64(s.InUseConns()) / float64(poolSize)
+}
+
+// stats 获取已创建的alt客户端的连接池统计
+func (r *readRouter) stats() *PoolStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.alt == nil {
+		return nil
+	}
+	return newPoolStats(r.alt.PoolStats())
+}
+
+// nodeStats 收集集群每个节点的连接池统计
+func nodeStats(ctx context.Context, rdb *redis.ClusterClient) (map[string]*PoolStats, error) {
+	var mu sync.Mutex
+	stats := make(map[string]*PoolStats)
+
+	err := rdb.ForEachShard(ctx, func(ctx context.Context, node *redis.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stats[node.Options().Addr] = newPoolStats(node.PoolStats())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,276 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 标签集合的成员为条目的键（不含KeyPrefix），过期时间不短于其中任何条目的过期时间；
+// 条目过期或被删除后成员不会立即移除，而是在标签集合过期、InvalidateTags或TagKeys时清理。
+// 标签集合总是带有过期时间，没有过期时间的集合只可能是刚被SADD创建、尚未设置过期时间
+
+// persistentTagTTL 包含永不过期条目的标签集合使用的过期时间
+const persistentTagTTL = 100 * 365 * 24 * time.Hour
+
+// invalidateBatchSize 失效标签时单个管道删除的最大条目数
+const invalidateBatchSize = 1000
+
+// CacheOptions 带标签的缓存配置
+type CacheOptions struct {
+	// 标签集合的键前缀，默认"tag:"，标签集合的键同样会添加KeyPrefix
+	TagPrefix string
+	// 条目的默认过期时间，为0时使用客户端配置的DefaultTTL
+	TTL time.Duration
+}
+
+// Cache 带标签的旁路缓存
+// 写入条目时可以附带标签（如user:42、tenant:7），InvalidateTags删除带有指定标签的所有条目，
+// 代替Keys(pattern)加Del的做法。每个标签对应一个集合，集群模式下条目和标签集合可以在不同槽位
+type Cache struct {
+	client    Client
+	config    *Config
+	tagPrefix string
+	ttl       time.Duration
+}
+
+// NewCache 创建带标签的缓存
+func NewCache(client Client, opts *CacheOptions) *Cache {
+	c := &Cache{
+		client:    client,
+		config:    clientConfig(client),
+		tagPrefix: "tag:",
+	}
+	if opts != nil {
+		if opts.TagPrefix != "" {
+			c.tagPrefix = opts.TagPrefix
+		}
+		c.ttl = opts.TTL
+	}
+	return c
+}
+
+// Get 获取条目，不存在时返回ErrKeyNotFound
+func (c *Cache) Get(ctx context.Context, key string) (string, error) {
+	return c.client.Get(ctx, key)
+}
+
+// Set 写入条目并添加到每个标签的集合中，ttl为0时使用默认过期时间
+// 写入条目前后各更新一次标签集合，Set返回后条目一定在每个标签的集合中；
+// 与Set并发的InvalidateTags可能发生在两次更新之间，这时条目不会被本次失效删除，但能被之后的失效找到
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	if len(tags) == 0 {
+		return c.client.Set(ctx, key, value, ttl)
+	}
+
+	tagTTL := c.tagTTL(ctx, ttl)
+	if err := c.addTags(ctx, key, tagTTL, tags, nil); err != nil {
+		return err
+	}
+	return c.addTags(ctx, key, tagTTL, tags, func(pipe Pipeliner) {
+		pipe.Set(ctx, key, value, ttl)
+	})
+}
+
+// GetOrLoad 获取条目，不存在时调用load加载并带标签写入
+// 写入失败时同时返回加载到的值和错误；并发的未命中会分别调用load
+func (c *Cache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, tags []string, load func(ctx context.Context) (string, error)) (string, error) {
+	val, err := c.client.Get(ctx, key)
+	if err == nil || !errors.Is(err, ErrKeyNotFound) {
+		return val, err
+	}
+
+	val, err = load(ctx)
+	if err != nil {
+		return "", err
+	}
+	return val, c.Set(ctx, key, val, ttl, tags...)
+}
+
+// Delete 删除条目，返回删除的数量；条目在标签集合中的成员留待之后清理
+func (c *Cache) Delete(ctx context.Context, keys ...string) (int64, error) {
+	return c.deleteKeys(ctx, keys)
+}
+
+// InvalidateTags 删除带有任一指定标签的所有条目，返回删除的条目数量
+// 先读取标签集合并删除其中的条目，条目全部删除后才从标签集合中移除读到的成员：删除条目失败时标签集合保持不变，
+// 重新调用即可继续清理；期间新写入的条目留在标签集合中，不会被本次调用删除。
+// 失效尽力而为，多个标签之间（集群模式下条目之间）不是原子的，返回错误时部分条目可能已被删除
+func (c *Cache) InvalidateTags(ctx context.Context, tags ...string) (int64, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	pipe := c.client.Pipeline()
+	members := make([]*StringSliceCmd, len(tags))
+	for i, tag := range tags {
+		members[i] = pipe.SMembers(ctx, c.tagKey(tag))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]struct{})
+	var keys []string
+	for _, cmd := range members {
+		for _, key := range cmd.Val() {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+	deleted, err := c.deleteKeys(ctx, keys)
+	if err != nil {
+		return deleted, err
+	}
+
+	// 只移除读到的成员，集合为空时Redis自动删除标签集合
+	pipe = c.client.Pipeline()
+	for i, tag := range tags {
+		vals := members[i].Val()
+		if len(vals) == 0 {
+			continue
+		}
+		args := make([]interface{}, len(vals))
+		for j, val := range vals {
+			args[j] = val
+		}
+		pipe.SRem(ctx, c.tagKey(tag), args...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// TagKeys 获取带有指定标签且仍然存在的条目，同时从标签集合中移除已不存在的条目
+func (c *Cache) TagKeys(ctx context.Context, tag string) ([]string, error) {
+	tagKey := c.tagKey(tag)
+	members, err := c.client.SMembers(ctx, tagKey)
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+
+	pipe := c.client.Pipeline()
+	exists := make([]*IntCmd, len(members))
+	for i, key := range members {
+		exists[i] = pipe.Exists(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(members))
+	var stale []interface{}
+	for i, key := range members {
+		if exists[i].Val() > 0 {
+			keys = append(keys, key)
+		} else {
+			stale = append(stale, key)
+		}
+	}
+	if len(stale) > 0 {
+		if _, err := c.client.SRem(ctx, tagKey, stale...); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// tagKey 标签集合的键
+func (c *Cache) tagKey(tag string) string {
+	return c.tagPrefix + tag
+}
+
+// tagTTL 标签集合需要的过期时间，取条目实际生效的过期时间加上可能的抖动
+// 条目永不过期时为persistentTagTTL
+func (c *Cache) tagTTL(ctx context.Context, ttl time.Duration) time.Duration {
+	if c.config != nil {
+		ttl = c.config.GetTTL(ttl)
+	}
+	if ttl <= 0 {
+		return persistentTagTTL
+	}
+	return ttl + callOptionsFromContext(ctx).ttlJitter
+}
+
+// addTags 将条目加入每个标签的集合，queue不为nil时在同一个管道中排入其他命令
+// 标签集合的过期时间不够时通过extendTag延长
+func (c *Cache) addTags(ctx context.Context, key string, tagTTL time.Duration, tags []string, queue func(pipe Pipeliner)) error {
+	pipe := c.client.Pipeline()
+	if queue != nil {
+		queue(pipe)
+	}
+	ttls := make([]*DurationCmd, len(tags))
+	for i, tag := range tags {
+		tagKey := c.tagKey(tag)
+		pipe.SAdd(ctx, tagKey, key)
+		ttls[i] = pipe.TTL(ctx, tagKey)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	for i, tag := range tags {
+		if tagTTLCovers(ttls[i].Val(), tagTTL) {
+			continue
+		}
+		if err := c.extendTag(ctx, c.tagKey(tag), key, tagTTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extendTag 在乐观事务中把条目加入标签集合并延长集合的过期时间，不会缩短其他条目需要的过期时间
+// 延长时多留一半，减少频繁写入时的延长次数
+func (c *Cache) extendTag(ctx context.Context, tagKey, key string, tagTTL time.Duration) error {
+	return c.client.Watch(ctx, func(tx Tx) error {
+		current, err := tx.TTL(ctx, tagKey)
+		if err != nil {
+			return err
+		}
+		covered := tagTTLCovers(current, tagTTL)
+		return tx.TxPipelined(ctx, func(pipe Pipeliner) error {
+			pipe.SAdd(ctx, tagKey, key)
+			if !covered {
+				pipe.Expire(ctx, tagKey, (tagTTL+tagTTL/2).Truncate(time.Second)+3*time.Second)
+			}
+			return nil
+		})
+	}, tagKey)
+}
+
+// tagTTLCovers 判断标签集合的剩余时间current（TTL的结果）是否不短于tagTTL
+// TTL按秒四舍五入，多要求两秒；集合不存在或没有过期时间时不足
+func tagTTLCovers(current, tagTTL time.Duration) bool {
+	return current >= 0 && current >= tagTTL.Truncate(time.Second)+2*time.Second
+}
+
+// deleteKeys 逐个删除键，集群模式下键可以在不同槽位
+func (c *Cache) deleteKeys(ctx context.Context, keys []string) (int64, error) {
+	var deleted int64
+	for start := 0; start < len(keys); start += invalidateBatchSize {
+		end := start + invalidateBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		pipe := c.client.Pipeline()
+		cmds := make([]*IntCmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, pipe.Del(ctx, key))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return deleted, err
+		}
+		for _, cmd := range cmds {
+			deleted += cmd.Val()
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,285 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEmbeddedSingle 单机模式客户端连接内嵌服务端
+func TestEmbeddedSingle(t *testing.T) {
+	server, err := cachetest.StartServer(cachetest.WithKeyPrefix("embedded:"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	factory, err := cache.NewFactory(server.Config())
+	require.NoError(t, err)
+	client, err := factory.CreateClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	testEmbeddedOperations(t, ctx, client)
+
+	t.Run("过期时间按服务端的时钟计算", func(t *testing.T) {
+		assert.NoError(t, client.Set(ctx, "session", "abc", time.Minute))
+		ttl, err := client.TTL(ctx, "session")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, ttl)
+
+		server.Fake().Advance(time.Minute)
+		_, err = client.Get(ctx, "session")
+		assert.Equal(t, cache.ErrKeyNotFound, err)
+	})
+
+	t.Run("客户端写入的数据可以通过Fake读取", func(t *testing.T) {
+		assert.NoError(t, client.Set(ctx, "shared", "v", 0))
+		val, err := server.Fake().Get(ctx, "shared")
+		assert.NoError(t, err)
+		assert.Equal(t, "v", val)
+	})
+
+	t.Run("乐观事务", func(t *testing.T) {
+		assert.NoError(t, client.Set(ctx, "stock", 1, 0))
+		err := client.Watch(ctx, func(tx cache.Tx) error {
+			n, err := tx.Get(ctx, "stock")
+			if err != nil {
+				return err
+			}
+			server.Fake().Set(ctx, "stock", "changed", 0)
+			return tx.TxPipelined(ctx, func(pipe cache.Pipeliner) error {
+				pipe.Set(ctx, "stock", n+"0", 0)
+				return nil
+			})
+		}, "stock")
+		assert.True(t, errors.Is(err, cache.ErrTxFailed))
+	})
+}
+
+// TestEmbeddedRESP2 使用RESP2协议的客户端连接内嵌服务端
+func TestEmbeddedRESP2(t *testing.T) {
+	server, err := cachetest.StartServer()
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr(), Protocol: 2})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.HSet(ctx, "hash", "a", "1", "b", "2").Err())
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, rdb.HGetAll(ctx, "hash").Val())
+
+	assert.NoError(t, rdb.ZAdd(ctx, "zset", redis.Z{Score: 1.5, Member: "x"}, redis.Z{Score: 2, Member: "y"}).Err())
+	assert.Equal(t, []redis.Z{{Score: 1.5, Member: "x"}, {Score: 2, Member: "y"}}, rdb.ZRangeWithScores(ctx, "zset", 0, -1).Val())
+	assert.Equal(t, 1.5, rdb.ZScore(ctx, "zset", "x").Val())
+	assert.Equal(t, redis.Nil, rdb.ZScore(ctx, "zset", "missing").Err())
+
+	pubsub := rdb.Subscribe(ctx, "events")
+	defer pubsub.Close()
+	_, err = pubsub.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rdb.Publish(ctx, "events", "hello").Val())
+	msg, err := pubsub.ReceiveMessage(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", msg.Payload)
+}
+
+// TestEmbeddedCluster 集群模式客户端处理内嵌集群的MOVED和ASK重定向
+func TestEmbeddedCluster(t *testing.T) {
+	cluster, err := cachetest.StartCluster(3)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	client, err := cache.NewClientFromConfig(cluster.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	testEmbeddedOperations(t, ctx, client)
+
+	t.Run("键按哈希槽分布在各节点上", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			key := fmt.Sprintf("spread:%d", i)
+			assert.NoError(t, client.Set(ctx, key, i, 0))
+			_, err := cluster.NodeForKey(key).Fake().Get(ctx, key)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("跨槽的多键命令返回CROSSSLOT", func(t *testing.T) {
+		_, err := client.MGet(ctx, "{a}:1", "{b}:1")
+		assert.True(t, redis.HasErrorPrefix(err, "CROSSSLOT"))
+	})
+
+	t.Run("脚本不能访问其他节点的键", func(t *testing.T) {
+		other := "other:0"
+		for i := 1; cluster.NodeForKey(other) == cluster.NodeForKey("{script}"); i++ {
+			other = fmt.Sprintf("other:%d", i)
+		}
+		_, err := client.Eval(ctx, "return redis.call('GET', ARGV[1])", []string{"{script}"}, other)
+		assert.ErrorContains(t, err, "non local key")
+	})
+
+	t.Run("迁移哈希槽", func(t *testing.T) {
+		slot := cache.KeySlot("{user}")
+		from := cluster.NodeForKey("{user}")
+		to := 0
+		if from == cluster.Nodes()[0] {
+			to = 1
+		}
+
+		assert.NoError(t, client.Set(ctx, "{user}:1", "v1", 0))
+		require.NoError(t, cluster.MigrateSlot(slot, to))
+
+		// 迁移期间新键通过ASK写入目标节点，已有的键仍由源节点处理
+		assert.NoError(t, client.Set(ctx, "{user}:2", "v2", 0))
+		_, err := cluster.Nodes()[to].Fake().Get(ctx, "{user}:2")
+		assert.NoError(t, err)
+		val, err := client.Get(ctx, "{user}:2")
+		assert.NoError(t, err)
+		assert.Equal(t, "v2", val)
+		val, err = client.Get(ctx, "{user}:1")
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", val)
+
+		// 完成迁移后源节点返回MOVED
+		require.NoError(t, cluster.MoveSlot(slot, to))
+		assert.Same(t, cluster.Nodes()[to], cluster.NodeForKey("{user}"))
+		val, err = client.Get(ctx, "{user}:1")
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", val)
+
+		moved, ask := cluster.Redirects()
+		assert.Greater(t, moved, int64(0))
+		assert.Greater(t, ask, int64(0))
+	})
+}
+
+// TestEmbeddedSentinel 哨兵模式客户端在内嵌哨兵部署故障转移后切换到新主节点
+func TestEmbeddedSentinel(t *testing.T) {
+	sentinel, err := cachetest.StartSentinel("mymaster", 1, 2)
+	require.NoError(t, err)
+	defer sentinel.Close()
+
+	client, err := cache.NewClientFromConfig(sentinel.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	testEmbeddedOperations(t, ctx, client)
+
+	events := client.(*cache.SentinelClient).Events()
+	// 等待客户端的事件订阅和go-redis的主节点订阅都建立后再故障转移
+	assert.Eventually(t, func() bool {
+		var subscribers int64
+		for _, addr := range sentinel.Addrs() {
+			rdb := redis.NewClient(&redis.Options{Addr: addr})
+			subscribers += rdb.PubSubNumSub(ctx, "+switch-master").Val()["+switch-master"]
+			rdb.Close()
+		}
+		return subscribers >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	oldMaster := sentinel.Master()
+	assert.NoError(t, client.Set(ctx, "before", "v", 0))
+
+	require.NoError(t, sentinel.Failover())
+	assert.NotSame(t, oldMaster, sentinel.Master())
+
+	select {
+	case event := <-events:
+		assert.Equal(t, cache.EventSwitchMaster, event.Type)
+		assert.Equal(t, sentinel.Master().Addr(), event.NewMaster)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no +switch-master event received")
+	}
+
+	// 原主节点成为从节点，写命令最终被发往新主节点
+	assert.Eventually(t, func() bool {
+		return client.Set(ctx, "after", "v", 0) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	val, err := client.Get(ctx, "before")
+	assert.NoError(t, err)
+	assert.Equal(t, "v", val)
+}
+
+// testEmbeddedOperations 三种模式共用的操作，键使用相同的哈希标签以便在集群模式下执行多键命令
+func testEmbeddedOperations(t *testing.T, ctx context.Context, client cache.Client) {
+	t.Run("Lua脚本", func(t *testing.T) {
+		script := cache.NewScript("return redis.call('INCRBY', KEYS[1], ARGV[1])")
+		_, err := client.EvalSha(ctx, script.Hash(), []string{"{op}:counter"}, 1)
+		assert.ErrorIs(t, err, cache.ErrScriptNotFound)
+
+		// 第一次执行改用EVAL并缓存脚本，之后EVALSHA直接命中
+		for i := int64(1); i <= 2; i++ {
+			n, err := script.Run(ctx, client, []string{"{op}:counter"}, 5).Int64()
+			assert.NoError(t, err)
+			assert.Equal(t, 5*i, n)
+		}
+		_, err = client.EvalSha(ctx, script.Hash(), []string{"{op}:counter"}, 1)
+		assert.NoError(t, err)
+
+		_, err = client.Eval(ctx, "return redis.call('GET', KEYS[1])", []string{"{op}:missing"})
+		assert.Equal(t, redis.Nil, err)
+	})
+
+	t.Run("数据操作", func(t *testing.T) {
+		assert.NoError(t, client.Ping(ctx))
+
+		assert.NoError(t, client.Set(ctx, "{op}:name", "alice", 0))
+		val, err := client.Get(ctx, "{op}:name")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", val)
+		_, err = client.Get(ctx, "{op}:missing")
+		assert.Equal(t, cache.ErrKeyNotFound, err)
+
+		ok, err := client.SetNX(ctx, "{op}:name", "bob", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.NoError(t, client.HMSet(ctx, "{op}:user", map[string]interface{}{"name": "alice", "age": 30}))
+		age, err := client.HIncrBy(ctx, "{op}:user", "age", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(31), age)
+
+		client.ZAdd(ctx, "{op}:board", cache.ZMember{Score: 10, Member: "p1"}, cache.ZMember{Score: 20, Member: "p2"})
+		members, err := client.ZRevRangeWithScores(ctx, "{op}:board", 0, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, []cache.ZMember{{Score: 20, Member: "p2"}, {Score: 10, Member: "p1"}}, members)
+
+		_, err = client.LPush(ctx, "{op}:user", "x")
+		assert.True(t, redis.HasErrorPrefix(err, "WRONGTYPE"))
+
+		n, err := client.Del(ctx, "{op}:name", "{op}:user", "{op}:board")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	t.Run("管道", func(t *testing.T) {
+		pipe := client.Pipeline()
+		pipe.Set(ctx, "{pipe}:counter", 1, 0)
+		incr := pipe.Incr(ctx, "{pipe}:counter")
+		missing := pipe.Get(ctx, "{pipe}:missing")
+		_, err := pipe.Exec(ctx)
+		assert.Equal(t, redis.Nil, err)
+		assert.Equal(t, int64(2), incr.Val())
+		assert.Equal(t, cache.ErrKeyNotFound, missing.Err())
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, client.Set(ctx, fmt.Sprintf("{scan}:%d", i), i, 0))
+		}
+		keys, err := client.Keys(ctx, "{scan}:*")
+		assert.NoError(t, err)
+		assert.Len(t, keys, 5)
+	})
+}
@@ -0,0 +1,142 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAtomicOperations 在三种客户端上测试原子操作脚本
+func TestAtomicOperations(t *testing.T) {
+	for name, deployment := range embeddedTestDeployments(t) {
+		t.Run(name, func(t *testing.T) {
+			client, err := cache.NewClientFromConfig(deployment.config)
+			require.NoError(t, err)
+			defer client.Close()
+			testAtomicOperations(t, client, deployment.advance)
+		})
+	}
+}
+
+// TestAtomicOperationsFake 在内存客户端上测试原子操作脚本，字段过期通过拨动时钟触发
+func TestAtomicOperationsFake(t *testing.T) {
+	fake := cachetest.NewFake(cachetest.WithKeyPrefix("atomic:"))
+	defer fake.Close()
+	testAtomicOperations(t, fake, fake.Advance)
+}
+
+// testAtomicOperations advance让时间前进，用于检查字段过期
+func testAtomicOperations(t *testing.T, client cache.Client, advance func(d time.Duration)) {
+	ctx := context.Background()
+
+	t.Run("CompareAndSet和CompareAndDelete", func(t *testing.T) {
+		key := "lock"
+		defer client.Del(ctx, key)
+		assert.NoError(t, client.Set(ctx, key, "owner-1", time.Minute))
+
+		ok, err := cache.CompareAndSet(ctx, client, key, "owner-2", "owner-3", 0)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = cache.CompareAndSet(ctx, client, key, "owner-1", "owner-2", 0)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ttl, _ := client.TTL(ctx, key)
+		assert.Greater(t, ttl, time.Duration(0))
+
+		ok, err = cache.CompareAndDelete(ctx, client, key, "owner-1")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = cache.CompareAndDelete(ctx, client, key, "owner-2")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("GetAndExpire", func(t *testing.T) {
+		key := "session"
+		defer client.Del(ctx, key)
+
+		_, err := cache.GetAndExpire(ctx, client, key, time.Minute)
+		assert.ErrorIs(t, err, cache.ErrKeyNotFound)
+
+		assert.NoError(t, client.Set(ctx, key, "data", time.Second))
+		val, err := cache.GetAndExpire(ctx, client, key, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, "data", val)
+		ttl, _ := client.TTL(ctx, key)
+		assert.Greater(t, ttl, time.Minute)
+	})
+
+	t.Run("SetIfVersionGreater", func(t *testing.T) {
+		key := "versioned"
+		defer client.Del(ctx, key)
+
+		ok, err := cache.SetIfVersionGreater(ctx, client, key, 2, "v2", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = cache.SetIfVersionGreater(ctx, client, key, 1, "v1", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		val, version, err := cache.GetVersioned(ctx, client, key)
+		assert.NoError(t, err)
+		assert.Equal(t, "v2", val)
+		assert.Equal(t, int64(2), version)
+	})
+
+	t.Run("IncrWithCap", func(t *testing.T) {
+		key := "quota"
+		defer client.Del(ctx, key)
+
+		val, ok, err := cache.IncrWithCap(ctx, client, key, 3, 5, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(3), val)
+
+		val, ok, err = cache.IncrWithCap(ctx, client, key, 3, 5, time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, int64(3), val)
+	})
+
+	t.Run("哈希字段过期", func(t *testing.T) {
+		key := "fields"
+		defer client.Del(ctx, key)
+
+		assert.NoError(t, cache.HSetWithTTL(ctx, client, key, "short", "a", 50*time.Millisecond))
+		assert.NoError(t, cache.HSetWithTTL(ctx, client, key, "long", "b", time.Minute))
+
+		val, err := cache.HGetWithTTL(ctx, client, key, "short")
+		assert.NoError(t, err)
+		assert.Equal(t, "a", val)
+
+		advance(100 * time.Millisecond)
+		_, err = cache.HGetWithTTL(ctx, client, key, "short")
+		assert.ErrorIs(t, err, cache.ErrKeyNotFound)
+
+		val, err = cache.HGetWithTTL(ctx, client, key, "long")
+		assert.NoError(t, err)
+		assert.Equal(t, "b", val)
+
+		assert.NoError(t, cache.HDelWithTTL(ctx, client, key, "long"))
+		_, err = cache.HGetWithTTL(ctx, client, key, "long")
+		assert.ErrorIs(t, err, cache.ErrKeyNotFound)
+
+		// 不过期的字段使哈希表不再过期
+		assert.NoError(t, cache.HSetWithTTL(ctx, client, key, "forever", "c", 0))
+		ttl, err := client.TTL(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(-1), ttl)
+		assert.NoError(t, cache.HSetWithTTL(ctx, client, key, "later", "d", time.Minute))
+		ttl, err = client.TTL(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(-1), ttl)
+	})
+}
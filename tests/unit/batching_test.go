@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBatchingClient 测试并发调用被合并为管道，且每个调用方都收到自己的结果
+func TestBatchingClient(t *testing.T) {
+	client, err := cache.NewClientFromConfig(unreachableConfig(cache.StartupLazy))
+	assert.NoError(t, err)
+
+	batching := cache.NewBatchingClient(client, &cache.BatchingOptions{
+		Window:   20 * time.Millisecond,
+		MaxBatch: 10,
+	})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make([]error, 30)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = batching.Get(ctx, "key")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.Error(t, err)
+	}
+	stats := batching.BatchingStats()
+	assert.Equal(t, int64(30), stats.Commands)
+	assert.GreaterOrEqual(t, stats.Batches, int64(3))
+	assert.Less(t, stats.Batches, int64(30))
+
+	assert.NoError(t, batching.Close())
+	_, err = batching.Get(ctx, "key")
+	assert.Equal(t, cache.ErrClientClosed, err)
+}
+
+// TestBatchingClientContext 测试调用方的ctx结束时不等待批次发送
+func TestBatchingClientContext(t *testing.T) {
+	client, err := cache.NewClientFromConfig(unreachableConfig(cache.StartupLazy))
+	assert.NoError(t, err)
+
+	batching := cache.NewBatchingClient(client, &cache.BatchingOptions{Window: time.Second})
+	defer batching.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = batching.HGet(ctx, "key", "field")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+// TestBatchingClientResults 测试同一批次中未命中的命令不影响其他调用方的结果
+func TestBatchingClientResults(t *testing.T) {
+	server, err := cachetest.StartServer()
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := cache.NewClientFromConfig(server.Config())
+	require.NoError(t, err)
+	batching := cache.NewBatchingClient(client, &cache.BatchingOptions{Window: time.Second, MaxBatch: 3})
+	defer batching.Close()
+
+	ctx := context.Background()
+	assert.NoError(t, client.Set(ctx, "existing", "v", time.Minute))
+
+	var (
+		wg              sync.WaitGroup
+		missErr, setErr error
+		hitErr          error
+		hit             string
+	)
+	// 按顺序加入批次，未命中的命令排在第一条
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		_, missErr = batching.Get(ctx, "missing")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		defer wg.Done()
+		setErr = batching.Set(ctx, "written", "w", time.Minute)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		defer wg.Done()
+		hit, hitErr = batching.Get(ctx, "existing")
+	}()
+	wg.Wait()
+
+	assert.Equal(t, cache.ErrKeyNotFound, missErr)
+	assert.NoError(t, setErr)
+	assert.NoError(t, hitErr)
+	assert.Equal(t, "v", hit)
+	assert.Equal(t, int64(1), batching.BatchingStats().Batches)
+
+	val, err := client.Get(ctx, "written")
+	assert.NoError(t, err)
+	assert.Equal(t, "w", val)
+}
+
+// TestBatchingClientDeadline 测试批次在调用方的截止时间下执行
+func TestBatchingClientDeadline(t *testing.T) {
+	faulty := cachetest.NewFaultyClient(cachetest.NewFake(), &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{{Commands: []string{"exec"}, Latency: 5 * time.Second}},
+	})
+	batching := cache.NewBatchingClient(faulty, &cache.BatchingOptions{Window: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	_, err := batching.Get(ctx, "key")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// 管道随截止时间结束，关闭时不需要等待注入的延迟
+	start := time.Now()
+	assert.NoError(t, batching.Close())
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+var errNetwork = errors.New("dial tcp: connection refused")
+
+// TestCircuitBreakerTrip 测试错误率达到阈值后熔断
+func TestCircuitBreakerTrip(t *testing.T) {
+	breaker := cache.NewCircuitBreaker("test", cache.CircuitBreakerConfig{
+		Enabled:              true,
+		MinRequests:          4,
+		FailureRateThreshold: 0.5,
+		OpenTimeout:          time.Hour,
+	})
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, breaker.Allow())
+		if i%2 == 0 {
+			breaker.Record(errNetwork, time.Millisecond)
+		} else {
+			breaker.Record(nil, time.Millisecond)
+		}
+	}
+
+	assert.Equal(t, cache.BreakerOpen, breaker.State())
+	assert.ErrorIs(t, breaker.Allow(), cache.ErrCircuitOpen)
+}
+
+// TestCircuitBreakerIgnoresNil 测试键不存在不计入失败
+func TestCircuitBreakerIgnoresNil(t *testing.T) {
+	breaker := cache.NewCircuitBreaker("test", cache.CircuitBreakerConfig{
+		Enabled:     true,
+		MinRequests: 2,
+	})
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, breaker.Allow())
+		breaker.Record(redis.Nil, time.Millisecond)
+	}
+	assert.Equal(t, cache.BreakerClosed, breaker.State())
+}
+
+// TestCircuitBreakerSlowCalls 测试慢调用率触发熔断
+func TestCircuitBreakerSlowCalls(t *testing.T) {
+	breaker := cache.NewCircuitBreaker("test", cache.CircuitBreakerConfig{
+		Enabled:               true,
+		MinRequests:           3,
+		SlowCallDuration:      100 * time.Millisecond,
+		SlowCallRateThreshold: 0.6,
+		OpenTimeout:           time.Hour,
+	})
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, breaker.Allow())
+		breaker.Record(nil, time.Second)
+	}
+	assert.Equal(t, cache.BreakerOpen, breaker.State())
+}
+
+// TestCircuitBreakerHalfOpen 测试半开状态的恢复与再次熔断
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	var transitions []cache.BreakerState
+	breaker := cache.NewCircuitBreaker("test", cache.CircuitBreakerConfig{
+		Enabled:             true,
+		MinRequests:         1,
+		OpenTimeout:         20 * time.Millisecond,
+		HalfOpenMaxRequests: 1,
+	})
+	breaker.OnStateChange(func(name string, from, to cache.BreakerState) {
+		assert.Equal(t, "test", name)
+		transitions = append(transitions, to)
+	})
+
+	assert.NoError(t, breaker.Allow())
+	breaker.Record(errNetwork, time.Millisecond)
+	assert.Equal(t, cache.BreakerOpen, breaker.State())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, breaker.Allow())
+	// 半开状态下探测请求数已满
+	assert.ErrorIs(t, breaker.Allow(), cache.ErrCircuitOpen)
+	breaker.Record(errNetwork, time.Millisecond)
+	assert.Equal(t, cache.BreakerOpen, breaker.State())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, breaker.Allow())
+	breaker.Record(nil, time.Millisecond)
+	assert.Equal(t, cache.BreakerClosed, breaker.State())
+
+	assert.Equal(t, []cache.BreakerState{
+		cache.BreakerOpen, cache.BreakerHalfOpen, cache.BreakerOpen,
+		cache.BreakerHalfOpen, cache.BreakerClosed,
+	}, transitions)
+}
+
+// TestCircuitBreakerConfigValidation 测试熔断器配置验证
+func TestCircuitBreakerConfigValidation(t *testing.T) {
+	config := cache.DefaultConfig()
+	config.Common.CircuitBreaker = &cache.CircuitBreakerConfig{
+		Enabled:              true,
+		FailureRateThreshold: 1.5,
+	}
+	assert.ErrorIs(t, config.Validate(), cache.ErrInvalidCircuitBreaker)
+
+	config.Common.CircuitBreaker.FailureRateThreshold = 0.5
+	assert.NoError(t, config.Validate())
+}
@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBulkWriterFailures 测试Redis不可用时每个条目都记录为失败并报告进度
+func TestBulkWriterFailures(t *testing.T) {
+	client, err := cache.NewClientFromConfig(unreachableConfig(cache.StartupLazy))
+	assert.NoError(t, err)
+	defer client.Close()
+
+	var progressCalls atomic.Int64
+	writer := cache.NewBulkWriter(client, &cache.BulkOptions{
+		BatchSize:   10,
+		Concurrency: 2,
+		OnProgress: func(progress cache.BulkProgress) {
+			progressCalls.Add(1)
+		},
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 25; i++ {
+		assert.NoError(t, writer.Add(ctx, cache.BulkSet(fmt.Sprintf("key:%d", i), i, 0)))
+	}
+	result, err := writer.Close(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), result.Written)
+	assert.Equal(t, int64(25), result.Failed)
+	assert.Len(t, result.Failures, 25)
+	assert.Error(t, result.Failures[0].Err)
+	assert.GreaterOrEqual(t, progressCalls.Load(), int64(3))
+
+	assert.Equal(t, cache.ErrClientClosed, writer.Add(ctx, cache.BulkSet("key", 1, 0)))
+}
+
+// TestBulkWriterConcurrentFlush 测试Flush与Add并发时等待所有已发送的管道
+func TestBulkWriterConcurrentFlush(t *testing.T) {
+	fake := cachetest.NewFake()
+	writer := cache.NewBulkWriter(fake, &cache.BulkOptions{BatchSize: 1, Concurrency: 2})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				assert.NoError(t, writer.Add(ctx, cache.BulkSet(fmt.Sprintf("key:%d:%d", i, j), j, 0)))
+			}
+		}(i)
+	}
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, writer.Flush(ctx))
+	}
+	wg.Wait()
+
+	result, err := writer.Close(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(800), result.Written)
+	keys, err := fake.Keys(ctx, "key:*")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 800)
+}
+
+// TestBulkWriter 在三种客户端上测试批量写入
+func TestBulkWriter(t *testing.T) {
+	for name, config := range embeddedTestConfigs(t) {
+		t.Run(name, func(t *testing.T) {
+			config.Common.DefaultTTL = time.Hour
+			client, err := cache.NewClientFromConfig(config)
+			require.NoError(t, err)
+			defer client.Close()
+			testBulkWriter(t, client)
+		})
+	}
+}
+
+func testBulkWriter(t *testing.T, client cache.Client) {
+	ctx := context.Background()
+	defer client.Del(ctx, "bulk:str:1", "bulk:str:2")
+	defer client.Del(ctx, "bulk:hash", "bulk:zset", "bulk:list")
+
+	writer := cache.NewBulkWriter(client, &cache.BulkOptions{BatchSize: 3, MaxArgs: 2})
+
+	fields := map[string]interface{}{"a": 1, "b": 2, "c": 3}
+	members := []cache.ZMember{{Score: 1, Member: "m1"}, {Score: 2, Member: "m2"}, {Score: 3, Member: "m3"}}
+	assert.NoError(t, writer.Add(ctx,
+		cache.BulkSet("bulk:str:1", "v1", time.Minute),
+		cache.BulkSet("bulk:str:2", "v2", 0),
+		cache.BulkHSet("bulk:hash", fields, 0),
+		cache.BulkZAdd("bulk:zset", time.Minute, members...),
+		cache.BulkRPush("bulk:list", 0, "x", "y", "z"),
+	))
+	result, err := writer.Close(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), result.Written)
+	assert.Equal(t, int64(0), result.Failed)
+
+	hash, err := client.HGetAll(ctx, "bulk:hash")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}, hash)
+
+	count, err := client.ZCard(ctx, "bulk:zset")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	list, err := client.LRange(ctx, "bulk:list", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x", "y", "z"}, list)
+
+	// 未指定过期时间的条目使用DefaultTTL
+	ttl, err := client.TTL(ctx, "bulk:list")
+	assert.NoError(t, err)
+	assert.Greater(t, ttl, time.Minute)
+	ttl, err = client.TTL(ctx, "bulk:zset")
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, ttl, time.Minute)
+}
@@ -0,0 +1,393 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFakeDataOperations 测试内存客户端的数据操作语义
+func TestFakeDataOperations(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFake()
+	defer client.Close()
+
+	t.Run("字符串操作", func(t *testing.T) {
+		assert.NoError(t, client.Set(ctx, "name", "alice", 0))
+		val, err := client.Get(ctx, "name")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", val)
+
+		_, err = client.Get(ctx, "missing")
+		assert.Equal(t, cache.ErrKeyNotFound, err)
+
+		ok, err := client.SetNX(ctx, "name", "bob", 0)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.NoError(t, client.MSet(ctx, "a", 1, "b", 2.5))
+		vals, err := client.MGet(ctx, "a", "b", "missing")
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"1", "2.5", nil}, vals)
+
+		n, err := client.IncrBy(ctx, "a", 9)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), n)
+		_, err = client.Incr(ctx, "name")
+		assert.Error(t, err)
+	})
+
+	t.Run("哈希表操作", func(t *testing.T) {
+		assert.NoError(t, client.HMSet(ctx, "user:1", map[string]interface{}{"name": "alice", "age": 30}))
+		all, err := client.HGetAll(ctx, "user:1")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"name": "alice", "age": "30"}, all)
+
+		age, err := client.HIncrBy(ctx, "user:1", "age", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(31), age)
+
+		_, err = client.HGet(ctx, "user:1", "email")
+		assert.Equal(t, cache.ErrKeyNotFound, err)
+
+		_, err = client.LPush(ctx, "user:1", "x")
+		assert.True(t, redis.HasErrorPrefix(err, "WRONGTYPE"))
+	})
+
+	t.Run("列表操作", func(t *testing.T) {
+		_, err := client.RPush(ctx, "queue", "a", "b", "c", "b")
+		assert.NoError(t, err)
+		items, err := client.LRange(ctx, "queue", 0, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "b"}, items)
+
+		removed, err := client.LRem(ctx, "queue", 0, "b")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), removed)
+
+		first, err := client.LPop(ctx, "queue")
+		assert.NoError(t, err)
+		assert.Equal(t, "a", first)
+		client.LPop(ctx, "queue")
+
+		// 列表为空后键被删除
+		n, err := client.Exists(ctx, "queue")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+	})
+
+	t.Run("集合操作", func(t *testing.T) {
+		client.SAdd(ctx, "s1", "a", "b", "c")
+		client.SAdd(ctx, "s2", "b", "c", "d")
+		inter, err := client.SInter(ctx, "s1", "s2")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b", "c"}, inter)
+		diff, err := client.SDiff(ctx, "s1", "s2")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a"}, diff)
+	})
+
+	t.Run("有序集合操作", func(t *testing.T) {
+		client.ZAdd(ctx, "board", cache.ZMember{Score: 10, Member: "p1"}, cache.ZMember{Score: 30, Member: "p3"}, cache.ZMember{Score: 20, Member: "p2"})
+		top, err := client.ZRevRange(ctx, "board", 0, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"p3", "p2"}, top)
+
+		byScore, err := client.ZRangeByScore(ctx, "board", "(10", "+inf")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"p2", "p3"}, byScore)
+
+		rank, err := client.ZRank(ctx, "board", "p2")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rank)
+
+		score, err := client.ZIncrBy(ctx, "board", 15, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, float64(25), score)
+	})
+}
+
+// TestFakeExpiration 测试过期时间按可控的时钟计算
+func TestFakeExpiration(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFake(cachetest.WithDefaultTTL(time.Hour))
+
+	assert.NoError(t, client.Set(ctx, "session", "abc", time.Minute))
+	assert.NoError(t, client.Set(ctx, "default", "v", 0))
+	client.HSet(ctx, "forever", "f", "v")
+
+	ttl, err := client.TTL(ctx, "session")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+	ttl, _ = client.TTL(ctx, "forever")
+	assert.Equal(t, time.Duration(-1), ttl)
+	ttl, _ = client.TTL(ctx, "missing")
+	assert.Equal(t, time.Duration(-2), ttl)
+
+	client.Advance(59 * time.Second)
+	_, err = client.Get(ctx, "session")
+	assert.NoError(t, err)
+
+	client.Advance(time.Second)
+	_, err = client.Get(ctx, "session")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+
+	client.Advance(time.Hour)
+	_, err = client.Get(ctx, "default")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+
+	ok, err := client.Expire(ctx, "forever", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	client.Advance(time.Second)
+	n, _ := client.Exists(ctx, "forever")
+	assert.Equal(t, int64(0), n)
+}
+
+// TestFakeKeyPrefix 测试键前缀和Scan
+func TestFakeKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFake(cachetest.WithKeyPrefix("app:"))
+
+	for _, key := range []string{"user:1", "user:2", "user:3", "order:1"} {
+		assert.NoError(t, client.Set(ctx, key, "v", 0))
+	}
+
+	keys, err := client.Keys(ctx, "user:*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1", "user:2", "user:3"}, keys)
+
+	_, err = client.Get(cache.WithoutKeyPrefix(ctx), "app:order:1")
+	assert.NoError(t, err)
+
+	var scanned []string
+	var cursor uint64
+	for {
+		var page []string
+		page, cursor, err = client.Scan(ctx, cursor, "user:*", 2)
+		assert.NoError(t, err)
+		scanned = append(scanned, page...)
+		if cursor == 0 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"user:1", "user:2", "user:3"}, scanned)
+}
+
+// TestFakePipeline 测试内存管道的结果在Exec之后可读
+func TestFakePipeline(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFake()
+
+	pipe := client.Pipeline()
+	pipe.Set(ctx, "counter", 1, 0)
+	incr := pipe.Incr(ctx, "counter")
+	missing := pipe.Get(ctx, "missing")
+	members := pipe.ZRangeWithScores(ctx, "empty", 0, -1)
+	assert.Equal(t, int64(0), incr.Val())
+
+	results, err := pipe.Exec(ctx)
+	assert.Equal(t, redis.Nil, err)
+	assert.Len(t, results, 4)
+	assert.Equal(t, int64(2), incr.Val())
+	assert.Equal(t, cache.ErrKeyNotFound, missing.Err())
+	assert.Empty(t, members.Val())
+}
+
+// TestFakeWatch 测试内存乐观事务在并发修改时重试
+func TestFakeWatch(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFake()
+	client.Set(ctx, "stock", 0, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := client.Watch(ctx, func(tx cache.Tx) error {
+				n, err := tx.Get(ctx, "stock")
+				if err != nil {
+					return err
+				}
+				return tx.TxPipelined(ctx, func(pipe cache.Pipeliner) error {
+					pipe.Set(ctx, "stock", n+"1", 0)
+					return nil
+				})
+			}, "stock")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	val, err := client.Get(ctx, "stock")
+	assert.NoError(t, err)
+	assert.Equal(t, "01111111111", val)
+
+	// 回调中修改被监视的键，重试后仍然失败
+	err = client.Watch(ctx, func(tx cache.Tx) error {
+		client.Set(ctx, "stock", "changed", 0)
+		return tx.TxPipelined(ctx, func(pipe cache.Pipeliner) error {
+			pipe.Del(ctx, "stock")
+			return nil
+		})
+	}, "stock")
+	assert.True(t, errors.Is(err, cache.ErrTxFailed))
+}
+
+// TestFakeBulkWriter 测试内存客户端可以替代Redis运行依赖Client的组件
+func TestFakeBulkWriter(t *testing.T) {
+	testBulkWriter(t, cachetest.NewFake(cachetest.WithDefaultTTL(time.Hour)))
+}
+
+// TestFakeScripts 测试内存客户端执行Lua脚本的语义和返回值的转换
+func TestFakeScripts(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFake(cachetest.WithKeyPrefix("app:"))
+	defer client.Close()
+
+	assert.NoError(t, client.Set(ctx, "str", "15", 0))
+	assert.NoError(t, client.HSet(ctx, "hash", "f", "v"))
+
+	tests := []struct {
+		name   string
+		script string
+		keys   []string
+		args   []interface{}
+		want   interface{}
+	}{
+		{"整数", "return 1", nil, nil, int64(1)},
+		{"数字截断", "return 3.9", nil, nil, int64(3)},
+		{"true", "return true", nil, nil, int64(1)},
+		{"字符串", "return 'a' .. \"b\" .. [[c]]", nil, nil, "abc"},
+		{"数组到第一个nil为止", "return {1, 'two', {3}, nil, 5}", nil, nil, []interface{}{int64(1), "two", []interface{}{int64(3)}}},
+		{"状态回复", "return redis.status_reply('FINE')", nil, nil, "FINE"},
+		{"KEYS和ARGV", "return {KEYS[1], ARGV[1], ARGV[2], #KEYS}", []string{"k"}, []interface{}{"a", 2}, []interface{}{"app:k", "a", "2", int64(1)}},
+		{"redis.call", "return redis.call('GET', KEYS[1]) + 1", []string{"str"}, nil, int64(16)},
+		{"未命中为false", "return redis.call('GET', KEYS[1]) == false", []string{"missing"}, nil, int64(1)},
+		{"多值回复", "local r = redis.call('HGETALL', KEYS[1]) return r[1] .. '=' .. r[2]", []string{"hash"}, nil, "f=v"},
+		{"状态回复转换为表", "return redis.call('SET', KEYS[1], 'x')['ok']", []string{"set"}, nil, "OK"},
+		{"pcall", "return redis.pcall('INCR', KEYS[1])['err']", []string{"hash"}, nil, "WRONGTYPE Operation against a key holding the wrong kind of value"},
+		{"控制流", `
+local sum = 0
+for i = 1, 10 do
+	if i % 2 == 0 then
+		sum = sum + i
+	elseif i == 5 then
+		sum = sum + 100
+	end
+end
+local n = 0
+while true do
+	n = n + 1
+	if n >= 3 then break end
+end
+repeat n = n + 1 until n > 5
+return {sum, n}`, nil, nil, []interface{}{int64(130), int64(6)}},
+		{"ipairs和pairs", `
+local t = {}
+for i, v in ipairs({'a', 'b'}) do table.insert(t, i .. v) end
+for k, v in pairs({x = 1, y = 2}) do table.insert(t, k .. v) end
+return table.concat(t, ',')`, nil, nil, "1a,2b,x1,y2"},
+		{"基础库", "return {tonumber('0x10'), tostring(1.5), type({}), string.format('%.0f-%05d-%s', 2.5e3, 42, 'x'), math.floor(-1.5), string.sub('hello', 2, -2), unpack({7, 8})}",
+			nil, nil, []interface{}{int64(16), "1.5", "table", "2500-00042-x", int64(-2), "ell", int64(7), int64(8)}},
+		{"逻辑运算", "return (nil or 'a') .. tostring(false and 1) .. tostring(not nil)", nil, nil, "afalsetrue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := client.Eval(ctx, tt.script, tt.keys, tt.args...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, val)
+		})
+	}
+
+	// 脚本返回nil或false时与go-redis一致返回redis.Nil
+	_, err := client.Eval(ctx, "return redis.call('GET', KEYS[1])", []string{"missing"})
+	assert.Equal(t, redis.Nil, err)
+
+	// 脚本原子地修改数据，键添加前缀
+	_, err = client.Eval(ctx, "redis.call('SET', KEYS[1], ARGV[1]) redis.call('PEXPIRE', KEYS[1], 1000)", []string{"written"}, "v")
+	assert.Equal(t, redis.Nil, err)
+	val, err := client.Get(ctx, "written")
+	assert.NoError(t, err)
+	assert.Equal(t, "v", val)
+	ttl, err := client.TTL(ctx, "written")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, ttl)
+}
+
+// TestFakeScriptErrors 测试脚本的编译错误、运行时错误和命令错误
+func TestFakeScriptErrors(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFake()
+	defer client.Close()
+	assert.NoError(t, client.HSet(ctx, "hash", "f", "v"))
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"编译错误", "return 1 +", "ERR Error compiling script"},
+		{"不支持的语法", "local function f() end", "function definitions are not supported"},
+		{"未定义的全局变量", "return x", "Script attempted to access nonexistent global variable 'x'"},
+		{"创建全局变量", "x = 1", "Script attempted to create global variable 'x'"},
+		{"运行时错误的行号", "local a = 1\nreturn a + nil", "user_script:2: attempt to perform arithmetic on a nil value"},
+		{"命令错误原样返回", "return redis.call('INCR', KEYS[1])", "WRONGTYPE"},
+		{"不支持的命令", "return redis.call('EVAL', 'return 1', 0)", "Unknown Redis command called from script"},
+		{"error_reply", "return redis.error_reply('MY failure')", "MY failure"},
+		{"error", "error({err = 'CUSTOM error'})", "CUSTOM error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Eval(ctx, tt.script, []string{"hash"})
+			assert.ErrorContains(t, err, tt.want)
+			var redisErr redis.Error
+			assert.ErrorAs(t, err, &redisErr)
+		})
+	}
+}
+
+// TestFakeScriptCache 测试脚本缓存和Script在EVALSHA未命中时改用EVAL
+func TestFakeScriptCache(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFake()
+	defer client.Close()
+
+	script := cache.NewScript("return redis.call('INCRBY', KEYS[1], ARGV[1])")
+	_, err := client.EvalSha(ctx, script.Hash(), []string{"n"}, 1)
+	assert.ErrorIs(t, err, cache.ErrScriptNotFound)
+
+	n, err := script.Run(ctx, client, []string{"n"}, 2).Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	exists, err := script.Exists(ctx, client)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	val, err := client.EvalSha(ctx, script.Hash(), []string{"n"}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), val)
+
+	assert.NoError(t, client.ScriptFlush(ctx))
+	hash, err := client.ScriptLoad(ctx, script.Source())
+	assert.NoError(t, err)
+	assert.Equal(t, script.Hash(), hash)
+
+	pipe := client.Pipeline()
+	evalSha := pipe.EvalSha(ctx, hash, []string{"n"}, 1)
+	eval := pipe.Eval(ctx, "return redis.call('GET', KEYS[1])", []string{"n"})
+	missing := pipe.EvalSha(ctx, "0000000000000000000000000000000000000000", nil)
+	_, err = pipe.Exec(ctx)
+	assert.ErrorIs(t, err, cache.ErrScriptNotFound)
+	assert.Equal(t, int64(6), evalSha.Val())
+	assert.Equal(t, "6", eval.Val())
+	assert.ErrorIs(t, missing.Err(), cache.ErrScriptNotFound)
+}
@@ -0,0 +1,200 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKeySlot 测试哈希槽计算
+func TestKeySlot(t *testing.T) {
+	assert.Equal(t, 12739, cache.KeySlot("123456789"))
+	assert.Equal(t, 12182, cache.KeySlot("foo"))
+	assert.Equal(t, cache.KeySlot("user1000"), cache.KeySlot("{user1000}.following"))
+	assert.Equal(t, cache.KeySlot("{user1000}.following"), cache.KeySlot("{user1000}.followers"))
+
+	// 空的hashtag按完整键计算
+	assert.NotEqual(t, cache.KeySlot("bar"), cache.KeySlot("foo{}{bar}"))
+
+	for _, key := range []string{"", "a", "app:user:42", "{tag}"} {
+		slot := cache.KeySlot(key)
+		assert.GreaterOrEqual(t, slot, 0)
+		assert.Less(t, slot, cache.ClusterSlotCount)
+	}
+}
+
+// startTopologyCluster 启动3个节点的内嵌集群并创建集群客户端
+func startTopologyCluster(t *testing.T, interval time.Duration) (*cachetest.Cluster, *cache.ClusterClient) {
+	cluster, err := cachetest.StartCluster(3)
+	require.NoError(t, err)
+	t.Cleanup(func() { cluster.Close() })
+
+	config := cluster.Config()
+	config.Cluster.TopologyCheckInterval = interval
+	client, err := cache.NewClientFromConfig(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	clusterClient, ok := client.(*cache.ClusterClient)
+	require.True(t, ok)
+	return cluster, clusterClient
+}
+
+// shardOwner 获取哈希槽所属分片的主节点地址
+func shardOwner(shards []cache.ClusterShard, slot int) string {
+	for _, shard := range shards {
+		for _, r := range shard.Slots {
+			if slot >= r.Start && slot <= r.End {
+				return shard.Master.Addr
+			}
+		}
+	}
+	return ""
+}
+
+// TestClusterTopology 测试在内嵌集群上查询分片、节点和键所在的分片
+func TestClusterTopology(t *testing.T) {
+	cluster, client := startTopologyCluster(t, time.Minute)
+	ctx := context.Background()
+
+	t.Run("Shards", func(t *testing.T) {
+		shards, err := client.Shards(ctx)
+		require.NoError(t, err)
+		require.Len(t, shards, 3)
+
+		// 槽位连续且完整地覆盖所有哈希槽
+		slots := 0
+		for _, shard := range shards {
+			assert.Contains(t, cluster.Addrs(), shard.Master.Addr)
+			assert.Equal(t, cache.RoleMaster, shard.Master.Role)
+			assert.NotEmpty(t, shard.Master.ID)
+			assert.Empty(t, shard.Replicas)
+			for _, r := range shard.Slots {
+				slots += r.End - r.Start + 1
+			}
+		}
+		assert.Equal(t, cache.ClusterSlotCount, slots)
+		for slot := 0; slot < cache.ClusterSlotCount; slot += 997 {
+			assert.NotEmpty(t, shardOwner(shards, slot), "slot %d", slot)
+		}
+	})
+
+	t.Run("Nodes", func(t *testing.T) {
+		nodes, err := client.Nodes(ctx)
+		require.NoError(t, err)
+		addrs := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			assert.Equal(t, cache.RoleMaster, node.Role)
+			addrs = append(addrs, node.Addr)
+		}
+		assert.ElementsMatch(t, cluster.Addrs(), addrs)
+	})
+
+	t.Run("SlotOwner", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			key := fmt.Sprintf("user:%d", i)
+			shard, err := client.SlotOwner(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, cluster.NodeForKey(key).Addr(), shard.Master.Addr, key)
+		}
+	})
+
+	t.Run("ForEachMaster", func(t *testing.T) {
+		for i := 0; i < 30; i++ {
+			require.NoError(t, client.Set(ctx, fmt.Sprintf("each:%d", i), "v", 0))
+		}
+
+		// 每个主节点只返回自己的键，合起来是全部的键
+		var mu sync.Mutex
+		var keys []string
+		var calls atomic.Int64
+		err := client.ForEachMaster(ctx, func(ctx context.Context, node cache.Client) error {
+			calls.Add(1)
+			nodeKeys, err := node.Keys(ctx, "each:*")
+			mu.Lock()
+			keys = append(keys, nodeKeys...)
+			mu.Unlock()
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), calls.Load())
+		assert.Len(t, keys, 30)
+	})
+
+	t.Run("ForEachShard", func(t *testing.T) {
+		var calls atomic.Int64
+		err := client.ForEachShard(ctx, func(ctx context.Context, node cache.Client) error {
+			calls.Add(1)
+			return node.Ping(ctx)
+		})
+		require.NoError(t, err)
+		// 内嵌集群没有从节点，每个分片只有主节点
+		assert.Equal(t, int64(3), calls.Load())
+	})
+}
+
+// TestClusterTopologyChange 测试迁移哈希槽后触发拓扑变化回调并刷新路由
+func TestClusterTopologyChange(t *testing.T) {
+	cluster, client := startTopologyCluster(t, 10*time.Millisecond)
+	ctx := context.Background()
+
+	key := "moved"
+	slot := cache.KeySlot(key)
+	require.NoError(t, client.Set(ctx, key, "v", 0))
+	from := cluster.NodeForKey(key)
+	to := 0
+	for i, node := range cluster.Nodes() {
+		if node != from {
+			to = i
+			break
+		}
+	}
+
+	type change struct{ old, new []cache.ClusterShard }
+	changes := make(chan change, 10)
+	unsubscribe := client.OnTopologyChange(func(old, new []cache.ClusterShard) {
+		changes <- change{old, new}
+	})
+	// 等待后台检查记录初始拓扑
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, cluster.MoveSlot(slot, to))
+	select {
+	case c := <-changes:
+		assert.Equal(t, from.Addr(), shardOwner(c.old, slot))
+		assert.Equal(t, cluster.Nodes()[to].Addr(), shardOwner(c.new, slot))
+	case <-time.After(2 * time.Second):
+		t.Fatal("topology change not reported")
+	}
+
+	// 回调前已刷新缓存的拓扑和路由
+	shard, err := client.SlotOwner(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.Nodes()[to].Addr(), shard.Master.Addr)
+	val, err := client.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "v", val)
+
+	// 取消注册后不再回调
+	unsubscribe()
+	fromIndex := 0
+	for i, node := range cluster.Nodes() {
+		if node == from {
+			fromIndex = i
+		}
+	}
+	require.NoError(t, cluster.MoveSlot(slot, fromIndex))
+	require.Eventually(t, func() bool {
+		shard, err := client.SlotOwner(ctx, key)
+		return err == nil && shard.Master.Addr == from.Addr()
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Empty(t, changes)
+}
@@ -1,6 +1,7 @@
 package unit
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -213,6 +214,51 @@ func TestConfigDefaults(t *testing.T) {
 	}
 }
 
+// TestPoolConfigValidation 测试连接池和超时配置验证
+func TestPoolConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *cache.CommonConfig)
+		wantErr error
+	}{
+		{
+			name:    "默认配置",
+			modify:  func(c *cache.CommonConfig) {},
+			wantErr: nil,
+		},
+		{
+			name:    "连接池大小为负数",
+			modify:  func(c *cache.CommonConfig) { c.PoolSize = -1 },
+			wantErr: cache.ErrInvalidPoolSize,
+		},
+		{
+			name:    "最小空闲连接数大于连接池大小",
+			modify:  func(c *cache.CommonConfig) { c.MinIdleConns = 20 },
+			wantErr: cache.ErrMinIdleExceedsPoolSize,
+		},
+		{
+			name:    "最大空闲连接数小于最小空闲连接数",
+			modify:  func(c *cache.CommonConfig) { c.MinIdleConns = 5; c.MaxIdleConns = 3 },
+			wantErr: cache.ErrMaxIdleBelowMinIdle,
+		},
+		{
+			name:    "超时时间为负数",
+			modify:  func(c *cache.CommonConfig) { c.IdleTimeout = -time.Second },
+			wantErr: cache.ErrNegativeTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := cache.DefaultConfig()
+			tt.modify(&config.Common)
+			if err := config.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // BenchmarkConfigValidation 配置验证性能基准测试
 func BenchmarkConfigValidation(b *testing.B) {
 	config := &cache.Config{
@@ -0,0 +1,159 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fillKeys 写入n个以prefix开头的键
+func fillKeys(t *testing.T, ctx context.Context, client cache.Client, prefix string, n int) {
+	pipe := client.Pipeline()
+	for i := 0; i < n; i++ {
+		pipe.Set(ctx, fmt.Sprintf("%s%d", prefix, i), i, time.Minute)
+	}
+	_, err := pipe.Exec(ctx)
+	require.NoError(t, err)
+}
+
+// TestDeleteByPattern 测试单机模式下按模式删除
+func TestDeleteByPattern(t *testing.T) {
+	server, err := cachetest.StartServer(cachetest.WithKeyPrefix("app:"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := cache.NewClientFromConfig(server.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	fillKeys(t, ctx, client, "session:", 250)
+	fillKeys(t, ctx, client, "user:", 10)
+	// 不带KeyPrefix的同名键不受影响
+	assert.NoError(t, client.Set(cache.WithoutKeyPrefix(ctx), "session:outside", "v", time.Minute))
+
+	t.Run("DryRun只统计", func(t *testing.T) {
+		n, err := client.DeleteByPattern(ctx, "session:*", &cache.DeleteOptions{BatchSize: 50, DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(250), n)
+		n, err = client.Exists(ctx, "session:0", "session:249")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+
+	t.Run("分批删除", func(t *testing.T) {
+		n, err := client.DeleteByPattern(ctx, "session:*", &cache.DeleteOptions{BatchSize: 50})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(250), n)
+
+		keys, _, err := client.Scan(ctx, 0, "*", 1000)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 10)
+		val, err := client.Get(cache.WithoutKeyPrefix(ctx), "session:outside")
+		assert.NoError(t, err)
+		assert.Equal(t, "v", val)
+	})
+
+	t.Run("限速", func(t *testing.T) {
+		fillKeys(t, ctx, client, "limited:", 100)
+		start := time.Now()
+		n, err := client.DeleteByPattern(ctx, "limited:*", &cache.DeleteOptions{BatchSize: 50, RateLimit: 500})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), n)
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+
+	t.Run("上下文取消时停止", func(t *testing.T) {
+		fillKeys(t, ctx, client, "slow:", 100)
+		cancelCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		n, err := client.DeleteByPattern(cancelCtx, "slow:*", &cache.DeleteOptions{BatchSize: 10, RateLimit: 100})
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Less(t, n, int64(100))
+	})
+}
+
+// TestDeleteByPatternCluster 测试集群模式下在所有主节点上删除
+func TestDeleteByPatternCluster(t *testing.T) {
+	cluster, err := cachetest.StartCluster(3)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	client, err := cache.NewClientFromConfig(cluster.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	fillKeys(t, ctx, client, "item:", 300)
+	fillKeys(t, ctx, client, "keep:", 30)
+
+	// 键分布在多个节点上
+	nodes := make(map[*cachetest.Server]bool)
+	for i := 0; i < 300; i++ {
+		nodes[cluster.NodeForKey(fmt.Sprintf("item:%d", i))] = true
+	}
+	assert.Len(t, nodes, 3)
+
+	n, err := client.DeleteByPattern(ctx, "item:*", &cache.DeleteOptions{BatchSize: 40})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(300), n)
+
+	for _, node := range cluster.Nodes() {
+		keys, err := node.Fake().Keys(ctx, "item:*")
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+	}
+	n, err = client.Exists(ctx, "keep:0")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+// TestDisableKeys 测试配置禁用Keys
+func TestDisableKeys(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("客户端", func(t *testing.T) {
+		server, err := cachetest.StartServer()
+		require.NoError(t, err)
+		defer server.Close()
+
+		config := server.Config()
+		config.Common.DisableKeys = true
+		client, err := cache.NewClientFromConfig(config)
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.Keys(ctx, "*")
+		assert.Equal(t, cache.ErrKeysDisabled, err)
+
+		pipe := client.Pipeline()
+		keys := pipe.Keys(ctx, "*")
+		ping := pipe.Exists(ctx, "a")
+		_, err = pipe.Exec(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, cache.ErrKeysDisabled, keys.Err())
+		assert.NoError(t, ping.Err())
+	})
+
+	t.Run("内存客户端", func(t *testing.T) {
+		config := &cache.Config{Mode: cache.ModeSingle, Common: cache.CommonConfig{KeyPrefix: "app:", DisableKeys: true}}
+		fake := cachetest.NewFake(cachetest.WithConfig(config))
+		_, err := fake.Keys(ctx, "*")
+		assert.Equal(t, cache.ErrKeysDisabled, err)
+
+		assert.NoError(t, fake.Set(ctx, "tmp:1", "v", 0))
+		assert.NoError(t, fake.Set(ctx, "tmp:2", "v", 0))
+		n, err := fake.DeleteByPattern(ctx, "tmp:*", &cache.DeleteOptions{DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		n, err = fake.DeleteByPattern(ctx, "tmp:*", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+}
@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/require"
+)
+
+// testDeployment 内嵌服务器上的一种部署模式
+type testDeployment struct {
+	config *cache.Config
+	// 拨动服务器的时钟，到期的键和HSetWithTTL的字段随之过期
+	advance func(d time.Duration)
+}
+
+// embeddedTestDeployments 启动三种部署模式的内嵌服务器，服务器在测试结束时关闭
+func embeddedTestDeployments(t *testing.T) map[string]testDeployment {
+	opts := []cachetest.Option{cachetest.WithKeyPrefix("atomic:")}
+
+	server, err := cachetest.StartServer(opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	cluster, err := cachetest.StartCluster(3, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { cluster.Close() })
+
+	sentinel, err := cachetest.StartSentinel("mymaster", 1, 1, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { sentinel.Close() })
+
+	return map[string]testDeployment{
+		"单机模式": {config: server.Config(), advance: server.Fake().Advance},
+		"集群模式": {config: cluster.Config(), advance: cluster.Advance},
+		"哨兵模式": {config: sentinel.Config(), advance: sentinel.Fake().Advance},
+	}
+}
+
+// embeddedTestConfigs 启动三种部署模式的内嵌服务器，返回连接它们的配置，服务器在测试结束时关闭
+func embeddedTestConfigs(t *testing.T) map[string]*cache.Config {
+	configs := make(map[string]*cache.Config)
+	for name, deployment := range embeddedTestDeployments(t) {
+		configs[name] = deployment.config
+	}
+	return configs
+}
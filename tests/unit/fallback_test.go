@@ -0,0 +1,193 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unavailableClient 所有操作都返回连接错误的客户端
+type unavailableClient struct {
+	cache.Client
+	err error
+}
+
+func (c *unavailableClient) Get(ctx context.Context, key string) (string, error) {
+	return "", c.err
+}
+
+func (c *unavailableClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return nil, c.err
+}
+
+func (c *unavailableClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return nil, c.err
+}
+
+func (c *unavailableClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return c.err
+}
+
+// TestIsUnavailableError 测试不可用错误判断
+func TestIsUnavailableError(t *testing.T) {
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	assert.True(t, cache.IsUnavailableError(netErr))
+	assert.True(t, cache.IsUnavailableError(cache.ErrCircuitOpen))
+	assert.True(t, cache.IsUnavailableError(context.DeadlineExceeded))
+	assert.True(t, cache.IsUnavailableError(cache.ErrPoolExhausted))
+	assert.False(t, cache.IsUnavailableError(nil))
+	assert.False(t, cache.IsUnavailableError(cache.ErrKeyNotFound))
+	assert.False(t, cache.IsUnavailableError(errors.New("WRONGTYPE")))
+}
+
+// TestFallbackClient 测试降级客户端
+func TestFallbackClient(t *testing.T) {
+	ctx := context.Background()
+	var ops []string
+	client := cache.NewFallbackClient(&unavailableClient{err: cache.ErrCircuitOpen}, &cache.FallbackOptions{
+		Logger: nopLogger{},
+		OnFallback: func(op string, err error) {
+			ops = append(ops, op)
+		},
+	})
+
+	_, err := client.Get(ctx, "key")
+	assert.ErrorIs(t, err, cache.ErrKeyNotFound)
+
+	vals, err := client.MGet(ctx, "a", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{nil, nil}, vals)
+
+	hash, err := client.HGetAll(ctx, "hash")
+	assert.NoError(t, err)
+	assert.Empty(t, hash)
+
+	assert.NoError(t, client.Set(ctx, "key", "value", time.Minute))
+
+	assert.Equal(t, cache.FallbackStats{Reads: 3, Writes: 1}, client.FallbackStats())
+	assert.Equal(t, []string{"Get", "MGet", "HGetAll", "Set"}, ops)
+}
+
+// TestFallbackClientWrites 测试回填和失效缓存的写操作降级，返回值参与判断的写操作不降级
+func TestFallbackClientWrites(t *testing.T) {
+	ctx := context.Background()
+	faulty := cachetest.NewFaultyClient(cachetest.NewFake(), &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{{Err: cache.ErrCircuitOpen}},
+	})
+	client := cache.NewFallbackClient(faulty, &cache.FallbackOptions{Logger: nopLogger{}})
+
+	degraded := map[string]func() error{
+		"Set":   func() error { return client.Set(ctx, "key", "v", time.Minute) },
+		"MSet":  func() error { return client.MSet(ctx, "a", "1") },
+		"HSet":  func() error { return client.HSet(ctx, "hash", "f", "v") },
+		"HMSet": func() error { return client.HMSet(ctx, "hash", "f", "v") },
+		"Del":   func() error { _, err := client.Del(ctx, "key"); return err },
+		"Expire": func() error {
+			_, err := client.Expire(ctx, "key", time.Minute)
+			return err
+		},
+		"ExpireAt": func() error {
+			_, err := client.ExpireAt(ctx, "key", time.Now().Add(time.Minute))
+			return err
+		},
+		"DeleteByPattern": func() error {
+			_, err := client.DeleteByPattern(ctx, "key:*", nil)
+			return err
+		},
+		"HDel":  func() error { _, err := client.HDel(ctx, "hash", "f"); return err },
+		"LPush": func() error { _, err := client.LPush(ctx, "list", "v"); return err },
+		"RPush": func() error { _, err := client.RPush(ctx, "list", "v"); return err },
+		"LSet":  func() error { return client.LSet(ctx, "list", 0, "v") },
+		"LRem":  func() error { _, err := client.LRem(ctx, "list", 0, "v"); return err },
+		"LTrim": func() error { return client.LTrim(ctx, "list", 0, 1) },
+		"SAdd":  func() error { _, err := client.SAdd(ctx, "set", "m"); return err },
+		"SRem":  func() error { _, err := client.SRem(ctx, "set", "m"); return err },
+		"ZAdd": func() error {
+			_, err := client.ZAdd(ctx, "zset", cache.ZMember{Score: 1, Member: "m"})
+			return err
+		},
+		"ZRem": func() error { _, err := client.ZRem(ctx, "zset", "m"); return err },
+	}
+	for op, fn := range degraded {
+		assert.NoError(t, fn(), op)
+	}
+	assert.Equal(t, cache.FallbackStats{Writes: int64(len(degraded))}, client.FallbackStats())
+
+	passThrough := map[string]func() error{
+		"Incr":   func() error { _, err := client.Incr(ctx, "counter"); return err },
+		"IncrBy": func() error { _, err := client.IncrBy(ctx, "counter", 2); return err },
+		"DecrBy": func() error { _, err := client.DecrBy(ctx, "counter", 2); return err },
+		"HIncrBy": func() error {
+			_, err := client.HIncrBy(ctx, "hash", "n", 1)
+			return err
+		},
+		"SetNX": func() error {
+			_, err := client.SetNX(ctx, "lock", "v", time.Minute)
+			return err
+		},
+		"HSetNX": func() error { _, err := client.HSetNX(ctx, "hash", "f", "v"); return err },
+		"GetSet": func() error { _, err := client.GetSet(ctx, "key", "v"); return err },
+		"LPop":   func() error { _, err := client.LPop(ctx, "list"); return err },
+		"SPop":   func() error { _, err := client.SPop(ctx, "set"); return err },
+	}
+	for op, fn := range passThrough {
+		assert.ErrorIs(t, fn(), cache.ErrCircuitOpen, op)
+	}
+	assert.Equal(t, cache.FallbackStats{Writes: int64(len(degraded))}, client.FallbackStats())
+}
+
+// TestFallbackClientPassThrough 测试非不可用错误不降级
+func TestFallbackClientPassThrough(t *testing.T) {
+	wrongType := errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	client := cache.NewFallbackClient(&unavailableClient{err: wrongType}, &cache.FallbackOptions{Logger: nopLogger{}})
+
+	_, err := client.Get(context.Background(), "key")
+	assert.Equal(t, wrongType, err)
+	assert.Equal(t, cache.FallbackStats{}, client.FallbackStats())
+}
+
+// TestGetOrLoad 测试旁路缓存读取
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	client := &unavailableClient{err: cache.ErrCircuitOpen}
+
+	val, err := cache.GetOrLoad(ctx, client, "key", time.Minute, func(ctx context.Context) (string, error) {
+		return "loaded", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "loaded", val)
+
+	loadErr := errors.New("db down")
+	_, err = cache.GetOrLoad(ctx, client, "key", time.Minute, func(ctx context.Context) (string, error) {
+		return "", loadErr
+	})
+	assert.ErrorIs(t, err, loadErr)
+}
+
+// TestGetOrLoadWriteBackFails 测试回写失败时仍然返回加载的结果
+func TestGetOrLoadWriteBackFails(t *testing.T) {
+	ctx := context.Background()
+	faulty := cachetest.NewFaultyClient(cachetest.NewFake(), &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{{Commands: []string{"set"}, Err: errors.New("OOM command not allowed when used memory > 'maxmemory'")}},
+	})
+
+	val, err := cache.GetOrLoad(ctx, faulty, "key", time.Minute, func(ctx context.Context) (string, error) {
+		return "loaded", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", val)
+	assert.Equal(t, int64(1), faulty.FaultStats().Errors)
+}
+
+// nopLogger 丢弃日志
+type nopLogger struct{}
+
+func (nopLogger) Printf(ctx context.Context, format string, v ...interface{}) {}
@@ -0,0 +1,191 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFaultyClientRules 测试故障规则按命令和键模式匹配
+func TestFaultyClientRules(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake()
+	client := cachetest.NewFaultyClient(fake, &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{
+			{Commands: []string{"GET"}, KeyPattern: "user:*", Err: cachetest.ErrInjectedTimeout},
+		},
+	})
+
+	assert.NoError(t, client.Set(ctx, "user:1", "alice", 0))
+	assert.NoError(t, client.Set(ctx, "order:1", "o1", 0))
+
+	_, err := client.Get(ctx, "user:1")
+	assert.Equal(t, cachetest.ErrInjectedTimeout, err)
+	assert.True(t, cache.IsUnavailableError(err))
+	assert.True(t, cache.IsRetryableError(err))
+
+	val, err := client.Get(ctx, "order:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "o1", val)
+
+	// 规则只匹配GET，同一个键的其他命令不受影响
+	n, err := client.Exists(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	client.ClearFaults()
+	val, err = client.Get(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", val)
+
+	stats := client.FaultStats()
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Equal(t, int64(6), stats.Calls)
+}
+
+// TestFaultyClientTimes 测试规则的触发次数限制
+func TestFaultyClientTimes(t *testing.T) {
+	ctx := context.Background()
+	client := cachetest.NewFaultyClient(cachetest.NewFake(), nil)
+	client.AddFault(cachetest.Fault{Commands: []string{"incr"}, Times: 2, Err: cache.ErrPoolExhausted})
+
+	_, err := client.Incr(ctx, "counter")
+	assert.Equal(t, cache.ErrPoolExhausted, err)
+	_, err = client.Incr(ctx, "counter")
+	assert.Equal(t, cache.ErrPoolExhausted, err)
+	n, err := client.Incr(ctx, "counter")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+// TestFaultyClientRate 测试相同的种子产生相同的故障序列
+func TestFaultyClientRate(t *testing.T) {
+	ctx := context.Background()
+	run := func(seed int64) []bool {
+		client := cachetest.NewFaultyClient(cachetest.NewFake(), &cachetest.FaultyOptions{
+			Seed:   seed,
+			Faults: []cachetest.Fault{{Rate: 0.3, Err: cache.ErrClusterDown}},
+		})
+		failures := make([]bool, 100)
+		for i := range failures {
+			failures[i] = client.Ping(ctx) != nil
+		}
+		return failures
+	}
+
+	first := run(42)
+	assert.Equal(t, first, run(42))
+
+	var count int
+	for _, failed := range first {
+		if failed {
+			count++
+		}
+	}
+	assert.Greater(t, count, 10)
+	assert.Less(t, count, 50)
+}
+
+// TestFaultyClientLatency 测试注入的延迟和上下文超时
+func TestFaultyClientLatency(t *testing.T) {
+	client := cachetest.NewFaultyClient(cachetest.NewFake(), &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{{Commands: []string{"get"}, Latency: 50 * time.Millisecond}},
+	})
+
+	start := time.Now()
+	_, err := client.Get(context.Background(), "key")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Get(ctx, "key")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, int64(2), client.FaultStats().Delayed)
+}
+
+// TestFaultyClientDropWrite 测试丢弃的写命令返回成功但不生效
+func TestFaultyClientDropWrite(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake()
+	client := cachetest.NewFaultyClient(fake, &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{{KeyPattern: "session:*", DropWrite: true}},
+	})
+
+	assert.NoError(t, client.Set(ctx, "session:1", "token", time.Minute))
+	_, err := fake.Get(ctx, "session:1")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+
+	// 读命令不受丢弃规则影响
+	assert.NoError(t, fake.Set(ctx, "session:2", "token", 0))
+	val, err := client.Get(ctx, "session:2")
+	assert.NoError(t, err)
+	assert.Equal(t, "token", val)
+	assert.Equal(t, int64(1), client.FaultStats().Dropped)
+}
+
+// TestFaultyClientPipeline 测试管道部分失败和整体失败
+func TestFaultyClientPipeline(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake()
+	client := cachetest.NewFaultyClient(fake, &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{{Commands: []string{"set"}, KeyPattern: "bad:*", Err: cachetest.ErrInjectedTimeout}},
+	})
+
+	t.Run("部分失败", func(t *testing.T) {
+		pipe := client.Pipeline()
+		good := pipe.Set(ctx, "good:1", "v", 0)
+		bad := pipe.Set(ctx, "bad:1", "v", 0)
+		incr := pipe.Incr(ctx, "good:counter")
+		results, err := pipe.Exec(ctx)
+		assert.Equal(t, cachetest.ErrInjectedTimeout, err)
+		assert.Len(t, results, 3)
+
+		assert.NoError(t, good.Err())
+		assert.Equal(t, cachetest.ErrInjectedTimeout, bad.Err())
+		assert.Equal(t, int64(1), incr.Val())
+
+		_, err = fake.Get(ctx, "good:1")
+		assert.NoError(t, err)
+		_, err = fake.Get(ctx, "bad:1")
+		assert.Equal(t, cache.ErrKeyNotFound, err)
+	})
+
+	t.Run("未命中的命令返回redis.Nil", func(t *testing.T) {
+		pipe := client.Pipeline()
+		missing := pipe.Get(ctx, "missing")
+		_, err := pipe.Exec(ctx)
+		assert.Equal(t, redis.Nil, err)
+		assert.Equal(t, cache.ErrKeyNotFound, missing.Err())
+	})
+
+	t.Run("整体失败", func(t *testing.T) {
+		client.AddFault(cachetest.Fault{Commands: []string{"exec"}, Times: 1, Err: cache.ErrClusterDown})
+		pipe := client.TxPipeline()
+		set := pipe.Set(ctx, "good:2", "v", 0)
+		_, err := pipe.Exec(ctx)
+		assert.Equal(t, cache.ErrClusterDown, err)
+		assert.Equal(t, cache.ErrClusterDown, set.Err())
+		_, err = fake.Get(ctx, "good:2")
+		assert.Equal(t, cache.ErrKeyNotFound, err)
+	})
+}
+
+// TestFaultyClientWithFallback 测试降级客户端处理注入的不可用错误
+func TestFaultyClientWithFallback(t *testing.T) {
+	ctx := context.Background()
+	faulty := cachetest.NewFaultyClient(cachetest.NewFake(), &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{{Err: cache.ErrPoolExhausted}},
+	})
+	client := cache.NewFallbackClient(faulty, &cache.FallbackOptions{Logger: nopLogger{}})
+
+	assert.NoError(t, client.Set(ctx, "key", "v", 0))
+	_, err := client.Get(ctx, "key")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+	assert.Equal(t, cache.FallbackStats{Reads: 1, Writes: 1}, client.FallbackStats())
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// libraryClient 模拟服务端函数库的客户端
+type libraryClient struct {
+	cache.Client
+	libs  map[string]string
+	loads int
+}
+
+func (c *libraryClient) FunctionList(ctx context.Context, pattern string, withCode bool) ([]cache.FunctionLibrary, error) {
+	var libs []cache.FunctionLibrary
+	for name, code := range c.libs {
+		if name == pattern {
+			libs = append(libs, cache.FunctionLibrary{Name: name, Engine: "LUA", Code: code})
+		}
+	}
+	return libs, nil
+}
+
+func (c *libraryClient) FunctionLoad(ctx context.Context, code string, replace bool) (string, error) {
+	c.loads++
+	name := strings.TrimPrefix(strings.SplitN(code, "\n", 2)[0], "#!lua name=")
+	c.libs[name] = code
+	return name, nil
+}
+
+// TestLibrarySource 测试带版本的函数库源码
+func TestLibrarySource(t *testing.T) {
+	source := cache.LibrarySource("mylib", "1.2.0", "redis.register_function('f', function() return 1 end)")
+	assert.True(t, strings.HasPrefix(source, "#!lua name=mylib\n"))
+	assert.Equal(t, "1.2.0", cache.LibraryVersion(source))
+	assert.Equal(t, "", cache.LibraryVersion("#!lua name=mylib\nreturn 1"))
+}
+
+// TestEnsureLibrary 测试按版本安装函数库
+func TestEnsureLibrary(t *testing.T) {
+	client := &libraryClient{libs: make(map[string]string)}
+	ctx := context.Background()
+	code := "redis.register_function('f', function() return 1 end)"
+
+	assert.NoError(t, cache.EnsureLibrary(ctx, client, "mylib", "1", code))
+	assert.Equal(t, 1, client.loads)
+
+	assert.NoError(t, cache.EnsureLibrary(ctx, client, "mylib", "1", code))
+	assert.Equal(t, 1, client.loads)
+
+	assert.NoError(t, cache.EnsureLibrary(ctx, client, "mylib", "2", code))
+	assert.Equal(t, 2, client.loads)
+	assert.Equal(t, "2", cache.LibraryVersion(client.libs["mylib"]))
+}
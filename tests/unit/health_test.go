@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHealthCheckerDown 测试Redis不可用时的健康状态
+func TestHealthCheckerDown(t *testing.T) {
+	client, err := cache.NewClientFromConfig(unreachableConfig(cache.StartupLazy))
+	assert.NoError(t, err)
+	defer client.Close()
+
+	checker := cache.NewHealthChecker(client, &cache.HealthCheckerOptions{
+		Interval: time.Second,
+		Timeout:  200 * time.Millisecond,
+		Logger:   nopLogger{},
+	})
+	assert.False(t, checker.Status().Healthy())
+
+	status := checker.Check(context.Background())
+	assert.Equal(t, cache.HealthDown, status.State)
+	assert.Equal(t, cache.ModeSingle, status.Mode)
+	assert.Equal(t, 1, status.ConsecutiveFailures)
+	assert.Len(t, status.Nodes, 1)
+	assert.Equal(t, "127.0.0.1:1", status.Nodes[0].Addr)
+	assert.NotEmpty(t, status.Error)
+
+	status = checker.Check(context.Background())
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+
+	recorder := httptest.NewRecorder()
+	checker.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var body cache.HealthStatus
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, cache.HealthDown, body.State)
+}
+
+// TestHealthCheckerLiveness 测试存活探针
+func TestHealthCheckerLiveness(t *testing.T) {
+	client, err := cache.NewClientFromConfig(unreachableConfig(cache.StartupLazy))
+	assert.NoError(t, err)
+	defer client.Close()
+
+	checker := cache.NewHealthChecker(client, &cache.HealthCheckerOptions{
+		Interval: 50 * time.Millisecond,
+		Timeout:  50 * time.Millisecond,
+		Logger:   nopLogger{},
+	})
+
+	recorder := httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	checker.Start()
+	defer checker.Stop()
+	assert.Eventually(t, func() bool {
+		return !checker.Status().CheckedAt.IsZero()
+	}, time.Second, 10*time.Millisecond)
+
+	recorder = httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+// TestHealthCheckerSentinel 测试哨兵模式的检查复用连接哨兵的客户端
+func TestHealthCheckerSentinel(t *testing.T) {
+	sentinel, err := cachetest.StartSentinel("mymaster", 1, 2)
+	require.NoError(t, err)
+	defer sentinel.Close()
+
+	client, err := cache.NewClientFromConfig(sentinel.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	checker := cache.NewHealthChecker(client, nil)
+	ctx := context.Background()
+	status := checker.Check(ctx)
+	assert.Equal(t, cache.HealthUp, status.State)
+	assert.Equal(t, sentinel.Master().Addr(), status.Master)
+
+	// 每个新连接都会发送HELLO，之后的检查不再建立连接
+	hellos := func() (n int64) {
+		for _, s := range sentinel.Sentinels() {
+			n += s.Calls("hello")
+		}
+		return n
+	}
+	connected := hellos()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, cache.HealthUp, checker.Check(ctx).State)
+	}
+	assert.Equal(t, connected, hellos())
+
+	// 停止后关闭连接，再次检查时重新建立
+	checker.Stop()
+	assert.Equal(t, cache.HealthUp, checker.Check(ctx).State)
+	assert.Greater(t, hellos(), connected)
+	checker.Stop()
+}
@@ -0,0 +1,163 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNamespaceGenerations 测试递增代数后命名空间中的键失效
+func TestNamespaceGenerations(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake(cachetest.WithKeyPrefix("app:"))
+	namespaces := cache.NewNamespaces(fake, nil)
+
+	before := time.Now().UnixMilli()
+	first, err := namespaces.Generation(ctx, "user")
+	require.NoError(t, err)
+	// 代数从当前的Unix毫秒时间戳开始
+	assert.GreaterOrEqual(t, first, before)
+	assert.LessOrEqual(t, first, time.Now().UnixMilli())
+
+	nsCtx, err := namespaces.WithNamespace(ctx, "user")
+	require.NoError(t, err)
+	assert.NoError(t, fake.Set(nsCtx, "42", "alice", time.Minute))
+	assert.NoError(t, fake.Set(ctx, "other", "kept", time.Minute))
+
+	// 键按KeyPrefix、命名空间和代数拼接
+	key := fake.Config().GetKeyWithNamespace("user", first, "42")
+	assert.Equal(t, fmt.Sprintf("app:user:v%d:42", first), key)
+	val, err := fake.Get(cache.WithoutKeyPrefix(ctx), key)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", val)
+
+	keys, err := fake.Keys(nsCtx, "*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"42"}, keys)
+
+	generation, err := namespaces.BumpNamespace(ctx, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, first+1, generation)
+
+	nsCtx, err = namespaces.WithNamespace(ctx, "user")
+	require.NoError(t, err)
+	_, err = fake.Get(nsCtx, "42")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+
+	// 旧代数的键留待过期，命名空间之外的键不受影响
+	n, err := fake.Exists(cache.WithoutKeyPrefix(ctx), key)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	val, err = fake.Get(ctx, "other")
+	assert.NoError(t, err)
+	assert.Equal(t, "kept", val)
+
+	val, err = fake.Get(ctx, "ns:user")
+	assert.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(first+1, 10), val)
+
+	// 保存代数的键丢失后不会回到用过的代数
+	_, err = fake.Del(ctx, "ns:user")
+	assert.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	generation, err = namespaces.Generation(ctx, "user")
+	assert.NoError(t, err)
+	assert.Greater(t, generation, first+1)
+}
+
+// TestNamespaceBumpMissing 测试递增不存在的代数时先写入初始值，且保存代数的键不受DefaultTTL影响
+func TestNamespaceBumpMissing(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake(cachetest.WithDefaultTTL(time.Minute))
+	namespaces := cache.NewNamespaces(fake, nil)
+
+	before := time.Now().UnixMilli()
+	generation, err := namespaces.BumpNamespace(ctx, "orders")
+	assert.NoError(t, err)
+	assert.Greater(t, generation, before)
+
+	ttl, err := fake.TTL(ctx, "ns:orders")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), ttl)
+}
+
+// TestNamespaceLocalCache 测试代数在本地缓存
+func TestNamespaceLocalCache(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake()
+	local := cache.NewNamespaces(fake, &cache.NamespaceOptions{LocalTTL: 50 * time.Millisecond})
+	remote := cache.NewNamespaces(fake, &cache.NamespaceOptions{GenerationPrefix: "ns:"})
+
+	first, err := local.Generation(ctx, "orders")
+	assert.NoError(t, err)
+
+	_, err = remote.BumpNamespace(ctx, "orders")
+	assert.NoError(t, err)
+
+	// 本地缓存未过期时仍使用旧的代数
+	generation, err := local.Generation(ctx, "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, first, generation)
+
+	time.Sleep(60 * time.Millisecond)
+	generation, err = local.Generation(ctx, "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, first+1, generation)
+
+	// 本地递增后立即生效
+	generation, err = local.BumpNamespace(ctx, "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, first+2, generation)
+	generation, err = local.Generation(ctx, "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, first+2, generation)
+}
+
+// TestNamespaceInvalidGeneration 测试保存代数的键不是整数
+func TestNamespaceInvalidGeneration(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake()
+	assert.NoError(t, fake.Set(ctx, "ns:broken", "x", 0))
+
+	_, err := cache.NewNamespaces(fake, nil).WithNamespace(ctx, "broken")
+	assert.Equal(t, cache.ErrInvalidType, err)
+}
+
+// TestNamespaceClient 测试客户端和管道在命名空间下读写
+func TestNamespaceClient(t *testing.T) {
+	server, err := cachetest.StartServer(cachetest.WithKeyPrefix("svc:"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := cache.NewClientFromConfig(server.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	namespaces := cache.NewNamespaces(client, nil)
+	generation, err := namespaces.BumpNamespace(ctx, "session")
+	require.NoError(t, err)
+	nsCtx, err := namespaces.WithNamespace(ctx, "session")
+	require.NoError(t, err)
+
+	pipe := client.Pipeline()
+	pipe.Set(nsCtx, "a", "1", time.Minute)
+	pipe.Set(nsCtx, "b", "2", time.Minute)
+	_, err = pipe.Exec(nsCtx)
+	assert.NoError(t, err)
+
+	keys, _, err := client.Scan(nsCtx, 0, "*", 100)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, keys)
+
+	val, err := client.Get(cache.WithoutKeyPrefix(ctx), fmt.Sprintf("svc:session:v%d:a", generation))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", val)
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithoutKeyPrefix 测试不添加前缀的调用直接访问完整的键
+func TestWithoutKeyPrefix(t *testing.T) {
+	server, err := cachetest.StartServer(cachetest.WithKeyPrefix("app:"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := cache.NewClientFromConfig(server.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	raw := cache.WithoutKeyPrefix(ctx)
+	assert.NoError(t, client.Set(ctx, "user:1", "alice", time.Minute))
+	assert.NoError(t, client.Set(raw, "other:1", "bob", time.Minute))
+
+	val, err := client.Get(raw, "app:user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", val)
+	_, err = client.Get(ctx, "other:1")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+
+	// Keys和Scan返回的键不去除前缀
+	keys, err := client.Keys(raw, "*")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"app:user:1", "other:1"}, keys)
+	keys, _, err = client.Scan(raw, 0, "app:*", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app:user:1"}, keys)
+
+	config := server.Config()
+	assert.Equal(t, "app:user:1", config.PrefixKey(ctx, "user:1"))
+	assert.Equal(t, "user:1", config.PrefixKey(raw, "user:1"))
+	assert.Empty(t, config.KeyPrefix(raw))
+}
+
+// TestWithTTLJitter 测试过期时间的抖动在[0, jitter)之间
+func TestWithTTLJitter(t *testing.T) {
+	ctx := cache.WithTTLJitter(context.Background(), 10*time.Second)
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 1000; i++ {
+		ttl := cache.JitterTTL(ctx, time.Minute)
+		assert.GreaterOrEqual(t, ttl, time.Minute)
+		assert.Less(t, ttl, time.Minute+10*time.Second)
+		seen[ttl] = true
+	}
+	assert.Greater(t, len(seen), 1)
+
+	// 不过期的键和未设置抖动的调用不受影响
+	assert.Zero(t, cache.JitterTTL(ctx, 0))
+	assert.Equal(t, time.Minute, cache.JitterTTL(context.Background(), time.Minute))
+
+	fake := cachetest.NewFake()
+	assert.NoError(t, fake.Set(ctx, "key", "v", time.Minute))
+	ttl, err := fake.TTL(ctx, "key")
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, ttl, time.Minute)
+	assert.LessOrEqual(t, ttl, time.Minute+10*time.Second)
+}
+
+// TestWithTimeout 测试调用超时作用于发送到服务器的命令，而不是等到读取超时
+func TestWithTimeout(t *testing.T) {
+	// 接受连接但从不回复的服务器
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	config := cache.DefaultConfig()
+	config.Single.Addr = listener.Addr().String()
+	config.Common.ReadTimeout = 5 * time.Second
+	config.Common.StartupMode = cache.StartupLazy
+	config.Common.MaxRetries = 0
+	client, err := cache.NewClientFromConfig(config)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := cache.WithNoRetry(cache.WithTimeout(context.Background(), 50*time.Millisecond))
+	start := time.Now()
+	_, err = client.Get(ctx, "key")
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	start = time.Now()
+	pipe := client.Pipeline()
+	pipe.Get(ctx, "key")
+	_, err = pipe.Exec(ctx)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPipelineCommands 在三种客户端上测试管道命令的结果在Exec之后可读
+func TestPipelineCommands(t *testing.T) {
+	for name, config := range embeddedTestConfigs(t) {
+		t.Run(name, func(t *testing.T) {
+			client, err := cache.NewClientFromConfig(config)
+			require.NoError(t, err)
+			defer client.Close()
+			testPipelineCommands(t, client)
+		})
+	}
+}
+
+func testPipelineCommands(t *testing.T, client cache.Client) {
+	ctx := context.Background()
+	keys := []string{"{pipe}:str", "{pipe}:hash", "{pipe}:list", "{pipe}:zset", "{pipe}:missing"}
+	defer client.Del(ctx, keys...)
+
+	pipe := client.Pipeline()
+	pipe.Set(ctx, "{pipe}:str", "10", time.Minute)
+	incrBy := pipe.IncrBy(ctx, "{pipe}:str", 5)
+	mget := pipe.MGet(ctx, "{pipe}:str", "{pipe}:missing")
+	missing := pipe.Get(ctx, "{pipe}:missing")
+
+	pipe.HSet(ctx, "{pipe}:hash", "a", "1")
+	hgetAll := pipe.HGetAll(ctx, "{pipe}:hash")
+
+	pipe.RPush(ctx, "{pipe}:list", "x", "y", "z")
+	lindex := pipe.LIndex(ctx, "{pipe}:list", 1)
+
+	pipe.ZAdd(ctx, "{pipe}:zset", cache.ZMember{Score: 1, Member: "m1"}, cache.ZMember{Score: 2, Member: "m2"})
+	zscore := pipe.ZScore(ctx, "{pipe}:zset", "m2")
+	zrange := pipe.ZRangeWithScores(ctx, "{pipe}:zset", 0, -1)
+
+	ttl := pipe.TTL(ctx, "{pipe}:str")
+	keyType := pipe.Type(ctx, "{pipe}:hash")
+	eval := pipe.Eval(ctx, "return redis.call('GET', KEYS[1])", []string{"{pipe}:str"})
+
+	// Get未命中时Exec返回redis.Nil，其余命令的结果仍然可读
+	pipe.Exec(ctx)
+
+	assert.Equal(t, int64(15), incrBy.Val())
+	assert.Equal(t, []interface{}{"15", nil}, mget.Val())
+	assert.Equal(t, cache.ErrKeyNotFound, missing.Err())
+	assert.Equal(t, map[string]string{"a": "1"}, hgetAll.Val())
+	assert.Equal(t, "y", lindex.Val())
+	assert.Equal(t, float64(2), zscore.Val())
+	assert.Equal(t, []cache.ZMember{{Score: 1, Member: "m1"}, {Score: 2, Member: "m2"}}, zrange.Val())
+	assert.True(t, ttl.Val() > 0)
+	assert.Equal(t, "hash", keyType.Val())
+	assert.Equal(t, "15", eval.Val())
+}
+
+// TestPipelineKeysRepeatedReads 测试管道中Keys和Scan的结果可以重复读取，前缀只移除一次
+func TestPipelineKeysRepeatedReads(t *testing.T) {
+	server, err := cachetest.StartServer(cachetest.WithKeyPrefix("app:"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := cache.NewClientFromConfig(server.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	require.NoError(t, client.Set(ctx, "user:1", "alice", time.Minute))
+
+	pipe := client.Pipeline()
+	keys := pipe.Keys(ctx, "user:*")
+	scan := pipe.Scan(ctx, 0, "user:*", 100)
+	_, err = pipe.Exec(ctx)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, keys.Err())
+		assert.Equal(t, []string{"user:1"}, keys.Val())
+		vals, err := keys.Result()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user:1"}, vals)
+
+		assert.NoError(t, scan.Err())
+		vals, cursor := scan.Val()
+		assert.Equal(t, []string{"user:1"}, vals)
+		assert.Zero(t, cursor)
+	}
+}
@@ -0,0 +1,148 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordSession 在客户端上执行一组覆盖各类命令的调用
+func recordSession(ctx context.Context, client cache.Client) {
+	client.Set(ctx, "user:1", "alice", time.Minute)
+	client.Get(ctx, "user:1")
+	client.Get(ctx, "user:missing")
+	client.MSet(ctx, "a", 1, "b", 2.5)
+	client.MGet(ctx, "a", "b", "c")
+	client.IncrBy(ctx, "a", 9)
+	client.HMSet(ctx, "profile:1", map[string]interface{}{"name": "alice", "age": 30})
+	client.HGetAll(ctx, "profile:1")
+	client.HIncrBy(ctx, "profile:1", "age", 1)
+	client.RPush(ctx, "queue", "x", "y", "z")
+	client.LRange(ctx, "queue", 0, -1)
+	client.SAdd(ctx, "tags", "go", "redis", "cache")
+	client.SMembers(ctx, "tags")
+	client.ZAdd(ctx, "board", cache.ZMember{Score: 10, Member: "p1"}, cache.ZMember{Score: 20.5, Member: "p2"})
+	client.ZRevRangeWithScores(ctx, "board", 0, -1)
+	client.Expire(ctx, "queue", time.Hour)
+	client.TTL(ctx, "queue")
+	client.LPush(ctx, "user:1", "wrong type")
+	client.Scan(ctx, 0, "user:*", 100)
+	client.Del(ctx, "a", "b")
+	client.Exists(ctx, "a", "user:1")
+}
+
+// TestRecordAndReplay 测试录制的调用在新客户端上重放的结果一致
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	recorder := cache.NewRecorder(&buf, nil)
+	recordSession(ctx, cache.NewRecordingClient(cachetest.NewFake(cachetest.WithKeyPrefix("app:")), recorder))
+	assert.NoError(t, recorder.Err())
+	assert.Equal(t, int64(21), recorder.Count())
+
+	t.Run("记录不含前缀的键和结果", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 21)
+
+		var call cache.RecordedCall
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &call))
+		assert.Equal(t, "Set", call.Op)
+		assert.Equal(t, []string{"user:1"}, call.Keys)
+		assert.Equal(t, []string{"alice", fmt.Sprint(int64(time.Minute))}, call.Args)
+		assert.Equal(t, "{}", string(call.Result))
+		assert.False(t, call.Time.IsZero())
+
+		var miss cache.RecordedCall
+		assert.NoError(t, json.Unmarshal([]byte(lines[2]), &miss))
+		assert.Equal(t, cache.ErrKeyNotFound.Error(), miss.Err)
+		assert.Empty(t, miss.Result)
+	})
+
+	t.Run("在新客户端上重放", func(t *testing.T) {
+		report, err := cache.Replay(ctx, cachetest.NewFake(), bytes.NewReader(buf.Bytes()), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 21, report.Calls)
+		assert.Equal(t, 21, report.Matched)
+		assert.Empty(t, report.Diffs)
+	})
+
+	t.Run("数据不同时报告差异", func(t *testing.T) {
+		fake := cachetest.NewFake()
+		fake.Set(ctx, "user:missing", "present", 0)
+
+		report, err := cache.Replay(ctx, fake, bytes.NewReader(buf.Bytes()), nil)
+		assert.NoError(t, err)
+		// GET和SCAN都能看到多出来的键
+		assert.Len(t, report.Diffs, 2)
+		diff := report.Diffs[0]
+		assert.Equal(t, 2, diff.Index)
+		assert.Equal(t, `"present"`, string(diff.Result))
+		assert.Contains(t, diff.String(), "Get [user:missing]")
+		assert.Equal(t, "Scan", report.Diffs[1].Call.Op)
+	})
+
+	t.Run("未知的方法被跳过", func(t *testing.T) {
+		data := `{"op":"Unknown"}` + "\n" + `{"op":"Ping","r":{}}` + "\n"
+		report, err := cache.Replay(ctx, cachetest.NewFake(), strings.NewReader(data), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Equal(t, 1, report.Matched)
+	})
+
+	t.Run("参数错误时返回错误", func(t *testing.T) {
+		data := `{"op":"IncrBy","k":["a"],"a":["x"]}` + "\n"
+		_, err := cache.Replay(ctx, cachetest.NewFake(), strings.NewReader(data), nil)
+		assert.Error(t, err)
+	})
+}
+
+// TestRecorderSampling 测试按采样率记录调用
+func TestRecorderSampling(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	recorder := cache.NewRecorder(&buf, &cache.RecorderOptions{SampleRate: 0.2})
+	client := cache.NewRecordingClient(cachetest.NewFake(), recorder)
+
+	for i := 0; i < 1000; i++ {
+		client.Incr(ctx, "counter")
+	}
+	n, err := client.Get(ctx, "counter")
+	assert.NoError(t, err)
+	assert.Equal(t, "1000", n)
+	assert.Greater(t, recorder.Count(), int64(100))
+	assert.Less(t, recorder.Count(), int64(300))
+}
+
+// TestFactoryRecorder 测试工厂创建的客户端通过录制器记录调用
+func TestFactoryRecorder(t *testing.T) {
+	server, err := cachetest.StartServer(cachetest.WithKeyPrefix("svc:"))
+	require.NoError(t, err)
+	defer server.Close()
+
+	factory, err := cache.NewFactory(server.Config())
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	recorder := cache.NewRecorder(&buf, nil)
+	factory.SetRecorder(recorder)
+
+	client, err := factory.CreateClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	assert.NoError(t, client.Set(ctx, "key", "v", 0))
+	val, err := client.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "v", val)
+	assert.Equal(t, int64(2), recorder.Count())
+	assert.Contains(t, buf.String(), `"k":["key"]`)
+}
@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIsIdempotent 测试命令幂等性判断
+func TestIsIdempotent(t *testing.T) {
+	assert.True(t, cache.IsIdempotent("get"))
+	assert.True(t, cache.IsIdempotent("SET"))
+	assert.True(t, cache.IsIdempotent("hset"))
+	assert.False(t, cache.IsIdempotent("incr"))
+	assert.False(t, cache.IsIdempotent("LPUSH"))
+	assert.False(t, cache.IsIdempotent("rpop"))
+}
+
+// TestRetryPolicyShouldRetry 测试重试判断
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	policy := &cache.ExponentialBackoff{Retries: 3}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("i/o timeout")}
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	assert.True(t, policy.ShouldRetry("get", readErr))
+	assert.False(t, policy.ShouldRetry("incr", readErr))
+	assert.True(t, policy.ShouldRetry("incr", dialErr))
+	assert.True(t, policy.ShouldRetry("incr", redis.ErrPoolTimeout))
+	assert.False(t, policy.ShouldRetry("get", redis.Nil))
+	assert.False(t, policy.ShouldRetry("get", context.Canceled))
+	assert.False(t, policy.ShouldRetry("get", cache.ErrCircuitOpen))
+
+	policy.RetryNonIdempotent = true
+	assert.True(t, policy.ShouldRetry("incr", readErr))
+}
+
+// TestRetryPolicyBackoff 测试退避时间范围
+func TestRetryPolicyBackoff(t *testing.T) {
+	exponential := &cache.ExponentialBackoff{
+		Retries:    5,
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 40 * time.Millisecond,
+	}
+	for attempt := 1; attempt <= 5; attempt++ {
+		backoff := exponential.Backoff(attempt, 0)
+		assert.GreaterOrEqual(t, backoff, time.Duration(0))
+		assert.LessOrEqual(t, backoff, 40*time.Millisecond)
+	}
+
+	decorrelated := &cache.DecorrelatedJitter{
+		Retries: 5,
+		Base:    10 * time.Millisecond,
+		Max:     100 * time.Millisecond,
+	}
+	var last time.Duration
+	for attempt := 1; attempt <= 5; attempt++ {
+		last = decorrelated.Backoff(attempt, last)
+		assert.GreaterOrEqual(t, last, 10*time.Millisecond)
+		assert.LessOrEqual(t, last, 100*time.Millisecond)
+	}
+}
+
+// TestNewRetryPolicy 测试根据配置创建重试策略
+func TestNewRetryPolicy(t *testing.T) {
+	config := cache.DefaultConfig()
+
+	policy := cache.NewRetryPolicy(&config.Common)
+	assert.IsType(t, &cache.ExponentialBackoff{}, policy)
+	assert.Equal(t, 3, policy.MaxRetries())
+
+	config.Common.RetryStrategy = cache.RetryDecorrelated
+	assert.IsType(t, &cache.DecorrelatedJitter{}, cache.NewRetryPolicy(&config.Common))
+
+	config.Common.MaxRetries = -1
+	assert.Equal(t, cache.NoRetry, cache.NewRetryPolicy(&config.Common))
+}
+
+// TestRetryError 测试重试错误包装
+func TestRetryError(t *testing.T) {
+	err := &cache.RetryError{Cmd: "get", Attempts: 3, Err: cache.ErrConnectionTimeout}
+	assert.ErrorIs(t, err, cache.ErrConnectionTimeout)
+	assert.Contains(t, err.Error(), "3 attempts")
+}
+
+// startDropFirstProxy 启动转发到addr的代理，第一个连接建立后立即关闭，返回代理地址和已接受的连接数
+func startDropFirstProxy(t *testing.T, addr string) (string, *atomic.Int64) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var accepted atomic.Int64
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if accepted.Add(1) == 1 {
+				conn.Close()
+				continue
+			}
+			upstream, err := net.Dial("tcp", addr)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			go func() {
+				defer upstream.Close()
+				io.Copy(upstream, conn)
+			}()
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return listener.Addr().String(), &accepted
+}
+
+// TestRetryThenMiss 测试重试后未命中仍然返回ErrKeyNotFound，而不是RetryError
+func TestRetryThenMiss(t *testing.T) {
+	server, err := cachetest.StartServer()
+	require.NoError(t, err)
+	defer server.Close()
+	proxy, accepted := startDropFirstProxy(t, server.Addr())
+
+	config := server.Config()
+	config.Single.Addr = proxy
+	config.Common.StartupMode = cache.StartupLazy
+	config.Common.MinIdleConns = 0
+	config.Common.MaxRetries = 2
+	client, err := cache.NewClientFromConfig(config)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Get(context.Background(), "missing")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+	assert.False(t, cache.IsUnavailableError(err))
+	assert.Equal(t, int64(2), accepted.Load())
+}
@@ -0,0 +1,53 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptCacheClient 模拟服务端脚本缓存的客户端
+type scriptCacheClient struct {
+	cache.Client
+	loaded map[string]bool
+	evals  int
+}
+
+func (c *scriptCacheClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) (interface{}, error) {
+	if !c.loaded[sha1] {
+		return nil, cache.ErrScriptNotFound
+	}
+	return int64(len(keys)), nil
+}
+
+func (c *scriptCacheClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	c.evals++
+	c.loaded[cache.NewScript(script).Hash()] = true
+	return int64(len(keys)), nil
+}
+
+// TestScriptHash 测试脚本SHA1计算
+func TestScriptHash(t *testing.T) {
+	script := cache.NewScript("return 1")
+	assert.Equal(t, "e0e1f9fabfc9d4800c877a703b823ac0578ff8db", script.Hash())
+	assert.Equal(t, "return 1", script.Source())
+}
+
+// TestScriptRunFallback 测试脚本不存在时改用EVAL
+func TestScriptRunFallback(t *testing.T) {
+	client := &scriptCacheClient{loaded: make(map[string]bool)}
+	script := cache.NewScript("return #KEYS")
+	ctx := context.Background()
+
+	n, err := script.Run(ctx, client, []string{"a", "b"}).Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, 1, client.evals)
+
+	n, err = script.Run(ctx, client, []string{"a"}).Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, 1, client.evals)
+}
@@ -1,11 +1,15 @@
 package unit
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"cache"
+	"cache/cachetest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestSentinelClientCreation 测试哨兵客户端创建
@@ -376,4 +380,76 @@ func BenchmarkSentinelConfigValidation(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = config.Validate()
 	}
-}
\ No newline at end of file
+}
+// TestSentinelReadRouting 测试读命令按配置和读偏好发往从节点或主节点
+func TestSentinelReadRouting(t *testing.T) {
+	sentinel, err := cachetest.StartSentinel("mymaster", 1, 1)
+	require.NoError(t, err)
+	defer sentinel.Close()
+	master, replica := sentinel.Master(), sentinel.Replicas()[0]
+
+	ctx := context.Background()
+	require.NoError(t, sentinel.Fake().Set(ctx, "key", "v", 0))
+	// get断言节点收到的GET命令数量
+	get := func(t *testing.T, client cache.Client, ctx context.Context, masterCalls, replicaCalls int64) {
+		val, err := client.Get(ctx, "key")
+		assert.NoError(t, err)
+		assert.Equal(t, "v", val)
+		assert.Equal(t, masterCalls, master.Calls("get"))
+		assert.Equal(t, replicaCalls, replica.Calls("get"))
+	}
+
+	t.Run("开启从节点读路由", func(t *testing.T) {
+		config := sentinel.Config()
+		config.Sentinel.ReplicaOnly = true
+		client, err := cache.NewClientFromConfig(config)
+		require.NoError(t, err)
+		defer client.Close()
+
+		get(t, client, ctx, 0, 1)
+		get(t, client, cache.WithReadPreference(ctx, cache.ReadPrimary), 1, 1)
+		get(t, client, cache.WithReadPreference(ctx, cache.ReplicaPreferred), 1, 2)
+	})
+
+	t.Run("默认读主节点", func(t *testing.T) {
+		client, err := cache.NewClientFromConfig(sentinel.Config())
+		require.NoError(t, err)
+		defer client.Close()
+
+		get(t, client, ctx, 2, 2)
+		get(t, client, cache.WithReadPreference(ctx, cache.ReplicaPreferred), 2, 3)
+		get(t, client, cache.WithReadPreference(ctx, cache.ReadPrimary), 3, 3)
+	})
+
+	t.Run("Keys和Scan始终在主节点执行", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			require.NoError(t, sentinel.Fake().Set(ctx, fmt.Sprintf("scan:%d", i), "v", 0))
+		}
+		config := sentinel.Config()
+		config.Sentinel.RouteRandomly = true
+		client, err := cache.NewClientFromConfig(config)
+		require.NoError(t, err)
+		defer client.Close()
+
+		var keys []string
+		var cursor uint64
+		for {
+			var page []string
+			page, cursor, err = client.Scan(ctx, cursor, "scan:*", 3)
+			require.NoError(t, err)
+			keys = append(keys, page...)
+			if cursor == 0 {
+				break
+			}
+		}
+		assert.Len(t, keys, 20)
+
+		all, err := client.Keys(ctx, "scan:*")
+		require.NoError(t, err)
+		assert.Len(t, all, 20)
+		assert.Zero(t, replica.Calls("scan"))
+		assert.Zero(t, replica.Calls("keys"))
+		assert.Positive(t, master.Calls("scan"))
+		assert.Equal(t, int64(1), master.Calls("keys"))
+	})
+}
@@ -0,0 +1,255 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startSentinelEvents 启动哨兵部署并创建订阅事件的哨兵客户端
+func startSentinelEvents(t *testing.T, sentinels int) (*cachetest.Sentinel, *cache.SentinelClient) {
+	sentinel, err := cachetest.StartSentinel("mymaster", 1, sentinels)
+	require.NoError(t, err)
+	t.Cleanup(func() { sentinel.Close() })
+
+	client, err := cache.NewClientFromConfig(sentinel.Config())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	sentinelClient, ok := client.(*cache.SentinelClient)
+	require.True(t, ok)
+	return sentinel, sentinelClient
+}
+
+// publishEvent 在哨兵节点上发布事件消息
+func publishEvent(t *testing.T, node *cachetest.Server, channel, payload string) {
+	rdb := redis.NewClient(&redis.Options{Addr: node.Addr()})
+	defer rdb.Close()
+	require.NoError(t, rdb.Publish(context.Background(), channel, payload).Err())
+}
+
+// waitEventSubscription 等待事件订阅连接到哨兵节点
+// 使用-sdown频道判断，go-redis自己只订阅+switch-master
+func waitEventSubscription(t *testing.T, node *cachetest.Server, want int64) {
+	rdb := redis.NewClient(&redis.Options{Addr: node.Addr()})
+	defer rdb.Close()
+	require.Eventually(t, func() bool {
+		counts, err := rdb.PubSubNumSub(context.Background(), string(cache.EventSDownCleared)).Result()
+		return err == nil && counts[string(cache.EventSDownCleared)] == want
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// receiveEvent 从通道接收一个事件
+func receiveEvent(t *testing.T, events <-chan cache.SentinelEvent) cache.SentinelEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "events channel closed")
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("no sentinel event received")
+	}
+	return cache.SentinelEvent{}
+}
+
+// TestSentinelEventParsing 测试哨兵事件消息的解析和按主节点名称过滤
+func TestSentinelEventParsing(t *testing.T) {
+	sentinel, client := startSentinelEvents(t, 1)
+	node := sentinel.Sentinels()[0]
+	events := client.Events()
+	waitEventSubscription(t, node, 1)
+
+	tests := []struct {
+		name    string
+		channel cache.SentinelEventType
+		payload string
+		want    *cache.SentinelEvent
+	}{
+		{
+			name:    "主节点切换",
+			channel: cache.EventSwitchMaster,
+			payload: "mymaster 10.0.0.1 6379 10.0.0.2 6380",
+			want: &cache.SentinelEvent{
+				MasterName: "mymaster", InstanceType: "master", Addr: "10.0.0.2:6380",
+				OldMaster: "10.0.0.1:6379", NewMaster: "10.0.0.2:6380",
+			},
+		},
+		{
+			name:    "IPv6地址",
+			channel: cache.EventSwitchMaster,
+			payload: "mymaster ::1 6379 ::2 6380",
+			want: &cache.SentinelEvent{
+				MasterName: "mymaster", InstanceType: "master", Addr: "[::2]:6380",
+				OldMaster: "[::1]:6379", NewMaster: "[::2]:6380",
+			},
+		},
+		{
+			name:    "主节点切换字段数量不符",
+			channel: cache.EventSwitchMaster,
+			payload: "mymaster 10.0.0.1 6379 10.0.0.2 6380 extra",
+		},
+		{
+			name:    "其他主节点的切换",
+			channel: cache.EventSwitchMaster,
+			payload: "othermaster 10.0.0.1 6379 10.0.0.2 6380",
+		},
+		{
+			name:    "主节点主观下线",
+			channel: cache.EventSDown,
+			payload: "master mymaster 10.0.0.1 6379",
+			want:    &cache.SentinelEvent{MasterName: "mymaster", InstanceType: "master", Addr: "10.0.0.1:6379"},
+		},
+		{
+			name:    "主节点客观下线",
+			channel: cache.EventODown,
+			payload: "master mymaster 10.0.0.1 6379 #quorum 2/2",
+			want:    &cache.SentinelEvent{MasterName: "mymaster", InstanceType: "master", Addr: "10.0.0.1:6379"},
+		},
+		{
+			name:    "主节点退出客观下线",
+			channel: cache.EventODownCleared,
+			payload: "master mymaster 10.0.0.1 6379",
+			want:    &cache.SentinelEvent{MasterName: "mymaster", InstanceType: "master", Addr: "10.0.0.1:6379"},
+		},
+		{
+			name:    "从节点退出主观下线",
+			channel: cache.EventSDownCleared,
+			payload: "slave 10.0.0.3:6381 10.0.0.3 6381 @ mymaster 10.0.0.1 6379",
+			want:    &cache.SentinelEvent{MasterName: "mymaster", InstanceType: "slave", Addr: "10.0.0.3:6381"},
+		},
+		{
+			name:    "哨兵主观下线",
+			channel: cache.EventSDown,
+			payload: "sentinel 6f8ae3b2c1 10.0.0.9 26379 @ mymaster 10.0.0.1 6379",
+			want:    &cache.SentinelEvent{MasterName: "mymaster", InstanceType: "sentinel", Addr: "10.0.0.9:26379"},
+		},
+		{
+			name:    "发现从节点",
+			channel: cache.EventReplicaAdded,
+			payload: "slave 10.0.0.3:6381 10.0.0.3 6381 @ mymaster 10.0.0.1 6379",
+			want:    &cache.SentinelEvent{MasterName: "mymaster", InstanceType: "slave", Addr: "10.0.0.3:6381"},
+		},
+		{
+			name:    "从节点完成重新配置",
+			channel: cache.EventReplicaReconfigured,
+			payload: "slave 10.0.0.3:6381 10.0.0.3 6381 @ mymaster 10.0.0.2 6380",
+			want:    &cache.SentinelEvent{MasterName: "mymaster", InstanceType: "slave", Addr: "10.0.0.3:6381"},
+		},
+		{
+			name:    "旧主节点转换为从节点",
+			channel: cache.EventConvertToReplica,
+			payload: "slave 10.0.0.1:6379 10.0.0.1 6379 @ mymaster 10.0.0.2 6380",
+			want:    &cache.SentinelEvent{MasterName: "mymaster", InstanceType: "slave", Addr: "10.0.0.1:6379"},
+		},
+		{
+			name:    "其他主节点的从节点",
+			channel: cache.EventSDown,
+			payload: "slave 10.0.0.3:6381 10.0.0.3 6381 @ othermaster 10.0.0.1 6379",
+		},
+		{
+			name:    "实例格式字段不足",
+			channel: cache.EventSDown,
+			payload: "master mymaster 10.0.0.1",
+		},
+	}
+
+	// 每条消息后发布一个探针事件，被丢弃的消息之后首先收到探针
+	const probe = "master mymaster 10.0.0.100 1"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publishEvent(t, node, string(tt.channel), tt.payload)
+			publishEvent(t, node, string(cache.EventSDown), probe)
+
+			if tt.want != nil {
+				event := receiveEvent(t, events)
+				assert.Equal(t, tt.channel, event.Type)
+				assert.Equal(t, tt.want.MasterName, event.MasterName)
+				assert.Equal(t, tt.want.InstanceType, event.InstanceType)
+				assert.Equal(t, tt.want.Addr, event.Addr)
+				assert.Equal(t, tt.want.OldMaster, event.OldMaster)
+				assert.Equal(t, tt.want.NewMaster, event.NewMaster)
+				assert.Equal(t, node.Addr(), event.Sentinel)
+				assert.Equal(t, tt.payload, event.Payload)
+				assert.False(t, event.Time.IsZero())
+			}
+			event := receiveEvent(t, events)
+			assert.Equal(t, probe, event.Payload)
+		})
+	}
+}
+
+// TestSentinelEventsReconnect 测试当前哨兵断开后切换到下一个哨兵继续接收事件
+func TestSentinelEventsReconnect(t *testing.T) {
+	sentinel, client := startSentinelEvents(t, 2)
+	first, second := sentinel.Sentinels()[0], sentinel.Sentinels()[1]
+	events := client.Events()
+	waitEventSubscription(t, first, 1)
+
+	first.Close()
+	waitEventSubscription(t, second, 1)
+
+	oldMaster := sentinel.Master().Addr()
+	require.NoError(t, sentinel.Failover())
+	event := receiveEvent(t, events)
+	assert.Equal(t, cache.EventSwitchMaster, event.Type)
+	assert.Equal(t, second.Addr(), event.Sentinel)
+	assert.Equal(t, oldMaster, event.OldMaster)
+	assert.Equal(t, sentinel.Master().Addr(), event.NewMaster)
+}
+
+// TestSentinelEventsUnsubscribe 测试取消注册后不再回调，其他回调不受影响
+func TestSentinelEventsUnsubscribe(t *testing.T) {
+	sentinel, client := startSentinelEvents(t, 1)
+	node := sentinel.Sentinels()[0]
+
+	received := make(chan cache.SentinelEvent, 10)
+	unsubscribe := client.OnEvent(func(event cache.SentinelEvent) {
+		received <- event
+	})
+	events := client.Events()
+	waitEventSubscription(t, node, 1)
+
+	publishEvent(t, node, string(cache.EventSDown), "master mymaster 10.0.0.1 6379")
+	receiveEvent(t, received)
+	receiveEvent(t, events)
+
+	unsubscribe()
+	publishEvent(t, node, string(cache.EventSDownCleared), "master mymaster 10.0.0.1 6379")
+	event := receiveEvent(t, events)
+	assert.Equal(t, cache.EventSDownCleared, event.Type)
+	assert.Empty(t, received)
+
+	// 取消注册不影响订阅连接
+	waitEventSubscription(t, node, 1)
+}
+
+// TestSentinelEventsClose 测试关闭客户端后停止订阅并关闭事件通道
+func TestSentinelEventsClose(t *testing.T) {
+	sentinel, client := startSentinelEvents(t, 1)
+	node := sentinel.Sentinels()[0]
+	events := client.Events()
+	waitEventSubscription(t, node, 1)
+
+	require.NoError(t, client.Close())
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(2 * time.Second):
+		t.Fatal("events channel not closed")
+	}
+	waitEventSubscription(t, node, 0)
+
+	// 关闭后注册的回调和通道不再收到事件
+	called := false
+	client.OnEvent(func(cache.SentinelEvent) { called = true })
+	_, ok := <-client.Events()
+	assert.False(t, ok)
+	waitEventSubscription(t, node, 0)
+	assert.False(t, called)
+}
@@ -0,0 +1,48 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// unreachableConfig 返回指向不可达地址的单机配置
+func unreachableConfig(mode cache.StartupMode) *cache.Config {
+	config := cache.DefaultConfig()
+	config.Single.Addr = "127.0.0.1:1"
+	config.Common.DialTimeout = 50 * time.Millisecond
+	config.Common.StartupMode = mode
+	config.Common.StartupTimeout = 300 * time.Millisecond
+	return config
+}
+
+// TestStartupLazy 测试延迟连接模式创建客户端不检查连接
+func TestStartupLazy(t *testing.T) {
+	client, err := cache.NewClientFromConfig(unreachableConfig(cache.StartupLazy))
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Error(t, client.Ready(ctx))
+}
+
+// TestStartupWait 测试等待模式在超时后返回错误
+func TestStartupWait(t *testing.T) {
+	start := time.Now()
+	_, err := cache.NewClientFromConfig(unreachableConfig(cache.StartupWait))
+	assert.Error(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+// TestStartupModeValidation 测试无效的启动模式
+func TestStartupModeValidation(t *testing.T) {
+	config := cache.DefaultConfig()
+	config.Common.StartupMode = "sometimes"
+	assert.ErrorIs(t, config.Validate(), cache.ErrInvalidStartupMode)
+}
@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPoolStatsSaturation 测试连接池饱和度计算
+func TestPoolStatsSaturation(t *testing.T) {
+	stats := &cache.PoolStats{TotalConns: 10, IdleConns: 2}
+	assert.Equal(t, uint32(8), stats.InUseConns())
+	assert.InDelta(t, 0.8, stats.Saturation(10), 1e-9)
+	assert.Equal(t, 0.0, stats.Saturation(0))
+
+	stats = &cache.PoolStats{TotalConns: 1, IdleConns: 3}
+	assert.Equal(t, uint32(0), stats.InUseConns())
+}
+
+// TestSingleClientStats 测试单机客户端在内嵌服务端上的连接池统计
+func TestSingleClientStats(t *testing.T) {
+	server, err := cachetest.StartServer()
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := cache.NewClientFromConfig(server.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	before := client.Stats()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, client.Set(ctx, fmt.Sprintf("key:%d", i), "v", 0))
+	}
+
+	// 每条命令从连接池取一次连接
+	stats := client.Stats()
+	assert.Equal(t, uint32(10), stats.Hits+stats.Misses-before.Hits-before.Misses)
+	assert.GreaterOrEqual(t, stats.TotalConns, uint32(1))
+	assert.Equal(t, stats.TotalConns, stats.IdleConns)
+	assert.Zero(t, stats.InUseConns())
+	assert.GreaterOrEqual(t, stats.Misses, uint32(1))
+	assert.Zero(t, stats.Timeouts)
+
+	// 事务执行期间占用一个连接
+	err = client.Watch(ctx, func(tx cache.Tx) error {
+		inUse := client.Stats()
+		assert.Equal(t, uint32(1), inUse.InUseConns())
+		return nil
+	}, "key:0")
+	require.NoError(t, err)
+	assert.Zero(t, client.Stats().InUseConns())
+}
+
+// TestClusterClientNodeStats 测试集群客户端按节点和汇总的连接池统计
+func TestClusterClientNodeStats(t *testing.T) {
+	cluster, err := cachetest.StartCluster(3)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	client, err := cache.NewClientFromConfig(cluster.Config())
+	require.NoError(t, err)
+	defer client.Close()
+	clusterClient, ok := client.(*cache.ClusterClient)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	for i := 0; i < 30; i++ {
+		require.NoError(t, client.Set(ctx, fmt.Sprintf("key:%d", i), "v", 0))
+	}
+
+	nodes, err := clusterClient.NodeStats(ctx)
+	require.NoError(t, err)
+	addrs := make([]string, 0, len(nodes))
+	var sum cache.PoolStats
+	for addr, stats := range nodes {
+		addrs = append(addrs, addr)
+		// 键分布在所有节点上，每个节点都建立过连接
+		assert.GreaterOrEqual(t, stats.TotalConns, uint32(1), addr)
+		assert.Zero(t, stats.InUseConns(), addr)
+		sum.TotalConns += stats.TotalConns
+		sum.IdleConns += stats.IdleConns
+		sum.Hits += stats.Hits
+		sum.Misses += stats.Misses
+	}
+	assert.ElementsMatch(t, cluster.Addrs(), addrs)
+
+	// 汇总统计是各节点统计之和
+	total := clusterClient.Stats()
+	assert.Equal(t, sum.TotalConns, total.TotalConns)
+	assert.Equal(t, sum.IdleConns, total.IdleConns)
+	assert.Equal(t, sum.Hits, total.Hits)
+	assert.Equal(t, sum.Misses, total.Misses)
+	assert.GreaterOrEqual(t, total.Hits+total.Misses, uint32(30))
+}
@@ -0,0 +1,228 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cache"
+	"cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCacheTags 在三种客户端上测试标签写入和失效
+func TestCacheTags(t *testing.T) {
+	for name, config := range embeddedTestConfigs(t) {
+		t.Run(name, func(t *testing.T) {
+			client, err := cache.NewClientFromConfig(config)
+			require.NoError(t, err)
+			defer client.Close()
+			testCacheTags(t, client)
+		})
+	}
+}
+
+func testCacheTags(t *testing.T, client cache.Client) {
+	ctx := context.Background()
+	c := cache.NewCache(client, &cache.CacheOptions{TTL: time.Minute})
+	defer client.Del(ctx, "user:42:profile", "user:42:orders", "tenant:7:settings", "tag:user:42", "tag:tenant:7")
+
+	assert.NoError(t, c.Set(ctx, "user:42:profile", "p", 0, "user:42", "tenant:7"))
+	assert.NoError(t, c.Set(ctx, "user:42:orders", "o", 10*time.Minute, "user:42"))
+	assert.NoError(t, c.Set(ctx, "tenant:7:settings", "s", 0, "tenant:7"))
+
+	t.Run("标签集合的过期时间不短于条目", func(t *testing.T) {
+		ttl, err := client.TTL(ctx, "tag:user:42")
+		assert.NoError(t, err)
+		assert.Greater(t, ttl, 9*time.Minute)
+	})
+
+	t.Run("失效标签删除所有条目", func(t *testing.T) {
+		n, err := c.InvalidateTags(ctx, "user:42")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+
+		_, err = c.Get(ctx, "user:42:profile")
+		assert.Equal(t, cache.ErrKeyNotFound, err)
+		val, err := c.Get(ctx, "tenant:7:settings")
+		assert.NoError(t, err)
+		assert.Equal(t, "s", val)
+	})
+
+	t.Run("惰性清理已删除的条目", func(t *testing.T) {
+		keys, err := c.TagKeys(ctx, "tenant:7")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tenant:7:settings"}, keys)
+
+		n, err := client.SCard(ctx, "tag:tenant:7")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+}
+
+// TestCacheTagsFake 在Fake上测试写入后按标签失效，以及标签集合的过期时间
+func TestCacheTagsFake(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake(cachetest.WithKeyPrefix("app:"))
+	c := cache.NewCache(fake, &cache.CacheOptions{TTL: time.Minute})
+
+	assert.NoError(t, c.Set(ctx, "long", "l", time.Hour, "group"))
+	assert.NoError(t, c.Set(ctx, "short", "s", 0, "group"))
+
+	// 较短的条目不会缩短标签集合的过期时间
+	ttl, err := fake.TTL(ctx, "tag:group")
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, ttl, time.Hour)
+
+	// 过期时间足够时不再延长
+	fake.Advance(10 * time.Minute)
+	assert.NoError(t, c.Set(ctx, "short", "s", 0, "group"))
+	next, err := fake.TTL(ctx, "tag:group")
+	assert.NoError(t, err)
+	assert.Equal(t, ttl-10*time.Minute, next)
+
+	// 永不过期的条目使标签集合在条目存在期间不会过期
+	assert.NoError(t, cache.NewCache(fake, nil).Set(ctx, "forever", "f", 0, "group"))
+	ttl, err = fake.TTL(ctx, "tag:group")
+	assert.NoError(t, err)
+	assert.Greater(t, ttl, 100*365*24*time.Hour)
+
+	n, err := c.InvalidateTags(ctx, "group")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	n, err = fake.Exists(ctx, "long", "short", "forever", "tag:group")
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// execHookClient 在第一个管道执行后调用after，用于模拟并发的调用
+type execHookClient struct {
+	cache.Client
+	after func()
+}
+
+func (c *execHookClient) Pipeline() cache.Pipeliner {
+	return &execHookPipeliner{Pipeliner: c.Client.Pipeline(), c: c}
+}
+
+type execHookPipeliner struct {
+	cache.Pipeliner
+	c *execHookClient
+}
+
+func (p *execHookPipeliner) Exec(ctx context.Context) ([]interface{}, error) {
+	results, err := p.Pipeliner.Exec(ctx)
+	if after := p.c.after; after != nil {
+		p.c.after = nil
+		after()
+	}
+	return results, err
+}
+
+// TestCacheSetAfterInvalidate 测试标签集合在写入条目前被失效时，Set返回后条目仍在集合中
+func TestCacheSetAfterInvalidate(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake()
+	c := cache.NewCache(&execHookClient{Client: fake, after: func() {
+		n, err := cache.NewCache(fake, nil).InvalidateTags(ctx, "t")
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	}}, nil)
+
+	assert.NoError(t, c.Set(ctx, "key", "v", time.Minute, "t"))
+
+	members, err := fake.SMembers(ctx, "tag:t")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key"}, members)
+	ttl, err := fake.TTL(ctx, "tag:t")
+	assert.NoError(t, err)
+	assert.Greater(t, ttl, time.Minute)
+
+	n, err := c.InvalidateTags(ctx, "t")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+// TestCacheInvalidateTags 测试失效标签时读取并删除标签集合
+func TestCacheInvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake(cachetest.WithKeyPrefix("app:"))
+	c := cache.NewCache(fake, &cache.CacheOptions{TagPrefix: "t:"})
+
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NoError(t, fake.Set(ctx, key, key, 0))
+	}
+	fake.SAdd(ctx, "t:x", "a", "b", "gone")
+	fake.SAdd(ctx, "t:y", "b", "c")
+
+	n, err := c.InvalidateTags(ctx, "x", "y", "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	n, err = fake.Exists(ctx, "a", "b", "c", "t:x", "t:y")
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = c.InvalidateTags(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// TestCacheInvalidateTagsDeleteFails 测试删除条目失败时标签集合保持不变，重新调用可以完成失效
+func TestCacheInvalidateTagsDeleteFails(t *testing.T) {
+	ctx := context.Background()
+	fake := cachetest.NewFake()
+	faulty := cachetest.NewFaultyClient(fake, &cachetest.FaultyOptions{
+		Faults: []cachetest.Fault{{Commands: []string{"del"}, KeyPattern: "[ab]", Err: errors.New("del failed")}},
+	})
+	c := cache.NewCache(faulty, nil)
+
+	assert.NoError(t, c.Set(ctx, "a", "1", time.Minute, "t"))
+	assert.NoError(t, c.Set(ctx, "b", "2", time.Minute, "t"))
+
+	_, err := c.InvalidateTags(ctx, "t")
+	assert.Error(t, err)
+	keys, err := fake.SMembers(ctx, "tag:t")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, keys)
+
+	faulty.ClearFaults()
+	n, err := c.InvalidateTags(ctx, "t")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	n, err = fake.Exists(ctx, "a", "b", "tag:t")
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// TestCacheGetOrLoad 测试未命中时加载并写入
+func TestCacheGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewCache(cachetest.NewFake(), nil)
+
+	var loads int
+	load := func(ctx context.Context) (string, error) {
+		loads++
+		return "loaded", nil
+	}
+	for i := 0; i < 3; i++ {
+		val, err := c.GetOrLoad(ctx, "key", time.Minute, nil, load)
+		assert.NoError(t, err)
+		assert.Equal(t, "loaded", val)
+	}
+	assert.Equal(t, 1, loads)
+
+	errLoad := errors.New("load failed")
+	_, err := c.GetOrLoad(ctx, "other", 0, nil, func(ctx context.Context) (string, error) {
+		return "", errLoad
+	})
+	assert.Equal(t, errLoad, err)
+	_, err = c.Get(ctx, "other")
+	assert.Equal(t, cache.ErrKeyNotFound, err)
+
+	n, err := c.Delete(ctx, "key", "other")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatch 测试并发的乐观事务在冲突后重试
+func TestWatch(t *testing.T) {
+	for name, config := range embeddedTestConfigs(t) {
+		t.Run(name, func(t *testing.T) {
+			config.Common.WatchRetries = 100
+			client, err := cache.NewClientFromConfig(config)
+			require.NoError(t, err)
+			defer client.Close()
+
+			ctx := context.Background()
+			key := "inventory:{sku-1}"
+			defer client.Del(ctx, key)
+			assert.NoError(t, client.Set(ctx, key, 0, 0))
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := client.Watch(ctx, func(tx cache.Tx) error {
+						val, err := tx.Get(ctx, key)
+						if err != nil {
+							return err
+						}
+						n, _ := strconv.Atoi(val)
+						return tx.TxPipelined(ctx, func(pipe cache.Pipeliner) error {
+							pipe.Set(ctx, key, n+1, 0)
+							return nil
+						})
+					}, key)
+					assert.NoError(t, err)
+				}()
+			}
+			wg.Wait()
+
+			val, err := client.Get(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, "10", val)
+		})
+	}
+}
+
+// TestTxFailedError 测试乐观事务失败错误
+func TestTxFailedError(t *testing.T) {
+	err := &cache.RetryError{Cmd: "watch", Attempts: 11, Err: cache.ErrTxFailed}
+	assert.ErrorIs(t, err, cache.ErrTxFailed)
+	assert.True(t, cache.IsRedisError(cache.ErrTxFailed))
+}
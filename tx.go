@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tx 乐观事务
+// 在Watch的回调中读取被监视的键，再通过TxPipelined提交写命令；
+// 回调执行期间被监视的键被其他客户端修改时，提交失败并重新执行回调
+type Tx interface {
+	// 读取操作，在监视键的连接上执行
+	Get(ctx context.Context, key string) (string, error)
+	MGet(ctx context.Context, keys ...string) ([]interface{}, error)
+	HGet(ctx context.Context, key, field string) (string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	Exists(ctx context.Context, keys ...string) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	SMembers(ctx context.Context, key string) ([]string, error)
+	ZScore(ctx context.Context, key, member string) (float64, error)
+
+	// TxPipelined 在MULTI/EXEC中执行fn排队的写命令，fn中不要调用Exec
+	TxPipelined(ctx context.Context, fn func(pipe Pipeliner) error) error
+	// Unwatch 取消监视
+	Unwatch(ctx context.Context, keys ...string) error
+}
+
+// watchTx 基于go-redis事务的Tx实现
+type watchTx struct {
+	tx     *redis.Tx
+	config *Config
+}
+
+// Get 获取字符串值
+func (t *watchTx) Get(ctx context.Context, key string) (string, error) {
+	result := t.tx.Get(ctx, t.config.prefixKey(ctx, key))
+	if result.Err() == redis.Nil {
+		return "", ErrKeyNotFound
+	}
+	return result.Result()
+}
+
+// MGet 批量获取字符串值
+func (t *watchTx) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = t.config.prefixKey(ctx, key)
+	}
+	return t.tx.MGet(ctx, prefixedKeys...).Result()
+}
+
+// HGet 获取哈希表字段值
+func (t *watchTx) HGet(ctx context.Context, key, field string) (string, error) {
+	result := t.tx.HGet(ctx, t.config.prefixKey(ctx, key), field)
+	if result.Err() == redis.Nil {
+		return "", ErrKeyNotFound
+	}
+	return result.Result()
+}
+
+// HGetAll 获取哈希表所有字段和值
+func (t *watchTx) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return t.tx.HGetAll(ctx, t.config.prefixKey(ctx, key)).Result()
+}
+
+// Exists 检查键是否存在
+func (t *watchTx) Exists(ctx context.Context, keys ...string) (int64, error) {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = t.config.prefixKey(ctx, key)
+	}
+	return t.tx.Exists(ctx, prefixedKeys...).Result()
+}
+
+// TTL 获取键的剩余生存时间
+func (t *watchTx) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return t.tx.TTL(ctx, t.config.prefixKey(ctx, key)).Result()
+}
+
+// LRange 获取列表指定范围的元素
+func (t *watchTx) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return t.tx.LRange(ctx, t.config.prefixKey(ctx, key), start, stop).Result()
+}
+
+// SMembers 获取集合所有成员
+func (t *watchTx) SMembers(ctx context.Context, key string) ([]string, error) {
+	return t.tx.SMembers(ctx, t.config.prefixKey(ctx, key)).Result()
+}
+
+// ZScore 获取有序集合成员的分数
+func (t *watchTx) ZScore(ctx context.Context, key, member string) (float64, error) {
+	result := t.tx.ZScore(ctx, t.config.prefixKey(ctx, key), member)
+	if result.Err() == redis.Nil {
+		return 0, ErrKeyNotFound
+	}
+	return result.Result()
+}
+
+// TxPipelined 在MULTI/EXEC中执行写命令，被监视的键已被修改时返回redis.TxFailedErr
+func (t *watchTx) TxPipelined(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	_, err := t.tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		return fn(&SinglePipeliner{pipe: pipe, config: t.config})
+	})
+	return err
+}
+
+// Unwatch 取消监视
+func (t *watchTx) Unwatch(ctx context.Context, keys ...string) error {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = t.config.prefixKey(ctx, key)
+	}
+	return t.tx.Unwatch(ctx, prefixedKeys...).Err()
+}
+
+// watcher 支持WATCH的go-redis客户端
+type watcher interface {
+	Watch(ctx context.Context, fn func(*redis.Tx) error, keys ...string) error
+}
+
+// watchPolicy 根据配置创建乐观事务的重试策略
+// WatchRetries为-1时不重试，为0时使用默认的10次
+func watchPolicy(config *CommonConfig) RetryPolicy {
+	retries := config.WatchRetries
+	switch {
+	case retries < 0:
+		return NoRetry
+	case retries == 0:
+		retries = 10
+	}
+	return &ExponentialBackoff{
+		Retries:    retries,
+		MinBackoff: config.WatchBackoff,
+		MaxBackoff: config.MaxRetryBackoff,
+	}
+}
+
+// watch 监视键并执行乐观事务，被监视的键被修改时按WatchRetries和WatchBackoff重试
+// 重试后仍然失败时返回包装了ErrTxFailed的RetryError
+func watch(ctx context.Context, rdb watcher, config *Config, fn func(tx Tx) error, keys ...string) error {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = config.prefixKey(ctx, key)
+	}
+
+	return runWithRetry(ctx, watchPolicy(&config.Common), "watch", func() error {
+		err := rdb.Watch(ctx, func(tx *redis.Tx) error {
+			return fn(&watchTx{tx: tx, config: config})
+		}, prefixedKeys...)
+		if err == redis.TxFailedErr {
+			return ErrTxFailed
+		}
+		return err
+	}, func(error) {}, func(err error) bool {
+		return err == ErrTxFailed
+	})
+}